@@ -11,19 +11,20 @@ import (
 )
 
 // appCommand creates the app subcommand for launching the TUI.
-func appCommand() *cobra.Command {
+func appCommand(lib library.Library) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "app",
 		Short: "Launch the GraphQL schema explorer TUI",
 		Long: `Opens the interactive TUI for exploring GraphQL schemas.
 
-With no schema flag, opens the library selector to choose from saved schemas.
+With no schema flag, opens the schema of the project config in the working directory
+(see 'gqlxp project'), or else the library selector to choose from saved schemas.
 Use --schema/-s to open a specific schema file or library ID.`,
 		Example: `  gqlxp app                             # Open library selector
   gqlxp app -s examples/github.graphqls # Open specific schema file
   gqlxp app -s github-api               # Open schema from library`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return executeTUICommand(cmd)
+			return executeTUICommand(cmd, lib)
 		},
 		SilenceErrors: true,
 		SilenceUsage:  true,
@@ -31,49 +32,59 @@ Use --schema/-s to open a specific schema file or library ID.`,
 
 	cmd.Flags().StringP("log-file", "l", "", "Enable debug logging to `FILE`")
 	cmd.Flags().String("select", "", "Pre-select TYPE or TYPE.FIELD in TUI")
+	cmd.Flags().StringArrayP("header", "H", nil, "HTTP header for running operations (e.g., 'Authorization: Bearer token')")
 
 	return cmd
 }
 
 // executeTUICommand is the shared logic for launching the TUI,
 // used by both the root command and app subcommand.
-func executeTUICommand(cmd *cobra.Command) error {
+func executeTUICommand(cmd *cobra.Command, lib library.Library) error {
 	logFile, _ := cmd.Flags().GetString("log-file")
-	setupLogging(logFile)
+	if err := tui.SetupLogging(logFile); err != nil {
+		return fmt.Errorf("error opening log file: %w", err)
+	}
 
 	schemaArg, _ := cmd.Flags().GetString("schema")
 	selectTarget, _ := cmd.Flags().GetString("select")
+	headers, _ := cmd.Flags().GetStringArray("header")
 
-	// No schema specified - open library selector
+	// No schema specified - use the project's schema or open library selector
 	if schemaArg == "" {
-		return openLibrarySelector()
+		projectArg, err := NewSchemaLoader(lib, terminalPrompter{}).projectSchemaArg()
+		if err != nil {
+			return err
+		}
+		if projectArg == "" {
+			return openLibrarySelector(lib, headers)
+		}
+		schemaArg = projectArg
 	}
 
 	// Load schema from file or library
-	return loadAndStartFromFile(schemaArg, selectTarget)
+	return loadAndStartFromFile(lib, schemaArg, selectTarget, headers)
 }
 
-func openLibrarySelector() error {
-	lib := library.NewLibrary()
+func openLibrarySelector(lib library.Library, headers []string) error {
 	schemas, err := lib.List()
 	if err != nil {
 		return fmt.Errorf("error checking library: %w", err)
 	}
 
 	if len(schemas) == 0 {
-		abort("No schemas in library. Usage: gqlxp <schema-file>")
+		return fmt.Errorf("no schemas in library. Usage: gqlxp <schema-file>")
 	}
 
 	// Library has schemas - open selector
-	if _, err := tui.StartSchemaSelector(); err != nil {
+	if _, err := tui.StartSchemaSelector(lib, headers); err != nil {
 		return fmt.Errorf("error starting library selector: %w", err)
 	}
 	return nil
 }
 
-func loadAndStartFromFile(schemaFile, selectTarget string) error {
+func loadAndStartFromFile(lib library.Library, schemaFile, selectTarget string, headers []string) error {
 	// Resolve schema argument (path, ID, or default)
-	schema, err := LoadSchema(schemaFile)
+	schema, err := NewSchemaLoader(lib, terminalPrompter{}).Load(schemaFile)
 	if err != nil {
 		return fmt.Errorf("error resolving schema: %w", err)
 	}
@@ -81,7 +92,6 @@ func loadAndStartFromFile(schemaFile, selectTarget string) error {
 	schemaView := adapters.NewSchemaView(schema.GQLSchema)
 
 	// Ensure search index exists before launching TUI
-	lib := library.NewLibrary()
 	_ = lib.EnsureIndex(schema.ID, &schema.GQLSchema)
 
 	// Load metadata for TUI
@@ -98,12 +108,12 @@ func loadAndStartFromFile(schemaFile, selectTarget string) error {
 			TypeName:  typeName,
 			FieldName: fieldName,
 		}
-		if _, err := tui.StartWithSelection(schemaView, schema.ID, libSchema.Metadata, target); err != nil {
+		if _, err := tui.StartWithSelection(lib, schemaView, schema.ID, libSchema.Metadata, target, headers); err != nil {
 			return fmt.Errorf("error starting tui: %w", err)
 		}
 	} else {
 		// Start normally without selection
-		if _, err := tui.StartWithLibraryData(schemaView, schema.ID, libSchema.Metadata); err != nil {
+		if _, err := tui.StartWithLibraryData(lib, schemaView, schema.ID, libSchema.Metadata, headers); err != nil {
 			return fmt.Errorf("error starting tui: %w", err)
 		}
 	}
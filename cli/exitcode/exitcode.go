@@ -0,0 +1,43 @@
+// Package exitcode lets commands choose the exit status of the process without exiting
+// themselves, so that the program can finish its background work before it exits.
+package exitcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error is returned by a command that has already reported its outcome, such as validation
+// errors or schema drift, to exit with Code without printing an error message.
+type Error struct {
+	Code int
+}
+
+// New returns an Error exiting with code.
+func New(code int) error {
+	return &Error{Code: code}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// Is reports whether err is, or wraps, an Error.
+func Is(err error) bool {
+	var exitErr *Error
+	return errors.As(err, &exitErr)
+}
+
+// FromError returns the exit status for the error returned by a command: 0 on success,
+// the code of an Error, and 1 for any other error.
+func FromError(err error) int {
+	var exitErr *Error
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr):
+		return exitErr.Code
+	default:
+		return 1
+	}
+}
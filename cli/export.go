@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+// Supported values of the export --format flag.
+const (
+	exportFormatSDL           = "sdl"
+	exportFormatIntrospection = "introspection"
+)
+
+func exportCommand(lib library.Library) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a schema as SDL or introspection JSON",
+		Long: `Exports a library schema in the requested format.
+
+Uses default schema when --schema is not specified.
+
+Formats:
+  sdl            GraphQL schema definition language (default)
+  introspection  Introspection result JSON ({"__schema": ...}), as accepted by
+                 tools that consume schema.json files`,
+		Example: `  gqlxp export -s github > schema.graphqls
+  gqlxp export -s github --format introspection -o schema.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemaArg, _ := cmd.Flags().GetString("schema")
+			format, _ := cmd.Flags().GetString("format")
+			output, _ := cmd.Flags().GetString("output")
+			// Check the format before creating the output file, so that it's not truncated
+			if err := validateExportFormat(format); err != nil {
+				return err
+			}
+
+			schema, err := NewSchemaLoader(lib, terminalPrompter{}).Load(schemaArg)
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				return exportSchema(os.Stdout, schema, format)
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			if err := exportSchema(f, schema, format); err != nil {
+				_ = f.Close()
+				return err
+			}
+			return f.Close()
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	cmd.Flags().String("format", exportFormatSDL, "output format: sdl or introspection")
+	cmd.Flags().StringP("output", "o", "", "write to `FILE` instead of stdout")
+
+	return cmd
+}
+
+// validateExportFormat returns an error if format is not a supported export format.
+func validateExportFormat(format string) error {
+	switch format {
+	case exportFormatSDL, exportFormatIntrospection:
+		return nil
+	default:
+		return fmt.Errorf("unknown format '%s': expected %s or %s", format, exportFormatSDL, exportFormatIntrospection)
+	}
+}
+
+// exportSchema writes schema to w in the given format.
+func exportSchema(w io.Writer, schema LoadedSchema, format string) error {
+	switch format {
+	case exportFormatSDL:
+		_, err := w.Write(schema.Content)
+		return err
+	case exportFormatIntrospection:
+		return writeIntrospectionJSON(w, schema.GQLSchema)
+	default:
+		return validateExportFormat(format)
+	}
+}
+
+func writeIntrospectionJSON(w io.Writer, schema gql.GraphQLSchema) error {
+	resp := introspection.FromSchema(schema)
+	data, err := json.MarshalIndent(resp.Data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal introspection JSON: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestExportCommand_UnknownFormatKeepsOutputFile(t *testing.T) {
+	is := is.New(t)
+	output := filepath.Join(t.TempDir(), "schema.json")
+	is.NoErr(os.WriteFile(output, []byte("existing"), 0644))
+
+	cmd := exportCommand(nil)
+	cmd.SetArgs([]string{"--format", "bogus", "-o", output})
+	err := cmd.Execute()
+
+	is.True(err != nil) // unknown format is rejected
+	content, err := os.ReadFile(output)
+	is.NoErr(err)
+	is.Equal(string(content), "existing") // output file is not truncated
+}
@@ -5,9 +5,10 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/tonysyu/gqlxp/gqlfmt"
+	"github.com/tonysyu/gqlxp/library"
 )
 
-func generateCommand() *cobra.Command {
+func generateCommand(lib library.Library) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "generate <Query.fieldName|Mutation.fieldName>",
 		Short: "Generate a skeleton GraphQL operation",
@@ -30,7 +31,7 @@ Use 'gqlxp show <type>' to inspect type definitions before generating.`,
 			depth, _ := cmd.Flags().GetInt("depth")
 			includeDeprecated, _ := cmd.Flags().GetBool("include-deprecated")
 
-			schema, err := LoadSchema(schemaArg)
+			schema, err := NewSchemaLoader(lib, terminalPrompter{}).Load(schemaArg)
 			if err != nil {
 				return err
 			}
@@ -11,30 +11,29 @@ import (
 	"github.com/spf13/cobra"
 	clilib "github.com/tonysyu/gqlxp/cli/library"
 	"github.com/tonysyu/gqlxp/cli/prompt"
+	"github.com/tonysyu/gqlxp/gql/introspection"
 	"github.com/tonysyu/gqlxp/library"
 )
 
 // Command creates the init subcommand.
-func Command() *cobra.Command {
+func Command(lib library.Library) *cobra.Command {
 	return &cobra.Command{
 		Use:   "init",
 		Short: "Interactive setup wizard for gqlxp",
 		Long: `Walks you through configuring a schema and optionally
 installing the /gqlxp skill for AI assistants like Claude Code.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runInitWizard(cmd.Context())
+			return runInitWizard(cmd.Context(), lib)
 		},
 		SilenceErrors: true,
 		SilenceUsage:  true,
 	}
 }
 
-func runInitWizard(ctx context.Context) error {
+func runInitWizard(ctx context.Context, lib library.Library) error {
 	fmt.Println("Welcome to gqlxp!")
 	fmt.Println()
 
-	lib := library.NewLibrary()
-
 	// Step 1: Schema setup (only if library is empty)
 	schemas, err := lib.List()
 	if err != nil {
@@ -70,7 +69,7 @@ func addNewSchema(ctx context.Context, lib library.Library) (string, error) {
 		return "", fmt.Errorf("schema source is required")
 	}
 
-	content, _, err := clilib.LoadSchemaContent(ctx, source, nil)
+	content, _, err := clilib.LoadSchemaContent(ctx, source, introspection.DefaultClientOptions())
 	if err != nil {
 		return "", err
 	}
@@ -93,6 +92,9 @@ func addNewSchema(ctx context.Context, lib library.Library) (string, error) {
 	if err := lib.AddFromContent(schemaID, displayName, content, source); err != nil {
 		return "", err
 	}
+	if err := lib.WaitForIndexing(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
 
 	fmt.Printf("Added schema '%s' to library\n", schemaID)
 	return schemaID, nil
@@ -11,24 +11,42 @@ import (
 	"github.com/tonysyu/gqlxp/library"
 )
 
-func addCommand() *cobra.Command {
+func addCommand(lib library.Library) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add <schema-file-or-url>",
 		Short: "Add a schema to the library from a file or URL",
-		Args:  cobra.ExactArgs(1),
+		Long: `Adds a schema to the library from a file or URL.
+
+For URLs, headers, --timeout, and --method are saved in the schema's connection profile
+and reused by 'library update', 'query', and the TUI. Secret header values are only saved
+as references, ${env:NAME} or ${file:path}, which are resolved each time a request is made.`,
+		Example: `  gqlxp library add ./schema.graphqls
+  gqlxp library add --id github -H 'Authorization: Bearer ${env:GITHUB_TOKEN}' https://api.github.com/graphql
+  gqlxp library add --id internal -H 'X-Api-Key: ${file:~/.tokens/internal}' --timeout 10s https://internal.example.com/graphql`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			source := args[0]
-			lib := library.NewLibrary()
+			defer waitForIndexing(lib)
 
 			var content []byte
 			var sourceInfo string
 			var err error
 
+			// Headers are saved without secrets; the request uses every header given
 			headers, _ := cmd.Flags().GetStringArray("header")
+			var profile library.ConnectionProfile
 
 			if introspection.IsURL(source) {
-				content, err = fetchSchemaFromURL(ctx, source, headers)
+				profile, err = connectionProfileFromFlags(cmd, library.ConnectionProfile{})
+				if err != nil {
+					return err
+				}
+				opts, err := profile.ClientOptions(headers)
+				if err != nil {
+					return err
+				}
+				content, err = fetchSchemaFromURL(ctx, source, opts)
 				if err != nil {
 					return err
 				}
@@ -82,22 +100,31 @@ func addCommand() *cobra.Command {
 					return err
 				}
 
-				// Update display name if it differs from existing
-				existing, err := lib.Get(schemaID)
+				// Update display name and connection profile
+				connection := profileOrNil(profile)
+				err := lib.ModifyMetadata(schemaID, func(metadata *library.SchemaMetadata) error {
+					metadata.DisplayName = displayName
+					metadata.Connection = connection
+					return nil
+				})
 				if err != nil {
 					return err
 				}
-				if existing.Metadata.DisplayName != displayName {
-					existing.Metadata.DisplayName = displayName
-					if err := lib.UpdateMetadata(schemaID, existing.Metadata); err != nil {
-						return err
-					}
-				}
 
 				fmt.Printf("Updated schema '%s' (%s) in library\n", schemaID, displayName)
 				return nil
 			}
 
+			if connection := profileOrNil(profile); connection != nil {
+				err := lib.ModifyMetadata(schemaID, func(metadata *library.SchemaMetadata) error {
+					metadata.Connection = connection
+					return nil
+				})
+				if err != nil {
+					return fmt.Errorf("failed to save connection profile: %w", err)
+				}
+			}
+
 			fmt.Printf("Added schema '%s' (%s) to library\n", schemaID, displayName)
 			return nil
 		},
@@ -107,7 +134,7 @@ func addCommand() *cobra.Command {
 
 	cmd.Flags().String("id", "", "schema ID (lowercase letters, numbers, hyphens)")
 	cmd.Flags().String("name", "", "display name for the schema")
-	cmd.Flags().StringArrayP("header", "H", nil, "HTTP header for URL requests (e.g., 'Authorization: Bearer token')")
+	addConnectionFlags(cmd)
 
 	return cmd
 }
@@ -0,0 +1,144 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+// runBulkUpdate refreshes every schema in the library, or those tagged tag, and prints a
+// summary table.
+func runBulkUpdate(cmd *cobra.Command, lib library.Library, tag string) error {
+	for _, name := range []string{"header", "timeout", "method", "remove-header"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("cannot use --%s with --all or --tag: connection settings are per schema", name)
+		}
+	}
+	staleOnly, _ := cmd.Flags().GetBool("stale")
+	workers, _ := cmd.Flags().GetInt("workers")
+	timeout, _ := cmd.Flags().GetDuration("schema-timeout")
+	if workers < 1 {
+		return fmt.Errorf("--workers must be at least 1")
+	}
+
+	schemas, err := lib.List()
+	if err != nil {
+		return fmt.Errorf("failed to list schemas: %w", err)
+	}
+	if tag != "" {
+		if schemas = library.FilterByTag(schemas, tag); len(schemas) == 0 {
+			return fmt.Errorf("no schemas tagged '%s'", tag)
+		}
+	}
+	if staleOnly {
+		staleAfterDays, err := lib.GetStaleAfterDays()
+		if err != nil {
+			return err
+		}
+		if staleAfterDays == 0 {
+			return fmt.Errorf("no staleness threshold set: run 'gqlxp library stale-after <days>' first")
+		}
+		now := time.Now()
+		var stale []library.SchemaInfo
+		for _, schema := range schemas {
+			if library.IsStale(schema.UpdatedAt, staleAfterDays, now) {
+				stale = append(stale, schema)
+			}
+		}
+		schemas = stale
+	}
+
+	var ids []string
+	for _, schema := range schemas {
+		ids = append(ids, schema.ID)
+	}
+	if len(ids) == 0 {
+		fmt.Println("No schemas to update")
+		return nil
+	}
+
+	fmt.Printf("Updating %d schema(s)...\n", len(ids))
+	results := library.RefreshSchemas(cmd.Context(), lib, ids, library.RefreshOptions{
+		Workers: workers,
+		Timeout: timeout,
+	})
+	waitForIndexing(lib)
+
+	counts := printRefreshResults(results)
+	if counts[library.RefreshFailed] > 0 {
+		return fmt.Errorf("%d schema(s) failed to update", counts[library.RefreshFailed])
+	}
+	return nil
+}
+
+// printRefreshResults prints a table of refresh results followed by a count of each
+// status, and returns the counts.
+func printRefreshResults(results []library.RefreshResult) map[library.RefreshStatus]int {
+	counts := make(map[library.RefreshStatus]int)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SCHEMA\tSTATUS\tDETAILS")
+	for _, result := range results {
+		counts[result.Status]++
+		details := ""
+		if result.Err != nil {
+			details = result.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.ID, result.Status, details)
+	}
+	_ = w.Flush()
+
+	fmt.Printf("%d updated, %d unchanged, %d skipped, %d failed\n",
+		counts[library.RefreshUpdated], counts[library.RefreshUnchanged],
+		counts[library.RefreshSkipped], counts[library.RefreshFailed])
+	return counts
+}
+
+func staleAfterCommand(lib library.Library) *cobra.Command {
+	return &cobra.Command{
+		Use:   "stale-after [days]",
+		Short: "Show or set the age after which schemas are stale",
+		Long: `Sets how many days after their last update schemas are considered stale. Stale
+schemas are flagged by 'library list' and the schema selector, which offers to refresh
+them, and can be refreshed with 'gqlxp library update --all --stale'.
+
+Run without arguments to show the current threshold; 0 turns staleness off.`,
+		Example: `  gqlxp library stale-after 7
+  gqlxp library update --all --stale`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				days, err := lib.GetStaleAfterDays()
+				if err != nil {
+					return err
+				}
+				if days == 0 {
+					fmt.Println("No staleness threshold set")
+				} else {
+					fmt.Printf("Schemas are stale after %d day(s)\n", days)
+				}
+				return nil
+			}
+
+			days, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid number of days '%s'", args[0])
+			}
+			if err := lib.SetStaleAfterDays(days); err != nil {
+				return err
+			}
+			if days == 0 {
+				fmt.Println("Staleness threshold cleared")
+			} else {
+				fmt.Printf("Schemas are stale after %d day(s)\n", days)
+			}
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+}
@@ -0,0 +1,143 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func exportCommand(lib library.Library) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [schema-id...] -o <bundle.tar.gz>",
+		Short: "Export schemas to a bundle file",
+		Long: `Packages library schemas into a bundle (a .tar.gz file) that can be imported into
+another library with 'gqlxp library import'. Exports the whole library when no schema
+IDs or --tag are given.
+
+Bundles include schema content, display names, descriptions, tags, URL patterns,
+environments, and connection profiles. Headers that carry or reference secrets are left
+out and listed, so they can be re-added after import with 'gqlxp library update -H'.
+Environment snapshots are included with --snapshots.`,
+		Example: `  gqlxp library export -o team.tar.gz
+  gqlxp library export billing users -o services.tar.gz
+  gqlxp library export --tag payments --snapshots -o payments.tar.gz`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			tag, _ := cmd.Flags().GetString("tag")
+			snapshots, _ := cmd.Flags().GetBool("snapshots")
+			if output == "" {
+				return fmt.Errorf("an output file is required: use -o <bundle.tar.gz>")
+			}
+
+			ids := args
+			if tag != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("cannot use schema IDs with --tag")
+				}
+				var err error
+				if ids, err = library.SchemaIDsWithTag(lib, tag); err != nil {
+					return err
+				}
+			}
+			for _, id := range ids {
+				if _, err := lib.Get(id); err != nil {
+					return schemaNotFoundError(lib, id)
+				}
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create bundle file: %w", err)
+			}
+			result, err := library.ExportBundle(lib, f, ids, library.ExportOptions{IncludeSnapshots: snapshots})
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(output)
+				return fmt.Errorf("failed to export schemas: %w", err)
+			}
+
+			fmt.Printf("Exported %d schema(s) to %s: %s\n", len(result.SchemaIDs), output, strings.Join(result.SchemaIDs, ", "))
+			refs := make([]string, 0, len(result.RemovedHeaders))
+			for ref := range result.RemovedHeaders {
+				refs = append(refs, ref)
+			}
+			sort.Strings(refs)
+			for _, ref := range refs {
+				fmt.Printf("  %s: left out secret headers %s\n", ref, strings.Join(result.RemovedHeaders[ref], ", "))
+			}
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	cmd.Flags().StringP("output", "o", "", "write the bundle to `FILE`")
+	cmd.Flags().String("tag", "", "export the schemas with this tag")
+	cmd.Flags().Bool("snapshots", false, "include fetched environment snapshots")
+
+	return cmd
+}
+
+func importCommand(lib library.Library) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <bundle.tar.gz>",
+		Short: "Import schemas from a bundle file",
+		Long: `Adds the schemas in a bundle created by 'gqlxp library export' to the library and
+rebuilds their search indexes.
+
+When a schema ID is already in use, --on-conflict decides what happens:
+  skip       keep the existing schema (default)
+  rename     import the bundled schema under a new ID, e.g. billing-2
+  overwrite  replace the existing schema, including its environments`,
+		Example: `  gqlxp library import team.tar.gz
+  gqlxp library import team.tar.gz --on-conflict overwrite`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			onConflict, _ := cmd.Flags().GetString("on-conflict")
+			policy, err := library.ParseConflictPolicy(onConflict)
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open bundle: %w", err)
+			}
+			defer f.Close()
+
+			fmt.Printf("Importing %s:\n", args[0])
+			imported, err := library.ImportBundle(lib, f, library.ImportOptions{OnConflict: policy})
+			printImported(imported)
+			if err != nil {
+				return fmt.Errorf("failed to import bundle: %w", err)
+			}
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	cmd.Flags().String("on-conflict", string(library.ConflictSkip), "what to do when a schema ID is in use: skip, rename, or overwrite")
+
+	return cmd
+}
+
+// printImported prints the outcome of importing each bundled schema.
+func printImported(imported []library.ImportedSchema) {
+	for _, schema := range imported {
+		switch schema.Status {
+		case library.ImportRenamed:
+			fmt.Printf("  %s: renamed to '%s' (ID in use)\n", schema.BundleID, schema.ID)
+		case library.ImportSkipped:
+			fmt.Printf("  %s: skipped (ID in use; see --on-conflict)\n", schema.BundleID)
+		default:
+			fmt.Printf("  %s: %s\n", schema.ID, schema.Status)
+		}
+	}
+}
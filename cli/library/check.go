@@ -0,0 +1,191 @@
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/cli/exitcode"
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+// Exit codes of 'library check'. Other errors exit with code 1.
+const (
+	checkExitDrifted     = 2
+	checkExitUnreachable = 3
+)
+
+func checkCommand(lib library.Library) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check [schema-id...]",
+		Short: "Check whether schemas match their sources",
+		Long: `Fetches the source of each schema (its URL or source file) and compares it with the
+stored schema, without updating the library. Checks every schema with a source when no
+schema IDs or --tag are given. Environments are checked given <schema-id>@<env>.
+
+Drifted schemas are listed with their changes: + added in the source, - removed, ~ changed.
+
+Exit codes:
+  0  all checked schemas are in sync
+  2  at least one schema drifted from its source
+  3  at least one source could not be reached (takes precedence over 2)
+  1  any other error`,
+		Example: `  gqlxp library check
+  gqlxp library check billing api@staging
+  gqlxp library check --tag payments --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tag, _ := cmd.Flags().GetString("tag")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			workers, _ := cmd.Flags().GetInt("workers")
+			timeout, _ := cmd.Flags().GetDuration("schema-timeout")
+			if workers < 1 {
+				return fmt.Errorf("--workers must be at least 1")
+			}
+
+			ids, err := checkedSchemaIDs(lib, args, tag)
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				fmt.Println("No schemas with a source to check")
+				return nil
+			}
+
+			results := library.CheckDrift(cmd.Context(), lib, ids, library.RefreshOptions{
+				Workers: workers,
+				Timeout: timeout,
+			})
+			if jsonOutput {
+				if err := printDriftJSON(results); err != nil {
+					return err
+				}
+			} else {
+				printDriftResults(results)
+			}
+
+			if code := checkExitCode(results); code != 0 {
+				return exitcode.New(code)
+			}
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	cmd.Flags().String("tag", "", "check the schemas with this tag")
+	cmd.Flags().Bool("json", false, "output results as JSON")
+	cmd.Flags().Int("workers", library.DefaultRefreshWorkers, "number of schemas checked at once")
+	cmd.Flags().Duration("schema-timeout", time.Minute, "time limit for fetching each source")
+
+	return cmd
+}
+
+// checkedSchemaIDs returns the schemas to check: those given, those tagged tag, or every
+// personal schema with a source.
+func checkedSchemaIDs(lib library.Library, args []string, tag string) ([]string, error) {
+	if tag != "" && len(args) > 0 {
+		return nil, fmt.Errorf("cannot use schema IDs with --tag")
+	}
+	if len(args) > 0 {
+		for _, ref := range args {
+			id, _ := library.ParseSchemaRef(ref)
+			if _, err := lib.Get(id); err != nil {
+				return nil, schemaNotFoundError(lib, id)
+			}
+		}
+		return args, nil
+	}
+	if tag != "" {
+		return library.SchemaIDsWithTag(lib, tag)
+	}
+
+	schemas, err := lib.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schemas: %w", err)
+	}
+	var ids []string
+	for _, info := range schemas {
+		if info.Provenance == library.ProvenanceShared {
+			continue
+		}
+		schema, err := lib.Get(info.ID)
+		if err != nil {
+			return nil, err
+		}
+		if schema.Metadata.SourceURL != "" || schema.Metadata.SourceFile != "" {
+			ids = append(ids, info.ID)
+		}
+	}
+	return ids, nil
+}
+
+func printDriftResults(results []library.DriftResult) {
+	counts := make(map[library.DriftStatus]int)
+	for _, result := range results {
+		counts[result.Status]++
+		switch result.Status {
+		case library.DriftInSync:
+			fmt.Printf("%s: in sync with %s\n", result.ID, result.Source)
+		case library.DriftDrifted:
+			if len(result.Changes) == 0 {
+				fmt.Printf("%s: drifted from %s (formatting or descriptions only)\n", result.ID, result.Source)
+				continue
+			}
+			fmt.Printf("%s: drifted from %s (%d change(s))\n", result.ID, result.Source, len(result.Changes))
+			for _, c := range result.Changes {
+				fmt.Printf("  %s\n", formatChange(c))
+			}
+		default:
+			fmt.Printf("%s: %s: %v\n", result.ID, result.Status, result.Err)
+		}
+	}
+	fmt.Printf("%d in sync, %d drifted, %d unreachable, %d skipped\n",
+		counts[library.DriftInSync], counts[library.DriftDrifted],
+		counts[library.DriftUnreachable], counts[library.DriftSkipped])
+}
+
+// driftJSON is the JSON output of a drift check of one schema.
+type driftJSON struct {
+	ID      string       `json:"id"`
+	Status  string       `json:"status"`
+	Source  string       `json:"source,omitempty"`
+	Changes []gql.Change `json:"changes,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+func printDriftJSON(results []library.DriftResult) error {
+	out := make([]driftJSON, len(results))
+	for i, result := range results {
+		out[i] = driftJSON{
+			ID:      result.ID,
+			Status:  string(result.Status),
+			Source:  result.Source,
+			Changes: result.Changes,
+		}
+		if result.Err != nil {
+			out[i].Error = result.Err.Error()
+		}
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal to JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// checkExitCode returns the exit code for a drift check.
+func checkExitCode(results []library.DriftResult) int {
+	code := 0
+	for _, result := range results {
+		switch result.Status {
+		case library.DriftUnreachable:
+			return checkExitUnreachable
+		case library.DriftDrifted:
+			code = checkExitDrifted
+		}
+	}
+	return code
+}
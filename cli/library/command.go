@@ -15,7 +15,7 @@ import (
 )
 
 // Command creates the library subcommand.
-func Command() *cobra.Command {
+func Command(lib library.Library) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "library",
 		Short: "Manage schema library",
@@ -25,19 +25,35 @@ Run 'gqlxp library list' to see all schemas and their last-updated timestamps.
 If any schema is out of date, run 'gqlxp library update <schema-id>' to refresh it.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Default action is to list schemas
-			return runLibraryList()
+			return runLibraryList(lib, "")
 		},
 		SilenceErrors: true,
 		SilenceUsage:  true,
 	}
 
 	cmd.AddCommand(
-		listCommand(),
-		addCommand(),
-		updateCommand(),
-		removeCommand(),
-		defaultCommand(),
-		reindexCommand(),
+		listCommand(lib),
+		addCommand(lib),
+		scanCommand(lib),
+		updateCommand(lib),
+		removeCommand(lib),
+		renameCommand(lib),
+		cloneCommand(lib),
+		defaultCommand(lib),
+		reindexCommand(lib),
+		envCommand(lib),
+		diffCommand(lib),
+		checkCommand(lib),
+		tagCommand(lib),
+		untagCommand(lib),
+		describeCommand(lib),
+		exportCommand(lib),
+		importCommand(lib),
+		mountCommand(lib),
+		unmountCommand(lib),
+		syncCommand(lib),
+		staleAfterCommand(lib),
+		doctorCommand(lib),
 	)
 
 	return cmd
@@ -50,9 +66,10 @@ type schemaSource struct {
 }
 
 // LoadSchemaContent loads schema content from a file path or URL.
-func LoadSchemaContent(ctx context.Context, source string, headers []string) ([]byte, schemaSource, error) {
+// opts configures the request when source is a URL.
+func LoadSchemaContent(ctx context.Context, source string, opts introspection.ClientOptions) ([]byte, schemaSource, error) {
 	if introspection.IsURL(source) {
-		content, err := fetchSchemaFromURL(ctx, source, headers)
+		content, err := fetchSchemaFromURL(ctx, source, opts)
 		if err != nil {
 			return nil, schemaSource{}, err
 		}
@@ -72,20 +89,7 @@ func LoadSchemaContent(ctx context.Context, source string, headers []string) ([]
 }
 
 // fetchSchemaFromURL fetches a GraphQL schema via introspection from the given URL.
-func fetchSchemaFromURL(ctx context.Context, endpoint string, headers []string) ([]byte, error) {
-	opts := introspection.DefaultClientOptions()
-
-	// Parse and add custom headers
-	if len(headers) > 0 {
-		customHeaders, err := introspection.ParseHeaders(headers)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse headers: %w", err)
-		}
-		for k, v := range customHeaders {
-			opts.Headers[k] = v
-		}
-	}
-
+func fetchSchemaFromURL(ctx context.Context, endpoint string, opts introspection.ClientOptions) ([]byte, error) {
 	fmt.Printf("Fetching schema from %s...\n", endpoint)
 
 	resp, err := introspection.FetchSchema(ctx, endpoint, opts)
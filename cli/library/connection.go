@@ -0,0 +1,62 @@
+package library
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+// addConnectionFlags registers the flags that configure a schema's connection profile.
+func addConnectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayP("header", "H", nil, "HTTP header for URL requests (e.g., 'Authorization: Bearer ${env:TOKEN}')")
+	cmd.Flags().Duration("timeout", 0, "request timeout saved in the connection profile (e.g., 10s)")
+	cmd.Flags().String("method", "", "HTTP method saved in the connection profile (GET or POST)")
+}
+
+// connectionProfileFromFlags returns base with the connection flags of cmd applied.
+// Sensitive headers with literal values are used for the current request but not saved.
+func connectionProfileFromFlags(cmd *cobra.Command, base library.ConnectionProfile) (library.ConnectionProfile, error) {
+	headers, _ := cmd.Flags().GetStringArray("header")
+	profile, skipped, err := base.WithHeaders(headers)
+	if err != nil {
+		return base, err
+	}
+	for _, name := range skipped {
+		fmt.Printf("Not saving header '%s': use a reference like '${env:NAME}' or '${file:path}' to store secrets\n", name)
+	}
+
+	if cmd.Flags().Lookup("remove-header") != nil {
+		removed, _ := cmd.Flags().GetStringArray("remove-header")
+		for _, name := range removed {
+			for k := range profile.Headers {
+				if strings.EqualFold(k, name) {
+					delete(profile.Headers, k)
+				}
+			}
+		}
+		if len(profile.Headers) == 0 {
+			profile.Headers = nil
+		}
+	}
+	if timeout, _ := cmd.Flags().GetDuration("timeout"); timeout > 0 {
+		profile.Timeout = timeout.String()
+	}
+	if method, _ := cmd.Flags().GetString("method"); method != "" {
+		profile.Method = strings.ToUpper(method)
+	}
+
+	if err := profile.Validate(); err != nil {
+		return base, err
+	}
+	return profile, nil
+}
+
+// profileOrNil returns nil for an empty profile so that it is omitted from metadata.
+func profileOrNil(profile library.ConnectionProfile) *library.ConnectionProfile {
+	if profile.IsEmpty() {
+		return nil
+	}
+	return &profile
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/tonysyu/gqlxp/library"
 )
 
-func defaultCommand() *cobra.Command {
+func defaultCommand(lib library.Library) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "default [schema-id]",
 		Short: "Set or show the default schema",
@@ -16,8 +16,6 @@ func defaultCommand() *cobra.Command {
   gqlxp library default github    # Set default to 'github'
   gqlxp library default --clear   # Clear default setting`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			lib := library.NewLibrary()
-
 			// Clear default if --clear is used
 			clear, _ := cmd.Flags().GetBool("clear")
 			if clear {
@@ -0,0 +1,124 @@
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/cli/exitcode"
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func diffCommand(lib library.Library) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <schema> <schema>",
+		Short: "Show differences between two library schemas",
+		Long: `Shows the types, fields, enum values, and directives that differ between two
+library schemas, typically two environments of the same schema.
+
+Schemas are given as <schema-id> or <schema-id>@<env>.
+
+Lines are prefixed with + (added in the second schema), - (removed), or ~ (changed).`,
+		Example: `  gqlxp library diff api@staging api@prod
+  gqlxp library diff api api@staging --exit-code  # Exit with code 1 if schemas differ`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			exitCode, _ := cmd.Flags().GetBool("exit-code")
+
+			oldRef, newRef := args[0], args[1]
+			oldSchema, err := parseLibrarySchema(lib, oldRef)
+			if err != nil {
+				return err
+			}
+			newSchema, err := parseLibrarySchema(lib, newRef)
+			if err != nil {
+				return err
+			}
+
+			changes := gql.DiffSchemas(oldSchema, newSchema)
+			if jsonOutput {
+				if err := printChangesJSON(changes); err != nil {
+					return err
+				}
+			} else {
+				printChanges(oldRef, newRef, changes)
+			}
+
+			if exitCode && len(changes) > 0 {
+				return exitcode.New(1)
+			}
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	cmd.Flags().Bool("json", false, "output changes as JSON")
+	cmd.Flags().Bool("exit-code", false, "exit with code 1 if the schemas differ")
+
+	return cmd
+}
+
+// parseLibrarySchema loads and parses the library schema identified by ref.
+func parseLibrarySchema(lib library.Library, ref string) (*gql.GraphQLSchema, error) {
+	id, _ := library.ParseSchemaRef(ref)
+	schema, err := lib.Get(ref)
+	if err != nil {
+		if _, baseErr := lib.Get(id); baseErr != nil {
+			return nil, schemaNotFoundError(lib, id)
+		}
+		return nil, err
+	}
+	parsed, err := gql.ParseSchema(schema.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema '%s': %w", ref, err)
+	}
+	return &parsed, nil
+}
+
+func printChanges(oldRef, newRef string, changes []gql.Change) {
+	if len(changes) == 0 {
+		fmt.Printf("No differences between '%s' and '%s'\n", oldRef, newRef)
+		return
+	}
+	fmt.Printf("Differences from '%s' to '%s':\n", oldRef, newRef)
+	for _, c := range changes {
+		fmt.Println(formatChange(c))
+	}
+}
+
+// formatChange describes a change prefixed with +, -, or ~.
+func formatChange(c gql.Change) string {
+	switch c.Kind {
+	case gql.ChangeAdded:
+		return "+ " + describeMember(c.Path, c.New)
+	case gql.ChangeRemoved:
+		return "- " + describeMember(c.Path, c.Old)
+	default:
+		return fmt.Sprintf("~ %s -> %s", describeMember(c.Path, c.Old), describeMember(c.Path, c.New))
+	}
+}
+
+// describeMember qualifies a member definition with its parent type, e.g. "User.name: String".
+func describeMember(path, definition string) string {
+	parent, _, isMember := strings.Cut(path, ".")
+	if !isMember {
+		return definition
+	}
+	return parent + "." + definition
+}
+
+func printChangesJSON(changes []gql.Change) error {
+	if changes == nil {
+		changes = []gql.Change{}
+	}
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal to JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
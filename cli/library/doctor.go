@@ -0,0 +1,106 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func doctorCommand(lib library.Library) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the library for problems and repair them",
+		Long: `Audits the library for integrity problems:
+
+  missing-file       metadata entry (or fetched environment) without a schema file
+  orphan-file        schema file without a metadata entry or environment
+  hash-mismatch      schema file changed outside gqlxp
+  invalid-schema     stored schema that no longer parses
+  missing-index      schema without a search index
+  outdated-index     search index built by an earlier version of gqlxp
+  orphan-index       search index of no schema, or left over from an interrupted build
+  unreadable-source  source file that can no longer be read
+  missing-default    default schema that is not in the library
+
+With --fix, problems are repaired where possible: indexes are rebuilt, hashes are recorded,
+and dangling entries, snapshots, and indexes are removed. Invalid schemas and unreadable
+sources must be fixed by hand, e.g. with 'gqlxp library update'.
+
+Exits with code 1 if problems remain.`,
+		Example: `  gqlxp library doctor
+  gqlxp library doctor --fix`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fix, _ := cmd.Flags().GetBool("fix")
+
+			problems, err := library.Diagnose(lib)
+			if err != nil {
+				return err
+			}
+			if len(problems) == 0 {
+				fmt.Println("No problems found")
+				return nil
+			}
+			if !fix {
+				return printProblems(problems)
+			}
+			return repairProblems(lib, problems)
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	cmd.Flags().Bool("fix", false, "repair the problems that can be fixed automatically")
+
+	return cmd
+}
+
+// printProblems prints a table of problems and returns an error reporting their number.
+func printProblems(problems []library.Problem) error {
+	fixable := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROBLEM\tSCHEMA\tDETAILS\tFIX")
+	for _, problem := range problems {
+		fix := "fix by hand"
+		if problem.Fixable() {
+			fixable++
+			fix = problem.Fix
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", problem.Kind, problem.Ref, problem.Detail, fix)
+	}
+	_ = w.Flush()
+
+	if fixable > 0 {
+		fmt.Printf("%d fixable: run 'gqlxp library doctor --fix' to repair them\n", fixable)
+	}
+	return fmt.Errorf("%d problem(s) found", len(problems))
+}
+
+// repairProblems repairs the fixable problems, printing the outcome of each, and returns an
+// error if any problem remains.
+func repairProblems(lib library.Library, problems []library.Problem) error {
+	remaining := 0
+	for _, problem := range problems {
+		if !problem.Fixable() {
+			remaining++
+			fmt.Printf("%s %s: %s (fix by hand)\n", problem.Kind, problem.Ref, problem.Detail)
+			continue
+		}
+		if err := library.Repair(lib, problem); err != nil {
+			remaining++
+			fmt.Printf("%s %s: failed to %s: %v\n", problem.Kind, problem.Ref, problem.Fix, err)
+			continue
+		}
+		fmt.Printf("%s %s: fixed (%s)\n", problem.Kind, problem.Ref, problem.Fix)
+	}
+	waitForIndexing(lib)
+
+	if remaining > 0 {
+		return fmt.Errorf("%d problem(s) remain", remaining)
+	}
+	fmt.Printf("Fixed %d problem(s)\n", len(problems))
+	return nil
+}
@@ -0,0 +1,141 @@
+package library
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func envCommand(lib library.Library) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Manage the environments of a schema",
+		Long: `Manages named environments of a library schema, such as dev, staging, and prod.
+
+Each environment has its own URL and connection profile, and its own schema snapshot.
+Refresh a snapshot with 'gqlxp library update --id <schema-id> --env <name>', and
+load it anywhere a schema is accepted with '<schema-id>@<name>'.`,
+		Example: `  gqlxp library env set api staging https://staging.example.com/graphql
+  gqlxp library env list api
+  gqlxp -s api@staging search User`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	cmd.AddCommand(
+		envListCommand(lib),
+		envSetCommand(lib),
+		envRemoveCommand(lib),
+	)
+
+	return cmd
+}
+
+func envListCommand(lib library.Library) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list <schema-id>",
+		Short: "List the environments of a schema",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemaID := args[0]
+			schema, err := lib.Get(schemaID)
+			if err != nil {
+				return schemaNotFoundError(lib, schemaID)
+			}
+
+			names := schema.Metadata.EnvironmentNames()
+			if len(names) == 0 {
+				fmt.Printf("Schema '%s' has no environments. Add one with: gqlxp library env set %s <name> <url>\n", schemaID, schemaID)
+				return nil
+			}
+
+			fmt.Printf("Environments of '%s':\n", schemaID)
+			for _, name := range names {
+				env := schema.Metadata.Environments[name]
+				fmt.Printf("  %s (%s; %s)\n", name, env.SourceURL, formatFreshness(env.UpdatedAt))
+			}
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+}
+
+func envSetCommand(lib library.Library) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <schema-id> <name> <url>",
+		Short: "Add or change an environment of a schema",
+		Long: `Adds an environment to a schema, or changes the URL of an existing one.
+
+Headers, --timeout, and --method are saved in the environment's connection profile.
+Secret header values are only saved as references, ${env:NAME} or ${file:path}.`,
+		Example: `  gqlxp library env set api staging https://staging.example.com/graphql
+  gqlxp library env set api prod https://example.com/graphql -H 'Authorization: Bearer ${env:PROD_TOKEN}'`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemaID, name, endpoint := args[0], args[1], args[2]
+			if err := library.ValidateEnvironmentName(name); err != nil {
+				return err
+			}
+			if !introspection.IsURL(endpoint) {
+				return fmt.Errorf("invalid URL '%s': environments must use an http or https endpoint", endpoint)
+			}
+
+			if _, err := lib.Get(schemaID); err != nil {
+				return schemaNotFoundError(lib, schemaID)
+			}
+
+			var env library.Environment
+			err := lib.ModifyMetadata(schemaID, func(metadata *library.SchemaMetadata) error {
+				env = metadata.Environments[name]
+				profile, err := connectionProfileFromFlags(cmd, env.ConnectionProfile())
+				if err != nil {
+					return err
+				}
+				env.SourceURL = endpoint
+				env.Connection = profileOrNil(profile)
+
+				if metadata.Environments == nil {
+					metadata.Environments = make(map[string]library.Environment)
+				}
+				metadata.Environments[name] = env
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to save environment: %w", err)
+			}
+
+			fmt.Printf("Environment '%s' of schema '%s' set to %s\n", name, schemaID, endpoint)
+			if env.UpdatedAt.IsZero() {
+				fmt.Printf("Fetch its schema with: gqlxp library update --id %s --env %s\n", schemaID, name)
+			}
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	addConnectionFlags(cmd)
+
+	return cmd
+}
+
+func envRemoveCommand(lib library.Library) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <schema-id> <name>",
+		Short: "Remove an environment and its schema snapshot",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemaID, name := args[0], args[1]
+			if err := lib.Remove(library.SchemaRef(schemaID, name)); err != nil {
+				return fmt.Errorf("failed to remove environment: %w", err)
+			}
+			fmt.Printf("Removed environment '%s' from schema '%s'\n", name, schemaID)
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+}
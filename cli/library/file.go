@@ -3,12 +3,15 @@ package library
 import (
 	"fmt"
 	"os"
+
+	"github.com/tonysyu/gqlxp/gql/introspection"
 )
 
+// loadSchemaFromFile reads a schema file, converting introspection JSON results to SDL.
 func loadSchemaFromFile(path string) ([]byte, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read schema file: %w", err)
 	}
-	return content, nil
+	return introspection.EnsureSDL(content)
 }
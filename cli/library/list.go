@@ -3,25 +3,33 @@ package library
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tonysyu/gqlxp/library"
 )
 
-func listCommand() *cobra.Command {
-	return &cobra.Command{
+func listCommand(lib library.Library) *cobra.Command {
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all schemas in the library",
+		Example: `  gqlxp library list
+  gqlxp library list --tag payments`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runLibraryList()
+			tag, _ := cmd.Flags().GetString("tag")
+			return runLibraryList(lib, tag)
 		},
 		SilenceErrors: true,
 		SilenceUsage:  true,
 	}
+
+	cmd.Flags().String("tag", "", "only list schemas with this tag")
+
+	return cmd
 }
 
-func runLibraryList() error {
-	lib := library.NewLibrary()
+// runLibraryList prints the schemas in the library, or only those tagged tag if non-empty.
+func runLibraryList(lib library.Library, tag string) error {
 	schemas, err := lib.List()
 	if err != nil {
 		return fmt.Errorf("failed to list schemas: %w", err)
@@ -31,9 +39,18 @@ func runLibraryList() error {
 		fmt.Println("No schemas in library. Add one with: gqlxp library add <schema-file>")
 		return nil
 	}
+	if tag != "" {
+		schemas = library.FilterByTag(schemas, tag)
+		if len(schemas) == 0 {
+			fmt.Printf("No schemas tagged '%s'. Add a tag with: gqlxp library tag <schema-id> %s\n", tag, tag)
+			return nil
+		}
+	}
 
 	// Get default schema to mark it
 	defaultID, _ := lib.GetDefaultSchema()
+	staleAfterDays, _ := lib.GetStaleAfterDays()
+	now := time.Now()
 
 	fmt.Println("Schemas in library:")
 	for _, schema := range schemas {
@@ -46,14 +63,40 @@ func runLibraryList() error {
 			parts = append(parts, schema.DisplayName)
 		}
 		if !schema.UpdatedAt.IsZero() {
-			parts = append(parts, "last-updated: "+schema.UpdatedAt.Format("2006-01-02 15:04"))
+			parts = append(parts, formatFreshness(schema.UpdatedAt))
+		}
+		if library.IsStale(schema.UpdatedAt, staleAfterDays, now) {
+			parts = append(parts, "stale")
+		}
+		switch schema.Provenance {
+		case library.ProvenanceShared:
+			parts = append(parts, "shared")
+		case library.ProvenanceOverride:
+			parts = append(parts, "local override of shared")
+		}
+		if len(schema.Tags) > 0 {
+			parts = append(parts, "tags: "+strings.Join(schema.Tags, ", "))
 		}
 		if len(parts) > 0 {
 			fmt.Printf("%s %s (%s)\n", marker, schema.ID, strings.Join(parts, "; "))
 		} else {
 			fmt.Printf("%s %s\n", marker, schema.ID)
 		}
+		if schema.Description != "" {
+			fmt.Printf("    %s\n", schema.Description)
+		}
+		for _, env := range schema.Environments {
+			fmt.Printf("    %s (%s; %s)\n", library.SchemaRef(schema.ID, env.Name), env.SourceURL, formatFreshness(env.UpdatedAt))
+		}
 	}
 
 	return nil
 }
+
+// formatFreshness describes when a schema or environment snapshot was last updated.
+func formatFreshness(updatedAt time.Time) string {
+	if updatedAt.IsZero() {
+		return "not fetched"
+	}
+	return "last-updated: " + updatedAt.Format("2006-01-02 15:04")
+}
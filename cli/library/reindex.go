@@ -7,7 +7,15 @@ import (
 	"github.com/tonysyu/gqlxp/library"
 )
 
-func reindexCommand() *cobra.Command {
+// waitForIndexing waits for the background indexing started by a command before it exits,
+// warning about schemas that failed to index.
+func waitForIndexing(lib library.Library) {
+	if err := lib.WaitForIndexing(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
+func reindexCommand(lib library.Library) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "reindex [schema-id]",
 		Short: "Rebuild search indexes",
@@ -15,8 +23,6 @@ func reindexCommand() *cobra.Command {
 		Example: `  gqlxp library reindex github    # Reindex 'github' schema
   gqlxp library reindex --all     # Reindex all schemas`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			lib := library.NewLibrary()
-
 			// Reindex all schemas
 			all, _ := cmd.Flags().GetBool("all")
 			if all {
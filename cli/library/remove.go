@@ -8,20 +8,22 @@ import (
 	"github.com/tonysyu/gqlxp/library"
 )
 
-func removeCommand() *cobra.Command {
+func removeCommand(lib library.Library) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "remove <schema-id>",
 		Short: "Remove a schema from the library",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			schemaID := args[0]
-			lib := library.NewLibrary()
 
 			// Verify schema exists
 			schema, err := lib.Get(schemaID)
 			if err != nil {
 				return schemaNotFoundError(lib, schemaID)
 			}
+			if schema.Provenance == library.ProvenanceShared {
+				return fmt.Errorf("schema '%s' belongs to the read-only shared library; unmount it with 'gqlxp library unmount'", schemaID)
+			}
 
 			// Confirm removal unless --force is used
 			if err := confirmSchemaRemoval(cmd, schemaID, schema); err != nil {
@@ -37,6 +39,11 @@ func removeCommand() *cobra.Command {
 				return fmt.Errorf("failed to remove schema: %w", err)
 			}
 
+			if schema.Provenance == library.ProvenanceOverride {
+				waitForIndexing(lib)
+				fmt.Printf("Removed local override of '%s'; using the shared schema\n", schemaID)
+				return nil
+			}
 			fmt.Printf("Removed schema '%s' from library\n", schemaID)
 
 			// Clear default if necessary
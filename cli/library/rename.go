@@ -0,0 +1,66 @@
+package library
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func renameCommand(lib library.Library) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <schema-id> <new-id>",
+		Short: "Change the ID of a schema",
+		Long: `Changes the ID of a schema, keeping its content, environments, metadata (display name,
+tags, URL patterns, connection profiles, and timestamps), and default schema setting.
+The search index is rebuilt under the new ID.
+
+A local override of a shared schema can be renamed, which restores the shared schema
+under the old ID; shared schemas themselves can only be cloned.`,
+		Example: `  gqlxp library rename github github-api`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemaID, newID := args[0], args[1]
+
+			if _, err := lib.Get(schemaID); err != nil {
+				return schemaNotFoundError(lib, schemaID)
+			}
+			if err := lib.Rename(schemaID, newID); err != nil {
+				return fmt.Errorf("failed to rename schema: %w", err)
+			}
+			waitForIndexing(lib)
+
+			fmt.Printf("Renamed schema '%s' to '%s'\n", schemaID, newID)
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+}
+
+func cloneCommand(lib library.Library) *cobra.Command {
+	return &cobra.Command{
+		Use:   "clone <schema-id> <new-id>",
+		Short: "Copy a schema to a new ID",
+		Long: `Copies a schema, with its environments and metadata, to a new ID, e.g. to keep a
+snapshot before updating it. Cloning a shared schema copies it into the personal library.`,
+		Example: `  gqlxp library clone github github-before-upgrade`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemaID, newID := args[0], args[1]
+
+			if _, err := lib.Get(schemaID); err != nil {
+				return schemaNotFoundError(lib, schemaID)
+			}
+			if err := lib.Clone(schemaID, newID); err != nil {
+				return fmt.Errorf("failed to clone schema: %w", err)
+			}
+			waitForIndexing(lib)
+
+			fmt.Printf("Cloned schema '%s' to '%s'\n", schemaID, newID)
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+}
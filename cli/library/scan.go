@@ -0,0 +1,103 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/cli/prompt"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func scanCommand(lib library.Library) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scan <dir>",
+		Short: "Find the schemas in a directory and add them to the library",
+		Long: `Walks a directory, such as a monorepo, for schema sources:
+
+  sdl             .graphqls files, and .graphql files containing type definitions
+  introspection   JSON introspection results
+  gqlgen          the schema files of a gqlgen.yml
+  graphql-config  the schema files of a .graphqlrc, graphql.config.*, or .gqlxp.yaml,
+                  with one schema per project
+
+Hidden, node_modules, and vendor directories are skipped. Schema IDs are proposed from file
+or directory names, and the sources are shown in a table before they are added to the
+library. Sources that were added before are updated when they have changed.`,
+		Example: `  gqlxp library scan .
+  gqlxp library scan --dry-run ~/src/monorepo
+  gqlxp library scan --force services`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			force, _ := cmd.Flags().GetBool("force")
+
+			results, err := library.Scan(lib, args[0])
+			if err != nil {
+				return err
+			}
+			if len(results) == 0 {
+				fmt.Printf("No schemas found in %s\n", args[0])
+				return nil
+			}
+			counts := printScanResults(args[0], results)
+			changes := counts[library.ScanAdd] + counts[library.ScanUpdate]
+			if dryRun || changes == 0 {
+				return nil
+			}
+
+			if !force {
+				confirm, err := prompt.YesNo(fmt.Sprintf("Add %d and update %d schema(s)?",
+					counts[library.ScanAdd], counts[library.ScanUpdate]))
+				if err != nil {
+					return err
+				}
+				if !confirm {
+					return nil
+				}
+			}
+			defer waitForIndexing(lib)
+			if err := library.ApplyScan(lib, results); err != nil {
+				return err
+			}
+			fmt.Printf("Added %d and updated %d schema(s)\n", counts[library.ScanAdd], counts[library.ScanUpdate])
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	cmd.Flags().Bool("dry-run", false, "show the schemas found without changing the library")
+	cmd.Flags().Bool("force", false, "skip confirmation prompt")
+
+	return cmd
+}
+
+// printScanResults prints a table of scan results, with sources relative to the scanned
+// directory, followed by a count of each action, and returns the counts.
+func printScanResults(dir string, results []library.ScanResult) map[library.ScanAction]int {
+	absDir, _ := filepath.Abs(dir)
+	counts := make(map[library.ScanAction]int)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SCHEMA\tACTION\tKIND\tSOURCE\tDETAILS")
+	for _, result := range results {
+		counts[result.Action]++
+		source := result.Source
+		if rel, err := filepath.Rel(absDir, source); err == nil {
+			source = rel
+		}
+		details := ""
+		if result.Err != nil {
+			details = result.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", result.ID, result.Action, result.Kind, source, details)
+	}
+	_ = w.Flush()
+
+	fmt.Printf("%d to add, %d to update, %d unchanged, %d skipped\n",
+		counts[library.ScanAdd], counts[library.ScanUpdate],
+		counts[library.ScanUnchanged], counts[library.ScanSkip])
+	return counts
+}
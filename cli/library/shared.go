@@ -0,0 +1,141 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func mountCommand(lib library.Library) *cobra.Command {
+	return &cobra.Command{
+		Use:   "mount <dir>",
+		Short: "Mount a shared read-only schema library",
+		Long: `Mounts a directory of schemas, such as a git repository maintained by another team,
+as a read-only library alongside your own. The directory holds <schema-id>.graphqls
+files and an optional metadata.json with the same layout as the personal library.
+
+Shared schemas appear in 'library list', search, and the schema selector. Editing a
+shared schema (e.g. tagging or updating it) copies it into your library as a local
+override; remove the override with 'gqlxp library remove' to use the shared version again.
+
+The ` + library.SharedLibraryEnvVar + ` environment variable takes precedence over the mounted directory.`,
+		Example: `  gqlxp library mount ~/src/platform-schemas
+  gqlxp library sync`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := lib.MountSharedLibrary(args[0]); err != nil {
+				return err
+			}
+			result, err := lib.SyncShared()
+			if err != nil {
+				return fmt.Errorf("failed to index shared library: %w", err)
+			}
+			fmt.Printf("Mounted shared library %s\n", result.Dir)
+			printSyncResult(result)
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+}
+
+func unmountCommand(lib library.Library) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unmount",
+		Short: "Unmount the shared schema library",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := lib.MountSharedLibrary(""); err != nil {
+				return err
+			}
+			fmt.Println("Unmounted shared library; local overrides are kept")
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+}
+
+func syncCommand(lib library.Library) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Pull the shared schema library and reindex what changed",
+		Long: `Pulls the latest schemas into the shared library when it is in a git repository
+(with 'git pull --ff-only'), then rebuilds the search indexes of the shared schemas that
+were added or changed since the last sync.`,
+		Example: `  gqlxp library sync
+  gqlxp library sync --no-pull`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			noPull, _ := cmd.Flags().GetBool("no-pull")
+			dir, err := lib.SharedLibraryDir()
+			if err != nil {
+				return err
+			}
+			if dir == "" {
+				return fmt.Errorf("%w: run 'gqlxp library mount <dir>' first", library.ErrNoSharedLibrary)
+			}
+
+			if !noPull && isGitWorkTree(dir) {
+				fmt.Printf("Pulling %s...\n", dir)
+				pull := exec.Command("git", "-C", dir, "pull", "--ff-only")
+				pull.Stdout = os.Stdout
+				pull.Stderr = os.Stderr
+				if err := pull.Run(); err != nil {
+					return fmt.Errorf("failed to pull shared library: %w", err)
+				}
+			}
+
+			result, err := lib.SyncShared()
+			if err != nil {
+				return fmt.Errorf("failed to sync shared library: %w", err)
+			}
+			fmt.Printf("Synced shared library %s\n", result.Dir)
+			printSyncResult(result)
+			if len(result.Failed) > 0 {
+				return errors.New("some shared schemas could not be indexed")
+			}
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	cmd.Flags().Bool("no-pull", false, "reindex without pulling the shared library first")
+
+	return cmd
+}
+
+// isGitWorkTree reports whether dir is inside a git working tree.
+func isGitWorkTree(dir string) bool {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--is-inside-work-tree").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// printSyncResult prints the shared schemas changed by a sync.
+func printSyncResult(result library.SharedSyncResult) {
+	fmt.Printf("  %d added, %d updated, %d removed, %d unchanged\n",
+		len(result.Added), len(result.Updated), len(result.Removed), len(result.Unchanged))
+	for _, id := range result.Added {
+		fmt.Printf("  + %s\n", id)
+	}
+	for _, id := range result.Updated {
+		fmt.Printf("  ~ %s\n", id)
+	}
+	for _, id := range result.Removed {
+		fmt.Printf("  - %s\n", id)
+	}
+	for _, id := range result.Overridden {
+		fmt.Printf("  %s: local override kept; remove it with 'gqlxp library remove %s' to use the shared version\n", id, id)
+	}
+	for _, id := range slices.Sorted(maps.Keys(result.Failed)) {
+		fmt.Printf("  %s: %v\n", id, result.Failed[id])
+	}
+}
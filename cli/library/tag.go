@@ -0,0 +1,123 @@
+package library
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func tagCommand(lib library.Library) *cobra.Command {
+	return &cobra.Command{
+		Use:   "tag <schema-id> <tag>...",
+		Short: "Add tags to a schema",
+		Long: `Adds tags to a schema. Tags group related schemas, for example by team or domain,
+and can be used to filter 'library list --tag' and 'search --tag'.
+
+Tags may contain only lowercase letters, numbers, and hyphens.`,
+		Example: `  gqlxp library tag billing payments core
+  gqlxp library list --tag payments`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemaID, tags := args[0], args[1:]
+			for _, tag := range tags {
+				if err := library.ValidateTag(tag); err != nil {
+					return err
+				}
+			}
+			metadata, err := editMetadata(lib, schemaID, func(metadata *library.SchemaMetadata) {
+				metadata.AddTags(tags...)
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Schema '%s' tags: %s\n", schemaID, formatTags(metadata.Tags))
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+}
+
+func untagCommand(lib library.Library) *cobra.Command {
+	return &cobra.Command{
+		Use:     "untag <schema-id> <tag>...",
+		Short:   "Remove tags from a schema",
+		Example: `  gqlxp library untag billing core`,
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemaID, tags := args[0], args[1:]
+			metadata, err := editMetadata(lib, schemaID, func(metadata *library.SchemaMetadata) {
+				metadata.RemoveTags(tags...)
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Schema '%s' tags: %s\n", schemaID, formatTags(metadata.Tags))
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+}
+
+func describeCommand(lib library.Library) *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe <schema-id> <description>",
+		Short: "Set the description of a schema",
+		Long: `Sets a free-text description of a schema, shown by 'library list'.
+
+Pass an empty description to clear it.`,
+		Example: `  gqlxp library describe billing "Invoices and payments (owned by the billing team)"
+  gqlxp library describe billing ""`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemaID, description := args[0], strings.TrimSpace(args[1])
+			_, err := editMetadata(lib, schemaID, func(metadata *library.SchemaMetadata) {
+				metadata.Description = description
+			})
+			if err != nil {
+				return err
+			}
+			if description == "" {
+				fmt.Printf("Cleared description of schema '%s'\n", schemaID)
+			} else {
+				fmt.Printf("Set description of schema '%s'\n", schemaID)
+			}
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+}
+
+// editMetadata applies edit to the metadata of a schema, saves it, and returns the result.
+func editMetadata(lib library.Library, schemaID string, edit func(metadata *library.SchemaMetadata)) (library.SchemaMetadata, error) {
+	if baseID, env := library.ParseSchemaRef(schemaID); env != "" {
+		return library.SchemaMetadata{}, fmt.Errorf("tags and descriptions belong to schema '%s', not to its environments", baseID)
+	}
+
+	if _, err := lib.Get(schemaID); err != nil {
+		return library.SchemaMetadata{}, schemaNotFoundError(lib, schemaID)
+	}
+
+	var edited library.SchemaMetadata
+	err := lib.ModifyMetadata(schemaID, func(metadata *library.SchemaMetadata) error {
+		edit(metadata)
+		edited = *metadata
+		return nil
+	})
+	if err != nil {
+		return library.SchemaMetadata{}, fmt.Errorf("failed to update schema: %w", err)
+	}
+	return edited, nil
+}
+
+// formatTags returns tags as a comma-separated list.
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return "(none)"
+	}
+	return strings.Join(tags, ", ")
+}
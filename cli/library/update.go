@@ -2,33 +2,81 @@ package library
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/gql/introspection"
 	"github.com/tonysyu/gqlxp/library"
 )
 
-func updateCommand() *cobra.Command {
+func updateCommand(lib library.Library) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update [schema-file-or-url]",
 		Short: "Update a schema in the library",
 		Long: `Updates a schema in the library with new content.
 
 If no schema source is provided, attempts to re-fetch from the original URL.
-If the schema has no stored URL, you must provide a file path or URL.`,
+If the schema has no stored URL, you must provide a file path or URL.
+
+Requests use the schema's saved connection profile. Headers, --timeout, and --method
+given here are added to the profile. Secret header values are only saved as references,
+${env:NAME} or ${file:path}, which are resolved each time a request is made.
+
+With --env, refreshes the snapshot of one of the schema's environments from its URL
+(see 'gqlxp library env'), using the environment's connection profile.
+
+With --all or --tag, refreshes every matching schema from its stored URL or source file,
+several at a time, and prints a summary. Only schemas whose content changed are rewritten
+and reindexed. With --stale, only schemas older than the threshold set with
+'gqlxp library stale-after' are refreshed.`,
 		Example: `  gqlxp library update --id github                    # Re-fetch from original URL
   gqlxp library update --id github ./schema.graphqls  # Update from file
-  gqlxp library update --id github https://api.example.com/graphql  # Update from URL`,
+  gqlxp library update --id github https://api.example.com/graphql  # Update from URL
+  gqlxp library update --id github -H 'Authorization: Bearer ${env:GITHUB_TOKEN}'  # Save auth header
+  gqlxp library update --id api --env staging         # Re-fetch the staging environment
+  gqlxp library update --all                          # Refresh every schema from its source
+  gqlxp library update --tag payments --stale         # Refresh stale schemas tagged payments`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			schemaID, _ := cmd.Flags().GetString("id")
+			envName, _ := cmd.Flags().GetString("env")
 			headers, _ := cmd.Flags().GetStringArray("header")
-			lib := library.NewLibrary()
+			all, _ := cmd.Flags().GetBool("all")
+			tag, _ := cmd.Flags().GetString("tag")
+			defer waitForIndexing(lib)
+
+			if all || tag != "" {
+				if schemaID != "" || envName != "" || len(args) > 0 {
+					return fmt.Errorf("cannot use --all or --tag with --id, --env, or a schema source")
+				}
+				return runBulkUpdate(cmd, lib, tag)
+			}
+			if schemaID == "" {
+				return fmt.Errorf("a schema is required: use --id <schema-id>, --all, or --tag <tag>")
+			}
 
 			// Verify schema exists
 			existingSchema, err := lib.Get(schemaID)
 			if err != nil {
 				return schemaNotFoundError(lib, schemaID)
 			}
+			if envName != "" {
+				existingSchema, err = environmentTarget(lib, existingSchema, envName)
+				if err != nil {
+					return err
+				}
+				schemaID = existingSchema.ID
+			}
+
+			profile, err := connectionProfileFromFlags(cmd, existingSchema.Metadata.ConnectionProfile())
+			if err != nil {
+				return err
+			}
+			opts, err := profile.ClientOptions(headers)
+			if err != nil {
+				return err
+			}
+			connection := profileOrNil(profile)
 
 			var content []byte
 			var newSource schemaSource
@@ -36,7 +84,10 @@ If the schema has no stored URL, you must provide a file path or URL.`,
 			if len(args) > 0 {
 				// Source provided - load from file or URL
 				source := args[0]
-				content, newSource, err = LoadSchemaContent(ctx, source, headers)
+				if envName != "" && !introspection.IsURL(source) {
+					return fmt.Errorf("environment '%s' can only be updated from a URL", envName)
+				}
+				content, newSource, err = LoadSchemaContent(ctx, source, opts)
 				if err != nil {
 					return err
 				}
@@ -46,7 +97,7 @@ If the schema has no stored URL, you must provide a file path or URL.`,
 					return fmt.Errorf("schema '%s' has no stored URL. Provide a file path or URL to update from", schemaID)
 				}
 
-				content, err = fetchSchemaFromURL(ctx, existingSchema.Metadata.SourceURL, headers)
+				content, err = fetchSchemaFromURL(ctx, existingSchema.Metadata.SourceURL, opts)
 				if err != nil {
 					return fmt.Errorf("failed to fetch from stored URL: %w", err)
 				}
@@ -61,8 +112,12 @@ If the schema has no stored URL, you must provide a file path or URL.`,
 			newHash := library.CalculateFileHash(content)
 
 			if newHash == existingSchema.Metadata.FileHash {
-				// Content unchanged - just update timestamp
-				if err := lib.UpdateMetadata(schemaID, existingSchema.Metadata); err != nil {
+				// Content unchanged - just update the connection profile and timestamp
+				err := lib.ModifyMetadata(schemaID, func(metadata *library.SchemaMetadata) error {
+					metadata.Connection = connection
+					return nil
+				})
+				if err != nil {
 					return fmt.Errorf("failed to update metadata: %w", err)
 				}
 				fmt.Printf("Schema '%s' is already up to date (timestamp updated)\n", schemaID)
@@ -74,22 +129,20 @@ If the schema has no stored URL, you must provide a file path or URL.`,
 				return fmt.Errorf("failed to update schema: %w", err)
 			}
 
-			// Update source info if a new source was provided
-			if newSource.URL != "" || newSource.FilePath != "" {
-				schema, err := lib.Get(schemaID)
-				if err != nil {
-					return fmt.Errorf("failed to get updated schema: %w", err)
-				}
+			// Update source info and connection profile
+			err = lib.ModifyMetadata(schemaID, func(metadata *library.SchemaMetadata) error {
+				metadata.Connection = connection
 				if newSource.URL != "" {
-					schema.Metadata.SourceURL = newSource.URL
-					schema.Metadata.SourceFile = "" // Clear file path when updating from URL
+					metadata.SourceURL = newSource.URL
+					metadata.SourceFile = "" // Clear file path when updating from URL
 				} else if newSource.FilePath != "" {
-					schema.Metadata.SourceFile = newSource.FilePath
-					schema.Metadata.SourceURL = "" // Clear URL when updating from file
-				}
-				if err := lib.UpdateMetadata(schemaID, schema.Metadata); err != nil {
-					return fmt.Errorf("failed to update source info: %w", err)
+					metadata.SourceFile = newSource.FilePath
+					metadata.SourceURL = "" // Clear URL when updating from file
 				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update source info: %w", err)
 			}
 
 			fmt.Printf("Schema '%s' updated successfully\n", schemaID)
@@ -99,9 +152,34 @@ If the schema has no stored URL, you must provide a file path or URL.`,
 		SilenceUsage:  true,
 	}
 
-	cmd.Flags().String("id", "", "schema ID to update (required)")
-	cmd.Flags().StringArrayP("header", "H", nil, "HTTP header for URL requests (e.g., 'Authorization: Bearer token')")
-	_ = cmd.MarkFlagRequired("id")
+	cmd.Flags().String("id", "", "schema ID to update")
+	cmd.Flags().String("env", "", "update the snapshot of environment `NAME` instead of the schema")
+	addConnectionFlags(cmd)
+	cmd.Flags().StringArray("remove-header", nil, "remove a header `NAME` from the connection profile")
+	cmd.Flags().Bool("all", false, "refresh every schema in the library from its source")
+	cmd.Flags().String("tag", "", "refresh the schemas with this tag")
+	cmd.Flags().Bool("stale", false, "with --all or --tag, only refresh stale schemas")
+	cmd.Flags().Int("workers", library.DefaultRefreshWorkers, "with --all or --tag, number of schemas refreshed at once")
+	cmd.Flags().Duration("schema-timeout", time.Minute, "with --all or --tag, time limit for refreshing each schema")
 
 	return cmd
 }
+
+// environmentTarget returns the snapshot of environment envName of schema to update.
+// An environment that was never fetched has no content yet, only its source and profile.
+func environmentTarget(lib library.Library, schema *library.Schema, envName string) (*library.Schema, error) {
+	env, err := schema.Metadata.Environment(envName)
+	if err != nil {
+		return nil, err
+	}
+	ref := library.SchemaRef(schema.ID, envName)
+	if snapshot, err := lib.Get(ref); err == nil {
+		return snapshot, nil
+	}
+	metadata := schema.Metadata
+	metadata.SourceFile = ""
+	metadata.SourceURL = env.SourceURL
+	metadata.FileHash = ""
+	metadata.Connection = env.Connection
+	return &library.Schema{ID: ref, Metadata: metadata}, nil
+}
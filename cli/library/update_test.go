@@ -0,0 +1,49 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func TestUpdateCommand_SavesConnectionProfile(t *testing.T) {
+	is := is.New(t)
+	t.Setenv("GQLXP_TEST_API_KEY", "secret")
+	lib := library.NewLibraryWithStore(library.NewMemoryStore())
+	schemaFile := filepath.Join(t.TempDir(), "api.graphqls")
+	is.NoErr(os.WriteFile(schemaFile, []byte(`type Query { hello: String }`), 0644))
+	is.NoErr(lib.Add("api", "API", schemaFile))
+
+	runUpdate := func(args ...string) {
+		t.Helper()
+		cmd := updateCommand(lib)
+		cmd.SetArgs(args)
+		is.NoErr(cmd.Execute())
+	}
+
+	// Content is unchanged, so only the profile is saved
+	runUpdate("--id", "api", "-H", "X-Api-Key: ${env:GQLXP_TEST_API_KEY}", "-H", "X-Client: gqlxp",
+		"--timeout", "10s", "--method", "get", schemaFile)
+	schema, err := lib.Get("api")
+	is.NoErr(err)
+	is.Equal(schema.Metadata.ConnectionProfile(), library.ConnectionProfile{
+		Headers: map[string]string{"X-Api-Key": "${env:GQLXP_TEST_API_KEY}", "X-Client": "gqlxp"},
+		Timeout: "10s",
+		Method:  "GET",
+	})
+
+	// Content changed, and the profile is saved along with it
+	is.NoErr(os.WriteFile(schemaFile, []byte(`type Query { hello: String, bye: String }`), 0644))
+	runUpdate("--id", "api", "--remove-header", "x-api-key", "--timeout", "5s", schemaFile)
+	schema, err = lib.Get("api")
+	is.NoErr(err)
+	is.Equal(string(schema.Content), `type Query { hello: String, bye: String }`)
+	is.Equal(schema.Metadata.ConnectionProfile(), library.ConnectionProfile{
+		Headers: map[string]string{"X-Client": "gqlxp"},
+		Timeout: "5s",
+		Method:  "GET",
+	})
+}
@@ -1,17 +1,24 @@
 package cli
 
 import (
-	"fmt"
-	"os"
+	"io"
 
+	"charm.land/fang/v2"
 	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/cli/exitcode"
 	initcmd "github.com/tonysyu/gqlxp/cli/init"
-	"github.com/tonysyu/gqlxp/cli/library"
-	"github.com/tonysyu/gqlxp/tui"
+	librarycmd "github.com/tonysyu/gqlxp/cli/library"
+	"github.com/tonysyu/gqlxp/library"
 )
 
-// NewRootCmd creates and configures the CLI application.
+// NewRootCmd creates and configures the CLI application, using the library selected by
+// library.OpenStore.
 func NewRootCmd() *cobra.Command {
+	return NewRootCmdWithLibrary(library.NewLibrary())
+}
+
+// NewRootCmdWithLibrary creates the CLI application with every command using lib.
+func NewRootCmdWithLibrary(lib library.Library) *cobra.Command {
 	root := &cobra.Command{
 		Use:   "gqlxp",
 		Short: "Explore GraphQL schemas interactively or via CLI",
@@ -21,13 +28,17 @@ For AI/programmatic use (use --ai flag for JSON output, no pager, no color):
   search    Find types and fields by keyword
   show      Display a full type definition
   validate  Validate a GraphQL operation against the schema
+  query     Validate and execute a GraphQL operation against the schema's endpoint
   generate  Scaffold a skeleton GraphQL operation (prints to stdout)
+  export    Print a schema as SDL or introspection JSON
 
 Schema files are saved to the library on first use.
 Use 'gqlxp library list' to see available schemas.
-Use 'gqlxp library default <id>' to set the default schema for all commands.`,
+Use 'gqlxp library default <id>' to set the default schema for all commands.
+A project config (.gqlxp.yaml, .graphqlrc, or graphql.config.*) in the working directory
+or its parents sets the schema for that project; see 'gqlxp project'.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return executeTUICommand(cmd)
+			return executeTUICommand(cmd, lib)
 		},
 		SilenceErrors: true,
 		SilenceUsage:  true,
@@ -36,28 +47,29 @@ Use 'gqlxp library default <id>' to set the default schema for all commands.`,
 	root.PersistentFlags().StringP("schema", "s", "", "Schema file path or library ID")
 	root.Flags().StringP("log-file", "l", "", "Enable debug logging to `FILE`")
 	root.Flags().String("select", "", "Pre-select TYPE or TYPE.FIELD in TUI")
+	root.Flags().StringArrayP("header", "H", nil, "HTTP header for running operations (e.g., 'Authorization: Bearer token')")
 
 	root.AddCommand(
-		appCommand(),
-		initcmd.Command(),
-		validateCommand(),
-		searchCommand(),
-		showCommand(),
-		generateCommand(),
-		library.Command(),
+		appCommand(lib),
+		initcmd.Command(lib),
+		validateCommand(lib),
+		queryCommand(lib),
+		searchCommand(lib),
+		showCommand(lib),
+		generateCommand(lib),
+		exportCommand(lib),
+		projectCommand(lib),
+		librarycmd.Command(lib),
 	)
 
 	return root
 }
 
-func setupLogging(logFile string) {
-	err := tui.SetupLogging(logFile)
-	if err != nil {
-		abort(fmt.Sprintf("Error opening log file: %v", err))
+// HandleError reports an error returned by a command, using the fang styles. Errors that
+// only set the exit status are not reported, since the command already printed its outcome.
+func HandleError(w io.Writer, styles fang.Styles, err error) {
+	if exitcode.Is(err) {
+		return
 	}
-}
-
-func abort(msg string) {
-	fmt.Println(msg)
-	os.Exit(1)
+	fang.DefaultErrorHandler(w, styles, err)
 }
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+	"github.com/tonysyu/gqlxp/library"
+	"github.com/tonysyu/gqlxp/project"
+)
+
+func projectCommand(lib library.Library) *cobra.Command {
+	return &cobra.Command{
+		Use:   "project",
+		Short: "Show the project config for the current directory",
+		Long: `Shows the project config found in the current directory or its parents.
+
+gqlxp reads its own .gqlxp.yaml and the standard graphql-config formats (.graphqlrc,
+.graphqlrc.{yaml,yml,json}, and graphql.config.{yaml,yml,json}). When a command is run
+without --schema, the project's schema is used instead of the library default. Configs
+written in JavaScript, TypeScript, or TOML are skipped with a warning.
+
+The schema may be a library ID (or <id>@<env>), a file or directory path relative to the
+config, or a URL. Several files, given as a list or a glob, make up a multi-file schema if
+they are all the .graphqls and .graphql files of one directory; other combinations of
+sources are not supported. A URL not yet in the library is fetched and added to it on
+first use; headers given for it are sent with the request and saved in the schema's
+connection profile:
+
+  schema: ./schema.graphql
+  documents: src/**/*.graphql
+
+  schema:
+    - https://api.example.com/graphql:
+        headers:
+          Authorization: Bearer ${env:API_TOKEN}
+
+Scalar mappings and lint rules are not supported yet, and configs that set them are
+rejected rather than silently ignored.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := discoverProject()
+			if err != nil {
+				return err
+			}
+			if cfg == nil {
+				fmt.Println("No project config found. Create .gqlxp.yaml with a 'schema' entry to set the schema for this directory.")
+				return nil
+			}
+			return printProject(lib, cfg)
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+}
+
+func printProject(lib library.Library, cfg *project.Config) error {
+	fmt.Printf("Config: %s\n", cfg.Path)
+	if len(cfg.Projects) > 0 {
+		fmt.Printf("Projects: %v\n", cfg.ProjectNames())
+	}
+	selected, err := cfg.Project("")
+	if err != nil {
+		return err
+	}
+
+	for _, s := range selected.Schema {
+		fmt.Printf("Schema: %s\n", s.Pointer)
+	}
+	if unfetchedURL(lib, selected.Schema) {
+		fmt.Println("Resolves to: not in the library yet; fetched and added on first use")
+	} else if len(selected.Schema) > 0 {
+		// Resolve the config already found, rather than discovering it again
+		loader := NewSchemaLoader(lib, terminalPrompter{})
+		loader.findProject = func() (*project.Config, error) { return cfg, nil }
+		if arg, err := loader.projectSchemaArg(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			fmt.Printf("Resolves to: %s\n", arg)
+		}
+	}
+
+	if len(selected.Documents) > 0 {
+		files, err := selected.DocumentFiles()
+		if err != nil {
+			return fmt.Errorf("failed to match documents: %w", err)
+		}
+		fmt.Printf("Documents: %v (%d files)\n", selected.Documents, len(files))
+	}
+	return nil
+}
+
+// unfetchedURL reports whether schema is a URL that is not in the library yet, which
+// resolving would fetch.
+func unfetchedURL(lib library.Library, schema []project.SchemaPointer) bool {
+	if len(schema) != 1 || !introspection.IsURL(schema[0].Pointer) {
+		return false
+	}
+	schemaID, err := findSchemaByURL(lib, schema[0].Pointer)
+	return err == nil && schemaID == ""
+}
@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/cli/exitcode"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func queryCommand(lib library.Library) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query [<operation-file>]",
+		Short: "Execute a GraphQL operation against the schema's endpoint",
+		Long: `Validates a GraphQL operation against a schema, then executes it.
+
+Uses default schema when --schema is not specified.
+The operation is sent to the URL the schema was fetched from (see 'gqlxp library add'),
+or to --endpoint when provided. Requests to the schema's URL use its saved connection
+profile (see 'gqlxp library add --help').
+
+Reads from a file argument if provided, or from stdin if omitted.
+Exits with code 0 on success, code 1 if validation fails or the response contains errors,
+in both text and JSON output.
+
+JSON output format: the raw GraphQL response {"data": {...}, "errors": [...]}.
+If validation fails, the validate JSON format is used instead.`,
+		Example: `  gqlxp query examples/queries/github-user.graphql
+  gqlxp query -s github --variables vars.json examples/queries/github-user.graphql
+  gqlxp query -H 'Authorization: Bearer TOKEN' examples/queries/github-user.graphql
+  gqlxp query --endpoint http://localhost:4000/graphql --json operation.graphql`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			aiMode, _ := cmd.Flags().GetBool("ai")
+			if aiMode {
+				jsonOutput = true
+				os.Setenv("NO_COLOR", "1")
+			}
+			opts := queryOptions{jsonOutput: jsonOutput}
+			opts.schemaArg, _ = cmd.Flags().GetString("schema")
+			opts.endpoint, _ = cmd.Flags().GetString("endpoint")
+			opts.variablesFile, _ = cmd.Flags().GetString("variables")
+			opts.operationName, _ = cmd.Flags().GetString("operation")
+			opts.headers, _ = cmd.Flags().GetStringArray("header")
+			if len(args) > 0 {
+				opts.filePath = args[0]
+			}
+			return handleError(runQueryCommand(cmd.Context(), lib, opts), jsonOutput)
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	cmd.Flags().String("variables", "", "JSON `FILE` containing operation variables")
+	cmd.Flags().String("endpoint", "", "GraphQL endpoint `URL` (defaults to the schema's source URL)")
+	cmd.Flags().String("operation", "", "operation `NAME` to execute when the document contains several")
+	cmd.Flags().StringArrayP("header", "H", nil, "HTTP header for requests (e.g., 'Authorization: Bearer token')")
+	cmd.Flags().Bool("json", false, "output results as JSON (recommended for AI/programmatic use)")
+	cmd.Flags().Bool("ai", false, "AI/programmatic mode: JSON output, no pager, no color")
+
+	return cmd
+}
+
+// queryOptions holds the parsed flags for the query command.
+type queryOptions struct {
+	schemaArg     string
+	filePath      string
+	endpoint      string
+	variablesFile string
+	operationName string
+	headers       []string
+	jsonOutput    bool
+}
+
+func runQueryCommand(ctx context.Context, lib library.Library, opts queryOptions) error {
+	schema, err := NewSchemaLoader(lib, terminalPrompter{}).Load(opts.schemaArg)
+	if err != nil {
+		return err
+	}
+
+	operationContent, sourceName, err := readOperation(opts.filePath)
+	if err != nil {
+		return err
+	}
+
+	// Validate before sending anything over the network
+	if opts.jsonOutput {
+		result := buildValidationResult(schema.Content, operationContent, sourceName)
+		if !result.Valid {
+			if err := printJSON(result); err != nil {
+				return err
+			}
+			return exitcode.New(1)
+		}
+	} else if errorLines := validateOperation(schema.Content, operationContent, sourceName); len(errorLines) > 0 {
+		for _, line := range errorLines {
+			fmt.Println(line)
+		}
+		return exitcode.New(1)
+	}
+
+	// The connection profile is only used for the schema's own endpoint, so that saved
+	// credentials are never sent to an --endpoint override
+	endpoint := opts.endpoint
+	var profile library.ConnectionProfile
+	if endpoint == "" {
+		libSchema, err := lib.Get(schema.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load schema metadata: %w", err)
+		}
+		endpoint, err = resolveEndpoint(schema.ID, libSchema.Metadata)
+		if err != nil {
+			return err
+		}
+		profile = libSchema.Metadata.ConnectionProfile()
+	}
+
+	variables, err := loadVariables(opts.variablesFile)
+	if err != nil {
+		return err
+	}
+
+	clientOpts, err := profile.ClientOptions(opts.headers)
+	if err != nil {
+		return err
+	}
+
+	req := introspection.OperationRequest{
+		Query:         operationContent,
+		OperationName: opts.operationName,
+		Variables:     variables,
+	}
+	resp, err := introspection.Execute(ctx, endpoint, req, clientOpts)
+	if err != nil {
+		return fmt.Errorf("failed to execute operation: %w", err)
+	}
+
+	if opts.jsonOutput {
+		if err := printJSON(resp); err != nil {
+			return err
+		}
+		if len(resp.Errors) > 0 {
+			return exitcode.New(1)
+		}
+		return nil
+	}
+
+	if err := printResponseData(resp.Data); err != nil {
+		return err
+	}
+	errorLines := formatOperationErrors(resp.Errors, sourceName)
+	for _, line := range errorLines {
+		fmt.Fprintln(os.Stderr, line)
+	}
+	if len(errorLines) > 0 {
+		return exitcode.New(1)
+	}
+	return nil
+}
+
+// readOperation reads an operation document from filePath, or from stdin if filePath is empty.
+func readOperation(filePath string) (content, sourceName string, err error) {
+	if filePath == "" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", "", fmt.Errorf("error reading stdin: %w", err)
+		}
+		return string(data), "<stdin>", nil
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", "", fmt.Errorf("error reading file: %w", err)
+	}
+	return string(data), filePath, nil
+}
+
+// resolveEndpoint returns the endpoint stored in the schema metadata.
+func resolveEndpoint(schemaID string, metadata library.SchemaMetadata) (string, error) {
+	if metadata.SourceURL == "" {
+		return "", fmt.Errorf("schema '%s' has no stored endpoint URL. Use --endpoint to specify one", schemaID)
+	}
+	return metadata.SourceURL, nil
+}
+
+// loadVariables reads operation variables from a JSON file. Returns nil if path is empty.
+func loadVariables(path string) (map[string]any, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading variables file: %w", err)
+	}
+	var variables map[string]any
+	if err := json.Unmarshal(data, &variables); err != nil {
+		return nil, fmt.Errorf("invalid variables file '%s': expected a JSON object: %w", path, err)
+	}
+	return variables, nil
+}
+
+// printResponseData pretty-prints the data portion of a GraphQL response.
+func printResponseData(data json.RawMessage) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return fmt.Errorf("failed to format response: %w", err)
+	}
+	fmt.Println(out.String())
+	return nil
+}
+
+// formatOperationErrors formats GraphQL response errors using the same
+// source:line:col: message layout as validation errors.
+func formatOperationErrors(errs []introspection.Error, sourceName string) []string {
+	lines := make([]string, 0, len(errs))
+	for _, e := range errs {
+		msg := e.Message
+		if len(e.Path) > 0 {
+			msg = fmt.Sprintf("%s (path: %s)", msg, formatErrorPath(e.Path))
+		}
+		if len(e.Locations) > 0 {
+			loc := e.Locations[0]
+			lines = append(lines, fmt.Sprintf("%s:%d:%d: %s", sourceName, loc.Line, loc.Column, msg))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s: %s", sourceName, msg))
+		}
+	}
+	return lines
+}
+
+// formatErrorPath joins a response path (field names and list indices) with dots.
+func formatErrorPath(path []any) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = fmt.Sprint(p)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/cli/exitcode"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func TestFormatOperationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		errs []introspection.Error
+		want []string
+	}{
+		{
+			name: "no errors",
+			errs: nil,
+			want: []string{},
+		},
+		{
+			name: "error with location",
+			errs: []introspection.Error{{
+				Message:   "Not authorized",
+				Locations: []introspection.ErrorLocation{{Line: 2, Column: 3}},
+			}},
+			want: []string{"query.graphql:2:3: Not authorized"},
+		},
+		{
+			name: "error with location and path",
+			errs: []introspection.Error{{
+				Message:   "Not found",
+				Locations: []introspection.ErrorLocation{{Line: 4, Column: 5}},
+				Path:      []any{"user", "friends", float64(1)},
+			}},
+			want: []string{"query.graphql:4:5: Not found (path: user.friends.1)"},
+		},
+		{
+			name: "error without location",
+			errs: []introspection.Error{{Message: "Internal error"}},
+			want: []string{"query.graphql: Internal error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(formatOperationErrors(tt.errs, "query.graphql"), tt.want)
+		})
+	}
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	is := is.New(t)
+
+	endpoint, err := resolveEndpoint("api", library.SchemaMetadata{SourceURL: "https://example.com/graphql"})
+	is.NoErr(err)
+	is.Equal(endpoint, "https://example.com/graphql")
+
+	_, err = resolveEndpoint("api", library.SchemaMetadata{SourceFile: "/tmp/schema.graphqls"})
+	is.True(err != nil) // schemas loaded from files have no endpoint
+}
+
+func TestLoadVariables(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+
+	vars, err := loadVariables("")
+	is.NoErr(err)
+	is.Equal(vars, nil) // no file means no variables
+
+	validPath := filepath.Join(dir, "vars.json")
+	is.NoErr(os.WriteFile(validPath, []byte(`{"id": "1", "first": 10}`), 0644))
+	vars, err = loadVariables(validPath)
+	is.NoErr(err)
+	is.Equal(vars["id"], "1")
+	is.Equal(vars["first"], float64(10))
+
+	invalidPath := filepath.Join(dir, "invalid.json")
+	is.NoErr(os.WriteFile(invalidPath, []byte(`["not", "an", "object"]`), 0644))
+	_, err = loadVariables(invalidPath)
+	is.True(err != nil) // variables must be a JSON object
+}
+
+func TestRunQueryCommand_ExitCode(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"users": null}, "errors": [{"message": "Not authorized"}]}`))
+	}))
+	defer server.Close()
+	lib := library.NewLibraryWithStore(library.NewMemoryStore())
+	is.NoErr(lib.AddFromContent("api", "API", []byte(parseTestSchema), ""))
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.graphql")
+	invalid := filepath.Join(dir, "invalid.graphql")
+	is.NoErr(os.WriteFile(valid, []byte(`query { users { id } }`), 0644))
+	is.NoErr(os.WriteFile(invalid, []byte(`query { users { email } }`), 0644))
+
+	for _, jsonOutput := range []bool{false, true} {
+		opts := queryOptions{schemaArg: "api", endpoint: server.URL, jsonOutput: jsonOutput}
+
+		opts.filePath = invalid
+		err := handleError(runQueryCommand(context.Background(), lib, opts), jsonOutput)
+		is.Equal(exitcode.FromError(err), 1) // validation errors exit with code 1
+
+		opts.filePath = valid
+		err = handleError(runQueryCommand(context.Background(), lib, opts), jsonOutput)
+		is.Equal(exitcode.FromError(err), 1) // response errors exit with code 1
+	}
+	is.NoErr(lib.WaitForIndexing())
+}
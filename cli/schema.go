@@ -1,13 +1,19 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 
+	librarycmd "github.com/tonysyu/gqlxp/cli/library"
 	"github.com/tonysyu/gqlxp/cli/prompt"
 	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/gql/introspection"
 	"github.com/tonysyu/gqlxp/library"
+	"github.com/tonysyu/gqlxp/project"
 )
 
 // Prompter abstracts interactive terminal prompts for schema resolution.
@@ -43,33 +49,48 @@ type LoadedSchema struct {
 type SchemaLoader struct {
 	lib      library.Library
 	prompter Prompter
+	// findProject returns the project config for the working directory, or nil if none.
+	findProject func() (*project.Config, error)
 }
 
 // NewSchemaLoader creates a SchemaLoader with injected dependencies.
 func NewSchemaLoader(lib library.Library, p Prompter) *SchemaLoader {
-	return &SchemaLoader{lib: lib, prompter: p}
+	return &SchemaLoader{lib: lib, prompter: p, findProject: discoverProject}
 }
 
-// NewDefaultSchemaLoader creates a SchemaLoader backed by the real filesystem library
-// and real terminal prompter. This is the one-liner for CLI commands.
-func NewDefaultSchemaLoader() *SchemaLoader {
-	return NewSchemaLoader(library.NewLibrary(), terminalPrompter{})
-}
-
-// LoadSchema is a convenience wrapper around NewDefaultSchemaLoader().Load(arg).
-func LoadSchema(arg string) (LoadedSchema, error) {
-	return NewDefaultSchemaLoader().Load(arg)
+// discoverProject finds the project config for the current working directory, warning
+// about config files it can't read.
+func discoverProject() (*project.Config, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	cfg, skipped, err := project.Discover(dir)
+	for _, path := range skipped {
+		fmt.Fprintf(os.Stderr, "Warning: skipping unsupported project config '%s': use .gqlxp.yaml or graphql.config.yaml instead\n", path)
+	}
+	return cfg, err
 }
 
 // Load resolves a schema argument and returns a LoadedSchema with a parsed schema.
 // arg can be:
-//   - Empty string: use default schema from config
-//   - A schema ID that exists in the library
-//   - A file path (will be added to library if needed)
+//   - Empty string: use the schema of the project config (.gqlxp.yaml, .graphqlrc, or
+//     graphql.config.*) found in the working directory or its parents, or else the
+//     default schema from config
+//   - A schema ID that exists in the library, or <id>@<env> for an environment snapshot
+//   - A file path to SDL or introspection JSON (will be added to library if needed)
 func (l *SchemaLoader) Load(arg string) (LoadedSchema, error) {
 	var schemaID string
 	var content []byte
 
+	if arg == "" {
+		projectArg, err := l.projectSchemaArg()
+		if err != nil {
+			return LoadedSchema{}, err
+		}
+		arg = projectArg
+	}
+
 	if arg == "" {
 		defaultSchemaID, err := l.lib.GetDefaultSchema()
 		if err != nil {
@@ -83,6 +104,8 @@ func (l *SchemaLoader) Load(arg string) (LoadedSchema, error) {
 		// First check if it's an existing schema ID
 		if _, err := l.lib.Get(arg); err == nil {
 			schemaID = arg
+		} else if isEnvironmentRef(arg) {
+			return LoadedSchema{}, fmt.Errorf("failed to load schema '%s': %w", arg, err)
 		} else {
 			// Not a schema ID - try as file path
 			resolvedID, resolvedContent, err := l.resolveFilePath(arg)
@@ -111,6 +134,107 @@ func (l *SchemaLoader) Load(arg string) (LoadedSchema, error) {
 	return LoadedSchema{ID: schemaID, Content: content, GQLSchema: parsedSchema}, nil
 }
 
+// projectSchemaArg returns the schema argument declared by the project config: a library
+// ID, a file or directory path, or the ID of the library schema fetched from a declared URL,
+// which is added to the library on first use. It returns an empty string when there is no
+// project config or it declares no schema.
+func (l *SchemaLoader) projectSchemaArg() (string, error) {
+	cfg, err := l.findProject()
+	if err != nil || cfg == nil {
+		return "", err
+	}
+	cfg, err = cfg.Project("")
+	if err != nil {
+		return "", err
+	}
+	if len(cfg.Schema) == 0 {
+		return "", nil
+	}
+	if len(cfg.Schema) == 1 {
+		pointer := cfg.Schema[0].Pointer
+		if introspection.IsURL(pointer) {
+			return l.projectURLSchema(cfg.Schema[0])
+		}
+		if dir := cfg.ResolvePath(pointer); library.IsSourceDir(dir) {
+			return dir, nil
+		}
+	}
+
+	var files []string
+	for _, s := range cfg.Schema {
+		if introspection.IsURL(s.Pointer) {
+			return "", fmt.Errorf("project config %s combines URL %s with other schema sources: a URL must be the only source", cfg.Path, s.Pointer)
+		}
+		matches, err := cfg.Glob(s.Pointer)
+		if err != nil {
+			return "", fmt.Errorf("invalid schema pattern '%s' in %s: %w", s.Pointer, cfg.Path, err)
+		}
+		if len(matches) == 0 {
+			if len(cfg.Schema) == 1 {
+				// Not a file, so it names a library schema
+				return s.Pointer, nil
+			}
+			return "", fmt.Errorf("schema pattern '%s' in %s matches no files", s.Pointer, cfg.Path)
+		}
+		files = append(files, matches...)
+	}
+	slices.Sort(files)
+	files = slices.Compact(files)
+	if len(files) == 1 {
+		return files[0], nil
+	}
+	return multiFileSchemaDir(cfg, files)
+}
+
+// multiFileSchemaDir returns the directory of a schema split across files. The library reads
+// multi-file schemas from a directory, so the files must be all the SDL files of one directory.
+func multiFileSchemaDir(cfg *project.Config, files []string) (string, error) {
+	dir := filepath.Dir(files[0])
+	if dirFiles, err := library.SourceFiles(dir); err == nil && slices.Equal(dirFiles, files) {
+		return dir, nil
+	}
+	return "", fmt.Errorf("schema sources in %s match %d files: a multi-file schema must be all of the .graphqls and .graphql files of one directory", cfg.Path, len(files))
+}
+
+// projectURLSchema returns the ID of the library schema fetched from the URL of pointer,
+// fetching the schema and adding it to the library if it's not there yet.
+func (l *SchemaLoader) projectURLSchema(pointer project.SchemaPointer) (string, error) {
+	schemaID, err := findSchemaByURL(l.lib, pointer.Pointer)
+	if err != nil || schemaID != "" {
+		return schemaID, err
+	}
+	return l.registerURLSchema(pointer.Pointer, pointer.Headers)
+}
+
+// findSchemaByURL returns the ID of the library schema (or environment) fetched from url,
+// or an empty string if there is none.
+func findSchemaByURL(lib library.Library, url string) (string, error) {
+	schemas, err := lib.List()
+	if err != nil {
+		return "", err
+	}
+	for _, info := range schemas {
+		for _, env := range info.Environments {
+			if env.SourceURL == url {
+				return library.SchemaRef(info.ID, env.Name), nil
+			}
+		}
+		if schema, err := lib.Get(info.ID); err == nil && schema.Metadata.SourceURL == url {
+			return info.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// isEnvironmentRef reports whether arg refers to a schema environment rather than a file.
+func isEnvironmentRef(arg string) bool {
+	if _, env := library.ParseSchemaRef(arg); env == "" {
+		return false
+	}
+	_, err := os.Stat(arg)
+	return os.IsNotExist(err)
+}
+
 // resolveFilePath handles the case where arg is a file path.
 func (l *SchemaLoader) resolveFilePath(filePath string) (schemaID string, content []byte, err error) {
 	absPath, err := filepath.Abs(filePath)
@@ -118,17 +242,29 @@ func (l *SchemaLoader) resolveFilePath(filePath string) (schemaID string, conten
 		return "", nil, fmt.Errorf("failed to resolve absolute path: %w", err)
 	}
 
-	content, err = os.ReadFile(absPath)
+	// A directory is a multi-file schema, stored as its joined SDL files
+	converted := library.IsSourceDir(absPath)
+	if converted {
+		content, err = library.ReadSourceFile(absPath)
+	} else {
+		content, err = os.ReadFile(absPath)
+	}
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to read schema file: %w", err)
 	}
+	// Introspection JSON is stored as SDL, so compare hashes of the converted content
+	converted = converted || introspection.IsJSON(content)
+	content, err = introspection.EnsureSDL(content)
+	if err != nil {
+		return "", nil, err
+	}
 
 	fileHash := library.CalculateFileHash(content)
 	existingSchema, err := l.lib.FindByPath(absPath)
 
 	// No match - register new schema
 	if err != nil {
-		id, err := l.registerSchema(absPath, content)
+		id, err := l.registerSchema(absPath, content, converted)
 		return id, content, err
 	}
 
@@ -161,25 +297,88 @@ func (l *SchemaLoader) handleSchemaUpdate(existingSchema *library.Schema, newCon
 	return existingSchema.ID, newContent, nil
 }
 
-func (l *SchemaLoader) registerSchema(filePath string, content []byte) (string, error) {
+func (l *SchemaLoader) registerSchema(filePath string, content []byte, converted bool) (string, error) {
 	basename := filepath.Base(filePath)
 	ext := filepath.Ext(basename)
-	suggested := library.SanitizeSchemaID(basename[:len(basename)-len(ext)])
+	schemaID, displayName, err := l.promptSchemaName(library.SanitizeSchemaID(basename[:len(basename)-len(ext)]))
+	if err != nil {
+		return "", err
+	}
 
-	schemaID, err := l.prompter.SchemaID(suggested)
+	// Converted content differs from the file, so it's added directly rather than read from disk
+	var addErr error
+	if converted {
+		addErr = l.lib.AddFromContent(schemaID, displayName, content, filePath)
+	} else {
+		addErr = l.lib.Add(schemaID, displayName, filePath)
+	}
+	if addErr != nil {
+		return "", fmt.Errorf("failed to add schema to library: %w", addErr)
+	}
+
+	fmt.Printf("Schema '%s' added to library\n", schemaID)
+	return schemaID, nil
+}
+
+// registerURLSchema fetches the schema at url and adds it to the library. The headers are
+// sent with the request and saved in the schema's connection profile, except for literal
+// secrets, so that later requests to url use them too.
+func (l *SchemaLoader) registerURLSchema(url string, headers map[string]string) (string, error) {
+	headerArgs := make([]string, 0, len(headers))
+	for name, value := range headers {
+		headerArgs = append(headerArgs, name+": "+value)
+	}
+	sort.Strings(headerArgs)
+	profile, skipped, err := library.ConnectionProfile{}.WithHeaders(headerArgs)
+	if err != nil {
+		return "", err
+	}
+	opts, err := library.ConnectionProfile{}.ClientOptions(headerArgs)
 	if err != nil {
-		return "", fmt.Errorf("failed to get schema ID: %w", err)
+		return "", err
 	}
 
-	displayName, err := l.prompter.String("Enter display name", schemaID)
+	content, _, err := librarycmd.LoadSchemaContent(context.Background(), url, opts)
 	if err != nil {
-		return "", fmt.Errorf("failed to get display name: %w", err)
+		return "", err
+	}
+	if err := librarycmd.ValidateSchema(content); err != nil {
+		return "", err
 	}
 
-	if err := l.lib.Add(schemaID, displayName, filePath); err != nil {
+	schemaID, displayName, err := l.promptSchemaName(librarycmd.ExtractSuggestedID(url))
+	if err != nil {
+		return "", err
+	}
+	if err := l.lib.AddFromContent(schemaID, displayName, content, url); err != nil {
 		return "", fmt.Errorf("failed to add schema to library: %w", err)
 	}
+	for _, name := range skipped {
+		fmt.Printf("Not saving header '%s': use a reference like '${env:NAME}' or '${file:path}' to store secrets\n", name)
+	}
+	if !profile.IsEmpty() {
+		err := l.lib.ModifyMetadata(schemaID, func(metadata *library.SchemaMetadata) error {
+			metadata.Connection = &profile
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to save connection profile: %w", err)
+		}
+	}
 
 	fmt.Printf("Schema '%s' added to library\n", schemaID)
 	return schemaID, nil
 }
+
+// promptSchemaName asks for the ID and display name of a schema being added to the library.
+func (l *SchemaLoader) promptSchemaName(suggestedID string) (schemaID, displayName string, err error) {
+	schemaID, err = l.prompter.SchemaID(suggestedID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get schema ID: %w", err)
+	}
+	displayName, err = l.prompter.String("Enter display name", schemaID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get display name: %w", err)
+	}
+	return schemaID, displayName, nil
+}
@@ -1,7 +1,11 @@
 package cli
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,7 +13,10 @@ import (
 
 	"github.com/matryer/is"
 	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/gql/introspection"
 	"github.com/tonysyu/gqlxp/library"
+	"github.com/tonysyu/gqlxp/project"
+	"github.com/tonysyu/gqlxp/search"
 )
 
 // fakeLib is a minimal in-memory Library for testing SchemaLoader.
@@ -18,6 +25,7 @@ type fakeLib struct {
 	defaultSchema string
 	// Track calls for assertion
 	addCalled     bool
+	addedContent  []byte
 	updateCalled  bool
 	updateID      string
 	updateContent []byte
@@ -94,17 +102,53 @@ func (f *fakeLib) Add(id, displayName, sourcePath string) error {
 	return nil
 }
 
-// Unused interface methods.
 func (f *fakeLib) AddFromContent(id, displayName string, content []byte, sourceInfo string) error {
+	f.addCalled = true
+	f.addedContent = content
+	f.schemas[id] = &library.Schema{
+		ID:      id,
+		Content: content,
+		Metadata: library.SchemaMetadata{
+			DisplayName: displayName,
+			SourceFile:  sourceInfo,
+			FileHash:    library.CalculateFileHash(content),
+			URLPatterns: make(map[string]string),
+		},
+	}
 	return nil
 }
-func (f *fakeLib) List() ([]library.SchemaInfo, error)                             { return nil, nil }
+
+// Unused interface methods.
 func (f *fakeLib) Remove(id string) error                                          { return nil }
 func (f *fakeLib) UpdateMetadata(id string, metadata library.SchemaMetadata) error { return nil }
+func (f *fakeLib) Rename(oldID, newID string) error                                { return nil }
+func (f *fakeLib) Clone(id, newID string) error                                    { return nil }
 func (f *fakeLib) SetURLPattern(id, typePattern, urlPattern string) error          { return nil }
 func (f *fakeLib) SetDefaultSchema(id string) error                                { return nil }
+func (f *fakeLib) GetStaleAfterDays() (int, error)                                 { return 0, nil }
+func (f *fakeLib) SetStaleAfterDays(days int) error                                { return nil }
 func (f *fakeLib) EnsureIndex(schemaID string, schema *gql.GraphQLSchema) error    { return nil }
 func (f *fakeLib) Reindex(schemaID string) error                                   { return nil }
+func (f *fakeLib) WaitForIndexing() error                                          { return nil }
+func (f *fakeLib) Search(schemaIDs []string, query string, limit int) (*search.SearchResponse, error) {
+	return nil, nil
+}
+func (f *fakeLib) ModifyMetadata(id string, modify func(metadata *library.SchemaMetadata) error) error {
+	return nil
+}
+func (f *fakeLib) SyncShared() (library.SharedSyncResult, error) {
+	return library.SharedSyncResult{}, nil
+}
+func (f *fakeLib) SharedLibraryDir() (string, error)   { return "", nil }
+func (f *fakeLib) MountSharedLibrary(dir string) error { return nil }
+
+func (f *fakeLib) List() ([]library.SchemaInfo, error) {
+	var infos []library.SchemaInfo
+	for id := range f.schemas {
+		infos = append(infos, library.SchemaInfo{ID: id})
+	}
+	return infos, nil
+}
 
 // fakePrompter records calls and returns pre-configured answers.
 type fakePrompter struct {
@@ -252,6 +296,26 @@ func TestSchemaLoader_FilePath_NewFile_Registers(t *testing.T) {
 	is.True(lib.addCalled)           // schema was added to library
 }
 
+func TestSchemaLoader_Directory_RegistersJoinedFiles(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+	is.NoErr(os.WriteFile(filepath.Join(dir, "query.graphqls"), []byte(validSchema), 0644))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "types.graphqls"), []byte(`type User { id: ID }`), 0644))
+	prompter := &fakePrompter{schemaIDResult: "multi"}
+
+	lib := newFakeLib()
+	loader := NewSchemaLoader(lib, prompter)
+
+	schema, err := loader.Load(dir)
+
+	is.NoErr(err)
+	is.Equal(schema.ID, "multi")
+	is.True(schema.GQLSchema.Object["User"] != nil)
+	is.Equal(string(lib.addedContent), validSchema+"\n\ntype User { id: ID }\n")
+	is.Equal(lib.schemas["multi"].Metadata.SourceFile, dir)
+}
+
 func TestSchemaLoader_ParsedSchemaIsValid(t *testing.T) {
 	is := is.New(t)
 
@@ -264,3 +328,220 @@ func TestSchemaLoader_ParsedSchemaIsValid(t *testing.T) {
 	// Verify the schema was actually parsed — Query type should exist
 	is.True(schema.GQLSchema.Query != nil)
 }
+
+const introspectionSchema = `{
+  "data": {
+    "__schema": {
+      "queryType": {"name": "Query"},
+      "types": [
+        {
+          "kind": "OBJECT",
+          "name": "Query",
+          "fields": [
+            {"name": "hello", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+          ],
+          "interfaces": []
+        }
+      ],
+      "directives": []
+    }
+  }
+}`
+
+func TestSchemaLoader_IntrospectionJSON_RegistersSDL(t *testing.T) {
+	is := is.New(t)
+
+	path := writeSchemaFile(t, introspectionSchema)
+	lib := newFakeLib()
+	loader := NewSchemaLoader(lib, &fakePrompter{schemaIDResult: "vendor"})
+
+	schema, err := loader.Load(path)
+
+	is.NoErr(err)
+	is.Equal(schema.ID, "vendor")
+	is.Equal(string(lib.addedContent), "type Query {\n  hello: String\n}\n\n") // stored as SDL
+	is.True(schema.GQLSchema.Query["hello"] != nil)
+}
+
+func TestSchemaLoader_IntrospectionJSON_HashMatchesConvertedContent(t *testing.T) {
+	is := is.New(t)
+
+	path := writeSchemaFile(t, introspectionSchema)
+	absPath, _ := filepath.Abs(path)
+	lib := newFakeLib()
+	prompter := &fakePrompter{schemaIDResult: "vendor"}
+	loader := NewSchemaLoader(lib, prompter)
+	_, err := loader.Load(path)
+	is.NoErr(err)
+
+	schema, err := loader.Load(absPath)
+
+	is.NoErr(err)
+	is.Equal(schema.ID, "vendor")
+	is.True(!prompter.yesNoCalled) // unchanged JSON file is not reported as modified
+}
+
+// withProjectConfig makes loader discover a project config with the given content,
+// written to a temporary directory, and returns that directory.
+func withProjectConfig(t *testing.T, loader *SchemaLoader, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	subdir := filepath.Join(dir, "src", "app")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	loader.findProject = func() (*project.Config, error) {
+		cfg, _, err := project.Discover(subdir)
+		return cfg, err
+	}
+	return dir
+}
+
+func TestSchemaLoader_EmptyArg_ProjectSchemaID(t *testing.T) {
+	is := is.New(t)
+
+	lib := newFakeLib().
+		withSchema("default-schema", []byte(validSchema), "hash", "").
+		withSchema("github", []byte(validSchema), "hash", "").
+		withDefault("default-schema")
+	loader := NewSchemaLoader(lib, &fakePrompter{})
+	withProjectConfig(t, loader, ".gqlxp.yaml", "schema: github\n")
+
+	schema, err := loader.Load("")
+
+	is.NoErr(err)
+	is.Equal(schema.ID, "github") // project config takes precedence over the default schema
+}
+
+func TestSchemaLoader_EmptyArg_ProjectSchemaFile(t *testing.T) {
+	is := is.New(t)
+
+	lib := newFakeLib()
+	loader := NewSchemaLoader(lib, &fakePrompter{})
+	dir := withProjectConfig(t, loader, ".graphqlrc", "schema: ./schema.graphqls\n")
+	schemaPath := filepath.Join(dir, "schema.graphqls")
+	is.NoErr(os.WriteFile(schemaPath, []byte(validSchema), 0644))
+	lib.withSchema("app", []byte(validSchema), library.CalculateFileHash([]byte(validSchema)), schemaPath)
+
+	schema, err := loader.Load("")
+
+	is.NoErr(err)
+	is.Equal(schema.ID, "app") // file is resolved relative to the config, not the working directory
+}
+
+func TestSchemaLoader_EmptyArg_ProjectSchemaURL(t *testing.T) {
+	is := is.New(t)
+
+	lib := newFakeLib().withSchema("api", []byte(validSchema), "hash", "")
+	lib.schemas["api"].Metadata.SourceURL = "https://example.com/graphql"
+	loader := NewSchemaLoader(lib, &fakePrompter{})
+	withProjectConfig(t, loader, "graphql.config.json", `{"schema": "https://example.com/graphql"}`)
+
+	schema, err := loader.Load("")
+
+	is.NoErr(err)
+	is.Equal(schema.ID, "api")
+}
+
+func TestSchemaLoader_EmptyArg_ProjectSchemaURLNotInLibrary(t *testing.T) {
+	is := is.New(t)
+	t.Setenv("GQLXP_TEST_TOKEN", "secret")
+	parsed, err := gql.ParseSchema([]byte(validSchema))
+	is.NoErr(err)
+	introspectionJSON, err := json.Marshal(introspection.FromSchema(parsed))
+	is.NoErr(err)
+	var authorization []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		_, _ = w.Write(introspectionJSON)
+	}))
+	defer server.Close()
+
+	lib := library.NewLibraryWithStore(library.NewMemoryStore())
+	loader := NewSchemaLoader(lib, &fakePrompter{schemaIDResult: "api"})
+	withProjectConfig(t, loader, "graphql.config.yaml", fmt.Sprintf(`
+schema:
+  - %s:
+      headers:
+        Authorization: Bearer ${env:GQLXP_TEST_TOKEN}
+        X-Client: gqlxp
+`, server.URL))
+
+	schema, err := loader.Load("")
+
+	is.NoErr(err)
+	is.Equal(schema.ID, "api") // the URL is fetched and added to the library
+	is.True(len(authorization) > 0)
+	is.Equal(authorization[len(authorization)-1], "Bearer secret") // headers are sent, with references resolved
+	added, err := lib.Get("api")
+	is.NoErr(err)
+	is.Equal(added.Metadata.SourceURL, server.URL)
+	is.Equal(added.Metadata.ConnectionProfile().Headers, map[string]string{ // and saved for later requests
+		"Authorization": "Bearer ${env:GQLXP_TEST_TOKEN}",
+		"X-Client":      "gqlxp",
+	})
+
+	schema, err = loader.Load("")
+
+	is.NoErr(err)
+	is.Equal(schema.ID, "api") // later loads use the library schema
+	is.NoErr(lib.WaitForIndexing())
+}
+
+func TestSchemaLoader_EmptyArg_ProjectMultiFileSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string // schema argument relative to the config directory, or "" for an error
+	}{
+		{name: "glob", config: "schema: schema/*.graphqls\n", want: "schema"},
+		{name: "list of files", config: "schema:\n  - schema/query.graphqls\n  - schema/user.graphqls\n", want: "schema"},
+		{name: "directory", config: "schema: schema\n", want: "schema"},
+		{name: "single file of a directory", config: "schema: schema/query.graphqls\n", want: "schema/query.graphqls"},
+		{name: "files of different directories", config: "schema:\n  - schema/*.graphqls\n  - other.graphqls\n"},
+		{name: "pattern without matches", config: "schema:\n  - schema/*.graphqls\n  - missing/*.graphqls\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			loader := NewSchemaLoader(newFakeLib(), &fakePrompter{schemaIDResult: "app"})
+			dir := withProjectConfig(t, loader, ".gqlxp.yaml", tt.config)
+			is.NoErr(os.Mkdir(filepath.Join(dir, "schema"), 0755))
+			for name, content := range map[string]string{
+				"schema/query.graphqls": "type Query { user: User }",
+				"schema/user.graphqls":  "type User { id: ID! }",
+				"other.graphqls":        "type Other { id: ID! }",
+			} {
+				is.NoErr(os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+			}
+
+			arg, err := loader.projectSchemaArg()
+
+			if tt.want == "" {
+				is.True(err != nil) // the library can't store the files as one schema
+				return
+			}
+			is.NoErr(err)
+			is.Equal(arg, filepath.Join(dir, tt.want))
+		})
+	}
+}
+
+func TestSchemaLoader_EmptyArg_ProjectSchemaDirectory(t *testing.T) {
+	is := is.New(t)
+	loader := NewSchemaLoader(newFakeLib(), &fakePrompter{schemaIDResult: "app"})
+	dir := withProjectConfig(t, loader, ".gqlxp.yaml", "schema: schema/*.graphqls\n")
+	is.NoErr(os.Mkdir(filepath.Join(dir, "schema"), 0755))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "schema", "query.graphqls"), []byte("type Query { user: User }"), 0644))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "schema", "user.graphqls"), []byte("type User { id: ID! }"), 0644))
+
+	schema, err := loader.Load("")
+
+	is.NoErr(err)
+	is.Equal(schema.ID, "app")
+	is.True(schema.GQLSchema.Query["user"] != nil) // the files are joined into one schema
+}
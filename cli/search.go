@@ -19,26 +19,30 @@ import (
 var (
 	headerStyle = lipgloss.NewStyle().Foreground(terminal.ColorDimMagenta)
 	codeStyle   = lipgloss.NewStyle().Foreground(terminal.ColorDimIndigo)
+	matchStyle  = lipgloss.NewStyle().Bold(true).Foreground(terminal.ColorDimMagenta)
 )
 
 // canonicalSearchKinds maps lowercase kind names to their canonical form.
 var canonicalSearchKinds = map[string]string{
-	"query":          "Query",
-	"mutation":       "Mutation",
-	"object":         "Object",
-	"input":          "Input",
-	"enum":           "Enum",
-	"scalar":         "Scalar",
-	"interface":      "Interface",
-	"union":          "Union",
-	"directive":      "Directive",
-	"objectfield":    "ObjectField",
-	"inputfield":     "InputField",
-	"interfacefield": "InterfaceField",
+	"query":             "Query",
+	"mutation":          "Mutation",
+	"object":            "Object",
+	"input":             "Input",
+	"enum":              "Enum",
+	"scalar":            "Scalar",
+	"interface":         "Interface",
+	"union":             "Union",
+	"directive":         "Directive",
+	"objectfield":       "ObjectField",
+	"inputfield":        "InputField",
+	"interfacefield":    "InterfaceField",
+	"argument":          "Argument",
+	"enumvalue":         "EnumValue",
+	"directiveargument": "DirectiveArgument",
 }
 
 // validSearchFields are the valid field names in search queries.
-var validSearchFields = []string{"kind", "name", "description", "path", "usage", "implements"}
+var validSearchFields = []string{"kind", "name", "description", "path", "usage", "implements", "parent", "directive", "returns", "accepts", "signature"}
 
 // searchFieldPattern matches fieldname: patterns in bleve query strings.
 var searchFieldPattern = regexp.MustCompile(`\b([a-zA-Z][a-zA-Z0-9]*):\S`)
@@ -78,7 +82,7 @@ func unknownFieldWarnings(query string) []string {
 }
 
 // searchCommand creates the search subcommand
-func searchCommand() *cobra.Command {
+func searchCommand(lib library.Library) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "search [query]",
 		Short: "Search for types and fields in a GraphQL schema",
@@ -88,15 +92,31 @@ Uses default schema when --schema is not specified.
 Use 'gqlxp library default' to set the default schema.
 
 For AI/programmatic use, add --json --no-pager for machine-readable output.
-JSON output: [{"path":"Type.field","kind":"Query|Object|...","description":"...","signature":"..."}]
+JSON output: {"total":N,"kinds":[{"term":"Query","count":N}],"parents":[...],
+  "results":[{"path":"Type.field","kind":"Query|Object|...","description":"...","signature":"..."}]}
+Matched terms of the name, description, and signature are marked with <mark></mark> in
+"fragments" (e.g., {"description":["Get a <mark>user</mark> by ID"]}).
+"total" counts every match, and "kinds" and "parents" count matches by kind and parent
+type, so a query returning more than --limit results can be refined.
+
+Use --all to search every schema in the library at once, or --tag to search every
+schema with a tag. Each result then names the schema it belongs to (JSON adds
+"schemaID" and "schemaName").
 
 Query syntax:
   Plain keyword   Matches names and descriptions
   kind:<Kind>     Filter by kind (e.g., kind:Query, kind:Object)
+  parent:<Type>   Fields, arguments, and enum values of a type (e.g., parent:User)
+  directive:<d>   Filter by applied directive (e.g., directive:deprecated)
+  returns:<Type>  Fields returning a type (e.g., returns:User)
+  accepts:<Type>  Fields, directives, and arguments taking a type (e.g., accepts:UserInput)
+  signature:<w>   Fields and arguments whose signature contains a word (e.g., signature:user)
   Combined        "+kind:Query user" filters to Query kind matching "user"`,
 		Example: `  gqlxp search user                                  # Uses default schema
   gqlxp search -s github user --json --no-pager      # JSON output for AI use
   gqlxp search -s github --kind Query                # List all queries
+  gqlxp search --all Invoice                         # Which schema defines Invoice?
+  gqlxp search --tag payments Invoice                # Only schemas tagged "payments"
   gqlxp search -s examples/github.graphqls user      # Uses specific file`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			showSyntax, _ := cmd.Flags().GetBool("syntax")
@@ -117,23 +137,25 @@ Query syntax:
 				cmd.Flags().Set("no-pager", "true")
 				os.Setenv("NO_COLOR", "1")
 			}
-			return handleError(runSearchCommand(cmd, args, jsonOutput), jsonOutput)
+			return handleError(runSearchCommand(cmd, lib, args, jsonOutput), jsonOutput)
 		},
 		SilenceErrors: true,
 		SilenceUsage:  true,
 	}
 
 	cmd.Flags().Bool("syntax", false, "show search syntax documentation and exit")
+	cmd.Flags().Bool("all", false, "search every schema in the library; results include their schema")
+	cmd.Flags().String("tag", "", "search every schema with this tag (like --all, for a subset of the library)")
 	cmd.Flags().Int("limit", 30, "maximum number of results to return")
 	cmd.Flags().Bool("no-pager", false, "disable pager; use for non-interactive/AI use")
 	cmd.Flags().Bool("json", false, "output results as JSON (recommended for AI/programmatic use)")
 	cmd.Flags().Bool("ai", false, "AI/programmatic mode: JSON output, no pager, no color")
-	cmd.Flags().String("kind", "", "filter by document kind: Query, Mutation, Object, Input, Enum, Scalar, Interface, Union, Directive, ObjectField, InputField, InterfaceField")
+	cmd.Flags().String("kind", "", "filter by document kind: Query, Mutation, Object, Input, Enum, Scalar, Interface, Union, Directive, ObjectField, InputField, InterfaceField, Argument, EnumValue, DirectiveArgument")
 
 	return cmd
 }
 
-func runSearchCommand(cmd *cobra.Command, args []string, jsonOutput bool) error {
+func runSearchCommand(cmd *cobra.Command, lib library.Library, args []string, jsonOutput bool) error {
 	kindFilter, _ := cmd.Flags().GetString("kind")
 	hasKindFilter := kindFilter != ""
 
@@ -165,41 +187,40 @@ func runSearchCommand(cmd *cobra.Command, args []string, jsonOutput bool) error
 	// Get schema (empty string for default when no flag specified)
 	schemaArg, _ := cmd.Flags().GetString("schema")
 
-	// Resolve schema argument (path, ID, or default)
-	schema, err := LoadSchema(schemaArg)
-	if err != nil {
-		return err
+	searchAll, _ := cmd.Flags().GetBool("all")
+	tag, _ := cmd.Flags().GetString("tag")
+	if searchAll || tag != "" {
+		if schemaArg != "" {
+			return fmt.Errorf("cannot use --all or --tag with --schema")
+		}
+		return runLibrarySearch(lib, query, tag, limit, noPager, jsonOutput)
 	}
 
-	// Get schemas directory for indexing
-	schemasDir, err := library.GetSchemasDir()
+	// Resolve schema argument (path, ID, or default)
+	schema, err := NewSchemaLoader(lib, terminalPrompter{}).Load(schemaArg)
 	if err != nil {
-		return fmt.Errorf("failed to get schemas directory: %w", err)
+		return err
 	}
 
-	indexer := search.NewIndexer(schemasDir)
-	defer indexer.Close()
-
 	// Create index if it doesn't exist
-	if !indexer.Exists(schema.ID) {
-		fmt.Printf("Indexing schema '%s'...\n", schema.ID)
-		if err := indexer.Index(schema.ID, &schema.GQLSchema); err != nil {
-			return fmt.Errorf("failed to index schema: %w", err)
-		}
+	if err := lib.EnsureIndex(schema.ID, &schema.GQLSchema); err != nil {
+		return fmt.Errorf("failed to index schema: %w", err)
 	}
 
-	// Search
-	searcher := search.NewSearcher(schemasDir)
-	defer searcher.Close()
-
-	results, err := searcher.Search(schema.ID, query, limit)
+	response, err := lib.Search([]string{schema.ID}, query, limit)
 	if err != nil {
 		return fmt.Errorf("search failed: %w (try using 'gqlxp library reindex %s')", err, schema.ID)
 	}
+	results := response.Results
+	// Results of a single schema don't name it
+	for i := range results {
+		results[i].SchemaID = ""
+		results[i].SchemaName = ""
+	}
 
 	// Handle JSON output
 	if jsonOutput {
-		err := printSearchResultsJSON(results)
+		err := printSearchResultsJSON(response)
 		for _, w := range unknownFieldWarnings(query) {
 			fmt.Fprintln(os.Stderr, w)
 		}
@@ -215,10 +236,6 @@ func runSearchCommand(cmd *cobra.Command, args []string, jsonOutput bool) error
 		return nil
 	}
 
-	var maxLimitInfo string
-	if len(results) == limit {
-		maxLimitInfo = fmt.Sprintf(" (increase search %s for more)", codeStyle.Render("--limit N"))
-	}
 	// Multiple results - show list and let user choose
 	var output strings.Builder
 
@@ -229,13 +246,12 @@ func runSearchCommand(cmd *cobra.Command, args []string, jsonOutput bool) error
 	fmt.Fprintf(&output, "To open result in TUI app, run: \n\t%s --select %s\n\n",
 		codeStyle.Render("gqlxp app --schema "+schema.ID), pathArg)
 
-	fmt.Fprintf(&output, "Found %d results for %q%s:\n\n", len(results), query, maxLimitInfo)
+	fmt.Fprintf(&output, "Found %d results for %q%s:\n", response.Total, query, limitInfo(response))
+	writeFacetCounts(&output, response)
 	for i, result := range results {
 		// Highlight the type in pink
-		fmt.Fprintf(&output, "%d. %s %s\n", i+1, headerStyle.Render(result.Path), "("+result.Kind+")")
-		if result.Description != "" {
-			fmt.Fprintf(&output, "   %s\n", result.Description)
-		}
+		fmt.Fprintf(&output, "%d. %s %s\n", i+1, formatResultPath(result), "("+result.Kind+")")
+		writeResultDetails(&output, result)
 	}
 
 	// Use pager if content is long enough and not disabled
@@ -257,6 +273,160 @@ func runSearchCommand(cmd *cobra.Command, args []string, jsonOutput bool) error
 	return nil
 }
 
+// runLibrarySearch searches every schema in the library at once, or only those tagged tag
+// if non-empty.
+func runLibrarySearch(lib library.Library, query, tag string, limit int, noPager, jsonOutput bool) error {
+	var schemaIDs []string
+	if tag != "" {
+		var err error
+		if schemaIDs, err = library.SchemaIDsWithTag(lib, tag); err != nil {
+			return err
+		}
+	}
+	response, err := lib.Search(schemaIDs, query, limit)
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+	results := response.Results
+
+	warnings := append(skippedSchemaWarnings(response), unknownFieldWarnings(query)...)
+	if jsonOutput {
+		err := printSearchResultsJSON(response)
+		for _, w := range warnings {
+			fmt.Fprintln(os.Stderr, w)
+		}
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No results found in library for query: %q\n", query)
+		for _, w := range warnings {
+			fmt.Println(w)
+		}
+		return nil
+	}
+
+	var output strings.Builder
+
+	pathArg := headerStyle.Render("<object>.<field>")
+	fmt.Fprintf(&output, "To display more info about a result, run: \n\t%s %s\n",
+		codeStyle.Render("gqlxp show --schema <id>"), pathArg)
+	fmt.Fprintf(&output, "To open result in TUI app, run: \n\t%s --select %s\n\n",
+		codeStyle.Render("gqlxp app --schema <id>"), pathArg)
+
+	fmt.Fprintf(&output, "Found %d results in library for %q%s:\n", response.Total, query, limitInfo(response))
+	writeFacetCounts(&output, response)
+	for i, result := range results {
+		fmt.Fprintf(&output, "%d. %s %s %s\n", i+1, formatResultPath(result), "("+result.Kind+")", formatResultSchema(result))
+		writeResultDetails(&output, result)
+	}
+
+	rendered := output.String()
+	if terminal.ShouldUsePager(rendered, noPager) {
+		if err := terminal.ShowInPager(rendered); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(rendered)
+	}
+	for _, w := range warnings {
+		fmt.Println(w)
+	}
+	return nil
+}
+
+// skippedSchemaWarnings returns warning messages for the schemas a search across the library
+// left out because they couldn't be indexed.
+func skippedSchemaWarnings(response *search.SearchResponse) []string {
+	var warnings []string
+	for _, skipped := range response.Skipped {
+		warnings = append(warnings, fmt.Sprintf("⚠️ skipped schema '%s': %s", skipped.SchemaID, skipped.Error))
+	}
+	return warnings
+}
+
+// limitInfo describes how many results are shown when some matches are past the limit.
+func limitInfo(response *search.SearchResponse) string {
+	if response.Total <= uint64(len(response.Results)) {
+		return ""
+	}
+	return fmt.Sprintf(" (showing %d, increase search %s for more)", len(response.Results), codeStyle.Render("--limit N"))
+}
+
+// writeFacetCounts writes the number of matches of each kind and parent type, followed by
+// a blank line, so that a broad query can be refined with kind: or parent:.
+func writeFacetCounts(output *strings.Builder, response *search.SearchResponse) {
+	if len(response.Kinds) > 0 {
+		fmt.Fprintf(output, "  By kind: %s\n", formatFacetCounts(response.Kinds))
+	}
+	if len(response.Parents) > 0 {
+		fmt.Fprintf(output, "  By type: %s\n", formatFacetCounts(response.Parents))
+	}
+	output.WriteString("\n")
+}
+
+// formatFacetCounts formats facet counts as "Query 3, ObjectField 2".
+func formatFacetCounts(counts []search.FacetCount) string {
+	parts := make([]string, len(counts))
+	for i, count := range counts {
+		parts[i] = fmt.Sprintf("%s %d", count.Term, count.Count)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatResultPath renders the path of a result, highlighting the terms of its name that
+// matched the query.
+func formatResultPath(result search.SearchResult) string {
+	names := result.Fragments["name"]
+	if len(names) == 0 || !strings.HasSuffix(result.Path, result.Name) {
+		return headerStyle.Render(result.Path)
+	}
+	name := renderHighlights(names[0], headerStyle)
+	if prefix := strings.TrimSuffix(result.Path, result.Name); prefix != "" {
+		return headerStyle.Render(prefix) + name
+	}
+	return name
+}
+
+// writeResultDetails writes the description of a result, or the parts of it matching the
+// query, and its signature when the query matched it.
+func writeResultDetails(output *strings.Builder, result search.SearchResult) {
+	if descriptions := result.Fragments["description"]; len(descriptions) > 0 {
+		fmt.Fprintf(output, "   %s\n", renderHighlights(strings.Join(descriptions, " … "), lipgloss.NewStyle()))
+	} else if result.Description != "" {
+		fmt.Fprintf(output, "   %s\n", result.Description)
+	}
+	if signatures := result.Fragments["signature"]; len(signatures) > 0 {
+		fmt.Fprintf(output, "   %s\n", renderHighlights(signatures[0], codeStyle))
+	}
+}
+
+// renderHighlights renders a search fragment with style, emphasizing the terms that matched
+// the query. Fragments are rendered as plain text when NO_COLOR is set.
+func renderHighlights(fragment string, style lipgloss.Style) string {
+	noColor := os.Getenv("NO_COLOR") != ""
+	var rendered strings.Builder
+	for _, segment := range search.SplitHighlights(fragment) {
+		switch {
+		case noColor:
+			rendered.WriteString(segment.Text)
+		case segment.Match:
+			rendered.WriteString(style.Inherit(matchStyle).Render(segment.Text))
+		default:
+			rendered.WriteString(style.Render(segment.Text))
+		}
+	}
+	return rendered.String()
+}
+
+// formatResultSchema describes the schema a cross-schema search result belongs to.
+func formatResultSchema(result search.SearchResult) string {
+	if result.SchemaName == "" || result.SchemaName == result.SchemaID {
+		return "in " + codeStyle.Render(result.SchemaID)
+	}
+	return fmt.Sprintf("in %s (id: %s)", result.SchemaName, codeStyle.Render(result.SchemaID))
+}
+
 // applyKindFilter validates kindFilter and prepends a +kind:<Canonical> clause to query.
 func applyKindFilter(kindFilter, query string) (string, error) {
 	if strings.Contains(query, "kind:") {
@@ -264,7 +434,7 @@ func applyKindFilter(kindFilter, query string) (string, error) {
 	}
 	canonical, ok := canonicalSearchKinds[strings.ToLower(kindFilter)]
 	if !ok {
-		return "", fmt.Errorf("invalid --kind value %q; valid values: Query, Mutation, Object, Input, Enum, Scalar, Interface, Union, Directive, ObjectField, InputField, InterfaceField", kindFilter)
+		return "", fmt.Errorf("invalid --kind value %q; valid values: Query, Mutation, Object, Input, Enum, Scalar, Interface, Union, Directive, ObjectField, InputField, InterfaceField, Argument, EnumValue, DirectiveArgument", kindFilter)
 	}
 	kindClause := "+kind:" + canonical
 	if query != "" {
@@ -273,12 +443,14 @@ func applyKindFilter(kindFilter, query string) (string, error) {
 	return kindClause, nil
 }
 
-// printSearchResultsJSON outputs search results as pretty-printed JSON
-func printSearchResultsJSON(results []search.SearchResult) error {
-	jsonBytes, err := json.MarshalIndent(results, "", "  ")
-	if err != nil {
+// printSearchResultsJSON outputs search results and match counts as pretty-printed JSON
+func printSearchResultsJSON(response *search.SearchResponse) error {
+	// Don't escape the <mark></mark> of fragments
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(response); err != nil {
 		return fmt.Errorf("failed to marshal results to JSON: %w", err)
 	}
-	fmt.Println(string(jsonBytes))
 	return nil
 }
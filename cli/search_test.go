@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"charm.land/lipgloss/v2"
 	"github.com/matryer/is"
 	"github.com/tonysyu/gqlxp/search"
 )
@@ -38,6 +39,11 @@ func TestUnknownFieldWarnings(t *testing.T) {
 			query:    "+kind:Query +name:user +usage:User",
 			wantMsgs: nil,
 		},
+		{
+			name:     "directive, returns, and accepts fields",
+			query:    "+directive:deprecated +returns:User +accepts:UserInput",
+			wantMsgs: nil,
+		},
 		{
 			name:     "unknown field with suggestion",
 			query:    "naem:user",
@@ -122,6 +128,18 @@ func TestApplyKindFilter(t *testing.T) {
 			query:      "email",
 			wantQuery:  "+kind:ObjectField email",
 		},
+		{
+			name:       "EnumValue",
+			kindFilter: "enumvalue",
+			query:      "",
+			wantQuery:  "+kind:EnumValue",
+		},
+		{
+			name:       "DirectiveArgument",
+			kindFilter: "DirectiveArgument",
+			query:      "reason",
+			wantQuery:  "+kind:DirectiveArgument reason",
+		},
 		{
 			name:       "invalid kind",
 			kindFilter: "BadType",
@@ -268,7 +286,10 @@ func TestPrintSearchResultsJSON(t *testing.T) {
 			r, w, _ := os.Pipe()
 			os.Stdout = w
 
-			err := printSearchResultsJSON(tt.results)
+			err := printSearchResultsJSON(&search.SearchResponse{
+				Total:   uint64(len(tt.results)),
+				Results: tt.results,
+			})
 			is.NoErr(err)
 
 			// Restore stdout and read captured output
@@ -281,9 +302,14 @@ func TestPrintSearchResultsJSON(t *testing.T) {
 			output := buf.String()
 
 			// Parse the JSON output
-			var got []map[string]any
-			err = json.Unmarshal([]byte(output), &got)
+			var response struct {
+				Total   uint64           `json:"total"`
+				Results []map[string]any `json:"results"`
+			}
+			err = json.Unmarshal([]byte(output), &response)
 			is.NoErr(err)
+			is.Equal(response.Total, uint64(len(tt.want)))
+			got := response.Results
 
 			// Verify the output matches expected
 			is.Equal(len(got), len(tt.want))
@@ -317,7 +343,7 @@ func TestPrintSearchResultsJSON_PrettyPrinted(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	err := printSearchResultsJSON(results)
+	err := printSearchResultsJSON(&search.SearchResponse{Total: 1, Results: results})
 	is.NoErr(err)
 
 	// Restore stdout and read captured output
@@ -334,3 +360,77 @@ func TestPrintSearchResultsJSON_PrettyPrinted(t *testing.T) {
 	is.True(bytes.Contains([]byte(output), []byte("  "))) // Contains indentation
 	is.True(bytes.Contains([]byte(output), []byte("\n"))) // Contains newlines
 }
+
+func TestWriteFacetCounts(t *testing.T) {
+	is := is.New(t)
+
+	var output strings.Builder
+	writeFacetCounts(&output, &search.SearchResponse{
+		Total:   5,
+		Kinds:   []search.FacetCount{{Term: "ObjectField", Count: 3}, {Term: "Query", Count: 2}},
+		Parents: []search.FacetCount{{Term: "User", Count: 3}, {Term: "Query", Count: 2}},
+	})
+	is.Equal(output.String(), "  By kind: ObjectField 3, Query 2\n  By type: User 3, Query 2\n\n")
+
+	// Types match no parent type, so only their kinds are counted
+	output.Reset()
+	writeFacetCounts(&output, &search.SearchResponse{
+		Total: 1,
+		Kinds: []search.FacetCount{{Term: "Object", Count: 1}},
+	})
+	is.Equal(output.String(), "  By kind: Object 1\n\n")
+}
+
+func TestLimitInfo(t *testing.T) {
+	is := is.New(t)
+	results := []search.SearchResult{{Path: "User"}, {Path: "Query.user"}}
+
+	is.Equal(limitInfo(&search.SearchResponse{Total: 2, Results: results}), "") // every match shown
+	is.True(strings.HasPrefix(limitInfo(&search.SearchResponse{Total: 7, Results: results}), " (showing 2,"))
+}
+
+func TestWriteResultDetails(t *testing.T) {
+	is := is.New(t)
+	t.Setenv("NO_COLOR", "1")
+
+	result := search.SearchResult{
+		Name:        "user",
+		Path:        "Query.user",
+		Description: "Get a user by ID",
+		Signature:   "user(id: ID!): User",
+	}
+	var output strings.Builder
+	writeResultDetails(&output, result)
+	is.Equal(output.String(), "   Get a user by ID\n")
+
+	// Matched descriptions and signatures are shown as fragments
+	result.Fragments = map[string][]string{
+		"description": {"Get a <mark>user</mark> by ID"},
+		"signature":   {"<mark>user</mark>(id: ID!): <mark>User</mark>"},
+	}
+	output.Reset()
+	writeResultDetails(&output, result)
+	is.Equal(output.String(), "   Get a user by ID\n   user(id: ID!): User\n")
+}
+
+func TestRenderHighlights(t *testing.T) {
+	is := is.New(t)
+
+	t.Setenv("NO_COLOR", "")
+	rendered := renderHighlights("Get a <mark>user</mark> by ID", lipgloss.NewStyle())
+	is.True(strings.Contains(rendered, "\x1b[")) // matched terms are emphasized
+	is.True(!strings.Contains(rendered, "<mark>"))
+
+	t.Setenv("NO_COLOR", "1")
+	is.Equal(renderHighlights("Get a <mark>user</mark> by ID", headerStyle), "Get a user by ID")
+}
+
+func TestSkippedSchemaWarnings(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(len(skippedSchemaWarnings(&search.SearchResponse{})), 0)
+	warnings := skippedSchemaWarnings(&search.SearchResponse{
+		Skipped: []search.SkippedSchema{{SchemaID: "broken", Error: "failed to parse schema"}},
+	})
+	is.Equal(warnings, []string{"⚠️ skipped schema 'broken': failed to parse schema"})
+}
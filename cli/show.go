@@ -6,11 +6,12 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/tonysyu/gqlxp/gqlfmt"
+	"github.com/tonysyu/gqlxp/library"
 	"github.com/tonysyu/gqlxp/utils/terminal"
 )
 
 // showCommand creates the show subcommand
-func showCommand() *cobra.Command {
+func showCommand(lib library.Library) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "show <type-name>",
 		Short: "Show a GraphQL type definition in the terminal",
@@ -49,7 +50,7 @@ Type-name formats:
 				os.Setenv("NO_COLOR", "1")
 			}
 
-			return handleError(printType(schemaArg, typeName, noPager, jsonOutput, include), jsonOutput)
+			return handleError(printType(lib, schemaArg, typeName, noPager, jsonOutput, include), jsonOutput)
 		},
 		SilenceErrors: true,
 		SilenceUsage:  true,
@@ -63,8 +64,8 @@ Type-name formats:
 	return cmd
 }
 
-func printType(schemaArg, typeName string, noPager bool, jsonOutput bool, include string) error {
-	schema, err := LoadSchema(schemaArg)
+func printType(lib library.Library, schemaArg, typeName string, noPager bool, jsonOutput bool, include string) error {
+	schema, err := NewSchemaLoader(lib, terminalPrompter{}).Load(schemaArg)
 	if err != nil {
 		return err
 	}
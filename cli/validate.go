@@ -7,10 +7,12 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/tonysyu/gqlxp/cli/exitcode"
 	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/library"
 )
 
-func validateCommand() *cobra.Command {
+func validateCommand(lib library.Library) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "validate [<operation-file>]",
 		Short: "Validate a GraphQL operation against a schema",
@@ -39,7 +41,7 @@ JSON output format: {"valid": true|false, "errors": [{"line": N, "column": N, "m
 			if len(args) > 0 {
 				filePath = args[0]
 			}
-			return handleError(runValidateCommand(schemaArg, filePath, jsonOutput), jsonOutput)
+			return handleError(runValidateCommand(lib, schemaArg, filePath, jsonOutput), jsonOutput)
 		},
 		SilenceErrors: true,
 		SilenceUsage:  true,
@@ -51,8 +53,8 @@ JSON output format: {"valid": true|false, "errors": [{"line": N, "column": N, "m
 	return cmd
 }
 
-func runValidateCommand(schemaArg, filePath string, jsonOutput bool) error {
-	schema, err := LoadSchema(schemaArg)
+func runValidateCommand(lib library.Library, schemaArg, filePath string, jsonOutput bool) error {
+	schema, err := NewSchemaLoader(lib, terminalPrompter{}).Load(schemaArg)
 	if err != nil {
 		return err
 	}
@@ -84,7 +86,7 @@ func runValidateCommand(schemaArg, filePath string, jsonOutput bool) error {
 		fmt.Println(line)
 	}
 	if len(errorLines) > 0 {
-		os.Exit(1)
+		return exitcode.New(1)
 	}
 	return nil
 }
@@ -145,7 +147,7 @@ func handleError(err error, jsonOutput bool) error {
 	if err == nil {
 		return nil
 	}
-	if jsonOutput {
+	if jsonOutput && !exitcode.Is(err) {
 		printJSONError(err)
 		return nil
 	}
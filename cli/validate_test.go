@@ -2,10 +2,14 @@ package cli
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/cli/exitcode"
+	"github.com/tonysyu/gqlxp/library"
 )
 
 const parseTestSchema = `
@@ -105,3 +109,18 @@ func TestValidationResultJSON_ParseError(t *testing.T) {
 	is.True(!result.Valid)
 	is.True(len(result.Errors) > 0)
 }
+
+func TestValidateCommand_ExitCode(t *testing.T) {
+	is := is.New(t)
+	lib := library.NewLibraryWithStore(library.NewMemoryStore())
+	is.NoErr(lib.AddFromContent("api", "API", []byte(parseTestSchema), ""))
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.graphql")
+	invalid := filepath.Join(dir, "invalid.graphql")
+	is.NoErr(os.WriteFile(valid, []byte(`query { users { id } }`), 0644))
+	is.NoErr(os.WriteFile(invalid, []byte(`query { users { email } }`), 0644))
+
+	is.Equal(exitcode.FromError(runValidateCommand(lib, "api", valid, false)), 0)   // valid operation exits with code 0
+	is.Equal(exitcode.FromError(runValidateCommand(lib, "api", invalid, false)), 1) // invalid operation exits with code 1
+	is.NoErr(lib.WaitForIndexing())
+}
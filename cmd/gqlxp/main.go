@@ -2,14 +2,22 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"charm.land/fang/v2"
 	"github.com/tonysyu/gqlxp/cli"
+	"github.com/tonysyu/gqlxp/cli/exitcode"
+	"github.com/tonysyu/gqlxp/library"
 )
 
 func main() {
-	if err := fang.Execute(context.Background(), cli.NewRootCmd()); err != nil {
-		os.Exit(1)
+	lib := library.NewLibrary()
+	err := fang.Execute(context.Background(), cli.NewRootCmdWithLibrary(lib), fang.WithErrorHandler(cli.HandleError))
+	// Commands index the schemas they add or update in the background, so wait for them to
+	// finish, even if the command failed, before exiting
+	if indexErr := lib.WaitForIndexing(); indexErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", indexErr)
 	}
+	os.Exit(exitcode.FromError(err))
 }
@@ -0,0 +1,125 @@
+package gql
+
+import (
+	"sort"
+	"strings"
+)
+
+// ChangeKind describes how a schema member differs between two schemas.
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// Change is a single difference between two schemas.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	// Path identifies the member, e.g. "User", "User.name", "Role.ADMIN", or "@auth".
+	Path string `json:"path"`
+	// Old and New are the member's definitions, e.g. a field signature; empty when absent.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// DiffSchemas returns the types, fields, enum values, and directives that differ between
+// old and new, sorted by path. Members of added or removed types are not listed separately.
+func DiffSchemas(old, new *GraphQLSchema) []Change {
+	oldMembers := schemaMembers(old)
+	newMembers := schemaMembers(new)
+
+	var changes []Change
+	for path, oldDef := range oldMembers {
+		newDef, ok := newMembers[path]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Kind: ChangeRemoved, Path: path, Old: oldDef})
+		case newDef != oldDef:
+			changes = append(changes, Change{Kind: ChangeChanged, Path: path, Old: oldDef, New: newDef})
+		}
+	}
+	for path, newDef := range newMembers {
+		if _, ok := oldMembers[path]; !ok {
+			changes = append(changes, Change{Kind: ChangeAdded, Path: path, New: newDef})
+		}
+	}
+
+	changes = withoutMembersOfChangedTypes(changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// withoutMembersOfChangedTypes drops changes to the members of types that were added or removed.
+func withoutMembersOfChangedTypes(changes []Change) []Change {
+	typeChanges := make(map[string]ChangeKind)
+	for _, c := range changes {
+		if !strings.Contains(c.Path, ".") && c.Kind != ChangeChanged {
+			typeChanges[c.Path] = c.Kind
+		}
+	}
+	var filtered []Change
+	for _, c := range changes {
+		parent, _, isMember := strings.Cut(c.Path, ".")
+		if isMember && typeChanges[parent] == c.Kind {
+			continue
+		}
+		filtered = append(filtered, c)
+	}
+	return filtered
+}
+
+// schemaMembers flattens a schema into member paths mapped to their definitions.
+func schemaMembers(s *GraphQLSchema) map[string]string {
+	members := make(map[string]string)
+	s.Walk(SchemaVisitor{
+		VisitField: func(ctx VisitContext, name string, f *Field) {
+			members[ctx.Kind] = "type " + ctx.Kind
+			members[ctx.Kind+"."+name] = f.Signature()
+		},
+		VisitObject: func(ctx VisitContext, name string, obj *Object) {
+			members[name] = "type " + name + implementsClause(obj.Interfaces())
+		},
+		VisitInterface: func(ctx VisitContext, name string, iface *Interface) {
+			members[name] = "interface " + name + implementsClause(iface.Interfaces())
+		},
+		VisitInput: func(ctx VisitContext, name string, input *InputObject) {
+			members[name] = "input " + name
+		},
+		VisitEnum: func(ctx VisitContext, name string, enum *Enum) {
+			members[name] = "enum " + name
+		},
+		VisitScalar: func(ctx VisitContext, name string, scalar *Scalar) {
+			members[name] = "scalar " + name
+		},
+		VisitUnion: func(ctx VisitContext, name string, union *Union) {
+			members[name] = "union " + name + " = " + strings.Join(union.Types(), " | ")
+		},
+		VisitDirective: func(ctx VisitContext, name string, directive *DirectiveDef) {
+			members["@"+name] = directive.Signature()
+		},
+		VisitObjectField: func(ctx VisitContext, field *Field) {
+			members[ctx.ParentName+"."+field.Name()] = field.Signature()
+		},
+		VisitInterfaceField: func(ctx VisitContext, field *Field) {
+			members[ctx.ParentName+"."+field.Name()] = field.Signature()
+		},
+		VisitInputField: func(ctx VisitContext, field *Field) {
+			members[ctx.ParentName+"."+field.Name()] = field.Signature()
+		},
+		VisitEnumValue: func(ctx VisitContext, value *EnumValue) {
+			members[ctx.ParentName+"."+value.Name()] = value.Name()
+		},
+	})
+	return members
+}
+
+func implementsClause(interfaces []string) string {
+	if len(interfaces) == 0 {
+		return ""
+	}
+	return " implements " + strings.Join(interfaces, " & ")
+}
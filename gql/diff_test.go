@@ -0,0 +1,71 @@
+package gql_test
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/gql"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	is := is.New(t)
+	old, err := gql.ParseSchema([]byte(`
+		type Query {
+			user(id: ID!): User
+			legacy: String
+		}
+		type User {
+			id: ID!
+			name: String
+		}
+		type Session {
+			token: String!
+		}
+		enum Role {
+			ADMIN
+			GUEST
+		}
+	`))
+	is.NoErr(err)
+	new, err := gql.ParseSchema([]byte(`
+		type Query {
+			user(id: ID!, active: Boolean): User
+		}
+		type User {
+			id: ID!
+			name: String!
+			email: String
+		}
+		type Team {
+			members: [User!]!
+		}
+		enum Role {
+			ADMIN
+			MEMBER
+		}
+		directive @auth(role: Role!) on FIELD_DEFINITION
+	`))
+	is.NoErr(err)
+
+	changes := gql.DiffSchemas(&old, &new)
+
+	is.Equal(changes, []gql.Change{
+		{Kind: gql.ChangeAdded, Path: "@auth", New: "@auth(role: Role!)"},
+		{Kind: gql.ChangeRemoved, Path: "Query.legacy", Old: "legacy: String"},
+		{Kind: gql.ChangeChanged, Path: "Query.user", Old: "user(id: ID!): User", New: "user(id: ID!, active: Boolean): User"},
+		{Kind: gql.ChangeRemoved, Path: "Role.GUEST", Old: "GUEST"},
+		{Kind: gql.ChangeAdded, Path: "Role.MEMBER", New: "MEMBER"},
+		{Kind: gql.ChangeRemoved, Path: "Session", Old: "type Session"}, // members of removed types are omitted
+		{Kind: gql.ChangeAdded, Path: "Team", New: "type Team"},
+		{Kind: gql.ChangeAdded, Path: "User.email", New: "email: String"},
+		{Kind: gql.ChangeChanged, Path: "User.name", Old: "name: String", New: "name: String!"},
+	})
+}
+
+func TestDiffSchemas_Identical(t *testing.T) {
+	is := is.New(t)
+	schema, err := gql.ParseSchema([]byte(walkTestSchema))
+	is.NoErr(err)
+
+	is.Equal(len(gql.DiffSchemas(&schema, &schema)), 0)
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -15,6 +17,9 @@ import (
 type ClientOptions struct {
 	Headers map[string]string
 	Timeout time.Duration
+	// Method is the HTTP method used for requests: POST (the default) or GET.
+	// GET requests encode the operation in the URL query string.
+	Method string
 }
 
 // DefaultClientOptions returns default client configuration.
@@ -24,6 +29,7 @@ func DefaultClientOptions() ClientOptions {
 			"Content-Type": "application/json",
 		},
 		Timeout: 30 * time.Second,
+		Method:  http.MethodPost,
 	}
 }
 
@@ -32,21 +38,103 @@ func IsURL(arg string) bool {
 	return strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://")
 }
 
+// NewClientOptions returns default client configuration with custom headers applied.
+// headers use the "Key: Value" format accepted by ParseHeaders.
+func NewClientOptions(headers []string) (ClientOptions, error) {
+	opts := DefaultClientOptions()
+	if len(headers) == 0 {
+		return opts, nil
+	}
+	customHeaders, err := ParseHeaders(headers)
+	if err != nil {
+		return ClientOptions{}, fmt.Errorf("failed to parse headers: %w", err)
+	}
+	for k, v := range customHeaders {
+		opts.Headers[k] = v
+	}
+	return opts, nil
+}
+
 // FetchSchema fetches a GraphQL schema via introspection from the given endpoint.
+// The server is probed first so that the richest supported introspection query is used.
 func FetchSchema(ctx context.Context, endpoint string, opts ClientOptions) (*Response, error) {
-	// Build request body
-	reqBody := map[string]string{
-		"query": Query,
+	caps, err := ProbeCapabilities(ctx, endpoint, opts)
+	if err != nil {
+		return nil, err
+	}
+	return FetchSchemaWithCapabilities(ctx, endpoint, caps, opts)
+}
+
+// ProbeCapabilities detects which optional introspection features the endpoint supports.
+// Servers that reject the probe are assumed to support only the standard query.
+func ProbeCapabilities(ctx context.Context, endpoint string, opts ClientOptions) (Capabilities, error) {
+	body, err := send(ctx, endpoint, OperationRequest{Query: CapabilityQuery}, opts)
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		// Some servers reject invalid queries with a non-200 status instead of GraphQL errors
+		return Capabilities{}, nil
+	} else if err != nil {
+		return Capabilities{}, err
+	}
+
+	var probeResp capabilityResponse
+	if err := json.Unmarshal(body, &probeResp); err != nil {
+		return Capabilities{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(probeResp.Errors) > 0 {
+		return Capabilities{}, nil
+	}
+	return probeResp.capabilities(), nil
+}
+
+// FetchSchemaWithCapabilities fetches a GraphQL schema using the introspection query for caps.
+func FetchSchemaWithCapabilities(ctx context.Context, endpoint string, caps Capabilities, opts ClientOptions) (*Response, error) {
+	body, err := send(ctx, endpoint, OperationRequest{Query: BuildQuery(caps)}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse response
+	var introspectionResp Response
+	if err := json.Unmarshal(body, &introspectionResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	// Check for GraphQL errors
+	if len(introspectionResp.Errors) > 0 {
+		return nil, fmt.Errorf("GraphQL error: %s", introspectionResp.Errors[0].Message)
+	}
+
+	// Validate response has data
+	if introspectionResp.Data == nil {
+		return nil, fmt.Errorf("introspection response missing data")
 	}
-	bodyBytes, err := json.Marshal(reqBody)
+
+	return &introspectionResp, nil
+}
+
+// Execute sends an arbitrary GraphQL operation to the given endpoint.
+// GraphQL errors are returned in the response rather than as an error, since a
+// response may contain both data and errors.
+func Execute(ctx context.Context, endpoint string, req OperationRequest, opts ClientOptions) (*OperationResponse, error) {
+	body, err := send(ctx, endpoint, req, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
+	}
+
+	var opResp OperationResponse
+	if err := json.Unmarshal(body, &opResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(bodyBytes))
+	return &opResp, nil
+}
+
+// send sends an operation to endpoint using opts.Method and returns the raw response body.
+func send(ctx context.Context, endpoint string, opReq OperationRequest, opts ClientOptions) ([]byte, error) {
+	req, err := newHTTPRequest(ctx, endpoint, opReq, opts.Method)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
 	// Set headers
@@ -70,26 +158,61 @@ func FetchSchema(ctx context.Context, endpoint string, opts ClientOptions) (*Res
 
 	// Check HTTP status
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+		return nil, &statusError{code: resp.StatusCode, body: string(body)}
 	}
 
-	// Parse response
-	var introspectionResp Response
-	if err := json.Unmarshal(body, &introspectionResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
+	return body, nil
+}
 
-	// Check for GraphQL errors
-	if len(introspectionResp.Errors) > 0 {
-		return nil, fmt.Errorf("GraphQL error: %s", introspectionResp.Errors[0].Message)
+// newHTTPRequest encodes opReq as a JSON body for POST, or as URL query parameters for GET.
+func newHTTPRequest(ctx context.Context, endpoint string, opReq OperationRequest, method string) (*http.Request, error) {
+	switch strings.ToUpper(method) {
+	case "", http.MethodPost:
+		bodyBytes, err := json.Marshal(opReq)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(bodyBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		return req, nil
+	case http.MethodGet:
+		reqURL, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		params := reqURL.Query()
+		params.Set("query", opReq.Query)
+		if opReq.OperationName != "" {
+			params.Set("operationName", opReq.OperationName)
+		}
+		if opReq.Variables != nil {
+			variables, err := json.Marshal(opReq.Variables)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal variables: %w", err)
+			}
+			params.Set("variables", string(variables))
+		}
+		reqURL.RawQuery = params.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		return req, nil
+	default:
+		return nil, fmt.Errorf("unsupported HTTP method '%s': expected GET or POST", method)
 	}
+}
 
-	// Validate response has data
-	if introspectionResp.Data == nil {
-		return nil, fmt.Errorf("introspection response missing data")
-	}
+// statusError is returned by send when the server responds with a non-200 status.
+type statusError struct {
+	code int
+	body string
+}
 
-	return &introspectionResp, nil
+func (e *statusError) Error() string {
+	return fmt.Sprintf("server returned status %d: %s", e.code, e.body)
 }
 
 // ParseHeaders parses header strings in "Key: Value" format.
@@ -0,0 +1,136 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestBuildQuery(t *testing.T) {
+	is := is.New(t)
+
+	standard := BuildQuery(Capabilities{})
+	modern := BuildQuery(Capabilities{
+		SpecifiedByURL:        true,
+		IsOneOf:               true,
+		DirectiveIsRepeatable: true,
+		InputValueDeprecation: true,
+		AppliedDirectives:     true,
+	})
+
+	is.True(!strings.Contains(standard, "specifiedByURL")) // optional fields are omitted by default
+	is.True(!strings.Contains(standard, "includeDeprecated: true) {\n    ...InputValue"))
+	is.True(!strings.Contains(standard, "AppliedDirective"))
+	is.True(strings.Contains(modern, "specifiedByURL"))
+	is.True(strings.Contains(modern, "isOneOf"))
+	is.True(strings.Contains(modern, "isRepeatable"))
+	is.True(strings.Contains(modern, "inputFields(includeDeprecated: true)"))
+	is.True(strings.Contains(modern, "fragment AppliedDirective on __AppliedDirective"))
+}
+
+func TestFetchSchema_UsesProbedCapabilities(t *testing.T) {
+	is := is.New(t)
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		queries = append(queries, req.Query)
+		if strings.Contains(req.Query, "IntrospectionCapabilities") {
+			_, _ = w.Write([]byte(`{"data": {
+				"type": {"fields": [{"name": "specifiedByURL", "args": []}]},
+				"directive": {"fields": [{"name": "isRepeatable", "args": []}]}
+			}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"__schema": {"queryType": {"name": "Query"}, "types": []}}}`))
+	}))
+	defer server.Close()
+
+	resp, err := FetchSchema(context.Background(), server.URL, DefaultClientOptions())
+
+	is.NoErr(err)
+	is.True(resp.Data != nil)
+	is.Equal(len(queries), 2)                                   // probe, then introspection
+	is.True(strings.Contains(queries[1], "specifiedByURL"))     // supported features are requested
+	is.True(strings.Contains(queries[1], "isRepeatable"))       // ...for directives too
+	is.True(!strings.Contains(queries[1], "isOneOf"))           // unsupported features are not
+	is.True(!strings.Contains(queries[1], "appliedDirectives")) // ...including extensions
+}
+
+func TestProbeCapabilities_RejectedProbeFallsBackToStandard(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errors": [{"message": "introspection of __type is disabled"}]}`))
+	}))
+	defer server.Close()
+
+	caps, err := ProbeCapabilities(context.Background(), server.URL, DefaultClientOptions())
+
+	is.NoErr(err)
+	is.Equal(caps, Capabilities{})
+}
+
+func TestExecute_GetMethodEncodesOperationInURL(t *testing.T) {
+	is := is.New(t)
+	var method, query, variables string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		query = r.URL.Query().Get("query")
+		variables = r.URL.Query().Get("variables")
+		_, _ = w.Write([]byte(`{"data": {"hello": "world"}}`))
+	}))
+	defer server.Close()
+	opts := DefaultClientOptions()
+	opts.Method = http.MethodGet
+
+	req := OperationRequest{Query: "{ hello }", Variables: map[string]any{"id": 1}}
+	resp, err := Execute(context.Background(), server.URL, req, opts)
+
+	is.NoErr(err)
+	is.Equal(string(resp.Data), `{"hello": "world"}`)
+	is.Equal(method, http.MethodGet)
+	is.Equal(query, "{ hello }")
+	is.Equal(variables, `{"id":1}`)
+}
+
+func TestExecute(t *testing.T) {
+	is := is.New(t)
+	var received OperationRequest
+	var authHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		_, _ = w.Write([]byte(`{
+			"data": {"user": null},
+			"errors": [{"message": "Not found", "locations": [{"line": 1, "column": 9}], "path": ["user"]}]
+		}`))
+	}))
+	defer server.Close()
+	opts, err := NewClientOptions([]string{"Authorization: Bearer token"})
+	is.NoErr(err)
+
+	req := OperationRequest{
+		Query:     `query { user(id: $id) { id } }`,
+		Variables: map[string]any{"id": "1"},
+	}
+	resp, err := Execute(context.Background(), server.URL, req, opts)
+
+	is.NoErr(err)
+	is.Equal(authHeader, "Bearer token")    // custom headers are sent
+	is.Equal(received.Query, req.Query)     // operation is sent as query
+	is.Equal(received.Variables["id"], "1") // variables are sent
+	is.Equal(string(resp.Data), `{"user": null}`)
+	is.Equal(resp.Errors, []Error{{ // errors are decoded
+		Message:   "Not found",
+		Locations: []ErrorLocation{{Line: 1, Column: 9}},
+		Path:      []any{"user"},
+	}})
+}
@@ -0,0 +1,278 @@
+package introspection
+
+import (
+	"strings"
+
+	"github.com/tonysyu/gqlxp/gql"
+)
+
+// defaultDeprecationReason is the reason reported for @deprecated without arguments.
+const defaultDeprecationReason = "No longer supported"
+
+// builtInScalars are the scalars every schema includes, in the order they are exported.
+var builtInScalars = []string{"Boolean", "Float", "ID", "Int", "String"}
+
+// FromSchema converts a parsed schema into an introspection response, the inverse of ToSDL.
+// The result uses the modern introspection fields (specifiedByURL, isRepeatable, isOneOf,
+// and deprecated arguments) but not extensions such as appliedDirectives.
+func FromSchema(schema gql.GraphQLSchema) *Response {
+	c := exporter{schema: &schema}
+	s := Schema{
+		Types:      c.types(),
+		Directives: c.directives(),
+	}
+	if len(schema.Query) > 0 {
+		s.QueryType = &TypeName{Name: "Query"}
+	}
+	if len(schema.Mutation) > 0 {
+		s.MutationType = &TypeName{Name: "Mutation"}
+	}
+	if _, ok := schema.Object["Subscription"]; ok {
+		s.SubscriptionType = &TypeName{Name: "Subscription"}
+	}
+	return &Response{Data: &Data{Schema: s}}
+}
+
+// exporter converts gql types into introspection types.
+type exporter struct {
+	schema *gql.GraphQLSchema
+}
+
+func (c exporter) types() []FullType {
+	s := c.schema
+	var types []FullType
+	if len(s.Query) > 0 {
+		types = append(types, c.objectType("Query", "", nil, s.QueryFields()))
+	}
+	if len(s.Mutation) > 0 {
+		types = append(types, c.objectType("Mutation", "", nil, s.MutationFields()))
+	}
+	for _, o := range gql.CollectAndSortMapValues(s.Object) {
+		types = append(types, c.objectType(o.Name(), o.Description(), o.Interfaces(), o.Fields()))
+	}
+	for _, i := range gql.CollectAndSortMapValues(s.Interface) {
+		t := c.objectType(i.Name(), i.Description(), i.Interfaces(), i.Fields())
+		t.Kind = "INTERFACE"
+		t.PossibleTypes = c.implementations(i.Name())
+		types = append(types, t)
+	}
+	for _, u := range gql.CollectAndSortMapValues(s.Union) {
+		t := FullType{Kind: "UNION", Name: u.Name(), Description: optional(u.Description()), PossibleTypes: []TypeRef{}}
+		for _, member := range u.Types() {
+			t.PossibleTypes = append(t.PossibleTypes, c.namedRef(member))
+		}
+		types = append(types, t)
+	}
+	for _, e := range gql.CollectAndSortMapValues(s.Enum) {
+		t := FullType{Kind: "ENUM", Name: e.Name(), Description: optional(e.Description()), EnumValues: []EnumValue{}}
+		for _, v := range e.Values() {
+			reason, deprecated := deprecation(v.Directives())
+			t.EnumValues = append(t.EnumValues, EnumValue{
+				Name:              v.Name(),
+				Description:       optional(v.Description()),
+				IsDeprecated:      deprecated,
+				DeprecationReason: reason,
+			})
+		}
+		types = append(types, t)
+	}
+	for _, i := range gql.CollectAndSortMapValues(s.Input) {
+		t := FullType{Kind: "INPUT_OBJECT", Name: i.Name(), Description: optional(i.Description()), InputFields: []InputValue{}}
+		_, t.IsOneOf = findDirective(i.Directives(), "oneOf")
+		for _, f := range i.Fields() {
+			t.InputFields = append(t.InputFields, c.inputValue(f.Name(), f.Description(), f.TypeString(), f.DefaultValue(), f.Directives()))
+		}
+		types = append(types, t)
+	}
+	for _, sc := range gql.CollectAndSortMapValues(s.Scalar) {
+		t := FullType{Kind: "SCALAR", Name: sc.Name(), Description: optional(sc.Description())}
+		if d, ok := findDirective(sc.Directives(), "specifiedBy"); ok {
+			if url, ok := stringArgument(d, "url"); ok {
+				t.SpecifiedByURL = &url
+			}
+		}
+		types = append(types, t)
+	}
+	for _, name := range builtInScalars {
+		types = append(types, FullType{Kind: "SCALAR", Name: name})
+	}
+	return types
+}
+
+func (c exporter) objectType(name, description string, interfaces []string, fields []*gql.Field) FullType {
+	t := FullType{
+		Kind:        "OBJECT",
+		Name:        name,
+		Description: optional(description),
+		Fields:      []Field{},
+		Interfaces:  []TypeRef{},
+	}
+	for _, iface := range interfaces {
+		t.Interfaces = append(t.Interfaces, c.namedRef(iface))
+	}
+	for _, f := range fields {
+		reason, deprecated := deprecation(f.Directives())
+		field := Field{
+			Name:              f.Name(),
+			Description:       optional(f.Description()),
+			Args:              []InputValue{},
+			Type:              c.typeRef(f.TypeString()),
+			IsDeprecated:      deprecated,
+			DeprecationReason: reason,
+		}
+		for _, arg := range f.Arguments() {
+			field.Args = append(field.Args, c.inputValue(arg.Name(), arg.Description(), arg.TypeString(), arg.DefaultValue(), arg.Directives()))
+		}
+		t.Fields = append(t.Fields, field)
+	}
+	return t
+}
+
+func (c exporter) inputValue(name, description, typeString, defaultValue string, directives []*gql.AppliedDirective) InputValue {
+	reason, deprecated := deprecation(directives)
+	return InputValue{
+		Name:              name,
+		Description:       optional(description),
+		Type:              c.typeRef(typeString),
+		DefaultValue:      optional(defaultValue),
+		IsDeprecated:      deprecated,
+		DeprecationReason: reason,
+	}
+}
+
+// implementations returns the object types that implement the named interface.
+func (c exporter) implementations(name string) []TypeRef {
+	refs := []TypeRef{}
+	for _, o := range gql.CollectAndSortMapValues(c.schema.Object) {
+		for _, iface := range o.Interfaces() {
+			if iface == name {
+				refs = append(refs, c.namedRef(o.Name()))
+				break
+			}
+		}
+	}
+	return refs
+}
+
+func (c exporter) directives() []Directive {
+	directives := standardDirectives()
+	for _, d := range gql.CollectAndSortMapValues(c.schema.Directive) {
+		directive := Directive{
+			Name:         d.Name(),
+			Description:  optional(d.Description()),
+			Locations:    d.Locations(),
+			Args:         []InputValue{},
+			IsRepeatable: d.IsRepeatable(),
+		}
+		for _, arg := range d.Arguments() {
+			directive.Args = append(directive.Args, c.inputValue(arg.Name(), arg.Description(), arg.TypeString(), arg.DefaultValue(), arg.Directives()))
+		}
+		directives = append(directives, directive)
+	}
+	return directives
+}
+
+// typeRef converts a type string such as "[String!]!" into a TypeRef.
+func (c exporter) typeRef(typeString string) TypeRef {
+	switch {
+	case strings.HasSuffix(typeString, "!"):
+		inner := c.typeRef(strings.TrimSuffix(typeString, "!"))
+		return TypeRef{Kind: "NON_NULL", OfType: &inner}
+	case strings.HasPrefix(typeString, "[") && strings.HasSuffix(typeString, "]"):
+		inner := c.typeRef(typeString[1 : len(typeString)-1])
+		return TypeRef{Kind: "LIST", OfType: &inner}
+	default:
+		return c.namedRef(typeString)
+	}
+}
+
+// namedRef returns a reference to a named type with its introspection kind.
+func (c exporter) namedRef(name string) TypeRef {
+	kind := "SCALAR"
+	switch c.schema.NameToKind[name] {
+	case "Query", "Mutation", "Object":
+		kind = "OBJECT"
+	case "Input":
+		kind = "INPUT_OBJECT"
+	case "Enum":
+		kind = "ENUM"
+	case "Interface":
+		kind = "INTERFACE"
+	case "Union":
+		kind = "UNION"
+	}
+	return TypeRef{Kind: kind, Name: &name}
+}
+
+// standardDirectives returns the directives defined by the GraphQL specification.
+func standardDirectives() []Directive {
+	boolean := TypeRef{Kind: "SCALAR", Name: optional("Boolean")}
+	str := TypeRef{Kind: "SCALAR", Name: optional("String")}
+	ifArg := InputValue{Name: "if", Type: TypeRef{Kind: "NON_NULL", OfType: &boolean}}
+	return []Directive{
+		{
+			Name:      "include",
+			Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+			Args:      []InputValue{ifArg},
+		},
+		{
+			Name:      "skip",
+			Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+			Args:      []InputValue{ifArg},
+		},
+		{
+			Name:      "deprecated",
+			Locations: []string{"FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INPUT_FIELD_DEFINITION", "ENUM_VALUE"},
+			Args:      []InputValue{{Name: "reason", Type: str, DefaultValue: optional(`"` + defaultDeprecationReason + `"`)}},
+		},
+		{
+			Name:      "specifiedBy",
+			Locations: []string{"SCALAR"},
+			Args:      []InputValue{{Name: "url", Type: TypeRef{Kind: "NON_NULL", OfType: &str}}},
+		},
+		{
+			Name:      "oneOf",
+			Locations: []string{"INPUT_OBJECT"},
+			Args:      []InputValue{},
+		},
+	}
+}
+
+// deprecation returns the deprecation reason and whether directives include @deprecated.
+func deprecation(directives []*gql.AppliedDirective) (*string, bool) {
+	d, ok := findDirective(directives, "deprecated")
+	if !ok {
+		return nil, false
+	}
+	reason, ok := stringArgument(d, "reason")
+	if !ok {
+		reason = defaultDeprecationReason
+	}
+	return &reason, true
+}
+
+func findDirective(directives []*gql.AppliedDirective, name string) (*gql.AppliedDirective, bool) {
+	for _, d := range directives {
+		if d.Name() == name {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// stringArgument returns the unquoted value of a string argument of an applied directive.
+func stringArgument(d *gql.AppliedDirective, name string) (string, bool) {
+	value, ok := d.FormattedArguments()[name]
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(value, `"`), `"`), true
+}
+
+// optional returns nil for empty strings, matching introspection's nullable fields.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,143 @@
+package introspection
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/gql"
+)
+
+const exportTestSchema = `type Query {
+  "Find a node"
+  node(id: ID!, legacy: String @deprecated(reason: "Use id")): Node
+  search(filter: Filter, first: Int = 10): [Result!]! @deprecated
+}
+
+type Bot implements Node {
+  id: ID!
+}
+
+scalar Date @specifiedBy(url: "https://example.com/date")
+
+input Filter @oneOf {
+  id: ID
+  name: String
+}
+
+interface Node {
+  id: ID!
+}
+
+enum Role {
+  ADMIN
+  USER @deprecated(reason: "Use ADMIN")
+}
+
+union Result = User | Bot
+
+type User implements Node {
+  id: ID!
+  role: Role
+  joined: Date
+}
+
+directive @auth(role: Role = ADMIN) repeatable on FIELD_DEFINITION
+`
+
+func TestFromSchema_RoundTripsThroughSDL(t *testing.T) {
+	is := is.New(t)
+	schema, err := gql.ParseSchema([]byte(exportTestSchema))
+	is.NoErr(err)
+
+	resp := FromSchema(schema)
+	sdl, err := ToSDL(resp)
+	is.NoErr(err)
+	roundTripped, err := gql.ParseSchema(sdl)
+	is.NoErr(err)
+
+	is.Equal(len(roundTripped.Query), len(schema.Query))
+	is.Equal(roundTripped.QueryFields()[0].Signature(), "node(id: ID!, legacy: String): Node") // field order is kept
+	is.Equal(len(roundTripped.Object), len(schema.Object))
+	is.Equal(roundTripped.Union["Result"].Types(), []string{"User", "Bot"})
+	is.True(roundTripped.Directive["auth"].IsRepeatable())
+	is.Equal(len(roundTripped.Scalar["Date"].Directives()), 1)  // @specifiedBy is kept
+	is.Equal(len(roundTripped.Input["Filter"].Directives()), 1) // @oneOf is kept
+}
+
+func TestFromSchema_IntrospectionFields(t *testing.T) {
+	is := is.New(t)
+	schema, err := gql.ParseSchema([]byte(exportTestSchema))
+	is.NoErr(err)
+
+	resp := FromSchema(schema)
+
+	types := make(map[string]FullType)
+	for _, ft := range resp.Data.Schema.Types {
+		types[ft.Name] = ft
+	}
+	is.Equal(resp.Data.Schema.QueryType.Name, "Query")
+	is.Equal(types["String"].Kind, "SCALAR") // built-in scalars are included
+	is.Equal(*types["Date"].SpecifiedByURL, "https://example.com/date")
+	is.True(types["Filter"].IsOneOf)
+
+	legacy := types["Query"].Fields[0].Args[1]
+	is.True(legacy.IsDeprecated)
+	is.Equal(*legacy.DeprecationReason, "Use id")
+	search := types["Query"].Fields[1]
+	is.Equal(*search.DeprecationReason, "No longer supported") // default reason
+	is.Equal(*search.Args[1].DefaultValue, "10")
+	is.Equal(formatTypeRef(&search.Type), "[Result!]!")
+	is.Equal(*search.Type.OfType.OfType.OfType.Name, "Result")
+	is.Equal(search.Type.OfType.OfType.OfType.Kind, "UNION")
+
+	is.Equal(len(types["Node"].PossibleTypes), 2) // objects implementing the interface
+}
+
+func TestParseResult(t *testing.T) {
+	bare := `{"__schema": {"queryType": {"name": "Query"}, "types": [{"kind": "OBJECT", "name": "Query", "fields": []}]}}`
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "bare result", content: bare},
+		{name: "data wrapper", content: `{"data": ` + bare + `}`},
+		{name: "errors", content: `{"errors": [{"message": "denied"}]}`, wantErr: true},
+		{name: "not introspection", content: `{"hello": "world"}`, wantErr: true},
+		{name: "invalid JSON", content: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			resp, err := ParseResult([]byte(tt.content))
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(resp.Data.Schema.QueryType.Name, "Query")
+		})
+	}
+}
+
+func TestFromSchema_MarshalsSpecNulls(t *testing.T) {
+	is := is.New(t)
+	schema, err := gql.ParseSchema([]byte(`type Query { hello: String }`))
+	is.NoErr(err)
+
+	data, err := json.Marshal(FromSchema(schema).Data)
+	is.NoErr(err)
+
+	var decoded struct {
+		Schema struct {
+			Types []map[string]any `json:"types"`
+		} `json:"__schema"`
+	}
+	is.NoErr(json.Unmarshal(data, &decoded))
+	query := decoded.Schema.Types[0]
+	is.Equal(query["name"], "Query")
+	is.Equal(query["interfaces"], []any{}) // objects have an empty interface list
+	is.Equal(query["inputFields"], nil)    // fields that don't apply to the kind are null
+}
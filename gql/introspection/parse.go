@@ -0,0 +1,50 @@
+package introspection
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// IsJSON reports whether content looks like a JSON document rather than SDL.
+func IsJSON(content []byte) bool {
+	trimmed := bytes.TrimSpace(content)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// ParseResult parses an introspection result file. Both the full response
+// ({"data": {"__schema": ...}}) and the bare result ({"__schema": ...}) are accepted.
+func ParseResult(content []byte) (*Response, error) {
+	var resp Response
+	if err := json.Unmarshal(content, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse introspection JSON: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("introspection result contains errors: %s", resp.Errors[0].Message)
+	}
+	if resp.Data != nil && resp.Data.Schema.Types != nil {
+		return &resp, nil
+	}
+
+	var data Data
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse introspection JSON: %w", err)
+	}
+	if data.Schema.Types == nil {
+		return nil, fmt.Errorf("introspection JSON has no __schema types")
+	}
+	return &Response{Data: &data}, nil
+}
+
+// EnsureSDL returns schema file content as SDL, converting introspection JSON results.
+// SDL content is returned unchanged.
+func EnsureSDL(content []byte) ([]byte, error) {
+	if !IsJSON(content) {
+		return content, nil
+	}
+	resp, err := ParseResult(content)
+	if err != nil {
+		return nil, err
+	}
+	return ToSDL(resp)
+}
@@ -1,9 +1,124 @@
 package introspection
 
-// Query is the standard GraphQL introspection query.
-const Query = `
+import (
+	"strings"
+	"text/template"
+)
+
+// Query is the standard GraphQL introspection query, which is supported by all servers.
+var Query = BuildQuery(Capabilities{})
+
+// Capabilities records which optional introspection features a server supports.
+// The zero value describes a server that only supports the standard query.
+type Capabilities struct {
+	SchemaDescription     bool // __Schema.description
+	SpecifiedByURL        bool // __Type.specifiedByURL
+	IsOneOf               bool // __Type.isOneOf
+	DirectiveIsRepeatable bool // __Directive.isRepeatable
+	InputValueDeprecation bool // includeDeprecated on args/inputFields and __InputValue.isDeprecated
+	AppliedDirectives     bool // appliedDirectives on types, fields, enum values, and input values
+}
+
+// CapabilityQuery lists the fields of the introspection types so that optional features
+// can be detected before requesting them.
+const CapabilityQuery = `
+query IntrospectionCapabilities {
+  schema: __type(name: "__Schema") { fields { name } }
+  type: __type(name: "__Type") { fields { name args { name } } }
+  field: __type(name: "__Field") { fields { name args { name } } }
+  directive: __type(name: "__Directive") { fields { name args { name } } }
+  inputValue: __type(name: "__InputValue") { fields { name } }
+  enumValue: __type(name: "__EnumValue") { fields { name } }
+}
+`
+
+// capabilityResponse is the response to CapabilityQuery.
+type capabilityResponse struct {
+	Data *struct {
+		Schema     *capabilityType `json:"schema"`
+		Type       *capabilityType `json:"type"`
+		Field      *capabilityType `json:"field"`
+		Directive  *capabilityType `json:"directive"`
+		InputValue *capabilityType `json:"inputValue"`
+		EnumValue  *capabilityType `json:"enumValue"`
+	} `json:"data"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+type capabilityType struct {
+	Fields []struct {
+		Name string `json:"name"`
+		Args []struct {
+			Name string `json:"name"`
+		} `json:"args"`
+	} `json:"fields"`
+}
+
+// hasField reports whether the introspection type has a field with the given name.
+func (t *capabilityType) hasField(name string) bool {
+	return t.hasArgument(name, "")
+}
+
+// hasArgument reports whether the field exists and accepts the given argument.
+// An empty argument name only checks for the field.
+func (t *capabilityType) hasArgument(field, arg string) bool {
+	if t == nil {
+		return false
+	}
+	for _, f := range t.Fields {
+		if f.Name != field {
+			continue
+		}
+		if arg == "" {
+			return true
+		}
+		for _, a := range f.Args {
+			if a.Name == arg {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// capabilities converts the probe response into feature flags.
+func (r *capabilityResponse) capabilities() Capabilities {
+	if r.Data == nil {
+		return Capabilities{}
+	}
+	d := r.Data
+	return Capabilities{
+		SchemaDescription:     d.Schema.hasField("description"),
+		SpecifiedByURL:        d.Type.hasField("specifiedByURL"),
+		IsOneOf:               d.Type.hasField("isOneOf"),
+		DirectiveIsRepeatable: d.Directive.hasField("isRepeatable"),
+		InputValueDeprecation: d.Type.hasArgument("inputFields", "includeDeprecated") &&
+			d.Field.hasArgument("args", "includeDeprecated") &&
+			d.Directive.hasArgument("args", "includeDeprecated") &&
+			d.InputValue.hasField("isDeprecated"),
+		AppliedDirectives: d.Type.hasField("appliedDirectives") &&
+			d.Field.hasField("appliedDirectives") &&
+			d.InputValue.hasField("appliedDirectives") &&
+			d.EnumValue.hasField("appliedDirectives"),
+	}
+}
+
+// BuildQuery returns the richest introspection query supported by a server with caps.
+func BuildQuery(caps Capabilities) string {
+	var sb strings.Builder
+	if err := queryTemplate.Execute(&sb, caps); err != nil {
+		// The template is static, so failures are programming errors
+		panic(err)
+	}
+	return sb.String()
+}
+
+var queryTemplate = template.Must(template.New("introspection").Parse(`
 query IntrospectionQuery {
   __schema {
+{{- if .SchemaDescription}}
+    description
+{{- end}}
     queryType { name }
     mutationType { name }
     subscriptionType { name }
@@ -13,8 +128,11 @@ query IntrospectionQuery {
     directives {
       name
       description
+{{- if .DirectiveIsRepeatable}}
+      isRepeatable
+{{- end}}
       locations
-      args {
+      args{{if .InputValueDeprecation}}(includeDeprecated: true){{end}} {
         ...InputValue
       }
     }
@@ -25,10 +143,21 @@ fragment FullType on __Type {
   kind
   name
   description
+{{- if .SpecifiedByURL}}
+  specifiedByURL
+{{- end}}
+{{- if .IsOneOf}}
+  isOneOf
+{{- end}}
+{{- if .AppliedDirectives}}
+  appliedDirectives {
+    ...AppliedDirective
+  }
+{{- end}}
   fields(includeDeprecated: true) {
     name
     description
-    args {
+    args{{if .InputValueDeprecation}}(includeDeprecated: true){{end}} {
       ...InputValue
     }
     type {
@@ -36,8 +165,13 @@ fragment FullType on __Type {
     }
     isDeprecated
     deprecationReason
+{{- if .AppliedDirectives}}
+    appliedDirectives {
+      ...AppliedDirective
+    }
+{{- end}}
   }
-  inputFields {
+  inputFields{{if .InputValueDeprecation}}(includeDeprecated: true){{end}} {
     ...InputValue
   }
   interfaces {
@@ -48,6 +182,11 @@ fragment FullType on __Type {
     description
     isDeprecated
     deprecationReason
+{{- if .AppliedDirectives}}
+    appliedDirectives {
+      ...AppliedDirective
+    }
+{{- end}}
   }
   possibleTypes {
     ...TypeRef
@@ -61,7 +200,26 @@ fragment InputValue on __InputValue {
     ...TypeRef
   }
   defaultValue
+{{- if .InputValueDeprecation}}
+  isDeprecated
+  deprecationReason
+{{- end}}
+{{- if .AppliedDirectives}}
+  appliedDirectives {
+    ...AppliedDirective
+  }
+{{- end}}
 }
+{{- if .AppliedDirectives}}
+
+fragment AppliedDirective on __AppliedDirective {
+  name
+  args {
+    name
+    value
+  }
+}
+{{- end}}
 
 fragment TypeRef on __Type {
   kind
@@ -95,4 +253,4 @@ fragment TypeRef on __Type {
     }
   }
 }
-`
+`))
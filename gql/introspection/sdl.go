@@ -29,6 +29,8 @@ var builtInDirectives = map[string]bool{
 	"include":     true,
 	"deprecated":  true,
 	"specifiedBy": true,
+	"oneOf":       true,
+	"defer":       true,
 }
 
 // ToSDL converts an introspection response to SDL format.
@@ -84,8 +86,10 @@ func writeSchemaDefinition(sb *strings.Builder, schema *Schema) {
 		subscriptionName = schema.SubscriptionType.Name
 	}
 
-	// Check if all root types have standard names
-	if queryName == "Query" || queryName == "" {
+	// Check if all root types have standard names. A description can only be
+	// written on a schema definition, so it always requires one.
+	hasDescription := schema.Description != nil && *schema.Description != ""
+	if !hasDescription && (queryName == "Query" || queryName == "") {
 		if mutationName == "Mutation" || mutationName == "" {
 			if subscriptionName == "Subscription" || subscriptionName == "" {
 				return
@@ -93,6 +97,7 @@ func writeSchemaDefinition(sb *strings.Builder, schema *Schema) {
 		}
 	}
 
+	writeDescription(sb, schema.Description, "")
 	sb.WriteString("schema {\n")
 	if queryName != "" {
 		sb.WriteString(fmt.Sprintf("  query: %s\n", queryName))
@@ -161,6 +166,7 @@ func writeObjectType(sb *strings.Builder, t *FullType) {
 			sb.WriteString(strings.Join(ifaces, " & "))
 		}
 	}
+	writeAppliedDirectives(sb, t.AppliedDirectives)
 
 	sb.WriteString(" {\n")
 	for _, f := range t.Fields {
@@ -185,6 +191,7 @@ func writeInterfaceType(sb *strings.Builder, t *FullType) {
 			sb.WriteString(strings.Join(ifaces, " & "))
 		}
 	}
+	writeAppliedDirectives(sb, t.AppliedDirectives)
 
 	sb.WriteString(" {\n")
 	for _, f := range t.Fields {
@@ -195,7 +202,9 @@ func writeInterfaceType(sb *strings.Builder, t *FullType) {
 
 func writeUnionType(sb *strings.Builder, t *FullType) {
 	writeDescription(sb, t.Description, "")
-	sb.WriteString(fmt.Sprintf("union %s = ", t.Name))
+	sb.WriteString(fmt.Sprintf("union %s", t.Name))
+	writeAppliedDirectives(sb, t.AppliedDirectives)
+	sb.WriteString(" = ")
 
 	var members []string
 	for _, pt := range t.PossibleTypes {
@@ -209,7 +218,9 @@ func writeUnionType(sb *strings.Builder, t *FullType) {
 
 func writeEnumType(sb *strings.Builder, t *FullType) {
 	writeDescription(sb, t.Description, "")
-	sb.WriteString(fmt.Sprintf("enum %s {\n", t.Name))
+	sb.WriteString(fmt.Sprintf("enum %s", t.Name))
+	writeAppliedDirectives(sb, t.AppliedDirectives)
+	sb.WriteString(" {\n")
 
 	for _, ev := range t.EnumValues {
 		writeDescription(sb, ev.Description, "  ")
@@ -217,6 +228,7 @@ func writeEnumType(sb *strings.Builder, t *FullType) {
 		if ev.IsDeprecated {
 			writeDeprecation(sb, ev.DeprecationReason)
 		}
+		writeAppliedDirectives(sb, ev.AppliedDirectives)
 		sb.WriteString("\n")
 	}
 
@@ -225,7 +237,12 @@ func writeEnumType(sb *strings.Builder, t *FullType) {
 
 func writeInputObjectType(sb *strings.Builder, t *FullType) {
 	writeDescription(sb, t.Description, "")
-	sb.WriteString(fmt.Sprintf("input %s {\n", t.Name))
+	sb.WriteString(fmt.Sprintf("input %s", t.Name))
+	if t.IsOneOf {
+		sb.WriteString(" @oneOf")
+	}
+	writeAppliedDirectives(sb, t.AppliedDirectives)
+	sb.WriteString(" {\n")
 
 	for _, f := range t.InputFields {
 		writeInputValue(sb, &f, "  ", false)
@@ -237,7 +254,12 @@ func writeInputObjectType(sb *strings.Builder, t *FullType) {
 
 func writeScalarType(sb *strings.Builder, t *FullType) {
 	writeDescription(sb, t.Description, "")
-	sb.WriteString(fmt.Sprintf("scalar %s\n\n", t.Name))
+	sb.WriteString(fmt.Sprintf("scalar %s", t.Name))
+	if t.SpecifiedByURL != nil && *t.SpecifiedByURL != "" {
+		sb.WriteString(fmt.Sprintf(" @specifiedBy(url: \"%s\")", escapeString(*t.SpecifiedByURL)))
+	}
+	writeAppliedDirectives(sb, t.AppliedDirectives)
+	sb.WriteString("\n\n")
 }
 
 func writeField(sb *strings.Builder, f *Field) {
@@ -261,6 +283,7 @@ func writeField(sb *strings.Builder, f *Field) {
 	if f.IsDeprecated {
 		writeDeprecation(sb, f.DeprecationReason)
 	}
+	writeAppliedDirectives(sb, f.AppliedDirectives)
 
 	sb.WriteString("\n")
 }
@@ -274,6 +297,10 @@ func writeInputValue(sb *strings.Builder, iv *InputValue, indent string, inline
 	if iv.DefaultValue != nil {
 		sb.WriteString(fmt.Sprintf(" = %s", *iv.DefaultValue))
 	}
+	if iv.IsDeprecated {
+		writeDeprecation(sb, iv.DeprecationReason)
+	}
+	writeAppliedDirectives(sb, iv.AppliedDirectives)
 }
 
 func writeDeprecation(sb *strings.Builder, reason *string) {
@@ -284,6 +311,25 @@ func writeDeprecation(sb *strings.Builder, reason *string) {
 	}
 }
 
+// writeAppliedDirectives writes directive usages reported via the appliedDirectives extension.
+// Built-in directives are skipped since they are written from dedicated introspection fields.
+func writeAppliedDirectives(sb *strings.Builder, directives []AppliedDirective) {
+	for _, d := range directives {
+		if builtInDirectives[d.Name] {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf(" @%s", d.Name))
+		if len(d.Args) == 0 {
+			continue
+		}
+		args := make([]string, len(d.Args))
+		for i, arg := range d.Args {
+			args[i] = fmt.Sprintf("%s: %s", arg.Name, arg.Value)
+		}
+		sb.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+}
+
 func writeDirective(sb *strings.Builder, d *Directive) {
 	writeDescription(sb, d.Description, "")
 	sb.WriteString(fmt.Sprintf("directive @%s", d.Name))
@@ -299,6 +345,10 @@ func writeDirective(sb *strings.Builder, d *Directive) {
 		sb.WriteString(")")
 	}
 
+	if d.IsRepeatable {
+		sb.WriteString(" repeatable")
+	}
+
 	if len(d.Locations) > 0 {
 		sb.WriteString(" on ")
 		sb.WriteString(strings.Join(d.Locations, " | "))
@@ -0,0 +1,81 @@
+package introspection
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func namedType(kind, name string) TypeRef {
+	return TypeRef{Kind: kind, Name: strPtr(name)}
+}
+
+func TestToSDL_ModernIntrospectionFeatures(t *testing.T) {
+	is := is.New(t)
+	resp := &Response{Data: &Data{Schema: Schema{
+		QueryType: &TypeName{Name: "Query"},
+		Types: []FullType{
+			{
+				Kind: "OBJECT",
+				Name: "Query",
+				Fields: []Field{{
+					Name: "search",
+					Args: []InputValue{
+						{Name: "filter", Type: namedType("INPUT_OBJECT", "Filter")},
+						{Name: "limit", Type: namedType("SCALAR", "Int"), IsDeprecated: true, DeprecationReason: strPtr("Use first")},
+					},
+					Type: namedType("SCALAR", "DateTime"),
+					AppliedDirectives: []AppliedDirective{{
+						Name: "cost",
+						Args: []AppliedDirectiveArgument{{Name: "weight", Value: "2"}},
+					}},
+				}},
+			},
+			{
+				Kind:    "INPUT_OBJECT",
+				Name:    "Filter",
+				IsOneOf: true,
+				InputFields: []InputValue{
+					{Name: "id", Type: namedType("SCALAR", "ID")},
+					{Name: "name", Type: namedType("SCALAR", "String"), IsDeprecated: true},
+				},
+			},
+			{
+				Kind:           "SCALAR",
+				Name:           "DateTime",
+				SpecifiedByURL: strPtr("https://example.com/datetime"),
+			},
+		},
+		Directives: []Directive{
+			{Name: "oneOf", Locations: []string{"INPUT_OBJECT"}},
+			{
+				Name:         "cost",
+				Locations:    []string{"FIELD_DEFINITION"},
+				Args:         []InputValue{{Name: "weight", Type: namedType("SCALAR", "Int")}},
+				IsRepeatable: true,
+			},
+		},
+	}}}
+
+	sdl, err := ToSDL(resp)
+
+	is.NoErr(err)
+	is.Equal(string(sdl), `scalar DateTime @specifiedBy(url: "https://example.com/datetime")
+
+input Filter @oneOf {
+  id: ID
+  name: String @deprecated
+}
+
+type Query {
+  search(filter: Filter, limit: Int @deprecated(reason: "Use first")): DateTime @cost(weight: 2)
+}
+
+directive @cost(weight: Int) repeatable on FIELD_DEFINITION
+
+`)
+}
@@ -1,5 +1,7 @@
 package introspection
 
+import "encoding/json"
+
 // Response represents the full introspection query response.
 type Response struct {
 	Data   *Data   `json:"data"`
@@ -13,6 +15,7 @@ type Data struct {
 
 // Schema represents the introspected GraphQL schema.
 type Schema struct {
+	Description      *string     `json:"description"`
 	QueryType        *TypeName   `json:"queryType"`
 	MutationType     *TypeName   `json:"mutationType"`
 	SubscriptionType *TypeName   `json:"subscriptionType"`
@@ -35,16 +38,22 @@ type FullType struct {
 	Interfaces    []TypeRef    `json:"interfaces"`
 	EnumValues    []EnumValue  `json:"enumValues"`
 	PossibleTypes []TypeRef    `json:"possibleTypes"`
+
+	// Optional fields, requested only when the server supports them
+	SpecifiedByURL    *string            `json:"specifiedByURL"`
+	IsOneOf           bool               `json:"isOneOf"`
+	AppliedDirectives []AppliedDirective `json:"appliedDirectives,omitempty"`
 }
 
 // Field represents a field on an object or interface type.
 type Field struct {
-	Name              string       `json:"name"`
-	Description       *string      `json:"description"`
-	Args              []InputValue `json:"args"`
-	Type              TypeRef      `json:"type"`
-	IsDeprecated      bool         `json:"isDeprecated"`
-	DeprecationReason *string      `json:"deprecationReason"`
+	Name              string             `json:"name"`
+	Description       *string            `json:"description"`
+	Args              []InputValue       `json:"args"`
+	Type              TypeRef            `json:"type"`
+	IsDeprecated      bool               `json:"isDeprecated"`
+	DeprecationReason *string            `json:"deprecationReason"`
+	AppliedDirectives []AppliedDirective `json:"appliedDirectives,omitempty"`
 }
 
 // InputValue represents an input field or argument.
@@ -53,6 +62,11 @@ type InputValue struct {
 	Description  *string `json:"description"`
 	Type         TypeRef `json:"type"`
 	DefaultValue *string `json:"defaultValue"`
+
+	// Optional fields, requested only when the server supports them
+	IsDeprecated      bool               `json:"isDeprecated"`
+	DeprecationReason *string            `json:"deprecationReason"`
+	AppliedDirectives []AppliedDirective `json:"appliedDirectives,omitempty"`
 }
 
 // TypeRef represents a type reference with potential wrapping.
@@ -64,10 +78,11 @@ type TypeRef struct {
 
 // EnumValue represents a value in an enum type.
 type EnumValue struct {
-	Name              string  `json:"name"`
-	Description       *string `json:"description"`
-	IsDeprecated      bool    `json:"isDeprecated"`
-	DeprecationReason *string `json:"deprecationReason"`
+	Name              string             `json:"name"`
+	Description       *string            `json:"description"`
+	IsDeprecated      bool               `json:"isDeprecated"`
+	DeprecationReason *string            `json:"deprecationReason"`
+	AppliedDirectives []AppliedDirective `json:"appliedDirectives,omitempty"`
 }
 
 // Directive represents a directive definition.
@@ -76,9 +91,48 @@ type Directive struct {
 	Description *string      `json:"description"`
 	Locations   []string     `json:"locations"`
 	Args        []InputValue `json:"args"`
+	// IsRepeatable is only requested when the server supports it
+	IsRepeatable bool `json:"isRepeatable"`
+}
+
+// AppliedDirective is a directive usage reported by servers that support the
+// appliedDirectives introspection extension.
+type AppliedDirective struct {
+	Name string                     `json:"name"`
+	Args []AppliedDirectiveArgument `json:"args"`
+}
+
+// AppliedDirectiveArgument is an argument of an applied directive.
+// Value is the argument value as a GraphQL literal.
+type AppliedDirectiveArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 // Error represents a GraphQL error in the response.
 type Error struct {
-	Message string `json:"message"`
+	Message   string          `json:"message"`
+	Locations []ErrorLocation `json:"locations,omitempty"`
+	Path      []any           `json:"path,omitempty"`
+}
+
+// ErrorLocation is a line/column position in the operation document.
+type ErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// OperationRequest is the JSON body for executing a GraphQL operation.
+type OperationRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// OperationResponse is the result of executing a GraphQL operation.
+// Data is kept raw so it can be printed without losing field order.
+type OperationResponse struct {
+	Data       json.RawMessage `json:"data,omitempty"`
+	Errors     []Error         `json:"errors,omitempty"`
+	Extensions json.RawMessage `json:"extensions,omitempty"`
 }
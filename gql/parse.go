@@ -21,6 +21,20 @@ type GraphQLSchema struct {
 	Directive  map[string]*DirectiveDef
 	NameToKind map[string]string
 	Usages     map[string][]*Usage
+
+	// Root fields in definition order, since the Query and Mutation maps are unordered
+	queryFields    []*Field
+	mutationFields []*Field
+}
+
+// QueryFields returns the Query fields in the order they are defined in the schema
+func (s *GraphQLSchema) QueryFields() []*Field {
+	return s.queryFields
+}
+
+// MutationFields returns the Mutation fields in the order they are defined in the schema
+func (s *GraphQLSchema) MutationFields() []*Field {
+	return s.mutationFields
 }
 
 func buildGraphQLTypes(schema *ast.Schema) GraphQLSchema {
@@ -46,6 +60,7 @@ func buildGraphQLTypes(schema *ast.Schema) GraphQLSchema {
 				continue
 			}
 			gqlSchema.Query[field.Name] = newField(field)
+			gqlSchema.queryFields = append(gqlSchema.queryFields, gqlSchema.Query[field.Name])
 		}
 		gqlSchema.NameToKind["Query"] = "Query"
 	}
@@ -58,6 +73,7 @@ func buildGraphQLTypes(schema *ast.Schema) GraphQLSchema {
 				continue
 			}
 			gqlSchema.Mutation[field.Name] = newField(field)
+			gqlSchema.mutationFields = append(gqlSchema.mutationFields, gqlSchema.Mutation[field.Name])
 		}
 		gqlSchema.NameToKind["Mutation"] = "Mutation"
 	}
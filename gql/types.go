@@ -509,6 +509,11 @@ func (d *DirectiveDef) Locations() []string {
 	return d.locations
 }
 
+// IsRepeatable reports whether the directive may be applied more than once per location
+func (d *DirectiveDef) IsRepeatable() bool {
+	return d.isRepeatable
+}
+
 // AppliedDirective represents a directive applied to a schema element (field, type, etc.).
 type AppliedDirective struct {
 	name             string
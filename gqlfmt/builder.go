@@ -0,0 +1,549 @@
+package gqlfmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/tonysyu/gqlxp/gql"
+)
+
+// PathSegment is one step in a SelectionPath: either a field or an inline fragment.
+type PathSegment struct {
+	Field         string // Field name (empty for inline fragments)
+	TypeCondition string // Type name for "... on Type" fragments (empty for fields)
+}
+
+// SelectionPath identifies a field in an operation, starting at a root type.
+type SelectionPath struct {
+	Root     string // "Query" or "Mutation"
+	Segments []PathSegment
+}
+
+// String formats the path for display (e.g. "Query.repository.issues").
+func (p SelectionPath) String() string {
+	parts := []string{p.Root}
+	for _, seg := range p.Segments {
+		if seg.TypeCondition != "" {
+			parts = append(parts, "... on "+seg.TypeCondition)
+		} else {
+			parts = append(parts, seg.Field)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// ArgumentValue describes a field argument and the value entered for it, if any.
+type ArgumentValue struct {
+	Name        string
+	Type        string
+	Description string
+	Value       string // Raw value as entered; empty if unset
+}
+
+// OperationBuilder incrementally builds a GraphQL operation from selected field paths.
+type OperationBuilder struct {
+	schema gql.GraphQLSchema
+	root   string // Root type of current selections ("Query" or "Mutation")
+	nodes  []*selectionNode
+}
+
+// selectionNode is a selected field (or inline fragment) and its sub-selections.
+type selectionNode struct {
+	segment  PathSegment
+	field    *gql.Field        // nil for inline fragments
+	args     map[string]string // raw argument values keyed by argument name
+	children []*selectionNode
+}
+
+// NewOperationBuilder creates an empty builder for the given schema.
+func NewOperationBuilder(schema gql.GraphQLSchema) *OperationBuilder {
+	return &OperationBuilder{schema: schema}
+}
+
+// Schema returns the schema selections are resolved against.
+func (b *OperationBuilder) Schema() gql.GraphQLSchema {
+	return b.schema
+}
+
+// ResolveSelectionPath converts navigation breadcrumbs into a SelectionPath.
+// crumbs alternate between field names and the types they return, as produced by
+// browsing the explorer from a root type; type names that differ from the previous
+// field's type (union members, interface implementations) become inline fragments.
+// fieldName is the field to select within the type reached by crumbs.
+func ResolveSelectionPath(schema gql.GraphQLSchema, root string, crumbs []string, fieldName string) (SelectionPath, error) {
+	if root != "Query" && root != "Mutation" {
+		return SelectionPath{}, fmt.Errorf("fields can only be selected when browsing Query or Mutation")
+	}
+
+	path := SelectionPath{Root: root}
+	parentType := root
+	expectType := false
+	for _, crumb := range crumbs {
+		if expectType && crumb == parentType {
+			expectType = false
+			continue
+		}
+		if field, ok := lookupField(schema, parentType, crumb); ok && !expectType {
+			path.Segments = append(path.Segments, PathSegment{Field: crumb})
+			parentType = field.ObjectTypeName()
+			expectType = true
+			continue
+		}
+		if isPossibleTypeCondition(schema, parentType, crumb) {
+			path.Segments = append(path.Segments, PathSegment{TypeCondition: crumb})
+			parentType = crumb
+			expectType = false
+			continue
+		}
+		return SelectionPath{}, fmt.Errorf("cannot select fields through %q", crumb)
+	}
+
+	if _, ok := lookupField(schema, parentType, fieldName); !ok {
+		return SelectionPath{}, fmt.Errorf("%q is not a field of %s", fieldName, parentType)
+	}
+	path.Segments = append(path.Segments, PathSegment{Field: fieldName})
+	return path, nil
+}
+
+// Toggle adds the field at path (and any missing ancestors) to the operation, or removes
+// it with its sub-selections if already selected. Returns whether the field is now selected.
+func (b *OperationBuilder) Toggle(path SelectionPath) (bool, error) {
+	if err := b.checkRoot(path); err != nil {
+		return false, err
+	}
+	if b.find(path) != nil {
+		b.remove(path)
+		return false, nil
+	}
+	if _, err := b.ensure(path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// IsSelected reports whether the field at path is part of the operation.
+func (b *OperationBuilder) IsSelected(path SelectionPath) bool {
+	return path.Root == b.root && b.find(path) != nil
+}
+
+// Root returns the root type of the selected fields ("Query" or "Mutation"), or "" if empty.
+func (b *OperationBuilder) Root() string {
+	return b.root
+}
+
+// IsEmpty reports whether no fields have been selected.
+func (b *OperationBuilder) IsEmpty() bool {
+	return len(b.nodes) == 0
+}
+
+// Clear removes all selections.
+func (b *OperationBuilder) Clear() {
+	b.nodes = nil
+	b.root = ""
+}
+
+// Arguments returns the arguments accepted by the field at path with their current values.
+func (b *OperationBuilder) Arguments(path SelectionPath) ([]ArgumentValue, error) {
+	field, err := b.resolveField(path)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]string
+	if node := b.find(path); node != nil && path.Root == b.root {
+		values = node.args
+	}
+	args := make([]ArgumentValue, 0, len(field.Arguments()))
+	for _, arg := range field.Arguments() {
+		args = append(args, ArgumentValue{
+			Name:        arg.Name(),
+			Type:        arg.TypeString(),
+			Description: arg.Description(),
+			Value:       values[arg.Name()],
+		})
+	}
+	return args, nil
+}
+
+// SetArguments stores raw argument values for the field at path, selecting it if needed.
+// Empty values are removed. Values are parsed as JSON when exported, falling back to strings.
+func (b *OperationBuilder) SetArguments(path SelectionPath, values map[string]string) error {
+	if err := b.checkRoot(path); err != nil {
+		return err
+	}
+	node, err := b.ensure(path)
+	if err != nil {
+		return err
+	}
+	node.args = make(map[string]string)
+	for name, value := range values {
+		if strings.TrimSpace(value) != "" {
+			node.args[name] = strings.TrimSpace(value)
+		}
+	}
+	return nil
+}
+
+// OperationName returns the name given to the operation, derived from its first root field.
+func (b *OperationBuilder) OperationName() string {
+	if b.IsEmpty() {
+		return ""
+	}
+	return toPascalCase(b.nodes[0].segment.Field)
+}
+
+// Operation renders the operation document and the variables referenced by it.
+// Argument values become variables so the operation can be reused with other inputs.
+func (b *OperationBuilder) Operation() (string, map[string]any) {
+	if b.IsEmpty() {
+		return "", nil
+	}
+
+	r := operationRenderer{schema: b.schema, used: map[string]bool{}, variables: map[string]any{}}
+	body := r.renderSelections(b.nodes, "  ")
+
+	operationType := strings.ToLower(b.root)
+	header := fmt.Sprintf("%s %s", operationType, b.OperationName())
+	if len(r.declarations) > 0 {
+		header += "(" + strings.Join(r.declarations, ", ") + ")"
+	}
+
+	operation := header + " {\n" + body + "\n}"
+	if len(r.variables) == 0 {
+		return operation, nil
+	}
+	return operation, r.variables
+}
+
+// Clone returns a copy of the builder whose selections can be changed independently.
+func (b *OperationBuilder) Clone() *OperationBuilder {
+	return &OperationBuilder{schema: b.schema, root: b.root, nodes: cloneNodes(b.nodes)}
+}
+
+func cloneNodes(nodes []*selectionNode) []*selectionNode {
+	if nodes == nil {
+		return nil
+	}
+	cloned := make([]*selectionNode, len(nodes))
+	for i, n := range nodes {
+		cloned[i] = &selectionNode{
+			segment:  n.segment,
+			field:    n.field,
+			args:     maps.Clone(n.args),
+			children: cloneNodes(n.children),
+		}
+	}
+	return cloned
+}
+
+// Rebase returns a builder for schema holding the selections that still exist in it, such
+// as after the schema was edited. Fields and fragments that no longer resolve are dropped
+// along with their sub-selections, as are values of arguments a field no longer accepts.
+func (b *OperationBuilder) Rebase(schema gql.GraphQLSchema) *OperationBuilder {
+	rebased := &OperationBuilder{schema: schema, root: b.root}
+	rebased.nodes = rebaseNodes(schema, b.root, b.nodes)
+	if len(rebased.nodes) == 0 {
+		rebased.root = ""
+	}
+	return rebased
+}
+
+// rebaseNodes resolves nodes selected within parentType against schema.
+func rebaseNodes(schema gql.GraphQLSchema, parentType string, nodes []*selectionNode) []*selectionNode {
+	var rebased []*selectionNode
+	for _, n := range nodes {
+		node := &selectionNode{segment: n.segment}
+		childType := n.segment.TypeCondition
+		if n.segment.TypeCondition != "" {
+			if !isPossibleTypeCondition(schema, parentType, n.segment.TypeCondition) {
+				continue
+			}
+		} else {
+			field, ok := lookupField(schema, parentType, n.segment.Field)
+			if !ok {
+				continue
+			}
+			node.field = field
+			childType = field.ObjectTypeName()
+			for _, arg := range field.Arguments() {
+				if value, ok := n.args[arg.Name()]; ok {
+					if node.args == nil {
+						node.args = make(map[string]string)
+					}
+					node.args[arg.Name()] = value
+				}
+			}
+		}
+		node.children = rebaseNodes(schema, childType, n.children)
+		rebased = append(rebased, node)
+	}
+	return rebased
+}
+
+// checkRoot ensures that all selections share the same root type.
+func (b *OperationBuilder) checkRoot(path SelectionPath) error {
+	if b.root != "" && b.root != path.Root {
+		return fmt.Errorf("operation already contains %s fields; clear it before selecting %s fields", b.root, path.Root)
+	}
+	return nil
+}
+
+// find returns the node at path, or nil if it hasn't been selected.
+func (b *OperationBuilder) find(path SelectionPath) *selectionNode {
+	nodes := b.nodes
+	var node *selectionNode
+	for _, seg := range path.Segments {
+		node = findNode(nodes, seg)
+		if node == nil {
+			return nil
+		}
+		nodes = node.children
+	}
+	return node
+}
+
+// ensure returns the node at path, creating it and any missing ancestors.
+func (b *OperationBuilder) ensure(path SelectionPath) (*selectionNode, error) {
+	if len(path.Segments) == 0 {
+		return nil, fmt.Errorf("empty selection path")
+	}
+	if _, err := b.resolveField(path); err != nil {
+		return nil, err
+	}
+
+	b.root = path.Root
+	nodes := &b.nodes
+	parentType := path.Root
+	var node *selectionNode
+	for _, seg := range path.Segments {
+		node = findNode(*nodes, seg)
+		if node == nil {
+			node = &selectionNode{segment: seg}
+			if seg.Field != "" {
+				node.field, _ = lookupField(b.schema, parentType, seg.Field)
+			}
+			*nodes = append(*nodes, node)
+		}
+		if node.field != nil {
+			parentType = node.field.ObjectTypeName()
+		} else {
+			parentType = seg.TypeCondition
+		}
+		nodes = &node.children
+	}
+	return node, nil
+}
+
+// remove deletes the node at path along with its sub-selections.
+func (b *OperationBuilder) remove(path SelectionPath) {
+	nodes := &b.nodes
+	for i, seg := range path.Segments {
+		idx := slices.IndexFunc(*nodes, func(n *selectionNode) bool { return n.segment == seg })
+		if idx < 0 {
+			return
+		}
+		if i == len(path.Segments)-1 {
+			*nodes = slices.Delete(*nodes, idx, idx+1)
+			break
+		}
+		nodes = &(*nodes)[idx].children
+	}
+	if len(b.nodes) == 0 {
+		b.root = ""
+	}
+}
+
+// resolveField validates path against the schema and returns the field it ends at.
+func (b *OperationBuilder) resolveField(path SelectionPath) (*gql.Field, error) {
+	parentType := path.Root
+	var field *gql.Field
+	for _, seg := range path.Segments {
+		if seg.TypeCondition != "" {
+			if !isPossibleTypeCondition(b.schema, parentType, seg.TypeCondition) {
+				return nil, fmt.Errorf("type %s cannot be used as a fragment on %s", seg.TypeCondition, parentType)
+			}
+			parentType = seg.TypeCondition
+			field = nil
+			continue
+		}
+		f, ok := lookupField(b.schema, parentType, seg.Field)
+		if !ok {
+			return nil, fmt.Errorf("%q is not a field of %s", seg.Field, parentType)
+		}
+		field = f
+		parentType = f.ObjectTypeName()
+	}
+	if field == nil {
+		return nil, fmt.Errorf("selection path %s does not end at a field", path)
+	}
+	return field, nil
+}
+
+func findNode(nodes []*selectionNode, seg PathSegment) *selectionNode {
+	for _, n := range nodes {
+		if n.segment == seg {
+			return n
+		}
+	}
+	return nil
+}
+
+// FieldTypeName returns the named type (without list or non-null wrappers) of a field on a
+// root, object, or interface type.
+func FieldTypeName(schema gql.GraphQLSchema, typeName, fieldName string) (string, bool) {
+	field, ok := lookupField(schema, typeName, fieldName)
+	if !ok {
+		return "", false
+	}
+	return field.ObjectTypeName(), true
+}
+
+// lookupField finds a field by name on a root, object, or interface type.
+func lookupField(schema gql.GraphQLSchema, typeName, fieldName string) (*gql.Field, bool) {
+	switch typeName {
+	case "Query":
+		f, ok := schema.Query[fieldName]
+		return f, ok
+	case "Mutation":
+		f, ok := schema.Mutation[fieldName]
+		return f, ok
+	}
+
+	var fields []*gql.Field
+	switch schema.NameToKind[typeName] {
+	case "Object":
+		fields = schema.Object[typeName].Fields()
+	case "Interface":
+		fields = schema.Interface[typeName].Fields()
+	}
+	for _, f := range fields {
+		if f.Name() == fieldName {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// isPossibleTypeCondition reports whether an inline fragment on condition is valid
+// within a selection set of parentType.
+func isPossibleTypeCondition(schema gql.GraphQLSchema, parentType, condition string) bool {
+	if parentType == condition {
+		return true
+	}
+	if union, ok := schema.Union[parentType]; ok {
+		return slices.Contains(union.Types(), condition)
+	}
+	if obj, ok := schema.Object[condition]; ok && slices.Contains(obj.Interfaces(), parentType) {
+		return true
+	}
+	if iface, ok := schema.Interface[condition]; ok && slices.Contains(iface.Interfaces(), parentType) {
+		return true
+	}
+	if obj, ok := schema.Object[parentType]; ok && slices.Contains(obj.Interfaces(), condition) {
+		return true
+	}
+	return false
+}
+
+// isCompositeType reports whether typeName requires a selection set.
+func isCompositeType(schema gql.GraphQLSchema, typeName string) bool {
+	switch schema.NameToKind[typeName] {
+	case "Object", "Interface", "Union":
+		return true
+	}
+	return false
+}
+
+// operationRenderer accumulates variable declarations while rendering selections.
+type operationRenderer struct {
+	schema       gql.GraphQLSchema
+	used         map[string]bool
+	declarations []string
+	variables    map[string]any
+}
+
+func (r *operationRenderer) renderSelections(nodes []*selectionNode, indent string) string {
+	lines := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		lines = append(lines, r.renderNode(n, indent))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *operationRenderer) renderNode(n *selectionNode, indent string) string {
+	var head string
+	needsSelection := true
+	if n.field == nil {
+		head = "... on " + n.segment.TypeCondition
+	} else {
+		head = n.field.Name() + r.renderArguments(n)
+		needsSelection = isCompositeType(r.schema, n.field.ObjectTypeName())
+	}
+
+	if !needsSelection {
+		return indent + head
+	}
+	if len(n.children) == 0 {
+		// Keep the operation valid until sub-fields are selected
+		return fmt.Sprintf("%s%s {\n%s  __typename\n%s}", indent, head, indent, indent)
+	}
+	return fmt.Sprintf("%s%s {\n%s\n%s}", indent, head, r.renderSelections(n.children, indent+"  "), indent)
+}
+
+// renderArguments formats argument references and registers their variables.
+func (r *operationRenderer) renderArguments(n *selectionNode) string {
+	var parts []string
+	for _, arg := range n.field.Arguments() {
+		raw, ok := n.args[arg.Name()]
+		if !ok {
+			continue
+		}
+		name := r.variableName(n.field.Name(), arg.Name())
+		r.declarations = append(r.declarations, fmt.Sprintf("$%s: %s", name, arg.TypeString()))
+		r.variables[name] = parseArgumentValue(raw, arg.TypeString())
+		parts = append(parts, fmt.Sprintf("%s: $%s", arg.Name(), name))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// variableName returns a unique variable name, preferring the bare argument name.
+func (r *operationRenderer) variableName(fieldName, argName string) string {
+	candidates := []string{argName, fieldName + toPascalCase(argName)}
+	for _, c := range candidates {
+		if !r.used[c] {
+			r.used[c] = true
+			return c
+		}
+	}
+	for i := 2; ; i++ {
+		c := fmt.Sprintf("%s%d", candidates[1], i)
+		if !r.used[c] {
+			r.used[c] = true
+			return c
+		}
+	}
+}
+
+// parseArgumentValue converts a raw entered value to a JSON variable value.
+// String and ID arguments are always strings; other values are parsed as JSON
+// when possible so numbers, booleans, lists, and objects keep their types.
+func parseArgumentValue(raw, typeString string) any {
+	baseType := strings.TrimSuffix(typeString, "!")
+	if baseType == "String" || baseType == "ID" {
+		var s string
+		if err := json.Unmarshal([]byte(raw), &s); err == nil {
+			return s
+		}
+		return raw
+	}
+	var v any
+	if err := json.Unmarshal([]byte(raw), &v); err == nil {
+		return v
+	}
+	return raw
+}
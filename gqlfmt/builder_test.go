@@ -0,0 +1,275 @@
+package gqlfmt
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const builderTestSchema = `
+	type Query {
+		repository(owner: String!, name: String!): Repository
+		search(query: String!, first: Int): [SearchResult!]!
+		viewer: User!
+	}
+	type Mutation {
+		addStar(id: ID!): Repository
+	}
+	type Repository {
+		name: String!
+		issues(first: Int, states: [IssueState!]): IssueConnection!
+		owner: User!
+	}
+	type IssueConnection {
+		totalCount: Int!
+	}
+	type User {
+		login: String!
+		issues(first: Int): IssueConnection!
+	}
+	enum IssueState { OPEN CLOSED }
+	union SearchResult = Repository | User
+`
+
+func mustResolvePath(t *testing.T, b *OperationBuilder, root string, crumbs []string, field string) SelectionPath {
+	t.Helper()
+	path, err := ResolveSelectionPath(b.schema, root, crumbs, field)
+	if err != nil {
+		t.Fatalf("failed to resolve path: %v", err)
+	}
+	return path
+}
+
+func TestResolveSelectionPath(t *testing.T) {
+	schema := mustParseSchema(t, builderTestSchema)
+
+	tests := []struct {
+		name    string
+		root    string
+		crumbs  []string
+		field   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "root field",
+			root:  "Query",
+			field: "repository",
+			want:  "Query.repository",
+		},
+		{
+			name:   "nested field via field and type breadcrumbs",
+			root:   "Query",
+			crumbs: []string{"repository", "Repository"},
+			field:  "issues",
+			want:   "Query.repository.issues",
+		},
+		{
+			name:   "union member becomes inline fragment",
+			root:   "Query",
+			crumbs: []string{"search", "SearchResult", "User"},
+			field:  "login",
+			want:   "Query.search.... on User.login",
+		},
+		{
+			name:    "non-root kind",
+			root:    "Object",
+			field:   "name",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			root:    "Query",
+			crumbs:  []string{"repository", "Repository"},
+			field:   "missing",
+			wantErr: true,
+		},
+		{
+			name:    "breadcrumb through argument",
+			root:    "Query",
+			crumbs:  []string{"repository", "owner"},
+			field:   "login",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			path, err := ResolveSelectionPath(schema, tt.root, tt.crumbs, tt.field)
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(path.String(), tt.want)
+		})
+	}
+}
+
+func TestOperationBuilder_ToggleNestedField(t *testing.T) {
+	is := is.New(t)
+	b := NewOperationBuilder(mustParseSchema(t, builderTestSchema))
+	issues := mustResolvePath(t, b, "Query", []string{"repository", "Repository", "issues", "IssueConnection"}, "totalCount")
+
+	selected, err := b.Toggle(issues)
+
+	is.NoErr(err)
+	is.True(selected)
+	operation, variables := b.Operation()
+	is.Equal(operation, `query Repository {
+  repository {
+    issues {
+      totalCount
+    }
+  }
+}`)
+	is.Equal(variables, nil)
+}
+
+func TestOperationBuilder_ToggleOffRemovesSubtree(t *testing.T) {
+	is := is.New(t)
+	b := NewOperationBuilder(mustParseSchema(t, builderTestSchema))
+	repo := mustResolvePath(t, b, "Query", nil, "repository")
+	name := mustResolvePath(t, b, "Query", []string{"repository", "Repository"}, "name")
+
+	_, err := b.Toggle(name)
+	is.NoErr(err)
+	selected, err := b.Toggle(repo)
+	is.NoErr(err)
+
+	is.True(!selected)            // toggling a selected field removes it
+	is.True(!b.IsSelected(name))  // children are removed with their parent
+	is.True(b.IsEmpty())          // nothing left in the operation
+	operation, _ := b.Operation() // empty builder renders nothing
+	is.Equal(operation, "")
+}
+
+func TestOperationBuilder_CompositeFieldWithoutChildren(t *testing.T) {
+	is := is.New(t)
+	b := NewOperationBuilder(mustParseSchema(t, builderTestSchema))
+
+	_, err := b.Toggle(mustResolvePath(t, b, "Query", nil, "viewer"))
+	is.NoErr(err)
+
+	operation, _ := b.Operation()
+	is.Equal(operation, `query Viewer {
+  viewer {
+    __typename
+  }
+}`)
+}
+
+func TestOperationBuilder_ArgumentsBecomeVariables(t *testing.T) {
+	is := is.New(t)
+	b := NewOperationBuilder(mustParseSchema(t, builderTestSchema))
+	repo := mustResolvePath(t, b, "Query", nil, "repository")
+	repoIssues := mustResolvePath(t, b, "Query", []string{"repository", "Repository"}, "issues")
+	viewerIssues := mustResolvePath(t, b, "Query", []string{"viewer", "User"}, "issues")
+
+	is.NoErr(b.SetArguments(repo, map[string]string{"owner": "tonysyu", "name": `"gqlxp"`}))
+	is.NoErr(b.SetArguments(repoIssues, map[string]string{"first": "10", "states": `["OPEN"]`}))
+	is.NoErr(b.SetArguments(viewerIssues, map[string]string{"first": "5"}))
+
+	operation, variables := b.Operation()
+	is.Equal(operation, `query Repository($owner: String!, $name: String!, $first: Int, $states: [IssueState!], $issuesFirst: Int) {
+  repository(owner: $owner, name: $name) {
+    issues(first: $first, states: $states) {
+      __typename
+    }
+  }
+  viewer {
+    issues(first: $issuesFirst) {
+      __typename
+    }
+  }
+}`)
+	is.Equal(variables["owner"], "tonysyu")        // bare strings are accepted
+	is.Equal(variables["name"], "gqlxp")           // quoted strings are unquoted
+	is.Equal(variables["first"], float64(10))      // numbers keep their type
+	is.Equal(variables["states"], []any{"OPEN"})   // lists are parsed as JSON
+	is.Equal(variables["issuesFirst"], float64(5)) // conflicting names are prefixed by field
+}
+
+func TestOperationBuilder_Arguments(t *testing.T) {
+	is := is.New(t)
+	b := NewOperationBuilder(mustParseSchema(t, builderTestSchema))
+	repo := mustResolvePath(t, b, "Query", nil, "repository")
+	is.NoErr(b.SetArguments(repo, map[string]string{"owner": "tonysyu", "name": " "}))
+
+	args, err := b.Arguments(repo)
+
+	is.NoErr(err)
+	is.Equal(len(args), 2)
+	is.Equal(args[0], ArgumentValue{Name: "owner", Type: "String!", Value: "tonysyu"})
+	is.Equal(args[1], ArgumentValue{Name: "name", Type: "String!"}) // blank values are dropped
+}
+
+func TestOperationBuilder_MixedRootsRejected(t *testing.T) {
+	is := is.New(t)
+	b := NewOperationBuilder(mustParseSchema(t, builderTestSchema))
+
+	_, err := b.Toggle(mustResolvePath(t, b, "Query", nil, "viewer"))
+	is.NoErr(err)
+	_, err = b.Toggle(mustResolvePath(t, b, "Mutation", nil, "addStar"))
+
+	is.True(err != nil) // an operation has a single root type
+}
+
+func TestOperationBuilder_Clone(t *testing.T) {
+	is := is.New(t)
+	b := NewOperationBuilder(mustParseSchema(t, builderTestSchema))
+	repo := mustResolvePath(t, b, "Query", nil, "repository")
+	name := mustResolvePath(t, b, "Query", []string{"repository", "Repository"}, "name")
+	_, err := b.Toggle(name)
+	is.NoErr(err)
+	is.NoErr(b.SetArguments(repo, map[string]string{"owner": "tonysyu"}))
+	original, originalVariables := b.Operation()
+
+	cloned := b.Clone()
+	_, err = cloned.Toggle(mustResolvePath(t, b, "Query", []string{"repository", "Repository"}, "issues"))
+	is.NoErr(err)
+	is.NoErr(cloned.SetArguments(repo, map[string]string{"owner": "other"}))
+
+	operation, variables := b.Operation()
+	is.Equal(operation, original) // changes to the clone leave the original unchanged
+	is.Equal(variables, originalVariables)
+	is.True(cloned.IsSelected(name)) // the clone keeps the original selections
+}
+
+func TestOperationBuilder_Rebase(t *testing.T) {
+	is := is.New(t)
+	b := NewOperationBuilder(mustParseSchema(t, builderTestSchema))
+	repo := mustResolvePath(t, b, "Query", nil, "repository")
+	_, err := b.Toggle(mustResolvePath(t, b, "Query", []string{"repository", "Repository"}, "name"))
+	is.NoErr(err)
+	_, err = b.Toggle(mustResolvePath(t, b, "Query", []string{"repository", "Repository"}, "issues"))
+	is.NoErr(err)
+	is.NoErr(b.SetArguments(repo, map[string]string{"owner": "tonysyu", "name": "gqlxp"}))
+
+	// Repository.issues and the name argument were removed
+	edited := mustParseSchema(t, `
+		type Query {
+			repository(owner: String!): Repository
+		}
+		type Repository {
+			name: String!
+			stars: Int!
+		}
+	`)
+	rebased := b.Rebase(edited)
+
+	operation, variables := rebased.Operation()
+	is.Equal(operation, `query Repository($owner: String!) {
+  repository(owner: $owner) {
+    name
+  }
+}`)
+	is.Equal(variables, map[string]any{"owner": "tonysyu"})
+	is.True(rebased.Schema().Query["repository"] != nil) // selections resolve against the new schema
+
+	// Nothing is left when the root fields no longer exist
+	rebased = b.Rebase(mustParseSchema(t, `type Query { viewer: String }`))
+	is.True(rebased.IsEmpty())
+	is.Equal(rebased.Root(), "")
+}
@@ -0,0 +1,387 @@
+package library
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/tonysyu/gqlxp/gql"
+)
+
+// bundleVersion is the format version of library bundles.
+const bundleVersion = 1
+
+// bundleManifestName is the bundle entry holding the manifest; schemas are stored as
+// schemas/<ref>.graphqls entries.
+const bundleManifestName = "manifest.json"
+
+// bundleManifest describes the schemas in a bundle.
+type bundleManifest struct {
+	Version    int                       `json:"version"`
+	ExportedAt time.Time                 `json:"exportedAt"`
+	Schemas    map[string]SchemaMetadata `json:"schemas"`
+}
+
+// ExportOptions configures ExportBundle.
+type ExportOptions struct {
+	// IncludeSnapshots includes the fetched snapshots of schema environments.
+	IncludeSnapshots bool
+}
+
+// ExportResult describes an exported bundle.
+type ExportResult struct {
+	SchemaIDs []string
+	// RemovedHeaders maps schema references to the connection headers left out as secrets.
+	RemovedHeaders map[string][]string
+}
+
+// ConflictPolicy decides what ImportBundle does with a schema whose ID is already in use.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip keeps the existing schema and skips the bundled one.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictRename imports the bundled schema under a new ID, e.g. "api-2".
+	ConflictRename ConflictPolicy = "rename"
+	// ConflictOverwrite replaces the existing schema, including its environments.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+)
+
+// ParseConflictPolicy parses a conflict policy name.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(s); policy {
+	case ConflictSkip, ConflictRename, ConflictOverwrite:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid conflict policy '%s': expected %s, %s, or %s", s, ConflictSkip, ConflictRename, ConflictOverwrite)
+	}
+}
+
+// ImportOptions configures ImportBundle.
+type ImportOptions struct {
+	OnConflict ConflictPolicy
+}
+
+// ImportStatus describes what happened to a bundled schema on import.
+type ImportStatus string
+
+const (
+	ImportAdded       ImportStatus = "added"
+	ImportRenamed     ImportStatus = "renamed"
+	ImportOverwritten ImportStatus = "overwritten"
+	ImportSkipped     ImportStatus = "skipped"
+)
+
+// ImportedSchema is the outcome of importing one bundled schema.
+type ImportedSchema struct {
+	// BundleID is the schema's ID in the bundle.
+	BundleID string
+	// ID is the schema's ID in the library; it differs from BundleID when renamed.
+	ID     string
+	Status ImportStatus
+}
+
+// ExportBundle writes the given schemas, or the whole library if ids is empty, to w as a
+// gzipped tar bundle. Metadata is included without local source paths and without
+// connection headers that carry or reference secrets.
+func ExportBundle(lib Library, w io.Writer, ids []string, opts ExportOptions) (ExportResult, error) {
+	if len(ids) == 0 {
+		schemas, err := lib.List()
+		if err != nil {
+			return ExportResult{}, err
+		}
+		for _, schema := range schemas {
+			ids = append(ids, schema.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return ExportResult{}, errors.New("library has no schemas to export")
+	}
+
+	manifest := bundleManifest{
+		Version:    bundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Schemas:    make(map[string]SchemaMetadata, len(ids)),
+	}
+	result := ExportResult{RemovedHeaders: make(map[string][]string)}
+	files := make(map[string][]byte)
+	for _, id := range ids {
+		if baseID, env := ParseSchemaRef(id); env != "" {
+			return ExportResult{}, fmt.Errorf("cannot export environment '%s' on its own: export schema '%s', which includes its environments", id, baseID)
+		}
+		schema, err := lib.Get(id)
+		if err != nil {
+			return ExportResult{}, err
+		}
+		metadata := schema.Metadata
+		metadata.SourceFile = ""
+		metadata.Connection, result.RemovedHeaders[id] = sharedConnection(metadata.Connection)
+
+		environments := make(map[string]Environment, len(metadata.Environments))
+		for _, name := range metadata.EnvironmentNames() {
+			env := metadata.Environments[name]
+			ref := SchemaRef(id, name)
+			env.Connection, result.RemovedHeaders[ref] = sharedConnection(env.Connection)
+			snapshot, err := lib.Get(ref)
+			if opts.IncludeSnapshots && err == nil {
+				files[bundleSchemaPath(ref)] = snapshot.Content
+			} else {
+				env.FileHash = ""
+				env.UpdatedAt = time.Time{}
+			}
+			environments[name] = env
+		}
+		if len(environments) > 0 {
+			metadata.Environments = environments
+		}
+
+		manifest.Schemas[id] = metadata
+		files[bundleSchemaPath(id)] = schema.Content
+		result.SchemaIDs = append(result.SchemaIDs, id)
+	}
+	for ref, headers := range result.RemovedHeaders {
+		if len(headers) == 0 {
+			delete(result.RemovedHeaders, ref)
+		}
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return ExportResult{}, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	files[bundleManifestName] = manifestData
+	if err := writeBundle(w, files, manifest.ExportedAt); err != nil {
+		return ExportResult{}, err
+	}
+	return result, nil
+}
+
+// sharedConnection returns a connection profile without secrets, or nil if nothing is left.
+func sharedConnection(profile *ConnectionProfile) (*ConnectionProfile, []string) {
+	if profile == nil {
+		return nil, nil
+	}
+	shared, removed := profile.WithoutSecrets()
+	if shared.IsEmpty() {
+		return nil, removed
+	}
+	return &shared, removed
+}
+
+// ImportBundle adds the schemas in a bundle written by ExportBundle to the library, resolving
+// ID conflicts according to opts, and waits for their search indexes to be rebuilt.
+func ImportBundle(lib Library, r io.Reader, opts ImportOptions) ([]ImportedSchema, error) {
+	if opts.OnConflict == "" {
+		opts.OnConflict = ConflictSkip
+	}
+	files, err := readBundle(r)
+	if err != nil {
+		return nil, err
+	}
+	manifestData, ok := files[bundleManifestName]
+	if !ok {
+		return nil, errors.New("invalid bundle: missing " + bundleManifestName)
+	}
+	var manifest bundleManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	if manifest.Version != bundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d (expected %d)", manifest.Version, bundleVersion)
+	}
+
+	// Validate every schema before changing the library
+	bundleIDs := make([]string, 0, len(manifest.Schemas))
+	for id := range manifest.Schemas {
+		if err := ValidateSchemaID(id); err != nil {
+			return nil, fmt.Errorf("invalid bundle: %w", err)
+		}
+		content, ok := files[bundleSchemaPath(id)]
+		if !ok {
+			return nil, fmt.Errorf("invalid bundle: missing schema '%s'", id)
+		}
+		if _, err := gql.ParseSchema(content); err != nil {
+			return nil, fmt.Errorf("invalid bundle: schema '%s': %w", id, err)
+		}
+		bundleIDs = append(bundleIDs, id)
+	}
+	sort.Strings(bundleIDs)
+
+	existing, err := lib.List()
+	if err != nil {
+		return nil, err
+	}
+	inUse := make(map[string]bool, len(existing))
+	sharedOnly := make(map[string]bool)
+	for _, schema := range existing {
+		inUse[schema.ID] = true
+		sharedOnly[schema.ID] = schema.Provenance == ProvenanceShared
+	}
+
+	var imported []ImportedSchema
+	for _, bundleID := range bundleIDs {
+		outcome := ImportedSchema{BundleID: bundleID, ID: bundleID, Status: ImportAdded}
+		if inUse[bundleID] {
+			switch opts.OnConflict {
+			case ConflictSkip:
+				outcome.Status = ImportSkipped
+				imported = append(imported, outcome)
+				continue
+			case ConflictRename:
+				outcome.ID = availableID(bundleID, inUse)
+				outcome.Status = ImportRenamed
+			case ConflictOverwrite:
+				// A shared schema can't be removed; importing it creates a local override instead
+				if !sharedOnly[bundleID] {
+					if err := lib.Remove(bundleID); err != nil {
+						return imported, fmt.Errorf("failed to replace schema '%s': %w", bundleID, err)
+					}
+				}
+				outcome.Status = ImportOverwritten
+			}
+		}
+
+		if err := importSchema(lib, outcome.ID, bundleID, manifest.Schemas[bundleID], files); err != nil {
+			return imported, fmt.Errorf("failed to import schema '%s': %w", bundleID, err)
+		}
+		inUse[outcome.ID] = true
+		imported = append(imported, outcome)
+	}
+
+	if err := lib.WaitForIndexing(); err != nil {
+		return imported, fmt.Errorf("imported schemas could not be indexed: %w", err)
+	}
+	return imported, nil
+}
+
+// importSchema adds a bundled schema and its environment snapshots to the library as id.
+func importSchema(lib Library, id, bundleID string, metadata SchemaMetadata, files map[string][]byte) error {
+	if err := lib.AddFromContent(id, metadata.DisplayName, files[bundleSchemaPath(bundleID)], metadata.SourceURL); err != nil {
+		return err
+	}
+	schema, err := lib.Get(id)
+	if err != nil {
+		return err
+	}
+
+	imported := schema.Metadata
+	imported.SourceFile = ""
+	imported.SourceURL = metadata.SourceURL
+	imported.URLPatterns = metadata.URLPatterns
+	if imported.URLPatterns == nil {
+		imported.URLPatterns = make(map[string]string)
+	}
+	imported.Connection = metadata.Connection
+	imported.Tags = metadata.Tags
+	imported.Description = metadata.Description
+	imported.Environments = make(map[string]Environment, len(metadata.Environments))
+	for name, env := range metadata.Environments {
+		if err := ValidateEnvironmentName(name); err != nil {
+			return err
+		}
+		// Freshness is recorded when the snapshot is written below
+		env.FileHash = ""
+		env.UpdatedAt = time.Time{}
+		imported.Environments[name] = env
+	}
+	if len(imported.Environments) == 0 {
+		imported.Environments = nil
+	}
+	if err := lib.UpdateMetadata(id, imported); err != nil {
+		return err
+	}
+
+	for _, name := range imported.EnvironmentNames() {
+		snapshot, ok := files[bundleSchemaPath(SchemaRef(bundleID, name))]
+		if !ok {
+			continue
+		}
+		if err := lib.UpdateContent(SchemaRef(id, name), snapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// availableID returns the first of id-2, id-3, ... that is not in use.
+func availableID(id string, inUse map[string]bool) string {
+	for n := 2; ; n++ {
+		candidate := id + "-" + strconv.Itoa(n)
+		if !inUse[candidate] {
+			return candidate
+		}
+	}
+}
+
+func bundleSchemaPath(ref string) string {
+	return "schemas/" + ref + ".graphqls"
+}
+
+// writeBundle writes files as a gzipped tar archive, in name order.
+func writeBundle(w io.Writer, files map[string][]byte, modTime time.Time) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(files[name])),
+			ModTime: modTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// readBundle reads the regular files of a gzipped tar archive. Entries are only looked up
+// by name, never written to disk under their own names.
+func readBundle(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle: %w", err)
+		}
+		files[header.Name] = data
+	}
+}
@@ -0,0 +1,132 @@
+package library_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+// exportTestBundle creates a library with a tagged schema "api" that has a fetched staging
+// environment, and returns it exported as a bundle.
+func exportTestBundle(t *testing.T, opts library.ExportOptions) []byte {
+	t.Helper()
+	is := is.New(t)
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	addStagingEnvironment(t, lib)
+	is.NoErr(lib.UpdateContent("api@staging", []byte(`type Query { hello: String, staging: Boolean }`)))
+
+	schema, err := lib.Get("api")
+	is.NoErr(err)
+	schema.Metadata.AddTags("core")
+	schema.Metadata.Description = "Main API"
+	schema.Metadata.URLPatterns = map[string]string{"*": "https://docs.example.com/${type}"}
+	schema.Metadata.Connection = &library.ConnectionProfile{
+		Headers: map[string]string{"Authorization": "Bearer ${env:TOKEN}", "X-Client": "gqlxp"},
+	}
+	is.NoErr(lib.UpdateMetadata("api", schema.Metadata))
+
+	var buf bytes.Buffer
+	result, err := library.ExportBundle(lib, &buf, nil, opts)
+	is.NoErr(err)
+	is.Equal(result.SchemaIDs, []string{"api"})
+	is.Equal(result.RemovedHeaders, map[string][]string{"api": {"Authorization"}})
+	return buf.Bytes()
+}
+
+func TestBundle_RoundTrip(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	bundle := exportTestBundle(t, library.ExportOptions{IncludeSnapshots: true})
+
+	// Import into an empty library
+	_, cleanupImport := setupTestLibrary(t)
+	defer cleanupImport()
+	mock := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(mock)
+	imported, err := library.ImportBundle(lib, bytes.NewReader(bundle), library.ImportOptions{})
+	is.NoErr(err)
+	is.Equal(imported, []library.ImportedSchema{{BundleID: "api", ID: "api", Status: library.ImportAdded}})
+
+	schema, err := lib.Get("api")
+	is.NoErr(err)
+	is.Equal(string(schema.Content), `type Query { hello: String }`)
+	is.Equal(schema.Metadata.DisplayName, "API")
+	is.Equal(schema.Metadata.SourceURL, "https://example.com/graphql")
+	is.Equal(schema.Metadata.Tags, []string{"core"})
+	is.Equal(schema.Metadata.Description, "Main API")
+	is.Equal(schema.Metadata.URLPatterns["*"], "https://docs.example.com/${type}")
+	is.Equal(schema.Metadata.Connection.Headers, map[string]string{"X-Client": "gqlxp"}) // secrets are left out
+
+	snapshot, err := lib.Get("api@staging")
+	is.NoErr(err)
+	is.Equal(string(snapshot.Content), `type Query { hello: String, staging: Boolean }`)
+	is.Equal(snapshot.Metadata.ConnectionProfile().Timeout, "5s")
+
+	// Imported schemas are indexed before ImportBundle returns
+	is.True(mock.indexed["api"])
+	is.True(mock.indexed["api@staging"])
+}
+
+func TestBundle_WithoutSnapshots(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	bundle := exportTestBundle(t, library.ExportOptions{})
+
+	_, cleanupImport := setupTestLibrary(t)
+	defer cleanupImport()
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	_, err := library.ImportBundle(lib, bytes.NewReader(bundle), library.ImportOptions{})
+	is.NoErr(err)
+
+	schemas, err := lib.List()
+	is.NoErr(err)
+	is.Equal(len(schemas[0].Environments), 1)
+	is.True(schemas[0].Environments[0].UpdatedAt.IsZero()) // environment is declared but not fetched
+}
+
+func TestBundle_ImportConflicts(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	bundle := exportTestBundle(t, library.ExportOptions{})
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+
+	imported, err := library.ImportBundle(lib, bytes.NewReader(bundle), library.ImportOptions{OnConflict: library.ConflictSkip})
+	is.NoErr(err)
+	is.Equal(imported[0].Status, library.ImportSkipped)
+
+	imported, err = library.ImportBundle(lib, bytes.NewReader(bundle), library.ImportOptions{OnConflict: library.ConflictRename})
+	is.NoErr(err)
+	is.Equal(imported[0], library.ImportedSchema{BundleID: "api", ID: "api-2", Status: library.ImportRenamed})
+	renamed, err := lib.Get("api-2")
+	is.NoErr(err)
+	is.Equal(renamed.Metadata.Tags, []string{"core"})
+
+	// Overwriting replaces the schema, dropping its fetched snapshot
+	imported, err = library.ImportBundle(lib, bytes.NewReader(bundle), library.ImportOptions{OnConflict: library.ConflictOverwrite})
+	is.NoErr(err)
+	is.Equal(imported[0].Status, library.ImportOverwritten)
+	_, err = lib.Get("api@staging")
+	is.True(err != nil) // snapshot was not in the bundle
+}
+
+func TestBundle_ImportInvalid(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	_, err := library.ImportBundle(lib, bytes.NewReader([]byte("not a bundle")), library.ImportOptions{})
+	is.True(err != nil)
+
+	_, err = library.ParseConflictPolicy("merge")
+	is.True(err != nil)
+}
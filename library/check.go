@@ -0,0 +1,92 @@
+package library
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tonysyu/gqlxp/gql"
+)
+
+// DriftStatus is the outcome of comparing a stored schema with its source.
+type DriftStatus string
+
+// Drift statuses. Schemas without a source, and shared schemas, are skipped.
+const (
+	DriftInSync      DriftStatus = "in-sync"
+	DriftDrifted     DriftStatus = "drifted"
+	DriftUnreachable DriftStatus = "unreachable"
+	DriftSkipped     DriftStatus = "skipped"
+)
+
+// DriftResult is the outcome of checking one schema against its source.
+type DriftResult struct {
+	ID     string
+	Status DriftStatus
+	// Source is the URL or file the schema was compared with.
+	Source string
+	// Changes are the schema changes from the stored schema to its source when drifted.
+	// A drifted schema may have no changes if only formatting or comments differ.
+	Changes []gql.Change
+	// Err is the reason a schema was unreachable or skipped.
+	Err error
+}
+
+// CheckDrift compares schemas, or <id>@<env> environment snapshots, with their sources
+// without changing the library. Sources are fetched using a bounded pool of workers.
+// Results are in the order of ids.
+func CheckDrift(ctx context.Context, lib Library, ids []string, opts RefreshOptions) []DriftResult {
+	results := make([]DriftResult, len(ids))
+	forEachConcurrently(len(ids), opts.Workers, func(i int) {
+		results[i] = checkDrift(ctx, lib, ids[i], opts)
+	})
+	return results
+}
+
+// checkDrift compares one schema with its source.
+func checkDrift(ctx context.Context, lib Library, id string, opts RefreshOptions) DriftResult {
+	schema, err := lib.Get(id)
+	if err != nil {
+		return DriftResult{ID: id, Status: DriftUnreachable, Err: err}
+	}
+	result := DriftResult{ID: id, Source: schema.Metadata.SourceURL}
+	if result.Source == "" {
+		result.Source = schema.Metadata.SourceFile
+	}
+	if schema.Provenance == ProvenanceShared {
+		result.Status, result.Err = DriftSkipped, fmt.Errorf("shared schemas are checked with 'library sync'")
+		return result
+	}
+	if result.Source == "" {
+		result.Status, result.Err = DriftSkipped, ErrNoSource
+		return result
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	content, err := FetchSource(ctx, schema.Metadata)
+	if err != nil {
+		result.Status, result.Err = DriftUnreachable, err
+		return result
+	}
+	if CalculateFileHash(content) == schema.Metadata.FileHash {
+		result.Status = DriftInSync
+		return result
+	}
+
+	source, err := gql.ParseSchema(content)
+	if err != nil {
+		result.Status, result.Err = DriftUnreachable, fmt.Errorf("invalid GraphQL schema: %w", err)
+		return result
+	}
+	result.Status = DriftDrifted
+	// An environment that was never fetched has no stored content to compare with
+	if len(schema.Content) > 0 {
+		if stored, err := gql.ParseSchema(schema.Content); err == nil {
+			result.Changes = gql.DiffSchemas(&stored, &source)
+		}
+	}
+	return result
+}
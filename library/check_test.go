@@ -0,0 +1,49 @@
+package library_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func TestCheckDrift(t *testing.T) {
+	is := is.New(t)
+	tmpDir, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	writeSchema := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		is.NoErr(os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	billingFile := writeSchema("billing.graphqls", `type Query { invoice: ID }`)
+	usersFile := writeSchema("users.graphqls", `type Query { user: ID }`)
+	ordersFile := writeSchema("orders.graphqls", `type Query { order: ID }`)
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	is.NoErr(lib.Add("billing", "Billing", billingFile))
+	is.NoErr(lib.Add("users", "Users", usersFile))
+	is.NoErr(lib.Add("orders", "Orders", ordersFile))
+
+	writeSchema("users.graphqls", `type Query { user: ID team: ID }`)
+	is.NoErr(os.Remove(ordersFile))
+
+	results := library.CheckDrift(context.Background(), lib, []string{"billing", "users", "orders"}, library.RefreshOptions{})
+	is.Equal(len(results), 3)
+	is.Equal(results[0].Status, library.DriftInSync)
+	is.Equal(results[0].Source, billingFile)
+	is.Equal(results[1].Status, library.DriftDrifted)
+	is.Equal(results[1].Changes, []gql.Change{{Kind: gql.ChangeAdded, Path: "Query.team", New: "team: ID"}})
+	is.Equal(results[2].Status, library.DriftUnreachable)
+	is.True(results[2].Err != nil)
+
+	// Checking does not update the library
+	users, err := lib.Get("users")
+	is.NoErr(err)
+	is.Equal(string(users.Content), `type Query { user: ID }`)
+}
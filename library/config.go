@@ -51,11 +51,6 @@ func schemasDir() (string, error) {
 	return filepath.Join(configDir, "schemas"), nil
 }
 
-// GetSchemasDir returns the schemas directory (exported for CLI use)
-func GetSchemasDir() (string, error) {
-	return schemasDir()
-}
-
 // metadataFile returns the path to the metadata.json file.
 func metadataFile() (string, error) {
 	schemasDir, err := schemasDir()
@@ -65,15 +60,6 @@ func metadataFile() (string, error) {
 	return filepath.Join(schemasDir, "metadata.json"), nil
 }
 
-// userConfigFile returns the path to the user config.json file.
-func userConfigFile() (string, error) {
-	configDir, err := configDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "config.json"), nil
-}
-
 // InitConfigDir creates the configuration directory structure if it doesn't exist.
 func InitConfigDir() error {
 	schemasDir, err := schemasDir()
@@ -0,0 +1,200 @@
+package library
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tonysyu/gqlxp/gql/introspection"
+)
+
+// ConnectionProfile configures how requests are sent to a schema's endpoint.
+// Header values may contain secret references, ${env:NAME} or ${file:path}, which are
+// resolved when a request is made so that secrets are never stored in the library.
+type ConnectionProfile struct {
+	Headers map[string]string `json:"headers,omitempty"`
+	Timeout string            `json:"timeout,omitempty"` // Go duration, e.g. "30s"
+	Method  string            `json:"method,omitempty"`  // GET or POST
+}
+
+// secretRefPattern matches ${env:NAME} and ${file:path} references.
+var secretRefPattern = regexp.MustCompile(`\$\{(env|file):([^}]+)\}`)
+
+// sensitiveHeaderPattern matches header names whose values are treated as secrets.
+var sensitiveHeaderPattern = regexp.MustCompile(`(?i)(auth|cookie|token|secret|password|session|api-?key)`)
+
+// ContainsSecretRef reports whether value contains a ${env:...} or ${file:...} reference.
+func ContainsSecretRef(value string) bool {
+	return secretRefPattern.MatchString(value)
+}
+
+// IsSensitiveHeader reports whether a header with this name likely carries a secret.
+func IsSensitiveHeader(name string) bool {
+	return sensitiveHeaderPattern.MatchString(name)
+}
+
+// ResolveSecretRefs replaces ${env:NAME} and ${file:path} references in value.
+// File contents are trimmed of surrounding whitespace and a leading ~ expands to the home directory.
+func ResolveSecretRefs(value string) (string, error) {
+	var resolveErr error
+	resolved := secretRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		match := secretRefPattern.FindStringSubmatch(ref)
+		kind, target := match[1], strings.TrimSpace(match[2])
+		switch kind {
+		case "env":
+			v, ok := os.LookupEnv(target)
+			if !ok && resolveErr == nil {
+				resolveErr = fmt.Errorf("environment variable '%s' is not set", target)
+			}
+			return v
+		default:
+			path, err := expandHome(target)
+			if err != nil {
+				if resolveErr == nil {
+					resolveErr = err
+				}
+				return ""
+			}
+			data, err := os.ReadFile(path)
+			if err != nil && resolveErr == nil {
+				resolveErr = fmt.Errorf("failed to read secret file: %w", err)
+			}
+			return strings.TrimSpace(string(data))
+		}
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// expandHome replaces a leading ~ in path with the user's home directory.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// IsEmpty reports whether the profile has no settings.
+func (p ConnectionProfile) IsEmpty() bool {
+	return len(p.Headers) == 0 && p.Timeout == "" && p.Method == ""
+}
+
+// Validate checks that the timeout and method can be used for requests.
+func (p ConnectionProfile) Validate() error {
+	if p.Timeout != "" {
+		if _, err := time.ParseDuration(p.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout '%s': %w", p.Timeout, err)
+		}
+	}
+	switch strings.ToUpper(p.Method) {
+	case "", http.MethodGet, http.MethodPost:
+		return nil
+	default:
+		return fmt.Errorf("invalid method '%s': expected GET or POST", p.Method)
+	}
+}
+
+// WithHeaders returns a copy of the profile with headers ("Key: Value" format) added or replaced.
+// Sensitive headers with literal values are not stored, since they would be written in plain
+// text; their names are returned so that callers can suggest using a secret reference.
+func (p ConnectionProfile) WithHeaders(headers []string) (ConnectionProfile, []string, error) {
+	parsed, err := introspection.ParseHeaders(headers)
+	if err != nil {
+		return p, nil, err
+	}
+	merged := make(map[string]string, len(p.Headers)+len(parsed))
+	for k, v := range p.Headers {
+		merged[k] = v
+	}
+	var skipped []string
+	for k, v := range parsed {
+		if IsSensitiveHeader(k) && !ContainsSecretRef(v) {
+			skipped = append(skipped, k)
+			continue
+		}
+		merged[k] = v
+	}
+	sort.Strings(skipped)
+	if len(merged) == 0 {
+		merged = nil
+	}
+	p.Headers = merged
+	return p, skipped, nil
+}
+
+// WithoutSecrets returns a copy of the profile without the headers that carry or reference
+// secrets, for sharing the profile with others. The names of removed headers are returned.
+func (p ConnectionProfile) WithoutSecrets() (ConnectionProfile, []string) {
+	var kept map[string]string
+	var removed []string
+	for k, v := range p.Headers {
+		if IsSensitiveHeader(k) || ContainsSecretRef(v) {
+			removed = append(removed, k)
+			continue
+		}
+		if kept == nil {
+			kept = make(map[string]string)
+		}
+		kept[k] = v
+	}
+	sort.Strings(removed)
+	p.Headers = kept
+	return p, removed
+}
+
+// ClientOptions resolves the profile into client options for a request.
+// extraHeaders ("Key: Value" format) are applied last and override profile headers.
+func (p ConnectionProfile) ClientOptions(extraHeaders []string) (introspection.ClientOptions, error) {
+	if err := p.Validate(); err != nil {
+		return introspection.ClientOptions{}, err
+	}
+	opts := introspection.DefaultClientOptions()
+	for k, v := range p.Headers {
+		resolved, err := ResolveSecretRefs(v)
+		if err != nil {
+			return introspection.ClientOptions{}, fmt.Errorf("failed to resolve header '%s': %w", k, err)
+		}
+		opts.Headers[k] = resolved
+	}
+	if p.Timeout != "" {
+		// Validated above
+		opts.Timeout, _ = time.ParseDuration(p.Timeout)
+	}
+	if p.Method != "" {
+		opts.Method = strings.ToUpper(p.Method)
+	}
+
+	if len(extraHeaders) > 0 {
+		parsed, err := introspection.ParseHeaders(extraHeaders)
+		if err != nil {
+			return introspection.ClientOptions{}, fmt.Errorf("failed to parse headers: %w", err)
+		}
+		for k, v := range parsed {
+			resolved, err := ResolveSecretRefs(v)
+			if err != nil {
+				return introspection.ClientOptions{}, fmt.Errorf("failed to resolve header '%s': %w", k, err)
+			}
+			opts.Headers[k] = resolved
+		}
+	}
+	return opts, nil
+}
+
+// ConnectionProfile returns the schema's saved connection profile, or an empty profile.
+func (m SchemaMetadata) ConnectionProfile() ConnectionProfile {
+	if m.Connection == nil {
+		return ConnectionProfile{}
+	}
+	return *m.Connection
+}
@@ -0,0 +1,162 @@
+package library_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func TestResolveSecretRefs(t *testing.T) {
+	t.Setenv("GQLXP_TEST_TOKEN", "env-secret")
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "literal", value: "gqlxp", want: "gqlxp"},
+		{name: "env reference", value: "Bearer ${env:GQLXP_TEST_TOKEN}", want: "Bearer env-secret"},
+		{name: "file reference is trimmed", value: "${file:" + tokenFile + "}", want: "file-secret"},
+		{name: "missing env variable", value: "${env:GQLXP_TEST_MISSING}", wantErr: true},
+		{name: "missing file", value: "${file:" + tokenFile + ".missing}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			got, err := library.ResolveSecretRefs(tt.value)
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(got, tt.want)
+		})
+	}
+}
+
+func TestIsSensitiveHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "Authorization", want: true},
+		{name: "Proxy-Authorization", want: true},
+		{name: "X-Auth", want: true},
+		{name: "X-Auth-Key", want: true},
+		{name: "X-Session-Id", want: true},
+		{name: "Cookie", want: true},
+		{name: "X-Access-Token", want: true},
+		{name: "X-Api-Key", want: true},
+		{name: "apikey", want: true},
+		{name: "X-Client-Secret", want: true},
+		{name: "X-Client", want: false},
+		{name: "X-Tenant", want: false},
+		{name: "Accept", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(library.IsSensitiveHeader(tt.name), tt.want)
+		})
+	}
+}
+
+func TestConnectionProfile_WithHeadersSkipsLiteralSecrets(t *testing.T) {
+	is := is.New(t)
+	base := library.ConnectionProfile{Headers: map[string]string{"X-Client": "gqlxp"}}
+
+	profile, skipped, err := base.WithHeaders([]string{
+		"Authorization: Bearer abc123",
+		"X-Api-Key: ${env:API_KEY}",
+		"X-Client: gqlxp-cli",
+	})
+
+	is.NoErr(err)
+	is.Equal(skipped, []string{"Authorization"}) // literal secrets are not stored
+	is.Equal(profile.Headers, map[string]string{
+		"X-Api-Key": "${env:API_KEY}", // references are stored unresolved
+		"X-Client":  "gqlxp-cli",      // existing headers are replaced
+	})
+	is.Equal(base.Headers["X-Client"], "gqlxp") // base profile is not modified
+}
+
+func TestConnectionProfile_WithoutSecrets(t *testing.T) {
+	is := is.New(t)
+	profile := library.ConnectionProfile{
+		Headers: map[string]string{
+			"Authorization": "Bearer ${env:TOKEN}",
+			"X-Tenant":      "${file:~/.tenant}",
+			"X-Client":      "gqlxp",
+		},
+		Timeout: "5s",
+	}
+
+	shared, removed := profile.WithoutSecrets()
+
+	is.Equal(removed, []string{"Authorization", "X-Tenant"})
+	is.Equal(shared.Headers, map[string]string{"X-Client": "gqlxp"})
+	is.Equal(shared.Timeout, "5s")
+	is.Equal(len(profile.Headers), 3) // original profile is not modified
+}
+
+func TestConnectionProfile_ClientOptions(t *testing.T) {
+	is := is.New(t)
+	t.Setenv("GQLXP_TEST_TOKEN", "env-secret")
+	profile := library.ConnectionProfile{
+		Headers: map[string]string{
+			"Authorization": "Bearer ${env:GQLXP_TEST_TOKEN}",
+			"X-Client":      "gqlxp",
+		},
+		Timeout: "5s",
+		Method:  "get",
+	}
+
+	opts, err := profile.ClientOptions([]string{"X-Client: override"})
+
+	is.NoErr(err)
+	is.Equal(opts.Headers["Authorization"], "Bearer env-secret") // references are resolved
+	is.Equal(opts.Headers["X-Client"], "override")               // extra headers take precedence
+	is.Equal(opts.Headers["Content-Type"], "application/json")   // defaults are kept
+	is.Equal(opts.Timeout, 5*time.Second)
+	is.Equal(opts.Method, "GET")
+}
+
+func TestConnectionProfile_Validate(t *testing.T) {
+	is := is.New(t)
+
+	is.NoErr(library.ConnectionProfile{}.Validate())
+	is.True(library.ConnectionProfile{Timeout: "soon"}.Validate() != nil)
+	is.True(library.ConnectionProfile{Method: "PUT"}.Validate() != nil)
+}
+
+func TestConnectionProfile_PersistedInMetadata(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	lib := library.NewLibrary()
+	is.NoErr(lib.AddFromContent("api", "API", []byte("type Query { hello: String }"), "https://example.com/graphql"))
+	schema, err := lib.Get("api")
+	is.NoErr(err)
+	schema.Metadata.Connection = &library.ConnectionProfile{
+		Headers: map[string]string{"Authorization": "Bearer ${env:API_TOKEN}"},
+		Timeout: "10s",
+	}
+	is.NoErr(lib.UpdateMetadata("api", schema.Metadata))
+
+	reloaded, err := lib.Get("api")
+
+	is.NoErr(err)
+	is.Equal(reloaded.Metadata.ConnectionProfile(), *schema.Metadata.Connection)
+}
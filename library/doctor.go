@@ -0,0 +1,360 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/search"
+)
+
+// ProblemKind is a kind of problem found by Diagnose.
+type ProblemKind string
+
+// Problem kinds.
+const (
+	// ProblemMissingFile is a metadata entry (or fetched environment) without a schema file.
+	ProblemMissingFile ProblemKind = "missing-file"
+	// ProblemOrphanFile is a schema file without a metadata entry or environment.
+	ProblemOrphanFile ProblemKind = "orphan-file"
+	// ProblemHashMismatch is a schema file whose content doesn't match its recorded hash.
+	ProblemHashMismatch ProblemKind = "hash-mismatch"
+	// ProblemInvalidSchema is a stored schema that no longer parses.
+	ProblemInvalidSchema ProblemKind = "invalid-schema"
+	// ProblemMissingIndex is a schema without a search index.
+	ProblemMissingIndex ProblemKind = "missing-index"
+	// ProblemOutdatedIndex is a search index built with an earlier mapping version.
+	ProblemOutdatedIndex ProblemKind = "outdated-index"
+	// ProblemOrphanIndex is a search index, or leftover temporary index, of no schema.
+	ProblemOrphanIndex ProblemKind = "orphan-index"
+	// ProblemUnreadableSource is a source file that can no longer be read.
+	ProblemUnreadableSource ProblemKind = "unreadable-source"
+	// ProblemMissingDefault is a default schema that is not in the library.
+	ProblemMissingDefault ProblemKind = "missing-default"
+)
+
+// tempIndexMaxAge is the age after which a temporary index directory is considered left
+// over from an interrupted build rather than in use by another process.
+const tempIndexMaxAge = time.Hour
+
+// Problem is an integrity problem in the library.
+type Problem struct {
+	Kind ProblemKind
+	// Ref is the schema, or <id>@<env> environment, with the problem. For orphan indexes it
+	// is the name of the index directory.
+	Ref    string
+	Detail string
+	// Fix describes how Repair fixes the problem; empty if it must be fixed by hand.
+	Fix string
+}
+
+// Fixable reports whether Repair can fix the problem.
+func (p Problem) Fixable() bool {
+	return p.Fix != ""
+}
+
+// ErrNotStoreLibrary is returned when diagnosing a library that is not a StoreLibrary.
+var ErrNotStoreLibrary = errors.New("only libraries created by NewLibrary can be diagnosed")
+
+// Diagnose audits the library files, metadata, search indexes, and configuration, and
+// returns the problems found, ordered by schema.
+func Diagnose(lib Library) ([]Problem, error) {
+	storeLib, ok := lib.(*StoreLibrary)
+	if !ok {
+		return nil, ErrNotStoreLibrary
+	}
+	allMetadata, err := storeLib.loadAllMetadata()
+	if err != nil {
+		return nil, err
+	}
+	files, err := readStoredSchemas(storeLib.store)
+	if err != nil {
+		return nil, err
+	}
+	indexes, tempIndexes, err := readIndexDir(storeLib.store.IndexDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	add := func(kind ProblemKind, ref, fix, format string, args ...any) {
+		problems = append(problems, Problem{Kind: kind, Ref: ref, Detail: fmt.Sprintf(format, args...), Fix: fix})
+	}
+
+	// Refs whose index is expected, with the content to index
+	indexed := make(map[string][]byte)
+	for _, id := range slices.Sorted(maps.Keys(allMetadata)) {
+		metadata := allMetadata[id]
+		content, ok := files[id]
+		if !ok {
+			add(ProblemMissingFile, id, "remove the metadata entry", "metadata entry has no schema file %s.graphqls", id)
+		} else {
+			checkContent(add, id, content, metadata.FileHash)
+			indexed[id] = content
+		}
+		for _, name := range metadata.EnvironmentNames() {
+			ref := SchemaRef(id, name)
+			content, ok := files[ref]
+			env := metadata.Environments[name]
+			switch {
+			case ok:
+				checkContent(add, ref, content, env.FileHash)
+				indexed[ref] = content
+			case env.FileHash != "":
+				add(ProblemMissingFile, ref, "clear the snapshot hash", "environment snapshot %s.graphqls is missing", ref)
+			}
+		}
+		if metadata.SourceFile != "" {
+			if _, err := SourceFiles(metadata.SourceFile); err != nil {
+				add(ProblemUnreadableSource, id, "", "source file %s cannot be read: %v", metadata.SourceFile, err)
+			}
+		}
+	}
+
+	for _, ref := range slices.Sorted(maps.Keys(files)) {
+		if _, ok := indexed[ref]; ok {
+			continue
+		}
+		id, env := ParseSchemaRef(ref)
+		if _, ok := allMetadata[id]; ok && env != "" {
+			add(ProblemOrphanFile, ref, "remove the snapshot", "snapshot of undeclared environment '%s'", env)
+			continue
+		}
+		if env != "" {
+			add(ProblemOrphanFile, ref, "remove the snapshot", "snapshot of unknown schema '%s'", id)
+			continue
+		}
+		add(ProblemOrphanFile, ref, "add a metadata entry", "schema file has no metadata entry")
+		checkContent(add, ref, files[ref], CalculateFileHash(files[ref]))
+		indexed[ref] = files[ref]
+	}
+
+	// Shared schemas are indexed alongside personal ones
+	if shared, err := storeLib.openSharedLibrary(); err == nil && shared != nil {
+		sharedIDs, _ := shared.ids()
+		for _, id := range sharedIDs {
+			if _, ok := indexed[id]; !ok {
+				if schema, err := shared.get(id); err == nil {
+					indexed[id] = schema.Content
+				}
+			}
+		}
+	}
+
+	if storeLib.indexer != nil {
+		for _, ref := range slices.Sorted(maps.Keys(indexed)) {
+			if _, err := gql.ParseSchema(indexed[ref]); err != nil {
+				continue // reported as an invalid schema
+			}
+			if !storeLib.indexer.Exists(ref) {
+				add(ProblemMissingIndex, ref, "build the index", "schema has no search index")
+			} else if outdated, err := storeLib.indexer.Outdated(ref); err != nil {
+				add(ProblemOutdatedIndex, ref, "rebuild the index", "index cannot be read: %v", err)
+			} else if outdated {
+				add(ProblemOutdatedIndex, ref, "rebuild the index", "index was built by an earlier version of gqlxp")
+			}
+		}
+	}
+	for _, name := range indexes {
+		if _, ok := indexed[strings.TrimSuffix(name, indexSuffix)]; !ok {
+			add(ProblemOrphanIndex, name, "remove the index", "index of no schema")
+		}
+	}
+	for _, name := range tempIndexes {
+		add(ProblemOrphanIndex, name, "remove the index", "left over from an interrupted index build")
+	}
+
+	if config, err := storeLib.loadUserConfig(); err == nil && config.DefaultSchema != "" {
+		if _, err := lib.Get(config.DefaultSchema); err != nil {
+			add(ProblemMissingDefault, config.DefaultSchema, "clear the default schema", "default schema is not in the library")
+		}
+	}
+
+	slices.SortStableFunc(problems, func(a, b Problem) int {
+		return strings.Compare(a.Ref, b.Ref)
+	})
+	return problems, nil
+}
+
+// checkContent reports a schema whose content doesn't match hash or no longer parses.
+func checkContent(add func(kind ProblemKind, ref, fix, format string, args ...any), ref string, content []byte, hash string) {
+	if CalculateFileHash(content) != hash {
+		add(ProblemHashMismatch, ref, "record the hash and rebuild the index", "schema file was changed outside gqlxp")
+	}
+	if _, err := gql.ParseSchema(content); err != nil {
+		add(ProblemInvalidSchema, ref, "", "schema no longer parses: %v", err)
+	}
+}
+
+// indexSuffix ends the names of search index directories.
+const indexSuffix = ".bleve"
+
+// readStoredSchemas returns the contents of the schema files in store by reference.
+func readStoredSchemas(store Store) (map[string][]byte, error) {
+	refs, err := store.SchemaRefs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schemas: %w", err)
+	}
+	files := make(map[string][]byte, len(refs))
+	for _, ref := range refs {
+		content, err := store.ReadSchema(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema file: %w", err)
+		}
+		files[ref] = content
+	}
+	return files, nil
+}
+
+// readIndexDir returns the names of the index directories in dir and of temporary index
+// directories left over from interrupted builds. Indexes kept in memory (an empty dir) have
+// no leftovers.
+func readIndexDir(dir string) (indexes, tempIndexes []string, err error) {
+	if dir == "" {
+		return nil, nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to read index directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case !entry.IsDir():
+			continue
+		case strings.HasPrefix(name, search.TempIndexPrefix):
+			if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > tempIndexMaxAge {
+				tempIndexes = append(tempIndexes, name)
+			}
+		case strings.HasSuffix(name, indexSuffix):
+			indexes = append(indexes, name)
+		}
+	}
+	return indexes, tempIndexes, nil
+}
+
+// Repair fixes a problem found by Diagnose.
+func Repair(lib Library, problem Problem) error {
+	storeLib, ok := lib.(*StoreLibrary)
+	if !ok {
+		return ErrNotStoreLibrary
+	}
+	if !problem.Fixable() {
+		return fmt.Errorf("%s problems must be fixed by hand", problem.Kind)
+	}
+	store := storeLib.store
+	id, env := ParseSchemaRef(problem.Ref)
+
+	switch problem.Kind {
+	case ProblemMissingFile:
+		if env != "" {
+			return storeLib.setSnapshotHash(id, env, "")
+		}
+		// Remove the entry along with its environment snapshots, as Remove does
+		var envNames []string
+		if err := storeLib.updateAllMetadata(func(allMetadata map[string]SchemaMetadata) error {
+			envNames = allMetadata[id].EnvironmentNames()
+			delete(allMetadata, id)
+			return nil
+		}); err != nil {
+			return err
+		}
+		for _, name := range envNames {
+			storeLib.removeSnapshot(SchemaRef(id, name))
+		}
+		return storeLib.removeIndex(id)
+	case ProblemOrphanFile:
+		if env != "" {
+			if err := store.DeleteSchema(problem.Ref); err != nil {
+				return fmt.Errorf("failed to remove snapshot: %w", err)
+			}
+			return storeLib.removeIndex(problem.Ref)
+		}
+		return storeLib.adoptSchemaFile(id)
+	case ProblemHashMismatch:
+		content, err := store.ReadSchema(problem.Ref)
+		if err != nil {
+			return fmt.Errorf("failed to read schema file: %w", err)
+		}
+		if env != "" {
+			err = storeLib.setSnapshotHash(id, env, CalculateFileHash(content))
+		} else {
+			err = storeLib.updateAllMetadata(func(allMetadata map[string]SchemaMetadata) error {
+				metadata, ok := allMetadata[id]
+				if !ok {
+					return fmt.Errorf("schema '%s' not found", id)
+				}
+				metadata.FileHash = CalculateFileHash(content)
+				allMetadata[id] = metadata
+				return nil
+			})
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := gql.ParseSchema(content); err != nil {
+			return nil // reported as an invalid schema
+		}
+		return storeLib.Reindex(problem.Ref)
+	case ProblemMissingIndex, ProblemOutdatedIndex:
+		return storeLib.Reindex(problem.Ref)
+	case ProblemOrphanIndex:
+		if store.IndexDir() == "" {
+			return nil
+		}
+		if err := os.RemoveAll(filepath.Join(store.IndexDir(), problem.Ref)); err != nil {
+			return fmt.Errorf("failed to remove index: %w", err)
+		}
+		return nil
+	case ProblemMissingDefault:
+		return lib.SetDefaultSchema("")
+	}
+	return fmt.Errorf("unknown problem kind '%s'", problem.Kind)
+}
+
+// setSnapshotHash records the hash of the snapshot of environment env of schema id.
+func (l *StoreLibrary) setSnapshotHash(id, env, hash string) error {
+	return l.updateAllMetadata(func(allMetadata map[string]SchemaMetadata) error {
+		metadata, ok := allMetadata[id]
+		if !ok {
+			return fmt.Errorf("schema '%s' not found", id)
+		}
+		environment, err := metadata.Environment(env)
+		if err != nil {
+			return err
+		}
+		environment.FileHash = hash
+		metadata.Environments[env] = environment
+		allMetadata[id] = metadata
+		return nil
+	})
+}
+
+// adoptSchemaFile adds a metadata entry for a schema file that has none.
+func (l *StoreLibrary) adoptSchemaFile(id string) error {
+	content, err := l.store.ReadSchema(id)
+	if err != nil {
+		return fmt.Errorf("failed to read schema file: %w", err)
+	}
+	now := time.Now()
+	return l.updateAllMetadata(func(allMetadata map[string]SchemaMetadata) error {
+		if _, ok := allMetadata[id]; ok {
+			return nil
+		}
+		allMetadata[id] = SchemaMetadata{
+			DisplayName: id,
+			FileHash:    CalculateFileHash(content),
+			URLPatterns: make(map[string]string),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		return nil
+	})
+}
@@ -0,0 +1,127 @@
+package library_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+const doctorSchema = `type Query { a: ID }`
+
+// problemsByRef returns the kinds of problems found for each schema reference.
+func problemsByRef(t *testing.T, lib library.Library) map[string][]library.ProblemKind {
+	t.Helper()
+	problems, err := library.Diagnose(lib)
+	if err != nil {
+		t.Fatalf("Diagnose: %v", err)
+	}
+	kinds := make(map[string][]library.ProblemKind)
+	for _, problem := range problems {
+		kinds[problem.Ref] = append(kinds[problem.Ref], problem.Kind)
+	}
+	return kinds
+}
+
+func TestDiagnose_HealthyLibrary(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	is.NoErr(lib.AddFromContent("api", "API", []byte(doctorSchema), ""))
+	is.NoErr(lib.SetDefaultSchema("api"))
+	is.NoErr(lib.WaitForIndexing())
+
+	problems, err := library.Diagnose(lib)
+	is.NoErr(err)
+	is.Equal(len(problems), 0)
+}
+
+func TestDiagnose_FindsAndRepairsProblems(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	indexer := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(indexer)
+	for _, id := range []string{"deleted", "edited", "broken", "unindexed", "stale"} {
+		is.NoErr(lib.AddFromContent(id, id, []byte(doctorSchema), ""))
+	}
+	is.NoErr(lib.SetDefaultSchema("deleted"))
+	is.NoErr(lib.ModifyMetadata("deleted", func(metadata *library.SchemaMetadata) error {
+		metadata.Environments = map[string]library.Environment{"staging": {SourceURL: "https://staging.example.com"}}
+		return nil
+	}))
+	is.NoErr(lib.UpdateContent("deleted@staging", []byte(doctorSchema)))
+	is.NoErr(lib.WaitForIndexing())
+
+	dir, err := library.SchemasDir()
+	is.NoErr(err)
+	is.NoErr(os.Remove(filepath.Join(dir, "deleted.graphqls")))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "edited.graphqls"), []byte(`type Query { b: ID }`), 0644))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "broken.graphqls"), []byte(`type Query {`), 0644))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "stray.graphqls"), []byte(doctorSchema), 0644))
+	is.NoErr(os.Mkdir(filepath.Join(dir, "gone.bleve"), 0755))
+	is.NoErr(indexer.Remove("unindexed"))
+	indexer.outdated = map[string]bool{"stale": true}
+
+	kinds := problemsByRef(t, lib)
+	is.Equal(kinds["deleted"], []library.ProblemKind{library.ProblemMissingFile, library.ProblemMissingDefault})
+	is.Equal(kinds["edited"], []library.ProblemKind{library.ProblemHashMismatch})
+	is.Equal(kinds["broken"], []library.ProblemKind{library.ProblemHashMismatch, library.ProblemInvalidSchema})
+	is.Equal(kinds["stray"], []library.ProblemKind{library.ProblemOrphanFile, library.ProblemMissingIndex})
+	is.Equal(kinds["unindexed"], []library.ProblemKind{library.ProblemMissingIndex})
+	is.Equal(kinds["stale"], []library.ProblemKind{library.ProblemOutdatedIndex})
+	is.Equal(kinds["gone.bleve"], []library.ProblemKind{library.ProblemOrphanIndex})
+
+	problems, err := library.Diagnose(lib)
+	is.NoErr(err)
+	for _, problem := range problems {
+		if problem.Fixable() {
+			is.NoErr(library.Repair(lib, problem))
+		} else {
+			is.True(library.Repair(lib, problem) != nil)
+		}
+	}
+	is.NoErr(lib.WaitForIndexing())
+
+	// Only the schema that no longer parses must be fixed by hand
+	kinds = problemsByRef(t, lib)
+	is.Equal(len(kinds), 1)
+	is.Equal(kinds["broken"], []library.ProblemKind{library.ProblemInvalidSchema})
+
+	_, err = lib.Get("deleted")
+	is.True(err != nil)
+	_, err = os.Stat(filepath.Join(dir, "deleted@staging.graphqls"))
+	is.True(os.IsNotExist(err))                 // environment snapshot is removed with its schema
+	is.True(indexer.removed["deleted@staging"]) // and so is its search index
+	stray, err := lib.Get("stray")
+	is.NoErr(err)
+	is.Equal(stray.Metadata.FileHash, library.CalculateFileHash([]byte(doctorSchema)))
+	is.True(indexer.indexed["edited"])
+	is.True(indexer.indexed["stray"])
+	_, err = os.Stat(filepath.Join(dir, "gone.bleve"))
+	is.True(os.IsNotExist(err))
+}
+
+func TestDiagnose_UnreadableSource(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	sourceFile := filepath.Join(t.TempDir(), "api.graphqls")
+	is.NoErr(os.WriteFile(sourceFile, []byte(doctorSchema), 0644))
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	is.NoErr(lib.Add("api", "API", sourceFile))
+	is.NoErr(lib.WaitForIndexing())
+	is.NoErr(os.Remove(sourceFile))
+
+	problems, err := library.Diagnose(lib)
+	is.NoErr(err)
+	is.Equal(len(problems), 1)
+	is.Equal(problems[0].Kind, library.ProblemUnreadableSource)
+	is.True(!problems[0].Fixable())
+}
@@ -0,0 +1,234 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EnvSeparator separates a schema ID from an environment name in a schema reference,
+// e.g. "api@staging".
+const EnvSeparator = "@"
+
+// Environment is a named deployment of a schema, such as staging or prod, with its own
+// endpoint. Its schema snapshot is stored separately from the base schema.
+type Environment struct {
+	SourceURL  string             `json:"sourceURL"`
+	FileHash   string             `json:"fileHash,omitempty"`
+	Connection *ConnectionProfile `json:"connection,omitempty"`
+	// UpdatedAt is the time the snapshot was last fetched; zero if it never was.
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+}
+
+// EnvironmentInfo represents basic environment information for listing.
+type EnvironmentInfo struct {
+	Name      string
+	SourceURL string
+	UpdatedAt time.Time
+}
+
+var envNamePattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// ValidateEnvironmentName checks that name can be used in a schema reference.
+func ValidateEnvironmentName(name string) error {
+	if !envNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid environment name '%s': must contain only lowercase letters, numbers, and hyphens", name)
+	}
+	return nil
+}
+
+// ParseSchemaRef splits a schema reference of the form <id> or <id>@<env>.
+func ParseSchemaRef(ref string) (id, env string) {
+	id, env, _ = strings.Cut(ref, EnvSeparator)
+	return id, env
+}
+
+// SchemaRef returns the reference to an environment of a schema, or id if env is empty.
+func SchemaRef(id, env string) string {
+	if env == "" {
+		return id
+	}
+	return id + EnvSeparator + env
+}
+
+// ConnectionProfile returns the environment's connection profile, or an empty profile.
+func (e Environment) ConnectionProfile() ConnectionProfile {
+	if e.Connection == nil {
+		return ConnectionProfile{}
+	}
+	return *e.Connection
+}
+
+// EnvironmentNames returns the names of the schema's environments in sorted order.
+func (m SchemaMetadata) EnvironmentNames() []string {
+	names := make([]string, 0, len(m.Environments))
+	for name := range m.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Environment returns the named environment or an error listing the available ones.
+func (m SchemaMetadata) Environment(name string) (Environment, error) {
+	env, ok := m.Environments[name]
+	if !ok {
+		if len(m.Environments) == 0 {
+			return Environment{}, fmt.Errorf("environment '%s' not found: schema has no environments", name)
+		}
+		return Environment{}, fmt.Errorf("environment '%s' not found. Available: %s", name, strings.Join(m.EnvironmentNames(), ", "))
+	}
+	return env, nil
+}
+
+// environmentView returns the metadata of an environment snapshot: the base schema's metadata
+// with the environment's source, hash, connection, and freshness.
+func environmentView(base SchemaMetadata, name string, env Environment) SchemaMetadata {
+	view := base
+	view.DisplayName = fmt.Sprintf("%s (%s)", base.DisplayName, name)
+	view.SourceFile = ""
+	view.SourceURL = env.SourceURL
+	view.FileHash = env.FileHash
+	view.Connection = env.Connection
+	view.UpdatedAt = env.UpdatedAt
+	view.Environments = nil
+	return view
+}
+
+// environmentInfos returns listing information for the environments in metadata.
+func environmentInfos(metadata SchemaMetadata) []EnvironmentInfo {
+	var infos []EnvironmentInfo
+	for _, name := range metadata.EnvironmentNames() {
+		env := metadata.Environments[name]
+		infos = append(infos, EnvironmentInfo{Name: name, SourceURL: env.SourceURL, UpdatedAt: env.UpdatedAt})
+	}
+	return infos
+}
+
+// getEnvironment returns the snapshot of an environment of schema id.
+func (l *StoreLibrary) getEnvironment(id, name string) (*Schema, error) {
+	allMetadata, err := l.loadAllMetadata()
+	if err != nil {
+		return nil, err
+	}
+	base, ok := allMetadata[id]
+	if !ok {
+		return nil, fmt.Errorf("schema '%s' not found", id)
+	}
+	env, err := base.Environment(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := SchemaRef(id, name)
+	content, err := l.store.ReadSchema(ref)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("environment '%s' of schema '%s' has not been fetched: run 'gqlxp library update --id %s --env %s'", name, id, id, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	return &Schema{
+		ID:       ref,
+		Content:  content,
+		Metadata: environmentView(base, name, env),
+	}, nil
+}
+
+// updateEnvironmentContent writes the snapshot of an environment and records its freshness.
+func (l *StoreLibrary) updateEnvironmentContent(id, name string, content []byte) error {
+	ref := SchemaRef(id, name)
+	err := l.updateAllMetadata(func(allMetadata map[string]SchemaMetadata) error {
+		base, ok := allMetadata[id]
+		if !ok {
+			return fmt.Errorf("schema '%s' not found", id)
+		}
+		env, err := base.Environment(name)
+		if err != nil {
+			return err
+		}
+
+		if err := l.store.WriteSchema(ref, content); err != nil {
+			return fmt.Errorf("failed to write schema file: %w", err)
+		}
+
+		env.FileHash = CalculateFileHash(content)
+		env.UpdatedAt = time.Now()
+		base.Environments[name] = env
+		allMetadata[id] = base
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Re-index the snapshot in the background (non-blocking)
+	l.indexAsync(ref)
+
+	return nil
+}
+
+// modifyEnvironmentMetadata applies modify to an environment view (see getEnvironment) of
+// the current metadata. The source URL and connection belong to the environment, marking it
+// as refreshed, while URL patterns are shared with the base schema.
+func (l *StoreLibrary) modifyEnvironmentMetadata(id, name string, modify func(metadata *SchemaMetadata) error) error {
+	return l.updateAllMetadata(func(allMetadata map[string]SchemaMetadata) error {
+		base, ok := allMetadata[id]
+		if !ok {
+			return fmt.Errorf("schema '%s' not found", id)
+		}
+		env, err := base.Environment(name)
+		if err != nil {
+			return err
+		}
+		metadata := environmentView(base, name, env)
+		if err := modify(&metadata); err != nil {
+			return err
+		}
+
+		env.SourceURL = metadata.SourceURL
+		env.Connection = metadata.Connection
+		env.UpdatedAt = time.Now()
+		base.Environments[name] = env
+		base.URLPatterns = metadata.URLPatterns
+		allMetadata[id] = base
+		return nil
+	})
+}
+
+// removeEnvironment removes an environment declaration and its snapshot.
+func (l *StoreLibrary) removeEnvironment(id, name string) error {
+	err := l.updateAllMetadata(func(allMetadata map[string]SchemaMetadata) error {
+		base, ok := allMetadata[id]
+		if !ok {
+			return fmt.Errorf("schema '%s' not found", id)
+		}
+		if _, err := base.Environment(name); err != nil {
+			return err
+		}
+
+		delete(base.Environments, name)
+		if len(base.Environments) == 0 {
+			base.Environments = nil
+		}
+		allMetadata[id] = base
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	l.removeSnapshot(SchemaRef(id, name))
+	return nil
+}
+
+// removeSnapshot deletes the stored snapshot and search index of an environment, if any.
+func (l *StoreLibrary) removeSnapshot(ref string) {
+	_ = l.store.DeleteSchema(ref)
+	_ = l.removeIndex(ref)
+}
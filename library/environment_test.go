@@ -0,0 +1,127 @@
+package library_test
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func TestParseSchemaRef(t *testing.T) {
+	is := is.New(t)
+
+	id, env := library.ParseSchemaRef("api@staging")
+	is.Equal(id, "api")
+	is.Equal(env, "staging")
+
+	id, env = library.ParseSchemaRef("api")
+	is.Equal(id, "api")
+	is.Equal(env, "")
+
+	is.Equal(library.SchemaRef("api", "staging"), "api@staging")
+	is.Equal(library.SchemaRef("api", ""), "api")
+}
+
+// addStagingEnvironment adds a schema "api" with an unfetched "staging" environment.
+func addStagingEnvironment(t *testing.T, lib library.Library) {
+	t.Helper()
+	is := is.New(t)
+	is.NoErr(lib.AddFromContent("api", "API", []byte(`type Query { hello: String }`), "https://example.com/graphql"))
+	schema, err := lib.Get("api")
+	is.NoErr(err)
+	schema.Metadata.Environments = map[string]library.Environment{
+		"staging": {
+			SourceURL:  "https://staging.example.com/graphql",
+			Connection: &library.ConnectionProfile{Timeout: "5s"},
+		},
+	}
+	is.NoErr(lib.UpdateMetadata("api", schema.Metadata))
+}
+
+func TestLibrary_EnvironmentSnapshot(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	mock := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(mock)
+	addStagingEnvironment(t, lib)
+
+	_, err := lib.Get("api@staging")
+	is.True(err != nil) // snapshot has not been fetched
+
+	is.NoErr(lib.UpdateContent("api@staging", []byte(`type Query { hello: String, staging: Boolean }`)))
+
+	snapshot, err := lib.Get("api@staging")
+	is.NoErr(err)
+	is.Equal(snapshot.ID, "api@staging")
+	is.Equal(string(snapshot.Content), `type Query { hello: String, staging: Boolean }`)
+	is.Equal(snapshot.Metadata.SourceURL, "https://staging.example.com/graphql")
+	is.Equal(snapshot.Metadata.ConnectionProfile().Timeout, "5s")
+	is.True(!snapshot.Metadata.UpdatedAt.IsZero())
+
+	base, err := lib.Get("api")
+	is.NoErr(err)
+	is.Equal(string(base.Content), `type Query { hello: String }`) // base schema is unchanged
+
+	is.NoErr(lib.WaitForIndexing())
+	is.True(mock.indexed["api@staging"])
+}
+
+func TestLibrary_ListIncludesEnvironments(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	addStagingEnvironment(t, lib)
+	is.NoErr(lib.UpdateContent("api@staging", []byte(`type Query { hello: String }`)))
+
+	schemas, err := lib.List()
+
+	is.NoErr(err)
+	is.Equal(len(schemas), 1) // snapshots are not listed as schemas
+	is.Equal(len(schemas[0].Environments), 1)
+	is.Equal(schemas[0].Environments[0].Name, "staging")
+	is.True(!schemas[0].Environments[0].UpdatedAt.IsZero())
+}
+
+func TestLibrary_RemoveEnvironment(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	mock := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(mock)
+	addStagingEnvironment(t, lib)
+	is.NoErr(lib.UpdateContent("api@staging", []byte(`type Query { hello: String }`)))
+	is.NoErr(lib.WaitForIndexing())
+
+	is.NoErr(lib.Remove("api@staging"))
+
+	base, err := lib.Get("api")
+	is.NoErr(err)
+	is.Equal(len(base.Metadata.Environments), 0)
+	_, err = lib.Get("api@staging")
+	is.True(err != nil)
+	is.True(mock.removed["api@staging"])
+}
+
+func TestLibrary_RemoveSchemaRemovesEnvironments(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	mock := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(mock)
+	addStagingEnvironment(t, lib)
+	is.NoErr(lib.UpdateContent("api@staging", []byte(`type Query { hello: String }`)))
+	is.NoErr(lib.WaitForIndexing())
+
+	is.NoErr(lib.Remove("api"))
+
+	is.True(mock.removed["api@staging"])
+	is.NoErr(lib.AddFromContent("api", "API", []byte(`type Query { hello: String }`), "https://example.com/graphql"))
+	_, err := lib.Get("api@staging")
+	is.True(err != nil) // environment does not survive removal of its schema
+}
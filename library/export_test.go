@@ -1,8 +1,24 @@
 package library
 
-import "github.com/tonysyu/gqlxp/search"
+import (
+	"time"
+
+	"github.com/tonysyu/gqlxp/search"
+)
 
 // NewLibraryWithIndexer creates a Library with an injected indexer, for testing.
 func NewLibraryWithIndexer(indexer search.Indexer) Library {
-	return &FileLibrary{indexer: indexer}
+	l := NewLibrary().(*StoreLibrary)
+	l.indexer = indexer
+	return l
+}
+
+// SchemasDir returns the directory the file store keeps schemas in, for testing.
+func SchemasDir() (string, error) {
+	return schemasDir()
+}
+
+// SetInterval sets how often the watcher checks its source files, for testing.
+func (w *SourceWatcher) SetInterval(interval time.Duration) {
+	w.interval = interval
 }
@@ -0,0 +1,108 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fileStore stores a library in a directory: schemas and their metadata in schemas/ (next
+// to their search indexes), and other state documents and lock files at the top level.
+type fileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store in directory dir, which is created when first written to.
+func NewFileStore(dir string) Store {
+	return &fileStore{dir: dir}
+}
+
+func (s *fileStore) schemasDir() string {
+	return filepath.Join(s.dir, "schemas")
+}
+
+func (s *fileStore) schemaFile(ref string) string {
+	return filepath.Join(s.schemasDir(), ref+".graphqls")
+}
+
+// documentFile returns the file of a state document. Metadata is kept with the schemas.
+func (s *fileStore) documentFile(name string) string {
+	if name == metadataDocument {
+		return filepath.Join(s.schemasDir(), name+".json")
+	}
+	return filepath.Join(s.dir, name+".json")
+}
+
+func (s *fileStore) ReadSchema(ref string) ([]byte, error) {
+	return os.ReadFile(s.schemaFile(ref))
+}
+
+func (s *fileStore) WriteSchema(ref string, content []byte) error {
+	if err := os.MkdirAll(s.schemasDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create schemas directory: %w", err)
+	}
+	return os.WriteFile(s.schemaFile(ref), content, 0644)
+}
+
+func (s *fileStore) DeleteSchema(ref string) error {
+	return os.Remove(s.schemaFile(ref))
+}
+
+func (s *fileStore) MoveSchema(oldRef, newRef string) error {
+	return os.Rename(s.schemaFile(oldRef), s.schemaFile(newRef))
+}
+
+func (s *fileStore) SchemaRefs() ([]string, error) {
+	entries, err := os.ReadDir(s.schemasDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schemas directory: %w", err)
+	}
+
+	var refs []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".graphqls") {
+			refs = append(refs, strings.TrimSuffix(entry.Name(), ".graphqls"))
+		}
+	}
+	sort.Strings(refs)
+	return refs, nil
+}
+
+func (s *fileStore) ReadDocument(name string) ([]byte, error) {
+	return os.ReadFile(s.documentFile(name))
+}
+
+func (s *fileStore) WriteDocument(name string, data []byte) error {
+	file := s.documentFile(name)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return writeFileAtomic(file, data)
+}
+
+func (s *fileStore) WithLock(name string, fn func() error) error {
+	return withFileLock(filepath.Join(s.dir, "locks"), name, fn)
+}
+
+func (s *fileStore) IndexDir() string {
+	return s.schemasDir()
+}
+
+// writeFileAtomic writes a file through a temporary file renamed into place, so that
+// readers never see a partially written file.
+func writeFileAtomic(file string, data []byte) error {
+	tempFile := file + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := os.Rename(tempFile, file); err != nil {
+		os.Remove(tempFile) // Clean up temp file on error
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	return nil
+}
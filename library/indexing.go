@@ -0,0 +1,129 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tonysyu/gqlxp/gql"
+)
+
+// indexJob is the background indexing of a schema reference.
+type indexJob struct {
+	ref  string
+	done chan struct{}
+	// err is the reason the job failed, set before done is closed
+	err error
+}
+
+// indexAsync starts a background job indexing the stored content of a schema reference.
+// The content is read when the job runs, so jobs for a schema that changed again, or was
+// removed, in the meantime don't leave an out-of-date index.
+func (l *StoreLibrary) indexAsync(ref string) {
+	if l.indexer == nil {
+		return
+	}
+	job := &indexJob{ref: ref, done: make(chan struct{})}
+	l.jobsMu.Lock()
+	l.jobs = append(l.jobs, job)
+	l.jobsMu.Unlock()
+
+	go func() {
+		defer close(job.done)
+		err := l.indexStored(ref, false)
+		if _, getErr := l.Get(ref); getErr != nil {
+			// Removed while the job was waiting: the removal took care of the index
+			err = nil
+		}
+		job.err = err
+	}()
+}
+
+// WaitForIndexing implements Library.WaitForIndexing.
+func (l *StoreLibrary) WaitForIndexing() error {
+	var errs []error
+	for {
+		l.jobsMu.Lock()
+		jobs := l.jobs
+		l.jobs = nil
+		l.jobsMu.Unlock()
+		if len(jobs) == 0 {
+			return errors.Join(errs...)
+		}
+
+		for _, job := range jobs {
+			<-job.done
+			if job.err != nil {
+				errs = append(errs, fmt.Errorf("failed to index '%s': %w", job.ref, job.err))
+			}
+		}
+	}
+}
+
+// withIndexLock calls fn while holding the lock on the index of a schema reference, within
+// the process and across gqlxp processes.
+func (l *StoreLibrary) withIndexLock(ref string, fn func() error) error {
+	l.indexMu.Lock()
+	defer l.indexMu.Unlock()
+	return l.store.WithLock(indexLock(ref), fn)
+}
+
+// indexStored indexes the content of a schema reference as currently stored. The index is
+// rebuilt if rebuild is set, and otherwise only the documents that changed are updated.
+func (l *StoreLibrary) indexStored(ref string, rebuild bool) error {
+	return l.withIndexLock(ref, func() error {
+		schema, err := l.Get(ref)
+		if err != nil {
+			return err
+		}
+		parsedSchema, err := gql.ParseSchema(schema.Content)
+		if err != nil {
+			return fmt.Errorf("failed to parse schema: %w", err)
+		}
+		if rebuild {
+			return l.indexer.Index(ref, &parsedSchema)
+		}
+		return l.indexer.Update(ref, &parsedSchema)
+	})
+}
+
+// removeIndex deletes the index of a schema reference, if any.
+func (l *StoreLibrary) removeIndex(ref string) error {
+	if l.indexer == nil {
+		return nil
+	}
+	return l.withIndexLock(ref, func() error {
+		return l.indexer.Remove(ref)
+	})
+}
+
+// EnsureIndex implements Library.EnsureIndex.
+func (l *StoreLibrary) EnsureIndex(schemaID string, schema *gql.GraphQLSchema) error {
+	if l.indexer == nil || l.indexCurrent(schemaID) {
+		return nil
+	}
+	return l.withIndexLock(schemaID, func() error {
+		// Another process may have built the index while this one waited for the lock
+		if l.indexCurrent(schemaID) {
+			return nil
+		}
+		return l.indexer.Index(schemaID, schema)
+	})
+}
+
+// indexCurrent reports whether the index of a schema reference exists and was built with
+// the current mapping version, so that indexes built before a gqlxp upgrade are rebuilt.
+func (l *StoreLibrary) indexCurrent(ref string) bool {
+	if !l.indexer.Exists(ref) {
+		return false
+	}
+	outdated, err := l.indexer.Outdated(ref)
+	return err == nil && !outdated
+}
+
+// Reindex implements Library.Reindex.
+func (l *StoreLibrary) Reindex(schemaID string) error {
+	if l.indexer == nil {
+		return nil
+	}
+	return l.indexStored(schemaID, true)
+}
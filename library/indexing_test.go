@@ -0,0 +1,188 @@
+package library_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func TestWaitForIndexing_ReportsFailedJobs(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	indexer := newMockIndexer()
+	indexer.err = errors.New("disk full")
+	lib := library.NewLibraryWithIndexer(indexer)
+	is.NoErr(lib.AddFromContent("api", "API", []byte(`type Query { a: ID }`), ""))
+
+	err := lib.WaitForIndexing()
+	is.True(err != nil)
+	is.Equal(err.Error(), "failed to index 'api': disk full")
+
+	// Failures are reported once
+	is.NoErr(lib.WaitForIndexing())
+}
+
+func TestIndexJob_SkipsRemovedSchema(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	indexer := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(indexer)
+	is.NoErr(lib.AddFromContent("api", "API", []byte(`type Query { a: ID }`), ""))
+	is.NoErr(lib.Remove("api"))
+
+	// The job started by AddFromContent doesn't recreate the index of the removed schema
+	is.NoErr(lib.WaitForIndexing())
+	is.True(!indexer.indexed["api"])
+}
+
+func TestIndexing_UpdatesChangedSchemas(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	indexer := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(indexer)
+	is.NoErr(lib.AddFromContent("api", "API", []byte(`type Query { a: ID }`), ""))
+	is.NoErr(lib.UpdateContent("api", []byte(`type Query { a: ID, b: ID }`)))
+	is.NoErr(lib.WaitForIndexing())
+	is.True(indexer.updated["api"]) // only changed documents are reindexed
+
+	// Reindexing rebuilds the index
+	delete(indexer.updated, "api")
+	is.NoErr(lib.Reindex("api"))
+	is.True(indexer.indexed["api"])
+	is.True(!indexer.updated["api"])
+}
+
+func TestIndexing_RebuildsOutdatedIndexes(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	indexer := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(indexer)
+	is.NoErr(lib.AddFromContent("api", "API", []byte(`type Query { a: ID }`), ""))
+	is.NoErr(lib.WaitForIndexing())
+
+	// A current index is left as it is
+	schema, err := gql.ParseSchema([]byte(`type Query { a: ID }`))
+	is.NoErr(err)
+	indexer.err = errors.New("unexpected rebuild")
+	is.NoErr(lib.EnsureIndex("api", &schema))
+
+	// Indexes built by an earlier version of gqlxp are rebuilt
+	indexer.err = nil
+	indexer.outdated = map[string]bool{"api": true}
+	is.NoErr(lib.EnsureIndex("api", &schema))
+	is.True(!indexer.outdated["api"])
+}
+
+func TestConcurrentMetadataUpdates(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	const n = 20
+	setup := library.NewLibraryWithIndexer(newMockIndexer())
+	for i := range n {
+		is.NoErr(setup.AddFromContent(fmt.Sprintf("s%d", i), "Schema", []byte(`type Query { a: ID }`), ""))
+	}
+
+	// Separate libraries stand in for separate gqlxp processes
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lib := library.NewLibraryWithIndexer(newMockIndexer())
+			id := fmt.Sprintf("s%d", i)
+			schema, err := lib.Get(id)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			schema.Metadata.Description = "updated " + id
+			errs[i] = lib.UpdateMetadata(id, schema.Metadata)
+		}()
+	}
+	wg.Wait()
+
+	for i := range n {
+		is.NoErr(errs[i])
+		schema, err := setup.Get(fmt.Sprintf("s%d", i))
+		is.NoErr(err)
+		is.Equal(schema.Metadata.Description, fmt.Sprintf("updated s%d", i)) // no update was lost
+	}
+}
+
+func TestConcurrentModifyMetadata_SameSchema(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	setup := library.NewLibraryWithIndexer(newMockIndexer())
+	is.NoErr(setup.AddFromContent("api", "API", []byte(`type Query { a: ID }`), ""))
+
+	// Separate libraries stand in for separate gqlxp processes changing the same schema
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lib := library.NewLibraryWithIndexer(newMockIndexer())
+			errs[i] = lib.ModifyMetadata("api", func(metadata *library.SchemaMetadata) error {
+				metadata.Tags = append(metadata.Tags, fmt.Sprintf("t%d", i))
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	for i := range n {
+		is.NoErr(errs[i])
+	}
+	schema, err := setup.Get("api")
+	is.NoErr(err)
+	is.Equal(len(schema.Metadata.Tags), n) // no tag was lost
+}
+
+func TestConcurrentAdds_SameID(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lib := library.NewLibraryWithIndexer(newMockIndexer())
+			errs[i] = lib.AddFromContent("api", fmt.Sprintf("API %d", i), []byte(`type Query { a: ID }`), "")
+		}()
+	}
+	wg.Wait()
+
+	added := 0
+	for _, err := range errs {
+		if err == nil {
+			added++
+		} else {
+			is.True(errors.Is(err, library.ErrSchemaExists))
+		}
+	}
+	is.Equal(added, 1) // only one process adds the schema
+}
@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/tonysyu/gqlxp/gql"
@@ -28,18 +31,36 @@ type Library interface {
 	// sourceInfo is either a file path or URL depending on the source.
 	AddFromContent(id, displayName string, content []byte, sourceInfo string) error
 
-	// Get retrieves a schema by ID.
+	// Get retrieves a schema by ID, from the personal library if it has one and otherwise
+	// from the shared library. An <id>@<env> reference retrieves the snapshot of one of the
+	// schema's environments.
 	Get(id string) (*Schema, error)
 
-	// List returns all schemas in the library.
+	// List returns all schemas in the personal and shared libraries, sorted by ID.
 	List() ([]SchemaInfo, error)
 
-	// Remove removes a schema and its metadata.
+	// Remove removes a schema and its metadata, or an environment given <id>@<env>.
+	// Removing a local override restores the shared schema it hides.
 	Remove(id string) error
 
-	// UpdateMetadata updates the metadata for a schema.
+	// UpdateMetadata updates the metadata for a schema. Updating a shared schema copies it
+	// into the personal library as a local override.
 	UpdateMetadata(id string, metadata SchemaMetadata) error
 
+	// ModifyMetadata updates the metadata for a schema by applying modify to its current
+	// metadata, which is read and written while holding the metadata lock so that changes
+	// made by other gqlxp processes in the meantime are kept. Like UpdateMetadata, it
+	// marks the schema as updated and overrides shared schemas.
+	ModifyMetadata(id string, modify func(metadata *SchemaMetadata) error) error
+
+	// Rename changes the ID of a schema, moving its content, environments, metadata, and
+	// search index, and updating the default schema if it was the renamed one.
+	Rename(oldID, newID string) error
+
+	// Clone copies a schema, including shared ones, with its environments and metadata to a
+	// new ID.
+	Clone(id, newID string) error
+
 	// SetURLPattern sets a URL pattern for a type.
 	SetURLPattern(id string, typePattern string, urlPattern string) error
 
@@ -55,25 +76,79 @@ type Library interface {
 	// SetDefaultSchema sets the default schema ID.
 	SetDefaultSchema(id string) error
 
-	// EnsureIndex creates the search index for a schema if it doesn't already exist.
+	// GetStaleAfterDays returns the age in days after which schemas are considered stale,
+	// or 0 if staleness is disabled.
+	GetStaleAfterDays() (int, error)
+
+	// SetStaleAfterDays sets the staleness threshold in days; 0 disables it.
+	SetStaleAfterDays(days int) error
+
+	// EnsureIndex creates the search index for a schema if it doesn't already exist, or
+	// rebuilds it if it was built by an earlier version of gqlxp.
 	EnsureIndex(schemaID string, schema *gql.GraphQLSchema) error
 
 	// Reindex rebuilds the search index for a schema from its stored content.
 	Reindex(schemaID string) error
+
+	// WaitForIndexing blocks until background indexing started by this library has finished
+	// and returns the errors of any index jobs that failed. Short-lived processes call it
+	// before exiting so that indexes are not left out of date.
+	WaitForIndexing() error
+
+	// Search finds matching types and fields across the given schemas, or the whole library
+	// if schemaIDs is empty, indexing any schema that has no index yet. When several schemas
+	// are searched, those that fail to index are left out and listed in the response's
+	// Skipped; a single schema that fails to index is an error. Results are annotated with
+	// their schema's ID and display name, and come with counts of every matching document.
+	Search(schemaIDs []string, query string, limit int) (*search.SearchResponse, error)
+
+	// SyncShared reindexes the shared schemas that were added or changed since the last
+	// sync and drops the indexes of removed ones.
+	SyncShared() (SharedSyncResult, error)
+
+	// SharedLibraryDir returns the directory of the mounted shared library, or an empty
+	// string if none is mounted.
+	SharedLibraryDir() (string, error)
+
+	// MountSharedLibrary mounts dir as the shared library, or unmounts it if dir is empty.
+	MountSharedLibrary(dir string) error
 }
 
-// FileLibrary implements Library using file-based storage.
-type FileLibrary struct {
-	indexer search.Indexer
+// StoreLibrary implements Library on top of a Store.
+type StoreLibrary struct {
+	store    Store
+	indexer  search.Indexer
+	searcher search.Searcher
+	// jobs are the background index jobs not yet waited for; indexMu serializes writes to
+	// indexes within the process (index locks serialize them across processes).
+	jobsMu  sync.Mutex
+	jobs    []*indexJob
+	indexMu sync.Mutex
 }
 
-// NewLibrary creates a new Library instance.
+// NewLibrary creates a Library in the store selected by OpenStore. If the store cannot be
+// opened, every operation of the library fails with the reason.
 func NewLibrary() Library {
-	dir, err := schemasDir()
+	store, err := OpenStore()
 	if err != nil {
-		return &FileLibrary{}
+		store = errStore{err: err}
+	}
+	return NewLibraryWithStore(store)
+}
+
+// NewLibraryWithStore creates a Library in store. Search indexes are kept in the store's
+// index directory or, if it has none, in memory.
+func NewLibraryWithStore(store Store) Library {
+	l := &StoreLibrary{store: store}
+	if dir := store.IndexDir(); dir != "" {
+		l.indexer = search.NewIndexer(dir)
+		l.searcher = search.NewSearcher(dir)
+	} else {
+		index := search.NewMemoryIndex()
+		l.indexer = index
+		l.searcher = index
 	}
-	return &FileLibrary{indexer: search.NewIndexer(dir)}
+	return l
 }
 
 // ValidateSchemaID checks if a schema ID is valid and returns an error with suggestions if not.
@@ -117,85 +192,61 @@ func CalculateFileHash(content []byte) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// schemaFilePath returns the path to a schema file.
-func schemaFilePath(id string) (string, error) {
-	schemasDir, err := schemasDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(schemasDir, id+".graphqls"), nil
-}
-
-// loadAllMetadata loads all metadata from the metadata.json file.
-func loadAllMetadata() (map[string]SchemaMetadata, error) {
-	metadataFile, err := metadataFile()
-	if err != nil {
-		return nil, err
-	}
-
-	data, err := os.ReadFile(metadataFile)
-	if os.IsNotExist(err) {
+// loadAllMetadata loads the metadata of all schemas from the store.
+func (l *StoreLibrary) loadAllMetadata() (map[string]SchemaMetadata, error) {
+	data, err := l.store.ReadDocument(metadataDocument)
+	if errors.Is(err, fs.ErrNotExist) {
 		return make(map[string]SchemaMetadata), nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read metadata file: %w", err)
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
 	}
 
 	var metadata map[string]SchemaMetadata
 	if err := json.Unmarshal(data, &metadata); err != nil {
-		return nil, fmt.Errorf("failed to parse metadata file: %w", err)
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if metadata == nil {
+		metadata = make(map[string]SchemaMetadata)
 	}
 
 	return metadata, nil
 }
 
-// saveAllMetadata saves all metadata to the metadata.json file atomically.
-func saveAllMetadata(metadata map[string]SchemaMetadata) error {
-	metadataFile, err := metadataFile()
-	if err != nil {
-		return err
-	}
+// updateAllMetadata loads all metadata, applies update, and saves the result, holding the
+// metadata lock so that concurrent gqlxp processes don't lose each other's changes.
+func (l *StoreLibrary) updateAllMetadata(update func(allMetadata map[string]SchemaMetadata) error) error {
+	return l.store.WithLock(metadataLock, func() error {
+		allMetadata, err := l.loadAllMetadata()
+		if err != nil {
+			return err
+		}
+		if err := update(allMetadata); err != nil {
+			return err
+		}
+		return l.saveAllMetadata(allMetadata)
+	})
+}
 
+// saveAllMetadata saves all metadata to the store. Callers must hold the metadata lock; see
+// updateAllMetadata.
+func (l *StoreLibrary) saveAllMetadata(metadata map[string]SchemaMetadata) error {
 	data, err := json.MarshalIndent(metadata, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
-
-	// Atomic write: write to temp file, then rename
-	tempFile := metadataFile + ".tmp"
-	if err := os.WriteFile(tempFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write temp metadata file: %w", err)
-	}
-
-	if err := os.Rename(tempFile, metadataFile); err != nil {
-		os.Remove(tempFile) // Clean up temp file on error
-		return fmt.Errorf("failed to rename temp metadata file: %w", err)
+	if err := l.store.WriteDocument(metadataDocument, data); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
 	}
-
 	return nil
 }
 
 // Add implements Library.Add.
-func (l *FileLibrary) Add(id string, displayName string, sourcePath string) error {
+func (l *StoreLibrary) Add(id string, displayName string, sourcePath string) error {
 	if err := ValidateSchemaID(id); err != nil {
 		return err
 	}
 
-	// Ensure config directory exists
-	if err := InitConfigDir(); err != nil {
-		return fmt.Errorf("failed to initialize config directory: %w", err)
-	}
-
-	// Check if schema already exists
-	schemaFile, err := schemaFilePath(id)
-	if err != nil {
-		return err
-	}
-
-	if _, err := os.Stat(schemaFile); err == nil {
-		return fmt.Errorf("%w: '%s'", ErrSchemaExists, id)
-	}
-
 	// Read source schema file
 	content, err := os.ReadFile(sourcePath)
 	if err != nil {
@@ -211,76 +262,34 @@ func (l *FileLibrary) Add(id string, displayName string, sourcePath string) erro
 		return fmt.Errorf("failed to convert source path to absolute: %w", err)
 	}
 
-	// Write schema file
-	if err := os.WriteFile(schemaFile, content, 0644); err != nil {
-		return fmt.Errorf("failed to write schema file: %w", err)
-	}
-
-	// Load existing metadata
-	allMetadata, err := loadAllMetadata()
-	if err != nil {
-		return err
-	}
-
-	// Add new metadata
 	now := time.Now()
-	allMetadata[id] = SchemaMetadata{
+	err = l.addSchema(id, content, SchemaMetadata{
 		DisplayName: displayName,
 		SourceFile:  absPath,
 		FileHash:    fileHash,
 		URLPatterns: make(map[string]string),
 		CreatedAt:   now,
 		UpdatedAt:   now,
-	}
-
-	// Save metadata
-	if err := saveAllMetadata(allMetadata); err != nil {
-		// Try to clean up schema file on metadata save error
-		os.Remove(schemaFile)
+	})
+	if err != nil {
 		return err
 	}
 
 	// Index the schema in the background (non-blocking)
-	l.indexAsync(id, content)
+	l.indexAsync(id)
 
 	return nil
 }
 
 // AddFromContent implements Library.AddFromContent.
-func (l *FileLibrary) AddFromContent(id, displayName string, content []byte, sourceInfo string) error {
+func (l *StoreLibrary) AddFromContent(id, displayName string, content []byte, sourceInfo string) error {
 	if err := ValidateSchemaID(id); err != nil {
 		return err
 	}
 
-	// Ensure config directory exists
-	if err := InitConfigDir(); err != nil {
-		return fmt.Errorf("failed to initialize config directory: %w", err)
-	}
-
-	// Check if schema already exists
-	schemaFile, err := schemaFilePath(id)
-	if err != nil {
-		return err
-	}
-
-	if _, err := os.Stat(schemaFile); err == nil {
-		return fmt.Errorf("%w: '%s'", ErrSchemaExists, id)
-	}
-
 	// Calculate content hash
 	fileHash := CalculateFileHash(content)
 
-	// Write schema file
-	if err := os.WriteFile(schemaFile, content, 0644); err != nil {
-		return fmt.Errorf("failed to write schema file: %w", err)
-	}
-
-	// Load existing metadata
-	allMetadata, err := loadAllMetadata()
-	if err != nil {
-		return err
-	}
-
 	// Determine if sourceInfo is a URL or file path
 	now := time.Now()
 	metadata := SchemaMetadata{
@@ -293,7 +302,7 @@ func (l *FileLibrary) AddFromContent(id, displayName string, content []byte, sou
 
 	if isURL(sourceInfo) {
 		metadata.SourceURL = sourceInfo
-	} else {
+	} else if sourceInfo != "" {
 		// Convert to absolute path
 		absPath, err := filepath.Abs(sourceInfo)
 		if err != nil {
@@ -302,54 +311,88 @@ func (l *FileLibrary) AddFromContent(id, displayName string, content []byte, sou
 		metadata.SourceFile = absPath
 	}
 
-	allMetadata[id] = metadata
-
-	// Save metadata
-	if err := saveAllMetadata(allMetadata); err != nil {
-		// Try to clean up schema file on metadata save error
-		os.Remove(schemaFile)
+	if err := l.addSchema(id, content, metadata); err != nil {
 		return err
 	}
 
 	// Index the schema in the background (non-blocking)
-	l.indexAsync(id, content)
+	l.indexAsync(id)
 
 	return nil
 }
 
+// addSchema writes the content and metadata of a new schema. The check that id is free is
+// made while holding the metadata lock, so that concurrent adds of the same ID can't both
+// succeed.
+func (l *StoreLibrary) addSchema(id string, content []byte, metadata SchemaMetadata) error {
+	written := false
+	err := l.updateAllMetadata(func(allMetadata map[string]SchemaMetadata) error {
+		if l.hasSchema(id) {
+			return fmt.Errorf("%w: '%s'", ErrSchemaExists, id)
+		}
+		if err := l.store.WriteSchema(id, content); err != nil {
+			return fmt.Errorf("failed to write schema file: %w", err)
+		}
+		written = true
+		allMetadata[id] = metadata
+		return nil
+	})
+	if err != nil && written {
+		// Try to clean up schema file on metadata save error
+		_ = l.store.DeleteSchema(id)
+	}
+	return err
+}
+
+// hasSchema reports whether the personal library stores schema reference ref.
+func (l *StoreLibrary) hasSchema(ref string) bool {
+	_, err := l.store.ReadSchema(ref)
+	return err == nil
+}
+
 // isURL checks if a string is a URL (http:// or https://).
 func isURL(s string) bool {
 	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
 }
 
 // Get implements Library.Get.
-func (l *FileLibrary) Get(id string) (*Schema, error) {
-	schemaFile, err := schemaFilePath(id)
-	if err != nil {
-		return nil, err
+func (l *StoreLibrary) Get(id string) (*Schema, error) {
+	if baseID, env := ParseSchemaRef(id); env != "" {
+		return l.getEnvironment(baseID, env)
 	}
 
-	content, err := os.ReadFile(schemaFile)
-	if os.IsNotExist(err) {
-		return nil, fmt.Errorf("schema '%s' not found", id)
+	content, err := l.store.ReadSchema(id)
+	if errors.Is(err, fs.ErrNotExist) {
+		shared, err := l.getShared(id)
+		if err != nil {
+			return nil, err
+		}
+		if shared == nil {
+			return nil, fmt.Errorf("schema '%s' not found", id)
+		}
+		return shared, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read schema file: %w", err)
 	}
 
 	// Load metadata
-	allMetadata, err := loadAllMetadata()
+	allMetadata, err := l.loadAllMetadata()
 	if err != nil {
 		return nil, err
 	}
 
 	metadata := getSchemaMetadata(allMetadata, id)
 
-	return &Schema{
+	schema := &Schema{
 		ID:       id,
 		Content:  content,
 		Metadata: metadata,
-	}, nil
+	}
+	if shared, err := l.openSharedLibrary(); err == nil && shared != nil && shared.has(id) {
+		schema.Provenance = ProvenanceOverride
+	}
+	return schema, nil
 }
 
 // getSchemaMetadata returns metadata for a schema ID or default metadata if not found
@@ -368,134 +411,185 @@ func getSchemaMetadata(allMetadata map[string]SchemaMetadata, id string) SchemaM
 }
 
 // List implements Library.List.
-func (l *FileLibrary) List() ([]SchemaInfo, error) {
-	schemasDir, err := schemasDir()
+func (l *StoreLibrary) List() ([]SchemaInfo, error) {
+	localIDs, err := l.localSchemaIDs()
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if schemas directory exists
-	if _, err := os.Stat(schemasDir); os.IsNotExist(err) {
-		return []SchemaInfo{}, nil
-	}
-
-	// Read all .graphqls files
-	entries, err := os.ReadDir(schemasDir)
+	// Load metadata
+	allMetadata, err := l.loadAllMetadata()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read schemas directory: %w", err)
+		return nil, err
 	}
 
-	// Load metadata
-	allMetadata, err := loadAllMetadata()
+	shared, err := l.openSharedLibrary()
 	if err != nil {
 		return nil, err
 	}
+	var sharedIDs []string
+	if shared != nil {
+		if sharedIDs, err = shared.ids(); err != nil {
+			return nil, err
+		}
+	}
+	isShared := make(map[string]bool, len(sharedIDs))
+	for _, id := range sharedIDs {
+		isShared[id] = true
+	}
 
-	var schemas []SchemaInfo
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".graphqls") {
+	schemas := []SchemaInfo{}
+	isLocal := make(map[string]bool, len(localIDs))
+	for _, id := range localIDs {
+		isLocal[id] = true
+		info := createSchemaInfo(id, allMetadata)
+		if isShared[id] {
+			info.Provenance = ProvenanceOverride
+		}
+		schemas = append(schemas, info)
+	}
+	for _, id := range sharedIDs {
+		if isLocal[id] {
 			continue
 		}
-
-		id := strings.TrimSuffix(entry.Name(), ".graphqls")
-		schemas = append(schemas, createSchemaInfo(id, allMetadata))
+		schema, err := shared.get(id)
+		if err != nil {
+			return nil, err
+		}
+		info := createSchemaInfo(id, map[string]SchemaMetadata{id: schema.Metadata})
+		info.Provenance = ProvenanceShared
+		schemas = append(schemas, info)
 	}
 
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].ID < schemas[j].ID })
 	return schemas, nil
 }
 
+// localSchemaIDs returns the IDs of the schemas in the personal library.
+func (l *StoreLibrary) localSchemaIDs() ([]string, error) {
+	refs, err := l.store.SchemaRefs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schemas: %w", err)
+	}
+
+	var ids []string
+	for _, ref := range refs {
+		if strings.Contains(ref, EnvSeparator) {
+			// Environment snapshots are listed with their base schema
+			continue
+		}
+		ids = append(ids, ref)
+	}
+	return ids, nil
+}
+
 // createSchemaInfo creates a SchemaInfo from an ID and metadata map
 func createSchemaInfo(id string, allMetadata map[string]SchemaMetadata) SchemaInfo {
 	info := SchemaInfo{ID: id}
 	if metadata, exists := allMetadata[id]; exists {
 		info.DisplayName = metadata.DisplayName
 		info.UpdatedAt = metadata.UpdatedAt
+		info.Environments = environmentInfos(metadata)
+		info.Tags = metadata.Tags
+		info.Description = metadata.Description
 	}
 	return info
 }
 
 // Remove implements Library.Remove.
-func (l *FileLibrary) Remove(id string) error {
-	schemaFile, err := schemaFilePath(id)
-	if err != nil {
-		return err
-	}
-
-	// Check if schema exists
-	if _, err := os.Stat(schemaFile); os.IsNotExist(err) {
-		return fmt.Errorf("schema '%s' not found", id)
+func (l *StoreLibrary) Remove(id string) error {
+	if baseID, env := ParseSchemaRef(id); env != "" {
+		return l.removeEnvironment(baseID, env)
 	}
 
 	// Remove schema file
-	if err := os.Remove(schemaFile); err != nil {
+	if err := l.store.DeleteSchema(id); errors.Is(err, fs.ErrNotExist) {
+		if shared, _ := l.getShared(id); shared != nil {
+			return fmt.Errorf("%w: cannot remove '%s'", ErrSharedSchema, id)
+		}
+		return fmt.Errorf("schema '%s' not found", id)
+	} else if err != nil {
 		return fmt.Errorf("failed to remove schema file: %w", err)
 	}
 
-	// Load and update metadata
-	allMetadata, err := loadAllMetadata()
+	// Update metadata
+	var envNames []string
+	err := l.updateAllMetadata(func(allMetadata map[string]SchemaMetadata) error {
+		envNames = allMetadata[id].EnvironmentNames()
+		delete(allMetadata, id)
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	delete(allMetadata, id)
-
-	if err := saveAllMetadata(allMetadata); err != nil {
-		return err
+	// Remove environment snapshots along with the schema
+	for _, env := range envNames {
+		l.removeSnapshot(SchemaRef(id, env))
 	}
 
 	// Remove the search index
-	if l.indexer != nil {
-		_ = l.indexer.Remove(id)
+	_ = l.removeIndex(id)
+
+	// Index the shared schema that the removed override was hiding, if any
+	if shared, _ := l.getShared(id); shared != nil {
+		l.indexAsync(id)
 	}
 
 	return nil
 }
 
 // UpdateMetadata implements Library.UpdateMetadata.
-func (l *FileLibrary) UpdateMetadata(id string, metadata SchemaMetadata) error {
-	// Verify schema exists
-	schemaFile, err := schemaFilePath(id)
-	if err != nil {
-		return err
-	}
+func (l *StoreLibrary) UpdateMetadata(id string, metadata SchemaMetadata) error {
+	return l.ModifyMetadata(id, func(current *SchemaMetadata) error {
+		*current = metadata
+		return nil
+	})
+}
 
-	if _, err := os.Stat(schemaFile); os.IsNotExist(err) {
-		return fmt.Errorf("schema '%s' not found", id)
+// ModifyMetadata implements Library.ModifyMetadata.
+func (l *StoreLibrary) ModifyMetadata(id string, modify func(metadata *SchemaMetadata) error) error {
+	if baseID, env := ParseSchemaRef(id); env != "" {
+		return l.modifyEnvironmentMetadata(baseID, env, modify)
 	}
 
-	// Load all metadata
-	allMetadata, err := loadAllMetadata()
-	if err != nil {
-		return err
+	// Verify schema exists
+	if !l.hasSchema(id) {
+		if shared, _ := l.getShared(id); shared == nil {
+			return fmt.Errorf("schema '%s' not found", id)
+		}
+		// Shared schemas are read-only, so changes go to a local override
+		if err := l.overrideShared(id); err != nil {
+			return err
+		}
 	}
 
-	// Update timestamp
-	metadata.UpdatedAt = time.Now()
-
-	allMetadata[id] = metadata
-
-	return saveAllMetadata(allMetadata)
+	return l.updateAllMetadata(func(allMetadata map[string]SchemaMetadata) error {
+		metadata := getSchemaMetadata(allMetadata, id)
+		if err := modify(&metadata); err != nil {
+			return err
+		}
+		metadata.UpdatedAt = time.Now()
+		allMetadata[id] = metadata
+		return nil
+	})
 }
 
 // SetURLPattern implements Library.SetURLPattern.
-func (l *FileLibrary) SetURLPattern(id string, typePattern string, urlPattern string) error {
-	schema, err := l.Get(id)
-	if err != nil {
-		return err
-	}
-
-	if schema.Metadata.URLPatterns == nil {
-		schema.Metadata.URLPatterns = make(map[string]string)
-	}
-
-	schema.Metadata.URLPatterns[typePattern] = urlPattern
-	return l.UpdateMetadata(id, schema.Metadata)
+func (l *StoreLibrary) SetURLPattern(id string, typePattern string, urlPattern string) error {
+	return l.ModifyMetadata(id, func(metadata *SchemaMetadata) error {
+		if metadata.URLPatterns == nil {
+			metadata.URLPatterns = make(map[string]string)
+		}
+		metadata.URLPatterns[typePattern] = urlPattern
+		return nil
+	})
 }
 
 // FindByPath implements Library.FindByPath.
-func (l *FileLibrary) FindByPath(absolutePath string) (*Schema, error) {
+func (l *StoreLibrary) FindByPath(absolutePath string) (*Schema, error) {
 	// Load all metadata
-	allMetadata, err := loadAllMetadata()
+	allMetadata, err := l.loadAllMetadata()
 	if err != nil {
 		return nil, err
 	}
@@ -511,49 +605,48 @@ func (l *FileLibrary) FindByPath(absolutePath string) (*Schema, error) {
 }
 
 // UpdateContent implements Library.UpdateContent.
-func (l *FileLibrary) UpdateContent(id string, content []byte) error {
+func (l *StoreLibrary) UpdateContent(id string, content []byte) error {
+	if baseID, env := ParseSchemaRef(id); env != "" {
+		return l.updateEnvironmentContent(baseID, env, content)
+	}
+
 	// Get existing schema to ensure it exists
-	schema, err := l.Get(id)
-	if err != nil {
+	if _, err := l.Get(id); err != nil {
 		return err
 	}
 
-	// Calculate new hash
+	// Update metadata with new hash and timestamp, overriding a shared schema before its
+	// content is replaced
 	newHash := CalculateFileHash(content)
-
-	// Update schema file
-	schemaFile, err := schemaFilePath(id)
+	err := l.ModifyMetadata(id, func(metadata *SchemaMetadata) error {
+		metadata.FileHash = newHash
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	if err := os.WriteFile(schemaFile, content, 0644); err != nil {
+	// Update schema file
+	if err := l.store.WriteSchema(id, content); err != nil {
 		return fmt.Errorf("failed to write schema file: %w", err)
 	}
 
-	// Update metadata with new hash and timestamp
-	schema.Metadata.FileHash = newHash
-	schema.Metadata.UpdatedAt = time.Now()
-
-	if err := l.UpdateMetadata(id, schema.Metadata); err != nil {
-		return err
-	}
-
 	// Re-index the schema in the background (non-blocking)
-	l.indexAsync(id, content)
+	l.indexAsync(id)
 
 	return nil
 }
 
-// loadUserConfig loads the user configuration from config.json.
-func loadUserConfig() (*UserConfig, error) {
-	configFile, err := userConfigFile()
-	if err != nil {
-		return nil, err
-	}
+// loadUserConfig loads the user configuration from the store.
+func (l *StoreLibrary) loadUserConfig() (*UserConfig, error) {
+	return readUserConfig(l.store)
+}
 
-	data, err := os.ReadFile(configFile)
-	if os.IsNotExist(err) {
+// readUserConfig reads the user configuration from store, returning an empty configuration
+// if there is none.
+func readUserConfig(store Store) (*UserConfig, error) {
+	data, err := store.ReadDocument(configDocument)
+	if errors.Is(err, fs.ErrNotExist) {
 		// Return empty config if file doesn't exist
 		return &UserConfig{}, nil
 	}
@@ -569,40 +662,35 @@ func loadUserConfig() (*UserConfig, error) {
 	return &config, nil
 }
 
-// saveUserConfig saves the user configuration to config.json.
-func saveUserConfig(config *UserConfig) error {
-	// Ensure config directory exists
-	if err := InitConfigDir(); err != nil {
-		return fmt.Errorf("failed to initialize config directory: %w", err)
-	}
-
-	configFile, err := userConfigFile()
-	if err != nil {
-		return err
-	}
+// updateUserConfig loads the user configuration, applies update, and saves the result,
+// holding the config lock so that concurrent gqlxp processes don't lose each other's changes.
+func (l *StoreLibrary) updateUserConfig(update func(config *UserConfig)) error {
+	return l.store.WithLock(configLock, func() error {
+		config, err := l.loadUserConfig()
+		if err != nil {
+			return err
+		}
+		update(config)
+		return l.saveUserConfig(config)
+	})
+}
 
+// saveUserConfig saves the user configuration to the store. Callers must hold the config
+// lock; see updateUserConfig.
+func (l *StoreLibrary) saveUserConfig(config *UserConfig) error {
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
-
-	// Atomic write: write to temp file, then rename
-	tempFile := configFile + ".tmp"
-	if err := os.WriteFile(tempFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write temp config file: %w", err)
-	}
-
-	if err := os.Rename(tempFile, configFile); err != nil {
-		os.Remove(tempFile) // Clean up temp file on error
-		return fmt.Errorf("failed to rename temp config file: %w", err)
+	if err := l.store.WriteDocument(configDocument, data); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
 	}
-
 	return nil
 }
 
 // GetDefaultSchema implements Library.GetDefaultSchema.
-func (l *FileLibrary) GetDefaultSchema() (string, error) {
-	config, err := loadUserConfig()
+func (l *StoreLibrary) GetDefaultSchema() (string, error) {
+	config, err := l.loadUserConfig()
 	if err != nil {
 		return "", err
 	}
@@ -610,7 +698,7 @@ func (l *FileLibrary) GetDefaultSchema() (string, error) {
 }
 
 // SetDefaultSchema implements Library.SetDefaultSchema.
-func (l *FileLibrary) SetDefaultSchema(id string) error {
+func (l *StoreLibrary) SetDefaultSchema(id string) error {
 	// Validate that the schema exists if not empty
 	if id != "" {
 		if _, err := l.Get(id); err != nil {
@@ -618,49 +706,27 @@ func (l *FileLibrary) SetDefaultSchema(id string) error {
 		}
 	}
 
-	config, err := loadUserConfig()
-	if err != nil {
-		return err
-	}
-
-	config.DefaultSchema = id
-	return saveUserConfig(config)
+	return l.updateUserConfig(func(config *UserConfig) {
+		config.DefaultSchema = id
+	})
 }
 
-// indexAsync indexes a schema in a background goroutine.
-func (l *FileLibrary) indexAsync(id string, content []byte) {
-	if l.indexer == nil {
-		return
+// GetStaleAfterDays implements Library.GetStaleAfterDays.
+func (l *StoreLibrary) GetStaleAfterDays() (int, error) {
+	config, err := l.loadUserConfig()
+	if err != nil {
+		return 0, err
 	}
-	go func() {
-		schema, err := gql.ParseSchema(content)
-		if err != nil {
-			return
-		}
-		_ = l.indexer.Index(id, &schema)
-	}()
+	return config.StaleAfterDays, nil
 }
 
-// EnsureIndex implements Library.EnsureIndex.
-func (l *FileLibrary) EnsureIndex(schemaID string, schema *gql.GraphQLSchema) error {
-	if l.indexer == nil || l.indexer.Exists(schemaID) {
-		return nil
+// SetStaleAfterDays implements Library.SetStaleAfterDays.
+func (l *StoreLibrary) SetStaleAfterDays(days int) error {
+	if days < 0 {
+		return fmt.Errorf("invalid staleness threshold %d: must be zero or more days", days)
 	}
-	return l.indexer.Index(schemaID, schema)
-}
 
-// Reindex implements Library.Reindex.
-func (l *FileLibrary) Reindex(schemaID string) error {
-	if l.indexer == nil {
-		return nil
-	}
-	schema, err := l.Get(schemaID)
-	if err != nil {
-		return err
-	}
-	parsedSchema, err := gql.ParseSchema(schema.Content)
-	if err != nil {
-		return fmt.Errorf("failed to parse schema: %w", err)
-	}
-	return l.indexer.Index(schemaID, &parsedSchema)
+	return l.updateUserConfig(func(config *UserConfig) {
+		config.StaleAfterDays = days
+	})
 }
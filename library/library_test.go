@@ -3,6 +3,7 @@ package library_test
 import (
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,35 +13,74 @@ import (
 	"github.com/tonysyu/gqlxp/search"
 )
 
-// mockIndexer records indexing calls for assertion in tests.
+// mockIndexer records indexing calls for assertion in tests. Background index jobs call it
+// concurrently, so tests call lib.WaitForIndexing before reading its fields.
 type mockIndexer struct {
+	mu      sync.Mutex
 	indexed map[string]bool
+	// updated marks indexes updated incrementally rather than rebuilt
+	updated map[string]bool
 	removed map[string]bool
+	// outdated marks indexes built with an earlier mapping version
+	outdated map[string]bool
+	// err is returned by Index when set
+	err error
 }
 
 func newMockIndexer() *mockIndexer {
 	return &mockIndexer{
 		indexed: make(map[string]bool),
+		updated: make(map[string]bool),
 		removed: make(map[string]bool),
 	}
 }
 
 func (m *mockIndexer) Index(schemaID string, _ *gql.GraphQLSchema) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.index(schemaID)
+}
+
+func (m *mockIndexer) index(schemaID string) error {
+	if m.err != nil {
+		return m.err
+	}
 	m.indexed[schemaID] = true
+	delete(m.outdated, schemaID)
 	delete(m.removed, schemaID)
 	return nil
 }
 
+func (m *mockIndexer) Update(schemaID string, _ *gql.GraphQLSchema) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.index(schemaID); err != nil {
+		return err
+	}
+	m.updated[schemaID] = true
+	return nil
+}
+
 func (m *mockIndexer) Remove(schemaID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.removed[schemaID] = true
 	delete(m.indexed, schemaID)
 	return nil
 }
 
 func (m *mockIndexer) Exists(schemaID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return m.indexed[schemaID]
 }
 
+func (m *mockIndexer) Outdated(schemaID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.outdated[schemaID], nil
+}
+
 func (m *mockIndexer) Close() error { return nil }
 
 var _ search.Indexer = (*mockIndexer)(nil)
@@ -52,6 +92,9 @@ func setupTestLibrary(t *testing.T) (string, func()) {
 	// Create temporary directory
 	tmpDir := t.TempDir()
 
+	// Use the file store in the config directory, whatever the developer's settings
+	t.Setenv(library.HomeEnvVar, "")
+
 	// Set environment variable to override config directory
 	oldHome := os.Getenv("HOME")
 	os.Setenv("HOME", tmpDir)
@@ -66,6 +109,7 @@ func setupTestLibrary(t *testing.T) (string, func()) {
 		for _, schema := range schemas {
 			_ = lib.Remove(schema.ID)
 		}
+		lib.WaitForIndexing()
 
 		os.Setenv("HOME", oldHome)
 	}
@@ -472,8 +516,7 @@ func TestLibrary_IndexerCalled_OnAdd(t *testing.T) {
 	err := lib.AddFromContent("test-schema", "Test", []byte(schemaContent), "test.graphqls")
 	is.NoErr(err)
 
-	// Give the background goroutine a moment to complete
-	time.Sleep(50 * time.Millisecond)
+	is.NoErr(lib.WaitForIndexing())
 	is.True(mock.indexed["test-schema"])
 }
 
@@ -489,6 +532,8 @@ func TestLibrary_IndexerCalled_OnRemove(t *testing.T) {
 	err := lib.AddFromContent("test-schema", "Test", []byte(schemaContent), "test.graphqls")
 	is.NoErr(err)
 
+	is.NoErr(lib.WaitForIndexing())
+
 	err = lib.Remove("test-schema")
 	is.NoErr(err)
 
@@ -512,8 +557,7 @@ func TestLibrary_IndexerCalled_OnUpdateContent(t *testing.T) {
 	err = lib.UpdateContent("test-schema", newContent)
 	is.NoErr(err)
 
-	// Give the background goroutine a moment to complete
-	time.Sleep(50 * time.Millisecond)
+	is.NoErr(lib.WaitForIndexing())
 	is.True(mock.indexed["test-schema"])
 }
 
@@ -552,6 +596,8 @@ func TestLibrary_Reindex(t *testing.T) {
 	err := lib.AddFromContent("test-schema", "Test", []byte(schemaContent), "test.graphqls")
 	is.NoErr(err)
 
+	is.NoErr(lib.WaitForIndexing())
+
 	err = lib.Reindex("test-schema")
 	is.NoErr(err)
 	is.True(mock.indexed["test-schema"])
@@ -0,0 +1,56 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Names of the locks guarding library state.
+const (
+	metadataLock = "metadata"
+	configLock   = "config"
+)
+
+// fileLock is an exclusive lock on a lock file. It is held across gqlxp processes (and by
+// one goroutine at a time within a process) until it is unlocked.
+type fileLock struct {
+	file *os.File
+}
+
+// acquireLock blocks until it holds the lock with the given name in a locks directory.
+func acquireLock(dir, name string) (*fileLock, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create locks directory: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Join(dir, name+".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", file.Name(), err)
+	}
+	return &fileLock{file: file}, nil
+}
+
+// unlock releases the lock.
+func (l *fileLock) unlock() {
+	_ = unlockFile(l.file)
+	l.file.Close()
+}
+
+// withFileLock calls fn while holding the lock with the given name in a locks directory.
+func withFileLock(dir, name string, fn func() error) error {
+	lock, err := acquireLock(dir, name)
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
+	return fn()
+}
+
+// indexLock returns the name of the lock guarding the search index of a schema reference.
+func indexLock(ref string) string {
+	return "index-" + ref
+}
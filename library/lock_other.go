@@ -0,0 +1,16 @@
+//go:build !unix
+
+package library
+
+import "os"
+
+// File locks are only supported on Unix, so other platforms rely on the in-process locking
+// of StoreLibrary alone.
+
+func lockFile(*os.File) error {
+	return nil
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
@@ -0,0 +1,107 @@
+package library
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// memoryStore keeps a library in memory, for tests and for programs embedding gqlxp. Its
+// content is lost when the process exits.
+type memoryStore struct {
+	mu        sync.Mutex
+	schemas   map[string][]byte
+	documents map[string][]byte
+	locks     map[string]*sync.Mutex
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		schemas:   make(map[string][]byte),
+		documents: make(map[string][]byte),
+		locks:     make(map[string]*sync.Mutex),
+	}
+}
+
+func (s *memoryStore) ReadSchema(ref string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.schemas[ref]
+	if !ok {
+		return nil, fmt.Errorf("schema '%s': %w", ref, fs.ErrNotExist)
+	}
+	return slices.Clone(content), nil
+}
+
+func (s *memoryStore) WriteSchema(ref string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemas[ref] = slices.Clone(content)
+	return nil
+}
+
+func (s *memoryStore) DeleteSchema(ref string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.schemas[ref]; !ok {
+		return fmt.Errorf("schema '%s': %w", ref, fs.ErrNotExist)
+	}
+	delete(s.schemas, ref)
+	return nil
+}
+
+func (s *memoryStore) MoveSchema(oldRef, newRef string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.schemas[oldRef]
+	if !ok {
+		return fmt.Errorf("schema '%s': %w", oldRef, fs.ErrNotExist)
+	}
+	s.schemas[newRef] = content
+	delete(s.schemas, oldRef)
+	return nil
+}
+
+func (s *memoryStore) SchemaRefs() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(maps.Keys(s.schemas)), nil
+}
+
+func (s *memoryStore) ReadDocument(name string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.documents[name]
+	if !ok {
+		return nil, fmt.Errorf("document '%s': %w", name, fs.ErrNotExist)
+	}
+	return slices.Clone(data), nil
+}
+
+func (s *memoryStore) WriteDocument(name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents[name] = slices.Clone(data)
+	return nil
+}
+
+func (s *memoryStore) WithLock(name string, fn func() error) error {
+	s.mu.Lock()
+	lock, ok := s.locks[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[name] = lock
+	}
+	s.mu.Unlock()
+
+	lock.Lock()
+	defer lock.Unlock()
+	return fn()
+}
+
+func (s *memoryStore) IndexDir() string {
+	return ""
+}
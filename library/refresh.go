@@ -0,0 +1,157 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+)
+
+// DefaultRefreshWorkers is the number of schemas refreshed at once by default.
+const DefaultRefreshWorkers = 4
+
+// ErrNoSource is returned when a schema has neither a source URL nor a source file.
+var ErrNoSource = errors.New("schema has no source URL or file")
+
+// RefreshStatus is the outcome of refreshing a schema from its source.
+type RefreshStatus string
+
+// Refresh statuses, from best to worst.
+const (
+	RefreshUnchanged RefreshStatus = "unchanged"
+	RefreshUpdated   RefreshStatus = "updated"
+	RefreshSkipped   RefreshStatus = "skipped"
+	RefreshFailed    RefreshStatus = "failed"
+)
+
+// RefreshResult is the outcome of refreshing one schema. Err is the reason a schema was
+// skipped or failed.
+type RefreshResult struct {
+	ID     string
+	Status RefreshStatus
+	Err    error
+}
+
+// RefreshOptions configures RefreshSchemas.
+type RefreshOptions struct {
+	// Workers bounds the number of schemas refreshed at once; zero uses DefaultRefreshWorkers.
+	Workers int
+	// Timeout bounds the refresh of each schema; zero means no timeout.
+	Timeout time.Duration
+}
+
+// FetchSource reads the current content of a schema's source, introspecting its URL with
+// its connection profile or reading its source file, and returns it as SDL.
+func FetchSource(ctx context.Context, metadata SchemaMetadata) ([]byte, error) {
+	switch {
+	case metadata.SourceURL != "":
+		opts, err := metadata.ConnectionProfile().ClientOptions(nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := introspection.FetchSchema(ctx, metadata.SourceURL, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch schema: %w", err)
+		}
+		return introspection.ToSDL(resp)
+	case metadata.SourceFile != "":
+		return ReadSourceFile(metadata.SourceFile)
+	default:
+		return nil, ErrNoSource
+	}
+}
+
+// RefreshSchemas refreshes schemas from their sources using a bounded pool of workers.
+// Sources are fetched concurrently while library writes happen one at a time, and only
+// schemas whose content changed are rewritten and reindexed. Results are in the order of
+// ids. Callers should call lib.WaitForIndexing before exiting, which reports schemas that
+// were updated but failed to index.
+func RefreshSchemas(ctx context.Context, lib Library, ids []string, opts RefreshOptions) []RefreshResult {
+	results := make([]RefreshResult, len(ids))
+	var writeMu sync.Mutex
+	forEachConcurrently(len(ids), opts.Workers, func(i int) {
+		results[i] = refreshSchema(ctx, lib, ids[i], opts.Timeout, &writeMu)
+	})
+	return results
+}
+
+// forEachConcurrently calls fn for each index in [0, n) using at most workers goroutines,
+// or DefaultRefreshWorkers if workers is zero, and waits for all calls to return.
+func forEachConcurrently(n, workers int, fn func(i int)) {
+	if workers <= 0 {
+		workers = DefaultRefreshWorkers
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// refreshSchema refreshes one schema, holding writeMu while writing to the library.
+func refreshSchema(ctx context.Context, lib Library, id string, timeout time.Duration, writeMu *sync.Mutex) RefreshResult {
+	failed := func(err error) RefreshResult {
+		return RefreshResult{ID: id, Status: RefreshFailed, Err: err}
+	}
+
+	schema, err := lib.Get(id)
+	if err != nil {
+		return failed(err)
+	}
+	if schema.Provenance == ProvenanceShared {
+		return RefreshResult{ID: id, Status: RefreshSkipped, Err: errors.New("shared schemas are refreshed with 'library sync'")}
+	}
+	if schema.Metadata.SourceURL == "" && schema.Metadata.SourceFile == "" {
+		return RefreshResult{ID: id, Status: RefreshSkipped, Err: ErrNoSource}
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	content, err := FetchSource(ctx, schema.Metadata)
+	if err != nil {
+		return failed(err)
+	}
+	if _, err := gql.ParseSchema(content); err != nil {
+		return failed(fmt.Errorf("invalid GraphQL schema: %w", err))
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	if CalculateFileHash(content) == schema.Metadata.FileHash {
+		// Content unchanged - just record that the schema was checked
+		if err := lib.ModifyMetadata(id, func(*SchemaMetadata) error { return nil }); err != nil {
+			return failed(err)
+		}
+		return RefreshResult{ID: id, Status: RefreshUnchanged}
+	}
+	if err := lib.UpdateContent(id, content); err != nil {
+		return failed(err)
+	}
+	return RefreshResult{ID: id, Status: RefreshUpdated}
+}
+
+// IsStale reports whether a schema last updated at updatedAt is more than staleAfterDays
+// days old at now. Staleness is disabled when staleAfterDays is zero.
+func IsStale(updatedAt time.Time, staleAfterDays int, now time.Time) bool {
+	staleAfter := time.Duration(staleAfterDays) * 24 * time.Hour
+	return staleAfterDays > 0 && !updatedAt.IsZero() && now.Sub(updatedAt) > staleAfter
+}
@@ -0,0 +1,70 @@
+package library_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func TestRefreshSchemas(t *testing.T) {
+	is := is.New(t)
+	tmpDir, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	writeSchema := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		is.NoErr(os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	billingFile := writeSchema("billing.graphqls", `type Query { invoice: ID }`)
+	usersFile := writeSchema("users.graphqls", `type Query { user: ID }`)
+	ordersFile := writeSchema("orders.graphqls", `type Query { order: ID }`)
+
+	indexer := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(indexer)
+	is.NoErr(lib.Add("billing", "Billing", billingFile))
+	is.NoErr(lib.Add("users", "Users", usersFile))
+	is.NoErr(lib.Add("orders", "Orders", ordersFile))
+	is.NoErr(lib.AddFromContent("inline", "Inline", []byte(`type Query { a: ID }`), ""))
+	inline, err := lib.Get("inline")
+	is.NoErr(err)
+	inline.Metadata.SourceFile = ""
+	is.NoErr(lib.UpdateMetadata("inline", inline.Metadata))
+	lib.WaitForIndexing()
+	indexer.indexed = make(map[string]bool)
+
+	writeSchema("users.graphqls", `type Query { user: ID team: ID }`)
+	is.NoErr(os.Remove(ordersFile))
+
+	ids := []string{"billing", "users", "orders", "inline"}
+	results := library.RefreshSchemas(context.Background(), lib, ids, library.RefreshOptions{Workers: 2, Timeout: time.Second})
+	lib.WaitForIndexing()
+
+	is.Equal(len(results), 4)
+	is.Equal(results[0], library.RefreshResult{ID: "billing", Status: library.RefreshUnchanged})
+	is.Equal(results[1], library.RefreshResult{ID: "users", Status: library.RefreshUpdated})
+	is.Equal(results[2].Status, library.RefreshFailed) // source file is gone
+	is.Equal(results[3].Status, library.RefreshSkipped)
+	is.Equal(results[3].Err, library.ErrNoSource)
+
+	// Only the changed schema is reindexed
+	is.Equal(indexer.indexed, map[string]bool{"users": true})
+	users, err := lib.Get("users")
+	is.NoErr(err)
+	is.Equal(string(users.Content), `type Query { user: ID team: ID }`)
+}
+
+func TestIsStale(t *testing.T) {
+	is := is.New(t)
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	is.True(library.IsStale(now.AddDate(0, 0, -8), 7, now))
+	is.True(!library.IsStale(now.AddDate(0, 0, -6), 7, now))
+	is.True(!library.IsStale(now.AddDate(0, 0, -8), 0, now)) // disabled
+	is.True(!library.IsStale(time.Time{}, 7, now))           // never updated
+}
@@ -0,0 +1,186 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// Rename implements Library.Rename.
+func (l *StoreLibrary) Rename(oldID, newID string) error {
+	oldRefs, newRefs, err := l.transferSchema(oldID, newID, true)
+	if err != nil {
+		return err
+	}
+
+	// Index documents record their schema ID, so indexes are rebuilt rather than moved
+	for _, ref := range oldRefs {
+		_ = l.removeIndex(ref)
+	}
+	for _, ref := range newRefs {
+		l.indexAsync(ref)
+	}
+
+	// Index the shared schema that the renamed override was hiding, if any
+	if shared, _ := l.getShared(oldID); shared != nil {
+		l.indexAsync(oldID)
+	}
+	return nil
+}
+
+// Clone implements Library.Clone.
+func (l *StoreLibrary) Clone(id, newID string) error {
+	_, newRefs, err := l.transferSchema(id, newID, false)
+	if err != nil {
+		return err
+	}
+	for _, ref := range newRefs {
+		l.indexAsync(ref)
+	}
+	return nil
+}
+
+// transferSchema moves (or copies) schema id, with its environment snapshots and metadata,
+// to newID, and for a move points the default schema at newID if it was id. It returns the
+// old and new schema references. The change is made under the metadata lock and undone if
+// any step fails, so the library never holds a half-renamed schema.
+func (l *StoreLibrary) transferSchema(id, newID string, move bool) (oldRefs, newRefs []string, err error) {
+	if baseID, env := ParseSchemaRef(id); env != "" {
+		return nil, nil, fmt.Errorf("'%s' is an environment: rename or clone schema '%s' instead", id, baseID)
+	}
+	if err := ValidateSchemaID(newID); err != nil {
+		return nil, nil, err
+	}
+
+	err = l.store.WithLock(metadataLock, func() error {
+		allMetadata, err := l.loadAllMetadata()
+		if err != nil {
+			return err
+		}
+		if err := l.checkSchemaIDFree(allMetadata, newID); err != nil {
+			return err
+		}
+
+		metadata, contents, err := l.readSchemaFiles(allMetadata, id, move)
+		if err != nil {
+			return err
+		}
+
+		var undo []func()
+		rollback := func() {
+			for _, fn := range undo {
+				fn()
+			}
+		}
+		for _, env := range append([]string{""}, metadata.EnvironmentNames()...) {
+			oldRef, newRef := SchemaRef(id, env), SchemaRef(newID, env)
+			content, ok := contents[oldRef]
+			if !ok {
+				continue // environment not fetched yet
+			}
+			undoFile, err := l.transferSchemaFile(oldRef, newRef, content, move)
+			if err != nil {
+				rollback()
+				return err
+			}
+			undo = append(undo, undoFile)
+			oldRefs = append(oldRefs, oldRef)
+			newRefs = append(newRefs, newRef)
+		}
+
+		if move {
+			delete(allMetadata, id)
+		} else {
+			metadata.CreatedAt = time.Now()
+		}
+		allMetadata[newID] = metadata
+		if err := l.saveAllMetadata(allMetadata); err != nil {
+			rollback()
+			return err
+		}
+		if !move {
+			return nil
+		}
+
+		err = l.updateUserConfig(func(config *UserConfig) {
+			if config.DefaultSchema == id {
+				config.DefaultSchema = newID
+			}
+		})
+		if err != nil {
+			delete(allMetadata, newID)
+			allMetadata[id] = metadata
+			_ = l.saveAllMetadata(allMetadata)
+			rollback()
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return oldRefs, newRefs, nil
+}
+
+// checkSchemaIDFree returns ErrSchemaExists if id is taken in the personal or shared library.
+func (l *StoreLibrary) checkSchemaIDFree(allMetadata map[string]SchemaMetadata, id string) error {
+	_, inMetadata := allMetadata[id]
+	shared, _ := l.openSharedLibrary()
+	if inMetadata || l.hasSchema(id) || (shared != nil && shared.has(id)) {
+		return fmt.Errorf("%w: '%s'", ErrSchemaExists, id)
+	}
+	return nil
+}
+
+// readSchemaFiles returns the metadata of schema id and the contents of its schema file and
+// environment snapshots by reference. A shared schema can be copied but not moved.
+func (l *StoreLibrary) readSchemaFiles(allMetadata map[string]SchemaMetadata, id string, move bool) (SchemaMetadata, map[string][]byte, error) {
+	content, err := l.store.ReadSchema(id)
+	if errors.Is(err, fs.ErrNotExist) {
+		shared, err := l.getShared(id)
+		if err != nil {
+			return SchemaMetadata{}, nil, err
+		}
+		if shared == nil {
+			return SchemaMetadata{}, nil, fmt.Errorf("schema '%s' not found", id)
+		}
+		if move {
+			return SchemaMetadata{}, nil, fmt.Errorf("%w: cannot rename '%s' (clone it instead)", ErrSharedSchema, id)
+		}
+		return shared.Metadata, map[string][]byte{id: shared.Content}, nil
+	}
+	if err != nil {
+		return SchemaMetadata{}, nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	metadata := getSchemaMetadata(allMetadata, id)
+	contents := map[string][]byte{id: content}
+	for _, env := range metadata.EnvironmentNames() {
+		ref := SchemaRef(id, env)
+		snapshot, err := l.store.ReadSchema(ref)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return SchemaMetadata{}, nil, fmt.Errorf("failed to read snapshot of '%s': %w", ref, err)
+		}
+		contents[ref] = snapshot
+	}
+	return metadata, contents, nil
+}
+
+// transferSchemaFile moves or copies the schema file of oldRef to newRef and returns a
+// function undoing it.
+func (l *StoreLibrary) transferSchemaFile(oldRef, newRef string, content []byte, move bool) (func(), error) {
+	if move {
+		if err := l.store.MoveSchema(oldRef, newRef); err != nil {
+			return nil, fmt.Errorf("failed to move schema file: %w", err)
+		}
+		return func() { _ = l.store.MoveSchema(newRef, oldRef) }, nil
+	}
+	if err := l.store.WriteSchema(newRef, content); err != nil {
+		return nil, fmt.Errorf("failed to write schema file: %w", err)
+	}
+	return func() { _ = l.store.DeleteSchema(newRef) }, nil
+}
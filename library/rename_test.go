@@ -0,0 +1,130 @@
+package library_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func TestLibrary_Rename(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	mock := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(mock)
+	addStagingEnvironment(t, lib)
+	is.NoErr(lib.SetURLPattern("api", "User", "https://example.com/users/${id}"))
+	is.NoErr(lib.UpdateContent("api@staging", []byte(`type Query { staging: String }`)))
+	is.NoErr(lib.SetDefaultSchema("api"))
+	is.NoErr(lib.WaitForIndexing())
+	before, err := lib.Get("api")
+	is.NoErr(err)
+
+	is.NoErr(lib.Rename("api", "public-api"))
+	is.NoErr(lib.WaitForIndexing())
+
+	_, err = lib.Get("api")
+	is.True(err != nil) // old ID is gone
+	_, err = lib.Get("api@staging")
+	is.True(err != nil)
+
+	renamed, err := lib.Get("public-api")
+	is.NoErr(err)
+	is.Equal(renamed.Content, before.Content)
+	is.Equal(renamed.Metadata.URLPatterns["User"], "https://example.com/users/${id}")
+	is.True(renamed.Metadata.CreatedAt.Equal(before.Metadata.CreatedAt))
+	is.True(renamed.Metadata.UpdatedAt.Equal(before.Metadata.UpdatedAt))
+
+	snapshot, err := lib.Get("public-api@staging")
+	is.NoErr(err)
+	is.Equal(string(snapshot.Content), `type Query { staging: String }`)
+
+	defaultID, err := lib.GetDefaultSchema()
+	is.NoErr(err)
+	is.Equal(defaultID, "public-api")
+
+	is.True(mock.removed["api"])
+	is.True(mock.removed["api@staging"])
+	is.True(mock.indexed["public-api"])
+	is.True(mock.indexed["public-api@staging"])
+}
+
+func TestLibrary_Rename_Errors(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+	setupSharedLibrary(t, map[string]string{"billing.graphqls": sharedBilling})
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	is.NoErr(lib.AddFromContent("api", "API", []byte(`type Query { a: ID }`), ""))
+	is.NoErr(lib.AddFromContent("users", "Users", []byte(`type Query { b: ID }`), ""))
+
+	err := lib.Rename("api", "users")
+	is.True(errors.Is(err, library.ErrSchemaExists))
+	err = lib.Rename("api", "billing")
+	is.True(errors.Is(err, library.ErrSchemaExists)) // would hide the shared schema
+	err = lib.Rename("billing", "payments")
+	is.True(errors.Is(err, library.ErrSharedSchema))
+	is.True(lib.Rename("api", "Bad ID") != nil)
+	is.True(lib.Rename("missing", "other") != nil)
+
+	// Failed renames leave the library unchanged
+	api, err := lib.Get("api")
+	is.NoErr(err)
+	is.Equal(string(api.Content), `type Query { a: ID }`)
+	users, err := lib.Get("users")
+	is.NoErr(err)
+	is.Equal(string(users.Content), `type Query { b: ID }`)
+}
+
+func TestLibrary_Clone(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	mock := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(mock)
+	addStagingEnvironment(t, lib)
+	is.NoErr(lib.UpdateContent("api@staging", []byte(`type Query { staging: String }`)))
+	is.NoErr(lib.SetDefaultSchema("api"))
+
+	is.NoErr(lib.Clone("api", "api-copy"))
+	is.NoErr(lib.WaitForIndexing())
+
+	original, err := lib.Get("api")
+	is.NoErr(err)
+	clone, err := lib.Get("api-copy")
+	is.NoErr(err)
+	is.Equal(clone.Content, original.Content)
+	is.Equal(clone.Metadata.DisplayName, "API")
+	is.Equal(clone.Metadata.SourceURL, "https://example.com/graphql")
+	snapshot, err := lib.Get("api-copy@staging")
+	is.NoErr(err)
+	is.Equal(string(snapshot.Content), `type Query { staging: String }`)
+	is.True(mock.indexed["api-copy"])
+	is.True(mock.indexed["api-copy@staging"])
+
+	defaultID, err := lib.GetDefaultSchema()
+	is.NoErr(err)
+	is.Equal(defaultID, "api") // the default stays with the original
+
+	is.True(lib.Clone("api@staging", "staging") != nil) // environments aren't schemas
+}
+
+func TestLibrary_CloneSharedSchema(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+	setupSharedLibrary(t, map[string]string{"billing.graphqls": sharedBilling})
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	is.NoErr(lib.Clone("billing", "my-billing"))
+
+	clone, err := lib.Get("my-billing")
+	is.NoErr(err)
+	is.Equal(string(clone.Content), sharedBilling)
+	is.Equal(clone.Provenance, library.ProvenanceLocal)
+}
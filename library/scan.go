@@ -0,0 +1,338 @@
+package library
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+	"github.com/tonysyu/gqlxp/project"
+)
+
+// ScanKind is the kind of schema source found by Scan.
+type ScanKind string
+
+// Scan kinds.
+const (
+	ScanSDL           ScanKind = "sdl"
+	ScanIntrospection ScanKind = "introspection"
+	ScanGqlgen        ScanKind = "gqlgen"
+	ScanGraphQLConfig ScanKind = "graphql-config"
+)
+
+// ScanAction is what applying a scan does with a schema source.
+type ScanAction string
+
+// Scan actions.
+const (
+	ScanAdd       ScanAction = "add"
+	ScanUpdate    ScanAction = "update"
+	ScanUnchanged ScanAction = "unchanged"
+	ScanSkip      ScanAction = "skip"
+)
+
+// ScanResult is a schema source found by Scan.
+type ScanResult struct {
+	// ID is the proposed schema ID, or the ID of the schema already added from Source.
+	ID     string
+	Kind   ScanKind
+	Action ScanAction
+	// Source is the absolute path of the schema file, or of the directory of a multi-file
+	// schema.
+	Source string
+	// Config is the gqlgen or graphql-config file the source was found through, if any.
+	Config string
+	// Err is the reason a source is skipped.
+	Err error
+
+	content []byte
+}
+
+// gqlgenFileNames are the names of gqlgen config files, whose schema globs point at the
+// SDL files of a Go service.
+var gqlgenFileNames = []string{"gqlgen.yml", "gqlgen.yaml"}
+
+// skippedScanDirs are directories of dependencies and build output that are not scanned.
+var skippedScanDirs = []string{"node_modules", "vendor"}
+
+// genericFileStems are file names that say nothing about a schema, so the ID of a schema
+// read from one is proposed from its directory instead.
+var genericFileStems = []string{"schema", "graphql", "index", "introspection"}
+
+// typeDefinitionPattern matches a type system definition, which tells an SDL file from a
+// .graphql file of operations.
+var typeDefinitionPattern = regexp.MustCompile(`(?m)^\s*(extend\s+)?(type|interface|enum|input|union|scalar|schema|directive)\b`)
+
+// scanFiles are the candidate files found while walking a directory.
+type scanFiles struct {
+	configs []string
+	sdl     []string
+	json    []string
+}
+
+// Scan walks dir for schema sources: SDL files, introspection JSON dumps, and the schemas
+// of gqlgen and graphql-config configs. Hidden, node_modules, and vendor directories are
+// skipped. Each source is given a unique ID, proposed from its file or directory name, and
+// compared with the library to decide whether it would be added, updated, or left
+// unchanged. Results are sorted by source. Scan does not change the library; call
+// ApplyScan to do so.
+func Scan(lib Library, dir string) ([]ScanResult, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	files, err := walkScanDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files belonging to a config are added through it rather than on their own
+	var results []ScanResult
+	claimed := make(map[string]bool)
+	for _, path := range files.configs {
+		for _, result := range scanConfig(path) {
+			if result.Err == nil {
+				sourceFiles, _ := SourceFiles(result.Source)
+				for _, file := range sourceFiles {
+					claimed[file] = true
+				}
+			}
+			results = append(results, result)
+		}
+	}
+	for _, path := range files.sdl {
+		if !claimed[path] && isSDLFile(path) {
+			results = append(results, ScanResult{ID: proposeFileID(path), Kind: ScanSDL, Source: path})
+		}
+	}
+	for _, path := range files.json {
+		if !claimed[path] && isIntrospectionFile(path) {
+			results = append(results, ScanResult{ID: proposeFileID(path), Kind: ScanIntrospection, Source: path})
+		}
+	}
+	slices.SortStableFunc(results, func(a, b ScanResult) int {
+		return strings.Compare(a.Source, b.Source)
+	})
+
+	taken := make(map[string]bool)
+	for i := range results {
+		compareScanResult(lib, &results[i], taken)
+	}
+	return results, nil
+}
+
+// walkScanDir returns the candidate files under dir.
+func walkScanDir(dir string) (scanFiles, error) {
+	var files scanFiles
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != dir && (strings.HasPrefix(name, ".") || slices.Contains(skippedScanDirs, name)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch ext := strings.ToLower(filepath.Ext(name)); {
+		case slices.Contains(gqlgenFileNames, name) || project.IsConfigFile(name):
+			files.configs = append(files.configs, path)
+		case slices.Contains(schemaFileExtensions, ext):
+			files.sdl = append(files.sdl, path)
+		case ext == ".json":
+			files.json = append(files.json, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return scanFiles{}, fmt.Errorf("failed to scan directory: %w", err)
+	}
+	return files, nil
+}
+
+// scanConfig returns the schema sources of a gqlgen or graphql-config file: one for the
+// top-level schema and one for each project declaring a schema.
+func scanConfig(path string) []ScanResult {
+	kind := ScanGraphQLConfig
+	if slices.Contains(gqlgenFileNames, filepath.Base(path)) {
+		kind = ScanGqlgen
+	}
+	dirID := filepath.Base(filepath.Dir(path))
+
+	config, err := project.Load(path)
+	if err != nil {
+		return []ScanResult{{ID: SanitizeSchemaID(dirID), Kind: kind, Action: ScanSkip, Source: path, Config: path, Err: err}}
+	}
+	var results []ScanResult
+	if len(config.Schema) > 0 {
+		results = append(results, configSource(config, dirID, kind))
+	}
+	for _, name := range config.ProjectNames() {
+		if p := config.Projects[name]; len(p.Schema) > 0 {
+			results = append(results, configSource(p, dirID+"-"+name, kind))
+		}
+	}
+	return results
+}
+
+// configSource resolves the schema pointers of a config to a schema file or to a directory
+// of SDL files, the sources a library schema can be read from.
+func configSource(config *project.Config, id string, kind ScanKind) ScanResult {
+	result := ScanResult{ID: SanitizeSchemaID(id), Kind: kind, Source: config.Path, Config: config.Path}
+	skip := func(err error) ScanResult {
+		result.Action, result.Err = ScanSkip, err
+		return result
+	}
+
+	var files []string
+	for _, pointer := range config.Schema {
+		if isURL(pointer.Pointer) {
+			return skip(fmt.Errorf("URL sources are added with 'gqlxp library add %s'", pointer.Pointer))
+		}
+		matches, err := config.Glob(pointer.Pointer)
+		if err != nil {
+			return skip(err)
+		}
+		if len(matches) == 0 {
+			return skip(fmt.Errorf("no files match schema '%s'", pointer.Pointer))
+		}
+		for _, match := range matches {
+			if !slices.Contains(files, match) {
+				files = append(files, match)
+			}
+		}
+	}
+	slices.Sort(files)
+
+	if len(files) == 1 {
+		result.Source = files[0]
+		return result
+	}
+	dir := filepath.Dir(files[0])
+	if sourceFiles, err := SourceFiles(dir); err != nil || !slices.Equal(sourceFiles, files) {
+		return skip(fmt.Errorf("schema files must be a single file or all the SDL files of one directory"))
+	}
+	result.Source = dir
+	return result
+}
+
+// isSDLFile reports whether path contains type definitions. Every .graphqls file is SDL,
+// while .graphql files may hold operations instead.
+func isSDLFile(path string) bool {
+	if strings.EqualFold(filepath.Ext(path), ".graphqls") {
+		return true
+	}
+	content, err := os.ReadFile(path)
+	return err == nil && typeDefinitionPattern.Match(content)
+}
+
+// isIntrospectionFile reports whether path is an introspection result.
+func isIntrospectionFile(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil || !introspection.IsJSON(content) || !bytes.Contains(content, []byte(`"__schema"`)) {
+		return false
+	}
+	_, err = introspection.ParseResult(content)
+	return err == nil
+}
+
+// proposeFileID proposes a schema ID from a file name, or from its directory when the file
+// name is generic, as in services/users/schema.graphqls.
+func proposeFileID(path string) string {
+	name := filepath.Base(path)
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+	if slices.Contains(genericFileStems, strings.ToLower(stem)) {
+		stem = filepath.Base(filepath.Dir(path))
+	}
+	return SanitizeSchemaID(stem)
+}
+
+// compareScanResult reads the content of a scanned source and decides its action: a
+// source already in the library is updated or unchanged, and a new source is added under
+// a proposed ID that is not in taken or in the library.
+func compareScanResult(lib Library, result *ScanResult, taken map[string]bool) {
+	if result.Action == ScanSkip {
+		return
+	}
+	content, err := ReadSourceFile(result.Source)
+	if err == nil {
+		_, err = gql.ParseSchema(content)
+	}
+	if err != nil {
+		result.Action, result.Err = ScanSkip, fmt.Errorf("invalid GraphQL schema: %w", err)
+		return
+	}
+	result.content = content
+
+	if existing, err := lib.FindByPath(result.Source); err == nil {
+		result.ID = existing.ID
+		taken[existing.ID] = true
+		if existing.Metadata.FileHash == CalculateFileHash(content) {
+			result.Action = ScanUnchanged
+		} else {
+			result.Action = ScanUpdate
+		}
+		return
+	}
+	result.ID = freeScanID(lib, result.ID, result.Source, taken)
+	result.Action = ScanAdd
+	taken[result.ID] = true
+}
+
+// freeScanID returns id if it is free, or else makes it unique by prefixing the name of
+// the source's parent directory and then by appending a number.
+func freeScanID(lib Library, id, source string, taken map[string]bool) string {
+	isFree := func(id string) bool {
+		if taken[id] {
+			return false
+		}
+		_, err := lib.Get(id)
+		return err != nil
+	}
+	if id == "" {
+		id = "schema"
+	}
+	if isFree(id) {
+		return id
+	}
+	parent := SanitizeSchemaID(filepath.Base(filepath.Dir(source)))
+	if parent != "" && parent != id {
+		if prefixed := parent + "-" + id; isFree(prefixed) {
+			return prefixed
+		}
+	}
+	for n := 2; ; n++ {
+		if numbered := id + "-" + strconv.Itoa(n); isFree(numbered) {
+			return numbered
+		}
+	}
+}
+
+// ApplyScan adds the new sources of a scan to the library, named by their ID, and updates
+// the content of changed ones. Other results are ignored.
+// Every result is applied even if some fail; the errors are joined.
+func ApplyScan(lib Library, results []ScanResult) error {
+	var errs []error
+	for _, result := range results {
+		var err error
+		switch result.Action {
+		case ScanAdd:
+			err = lib.AddFromContent(result.ID, result.ID, result.content, result.Source)
+		case ScanUpdate:
+			err = lib.UpdateContent(result.ID, result.content)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
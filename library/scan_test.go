@@ -0,0 +1,141 @@
+package library_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+// writeScanTree writes files, by path relative to dir, for a scan.
+func writeScanTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	is := is.New(t)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		is.NoErr(os.MkdirAll(filepath.Dir(path), 0755))
+		is.NoErr(os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+// introspectionJSON returns the introspection result of an SDL schema.
+func introspectionJSON(t *testing.T, sdl string) string {
+	t.Helper()
+	is := is.New(t)
+	schema, err := gql.ParseSchema([]byte(sdl))
+	is.NoErr(err)
+	content, err := json.Marshal(introspection.FromSchema(schema))
+	is.NoErr(err)
+	return string(content)
+}
+
+func TestScan(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	writeScanTree(t, dir, map[string]string{
+		"web/billing.graphqls":             `type Query { invoice: ID }`,
+		"web/queries.graphql":              `query Invoice { invoice }`,
+		"web/users.graphql":                `type Query { user: ID }`,
+		"payments/schema.json":             introspectionJSON(t, `type Query { payment: ID }`),
+		"payments/package.json":            `{"name": "payments"}`,
+		"orders/gqlgen.yml":                "schema:\n  - graph/*.graphqls\n",
+		"orders/graph/query.graphqls":      `type Query { order: Order }`,
+		"orders/graph/order.graphqls":      `type Order { id: ID! }`,
+		"app/.graphqlrc.yaml":              "projects:\n  api:\n    schema: api.graphqls\n  remote:\n    schema: https://example.com/graphql\n",
+		"app/api.graphqls":                 `type Query { app: ID }`,
+		"broken/broken.graphqls":           `type Query {`,
+		"node_modules/lib/schema.graphqls": `type Query { dependency: ID }`,
+		".cache/schema.graphqls":           `type Query { cached: ID }`,
+	})
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	results, err := library.Scan(lib, dir)
+	is.NoErr(err)
+
+	type scanned struct {
+		id     string
+		kind   library.ScanKind
+		action library.ScanAction
+		source string
+	}
+	var got []scanned
+	for _, result := range results {
+		source, err := filepath.Rel(dir, result.Source)
+		is.NoErr(err)
+		got = append(got, scanned{result.ID, result.Kind, result.Action, filepath.ToSlash(source)})
+	}
+	is.Equal(got, []scanned{
+		{"app-remote", library.ScanGraphQLConfig, library.ScanSkip, "app/.graphqlrc.yaml"}, // URL
+		{"app-api", library.ScanGraphQLConfig, library.ScanAdd, "app/api.graphqls"},
+		{"broken", library.ScanSDL, library.ScanSkip, "broken/broken.graphqls"},
+		{"orders", library.ScanGqlgen, library.ScanAdd, "orders/graph"},
+		{"payments", library.ScanIntrospection, library.ScanAdd, "payments/schema.json"},
+		{"billing", library.ScanSDL, library.ScanAdd, "web/billing.graphqls"},
+		{"users", library.ScanSDL, library.ScanAdd, "web/users.graphql"},
+	})
+
+	is.NoErr(library.ApplyScan(lib, results))
+	orders, err := lib.Get("orders")
+	is.NoErr(err)
+	is.Equal(orders.Metadata.SourceFile, filepath.Join(dir, "orders", "graph"))
+	_, err = gql.ParseSchema(orders.Content)
+	is.NoErr(err)
+	payments, err := lib.Get("payments")
+	is.NoErr(err)
+	is.True(!introspection.IsJSON(payments.Content)) // stored as SDL
+
+	// Rescanning finds the sources already added, and the changed ones to update
+	writeScanTree(t, dir, map[string]string{"web/billing.graphqls": `type Query { invoice: ID, total: Int }`})
+	results, err = library.Scan(lib, dir)
+	is.NoErr(err)
+	actions := make(map[string]library.ScanAction)
+	for _, result := range results {
+		actions[result.ID] = result.Action
+	}
+	is.Equal(actions["billing"], library.ScanUpdate)
+	is.Equal(actions["orders"], library.ScanUnchanged)
+	is.Equal(actions["users"], library.ScanUnchanged)
+
+	is.NoErr(library.ApplyScan(lib, results))
+	billing, err := lib.Get("billing")
+	is.NoErr(err)
+	is.Equal(string(billing.Content), `type Query { invoice: ID, total: Int }`)
+}
+
+func TestScan_UniqueIDs(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	writeScanTree(t, dir, map[string]string{
+		"public/users.graphqls":   `type Query { user: ID }`,
+		"internal/users.graphqls": `type Query { user: ID, admin: Boolean }`,
+		"accounts/api.graphqls":   `type Query { account: ID }`,
+	})
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	is.NoErr(lib.AddFromContent("api", "API", []byte(`type Query { a: ID }`), ""))
+	results, err := library.Scan(lib, dir)
+	is.NoErr(err)
+
+	ids := make(map[string]string)
+	for _, result := range results {
+		source, err := filepath.Rel(dir, result.Source)
+		is.NoErr(err)
+		ids[filepath.ToSlash(source)] = result.ID
+	}
+	is.Equal(ids, map[string]string{
+		"accounts/api.graphqls":   "accounts-api", // api is in the library
+		"internal/users.graphqls": "users",
+		"public/users.graphqls":   "public-users",
+	})
+}
@@ -0,0 +1,65 @@
+package library
+
+import (
+	"fmt"
+
+	"github.com/tonysyu/gqlxp/search"
+)
+
+// Search implements Library.Search.
+func (l *StoreLibrary) Search(schemaIDs []string, query string, limit int) (*search.SearchResponse, error) {
+	schemas, err := l.List()
+	if err != nil {
+		return nil, err
+	}
+	displayNames := make(map[string]string, len(schemas))
+	for _, schema := range schemas {
+		displayNames[schema.ID] = schema.DisplayName
+	}
+
+	if len(schemaIDs) == 0 {
+		for _, schema := range schemas {
+			schemaIDs = append(schemaIDs, schema.ID)
+		}
+	}
+	var searched []string
+	var skipped []search.SkippedSchema
+	for _, id := range schemaIDs {
+		if _, ok := displayNames[id]; !ok {
+			// Environment snapshots aren't listed, but can be searched by reference
+			schema, err := l.Get(id)
+			if _, env := ParseSchemaRef(id); env == "" || err != nil {
+				return nil, fmt.Errorf("schema '%s' not found", id)
+			}
+			displayNames[id] = schema.Metadata.DisplayName
+		}
+		if l.indexer != nil && !l.indexCurrent(id) {
+			// A schema that can't be indexed, e.g. because it no longer parses, doesn't
+			// keep the other schemas from being searched, but fails a search of it alone
+			if err := l.Reindex(id); err != nil {
+				if len(schemaIDs) == 1 {
+					return nil, err
+				}
+				skipped = append(skipped, search.SkippedSchema{SchemaID: id, Error: err.Error()})
+				continue
+			}
+		}
+		searched = append(searched, id)
+	}
+	if len(searched) == 0 {
+		return &search.SearchResponse{Results: []search.SearchResult{}, Skipped: skipped}, nil
+	}
+
+	if l.searcher == nil {
+		return nil, fmt.Errorf("library has no search index")
+	}
+	response, err := l.searcher.SearchSchemas(searched, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	response.Skipped = skipped
+	for i := range response.Results {
+		response.Results[i].SchemaName = displayNames[response.Results[i].SchemaID]
+	}
+	return response, nil
+}
@@ -0,0 +1,73 @@
+package library_test
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/library"
+	"github.com/tonysyu/gqlxp/search"
+)
+
+func TestLibrary_Search(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	// Add schemas without indexing them, so Search has to build the indexes
+	setup := library.NewLibraryWithIndexer(newMockIndexer())
+	is.NoErr(setup.AddFromContent("billing", "Billing Service", []byte(`type Query { invoice: Invoice } type Invoice { id: ID! }`), "billing.graphqls"))
+	is.NoErr(setup.AddFromContent("users", "Users Service", []byte(`type Query { user: User } type User { id: ID! }`), "users.graphqls"))
+
+	lib := library.NewLibrary()
+
+	response, err := lib.Search(nil, "Invoice", 10)
+	is.NoErr(err)
+	results := response.Results
+	is.True(len(results) > 0)
+	for _, result := range results {
+		is.Equal(result.SchemaID, "billing")
+		is.Equal(result.SchemaName, "Billing Service")
+	}
+
+	response, err = lib.Search([]string{"users"}, "+kind:Query", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.Equal(len(results), 1)
+	is.Equal(results[0].Path, "Query.user")
+	is.Equal(results[0].SchemaName, "Users Service")
+	is.Equal(response.Total, uint64(1))
+	is.Equal(response.Kinds, []search.FacetCount{{Term: "Query", Count: 1}})
+}
+
+func TestLibrary_Search_UnknownSchema(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	_, err := lib.Search([]string{"missing"}, "User", 10)
+	is.True(err != nil) // unknown schema IDs are rejected
+}
+
+func TestLibrary_Search_SkipsSchemasThatFailToIndex(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	setup := library.NewLibraryWithIndexer(newMockIndexer())
+	is.NoErr(setup.AddFromContent("billing", "Billing Service", []byte(`type Query { invoice: Invoice } type Invoice { id: ID! }`), "billing.graphqls"))
+	is.NoErr(setup.AddFromContent("broken", "Broken Service", []byte(`type Query { invoice: `), "broken.graphqls"))
+
+	lib := library.NewLibrary()
+
+	response, err := lib.Search(nil, "Invoice", 10)
+	is.NoErr(err) // the other schemas are still searched
+	is.True(len(response.Results) > 0)
+	is.Equal(response.Results[0].SchemaID, "billing")
+	is.Equal(len(response.Skipped), 1)
+	is.Equal(response.Skipped[0].SchemaID, "broken")
+	is.True(response.Skipped[0].Error != "")
+
+	_, err = lib.Search([]string{"broken"}, "Invoice", 10)
+	is.True(err != nil) // searching only the schema that fails to index is an error
+}
@@ -0,0 +1,329 @@
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SharedLibraryEnvVar names the environment variable that mounts a shared library directory,
+// taking precedence over the directory set with MountSharedLibrary.
+const SharedLibraryEnvVar = "GQLXP_SHARED_LIBRARY"
+
+// ErrSharedSchema is returned when trying to remove a schema that only exists in the
+// read-only shared library.
+var ErrSharedSchema = errors.New("schema belongs to the read-only shared library")
+
+// ErrNoSharedLibrary is returned when a shared library operation runs with none mounted.
+var ErrNoSharedLibrary = errors.New("no shared library mounted")
+
+// Provenance describes where a library schema comes from.
+type Provenance string
+
+const (
+	// ProvenanceLocal marks a schema stored only in the personal library.
+	ProvenanceLocal Provenance = ""
+	// ProvenanceShared marks a schema read from the shared library.
+	ProvenanceShared Provenance = "shared"
+	// ProvenanceOverride marks a personal schema that hides a shared schema with the same ID.
+	ProvenanceOverride Provenance = "override"
+)
+
+// SharedSyncResult summarizes which shared schemas changed since the last sync.
+type SharedSyncResult struct {
+	Dir       string
+	Added     []string
+	Updated   []string
+	Removed   []string
+	Unchanged []string
+	// Overridden lists added or updated schemas that were not reindexed because a local
+	// override hides them.
+	Overridden []string
+	// Failed maps schemas that could not be indexed to the reason; they are retried by the
+	// next sync.
+	Failed map[string]error
+}
+
+// sharedState records the schema hashes seen by the last sync of a shared library.
+type sharedState struct {
+	Dir      string            `json:"dir"`
+	SyncedAt time.Time         `json:"syncedAt"`
+	Hashes   map[string]string `json:"hashes"`
+}
+
+// sharedLibrary is a read-only library directory laid out like the personal schemas
+// directory: <id>.graphqls files with an optional metadata.json.
+type sharedLibrary struct {
+	dir      string
+	metadata map[string]SchemaMetadata
+}
+
+// SharedLibraryDir implements Library.SharedLibraryDir.
+func (l *StoreLibrary) SharedLibraryDir() (string, error) {
+	if dir := os.Getenv(SharedLibraryEnvVar); dir != "" {
+		return filepath.Abs(dir)
+	}
+	config, err := l.loadUserConfig()
+	if err != nil {
+		return "", err
+	}
+	return config.SharedLibrary, nil
+}
+
+// MountSharedLibrary implements Library.MountSharedLibrary.
+func (l *StoreLibrary) MountSharedLibrary(dir string) error {
+	if dir != "" {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve shared library directory: %w", err)
+		}
+		info, err := os.Stat(absDir)
+		if err != nil {
+			return fmt.Errorf("failed to open shared library directory: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("shared library '%s' is not a directory", absDir)
+		}
+		dir = absDir
+	}
+
+	return l.updateUserConfig(func(config *UserConfig) {
+		config.SharedLibrary = dir
+	})
+}
+
+// openSharedLibrary opens the mounted shared library, returning nil if none is mounted.
+func (l *StoreLibrary) openSharedLibrary() (*sharedLibrary, error) {
+	dir, err := l.SharedLibraryDir()
+	if err != nil || dir == "" {
+		return nil, err
+	}
+
+	shared := &sharedLibrary{dir: dir, metadata: make(map[string]SchemaMetadata)}
+	data, err := os.ReadFile(filepath.Join(dir, "metadata.json"))
+	if os.IsNotExist(err) {
+		return shared, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shared library metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, &shared.metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse shared library metadata: %w", err)
+	}
+	return shared, nil
+}
+
+// ids returns the IDs of the shared schemas, sorted. Files that are not valid schema IDs,
+// such as environment snapshots, are ignored.
+func (s *sharedLibrary) ids() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shared library directory: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".graphqls") {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ".graphqls")
+		if ValidateSchemaID(id) != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// has reports whether the shared library contains schema id.
+func (s *sharedLibrary) has(id string) bool {
+	if ValidateSchemaID(id) != nil {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(s.dir, id+".graphqls"))
+	return err == nil
+}
+
+// get reads a shared schema, or returns an error wrapping os.ErrNotExist if there is none.
+func (s *sharedLibrary) get(id string) (*Schema, error) {
+	if ValidateSchemaID(id) != nil {
+		return nil, fmt.Errorf("schema '%s': %w", id, os.ErrNotExist)
+	}
+	schemaFile := filepath.Join(s.dir, id+".graphqls")
+	content, err := os.ReadFile(schemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shared schema file: %w", err)
+	}
+
+	metadata := getSchemaMetadata(s.metadata, id)
+	// Source files and environments belong to the machine that wrote the metadata
+	metadata.SourceFile = ""
+	metadata.Environments = nil
+	metadata.FileHash = CalculateFileHash(content)
+	if metadata.URLPatterns == nil {
+		metadata.URLPatterns = make(map[string]string)
+	}
+	if metadata.UpdatedAt.IsZero() {
+		if info, err := os.Stat(schemaFile); err == nil {
+			metadata.UpdatedAt = info.ModTime()
+		}
+	}
+
+	return &Schema{
+		ID:         id,
+		Content:    content,
+		Metadata:   metadata,
+		Provenance: ProvenanceShared,
+	}, nil
+}
+
+// getShared returns schema id from the shared library, or nil if it isn't there.
+func (l *StoreLibrary) getShared(id string) (*Schema, error) {
+	shared, err := l.openSharedLibrary()
+	if err != nil || shared == nil || !shared.has(id) {
+		return nil, err
+	}
+	return shared.get(id)
+}
+
+// overrideShared copies shared schema id into the personal library, where later changes
+// are made instead of in the read-only shared library.
+func (l *StoreLibrary) overrideShared(id string) error {
+	schema, err := l.getShared(id)
+	if err != nil {
+		return err
+	}
+	if schema == nil {
+		return fmt.Errorf("schema '%s' not found", id)
+	}
+
+	if err := l.store.WriteSchema(id, schema.Content); err != nil {
+		return fmt.Errorf("failed to write schema file: %w", err)
+	}
+
+	metadata := schema.Metadata
+	if metadata.CreatedAt.IsZero() {
+		metadata.CreatedAt = time.Now()
+	}
+	err = l.updateAllMetadata(func(allMetadata map[string]SchemaMetadata) error {
+		allMetadata[id] = metadata
+		return nil
+	})
+	if err != nil {
+		_ = l.store.DeleteSchema(id)
+		return err
+	}
+	return nil
+}
+
+// SyncShared implements Library.SyncShared.
+func (l *StoreLibrary) SyncShared() (SharedSyncResult, error) {
+	shared, err := l.openSharedLibrary()
+	if err != nil {
+		return SharedSyncResult{}, err
+	}
+	if shared == nil {
+		return SharedSyncResult{}, ErrNoSharedLibrary
+	}
+	ids, err := shared.ids()
+	if err != nil {
+		return SharedSyncResult{}, err
+	}
+	localIDs, err := l.localSchemaIDs()
+	if err != nil {
+		return SharedSyncResult{}, err
+	}
+	overridden := make(map[string]bool, len(localIDs))
+	for _, id := range localIDs {
+		overridden[id] = true
+	}
+
+	previous, err := l.loadSharedState()
+	if err != nil {
+		return SharedSyncResult{}, err
+	}
+	if previous.Dir != shared.dir {
+		// A different directory was mounted, so nothing has been synced from it yet
+		previous.Hashes = nil
+	}
+
+	result := SharedSyncResult{Dir: shared.dir, Failed: make(map[string]error)}
+	state := sharedState{Dir: shared.dir, SyncedAt: time.Now(), Hashes: make(map[string]string, len(ids))}
+	for _, id := range ids {
+		schema, err := shared.get(id)
+		if err != nil {
+			result.Failed[id] = err
+			continue
+		}
+		hash := schema.Metadata.FileHash
+		previousHash, seen := previous.Hashes[id]
+		if seen && previousHash == hash {
+			result.Unchanged = append(result.Unchanged, id)
+			state.Hashes[id] = hash
+			continue
+		}
+
+		if overridden[id] {
+			result.Overridden = append(result.Overridden, id)
+		} else if err := l.Reindex(id); err != nil {
+			// Leave the hash unrecorded so the next sync retries the schema
+			result.Failed[id] = err
+			continue
+		}
+		if seen {
+			result.Updated = append(result.Updated, id)
+		} else {
+			result.Added = append(result.Added, id)
+		}
+		state.Hashes[id] = hash
+	}
+
+	for id := range previous.Hashes {
+		if _, ok := state.Hashes[id]; ok || shared.has(id) {
+			continue
+		}
+		result.Removed = append(result.Removed, id)
+		if !overridden[id] {
+			_ = l.removeIndex(id)
+		}
+	}
+	sort.Strings(result.Removed)
+
+	if err := l.saveSharedState(state); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// loadSharedState loads the state of the last shared library sync, if any.
+func (l *StoreLibrary) loadSharedState() (sharedState, error) {
+	data, err := l.store.ReadDocument(sharedStateDocument)
+	if errors.Is(err, fs.ErrNotExist) {
+		return sharedState{}, nil
+	}
+	if err != nil {
+		return sharedState{}, fmt.Errorf("failed to read shared library state: %w", err)
+	}
+	var state sharedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return sharedState{}, fmt.Errorf("failed to parse shared library state: %w", err)
+	}
+	return state, nil
+}
+
+// saveSharedState records the state of a shared library sync.
+func (l *StoreLibrary) saveSharedState(state sharedState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal shared library state: %w", err)
+	}
+	if err := l.store.WriteDocument(sharedStateDocument, data); err != nil {
+		return fmt.Errorf("failed to write shared library state: %w", err)
+	}
+	return nil
+}
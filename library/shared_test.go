@@ -0,0 +1,157 @@
+package library_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+const (
+	sharedBilling = `type Query { invoice: Invoice } type Invoice { id: ID! }`
+	sharedUsers   = `type Query { user: User } type User { id: ID! }`
+)
+
+// setupSharedLibrary mounts a shared library directory holding the given schema files.
+func setupSharedLibrary(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(library.SharedLibraryEnvVar, dir)
+	return dir
+}
+
+func TestLibrary_SharedSchemas(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+	setupSharedLibrary(t, map[string]string{
+		"billing.graphqls": sharedBilling,
+		"users.graphqls":   sharedUsers,
+		"metadata.json":    `{"billing": {"displayName": "Billing Service", "tags": ["payments"]}}`,
+	})
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	is.NoErr(lib.AddFromContent("orders", "Orders", []byte(`type Query { order: ID }`), "orders.graphqls"))
+	is.NoErr(lib.AddFromContent("users", "My Users", []byte(`type Query { me: ID }`), "users.graphqls"))
+
+	schemas, err := lib.List()
+	is.NoErr(err)
+	is.Equal(len(schemas), 3)
+	is.Equal(schemas[0].ID, "billing")
+	is.Equal(schemas[0].DisplayName, "Billing Service")
+	is.Equal(schemas[0].Tags, []string{"payments"})
+	is.Equal(schemas[0].Provenance, library.ProvenanceShared)
+	is.Equal(schemas[1].ID, "orders")
+	is.Equal(schemas[1].Provenance, library.ProvenanceLocal)
+	is.Equal(schemas[2].ID, "users")
+	is.Equal(schemas[2].Provenance, library.ProvenanceOverride)
+
+	billing, err := lib.Get("billing")
+	is.NoErr(err)
+	is.Equal(string(billing.Content), sharedBilling)
+	is.Equal(billing.Provenance, library.ProvenanceShared)
+
+	users, err := lib.Get("users")
+	is.NoErr(err)
+	is.Equal(string(users.Content), `type Query { me: ID }`) // local override wins
+
+	err = lib.Remove("billing")
+	is.True(errors.Is(err, library.ErrSharedSchema)) // shared schemas are read-only
+}
+
+func TestLibrary_OverrideSharedSchema(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+	sharedDir := setupSharedLibrary(t, map[string]string{"billing.graphqls": sharedBilling})
+
+	indexer := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(indexer)
+	schema, err := lib.Get("billing")
+	is.NoErr(err)
+	schema.Metadata.AddTags("payments")
+	is.NoErr(lib.UpdateMetadata("billing", schema.Metadata))
+
+	schema, err = lib.Get("billing")
+	is.NoErr(err)
+	is.Equal(schema.Provenance, library.ProvenanceOverride)
+	is.Equal(schema.Metadata.Tags, []string{"payments"})
+	is.Equal(string(schema.Content), sharedBilling)
+
+	// The shared library itself is left untouched
+	_, err = os.Stat(filepath.Join(sharedDir, "metadata.json"))
+	is.True(os.IsNotExist(err))
+
+	// Removing the override restores and reindexes the shared schema
+	is.NoErr(lib.Remove("billing"))
+	lib.WaitForIndexing()
+	schema, err = lib.Get("billing")
+	is.NoErr(err)
+	is.Equal(schema.Provenance, library.ProvenanceShared)
+	is.Equal(schema.Metadata.Tags, nil)
+	is.True(indexer.indexed["billing"])
+}
+
+func TestLibrary_SyncShared(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+	sharedDir := setupSharedLibrary(t, map[string]string{
+		"billing.graphqls": sharedBilling,
+		"users.graphqls":   sharedUsers,
+	})
+
+	indexer := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(indexer)
+	result, err := lib.SyncShared()
+	is.NoErr(err)
+	is.Equal(result.Dir, sharedDir)
+	is.Equal(result.Added, []string{"billing", "users"})
+	is.True(indexer.indexed["billing"])
+	is.True(indexer.indexed["users"])
+
+	// Nothing is reindexed when nothing changed
+	indexer.indexed = make(map[string]bool)
+	result, err = lib.SyncShared()
+	is.NoErr(err)
+	is.Equal(result.Unchanged, []string{"billing", "users"})
+	is.Equal(len(indexer.indexed), 0)
+
+	// Update billing, override users, and add then remove orders
+	is.NoErr(os.WriteFile(filepath.Join(sharedDir, "billing.graphqls"), []byte(sharedBilling+` type Refund { id: ID! }`), 0644))
+	is.NoErr(os.WriteFile(filepath.Join(sharedDir, "users.graphqls"), []byte(sharedUsers+` type Team { id: ID! }`), 0644))
+	is.NoErr(lib.AddFromContent("users", "My Users", []byte(`type Query { me: ID }`), "users.graphqls"))
+	lib.WaitForIndexing()
+	indexer.indexed = make(map[string]bool)
+
+	result, err = lib.SyncShared()
+	is.NoErr(err)
+	is.Equal(result.Updated, []string{"billing", "users"})
+	is.Equal(result.Overridden, []string{"users"})
+	is.True(indexer.indexed["billing"])
+	is.True(!indexer.indexed["users"]) // the local override keeps its own index
+
+	is.NoErr(os.Remove(filepath.Join(sharedDir, "billing.graphqls")))
+	result, err = lib.SyncShared()
+	is.NoErr(err)
+	is.Equal(result.Removed, []string{"billing"})
+	is.True(indexer.removed["billing"])
+}
+
+func TestLibrary_SyncShared_NotMounted(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	_, err := lib.SyncShared()
+	is.True(errors.Is(err, library.ErrNoSharedLibrary))
+}
@@ -0,0 +1,156 @@
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// singleFileStore keeps the schemas and state of a library in one JSON file, which is
+// easy to copy, back up, or keep in a dotfiles repository. Search indexes, which can be
+// rebuilt at any time, and lock files are kept in directories next to it.
+type singleFileStore struct {
+	path string
+}
+
+// singleFileData is the content of a single-file store.
+type singleFileData struct {
+	Schemas   map[string]string          `json:"schemas"`
+	Documents map[string]json.RawMessage `json:"documents"`
+}
+
+// storeLock is the lock serializing changes to the file of a single-file store.
+const storeLock = "store"
+
+// NewSingleFileStore creates a store in the JSON file at path, which is created when first
+// written to.
+func NewSingleFileStore(path string) Store {
+	return &singleFileStore{path: path}
+}
+
+// load reads the store file; a missing file is an empty store.
+func (s *singleFileStore) load() (*singleFileData, error) {
+	data := &singleFileData{
+		Schemas:   make(map[string]string),
+		Documents: make(map[string]json.RawMessage),
+	}
+	content, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read library file: %w", err)
+	}
+	if err := json.Unmarshal(content, data); err != nil {
+		return nil, fmt.Errorf("failed to parse library file %s: %w", s.path, err)
+	}
+	if data.Schemas == nil {
+		data.Schemas = make(map[string]string)
+	}
+	if data.Documents == nil {
+		data.Documents = make(map[string]json.RawMessage)
+	}
+	return data, nil
+}
+
+// update loads the store file, applies update, and saves the result atomically, holding
+// the store lock so that concurrent changes are not lost.
+func (s *singleFileStore) update(update func(data *singleFileData) error) error {
+	return s.WithLock(storeLock, func() error {
+		data, err := s.load()
+		if err != nil {
+			return err
+		}
+		if err := update(data); err != nil {
+			return err
+		}
+		content, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal library file: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+			return fmt.Errorf("failed to create library directory: %w", err)
+		}
+		return writeFileAtomic(s.path, content)
+	})
+}
+
+func (s *singleFileStore) ReadSchema(ref string) ([]byte, error) {
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	content, ok := data.Schemas[ref]
+	if !ok {
+		return nil, fmt.Errorf("schema '%s': %w", ref, fs.ErrNotExist)
+	}
+	return []byte(content), nil
+}
+
+func (s *singleFileStore) WriteSchema(ref string, content []byte) error {
+	return s.update(func(data *singleFileData) error {
+		data.Schemas[ref] = string(content)
+		return nil
+	})
+}
+
+func (s *singleFileStore) DeleteSchema(ref string) error {
+	return s.update(func(data *singleFileData) error {
+		if _, ok := data.Schemas[ref]; !ok {
+			return fmt.Errorf("schema '%s': %w", ref, fs.ErrNotExist)
+		}
+		delete(data.Schemas, ref)
+		return nil
+	})
+}
+
+func (s *singleFileStore) MoveSchema(oldRef, newRef string) error {
+	return s.update(func(data *singleFileData) error {
+		content, ok := data.Schemas[oldRef]
+		if !ok {
+			return fmt.Errorf("schema '%s': %w", oldRef, fs.ErrNotExist)
+		}
+		data.Schemas[newRef] = content
+		delete(data.Schemas, oldRef)
+		return nil
+	})
+}
+
+func (s *singleFileStore) SchemaRefs() ([]string, error) {
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(data.Schemas)), nil
+}
+
+func (s *singleFileStore) ReadDocument(name string) ([]byte, error) {
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	document, ok := data.Documents[name]
+	if !ok {
+		return nil, fmt.Errorf("document '%s': %w", name, fs.ErrNotExist)
+	}
+	return document, nil
+}
+
+func (s *singleFileStore) WriteDocument(name string, document []byte) error {
+	return s.update(func(data *singleFileData) error {
+		data.Documents[name] = json.RawMessage(slices.Clone(document))
+		return nil
+	})
+}
+
+func (s *singleFileStore) WithLock(name string, fn func() error) error {
+	return withFileLock(s.path+".locks", name, fn)
+}
+
+func (s *singleFileStore) IndexDir() string {
+	return s.path + ".indexes"
+}
@@ -0,0 +1,74 @@
+package library
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tonysyu/gqlxp/gql/introspection"
+)
+
+// schemaFileExtensions are the extensions of the SDL files read from a source directory.
+var schemaFileExtensions = []string{".graphqls", ".graphql"}
+
+// SourceFiles returns the files making up a schema source: the file itself, or the
+// .graphqls and .graphql files directly inside a source directory, sorted by name.
+func SourceFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && slices.Contains(schemaFileExtensions, ext) {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .graphqls or .graphql files in %s", path)
+	}
+	return files, nil
+}
+
+// ReadSourceFile reads a schema source file as SDL, converting introspection JSON. A
+// directory is read as a multi-file schema by joining its SDL files.
+func ReadSourceFile(path string) ([]byte, error) {
+	files, err := SourceFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+	if len(files) == 1 && files[0] == path {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema file: %w", err)
+		}
+		return introspection.EnsureSDL(content)
+	}
+
+	parts := make([][]byte, len(files))
+	for i, file := range files {
+		if parts[i], err = os.ReadFile(file); err != nil {
+			return nil, fmt.Errorf("failed to read schema file: %w", err)
+		}
+		parts[i] = bytes.TrimSpace(parts[i])
+	}
+	return append(bytes.Join(parts, []byte("\n\n")), '\n'), nil
+}
+
+// IsSourceDir reports whether path is a directory, and so a multi-file schema source.
+func IsSourceDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
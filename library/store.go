@@ -0,0 +1,130 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Store persists the schemas and state of a library. Schemas are stored by reference: a
+// schema ID, or <id>@<env> for an environment snapshot. State is kept in small named JSON
+// documents such as the schema metadata and the user configuration.
+type Store interface {
+	// ReadSchema returns the content of a schema reference, or an error wrapping
+	// fs.ErrNotExist if it is not stored.
+	ReadSchema(ref string) ([]byte, error)
+
+	// WriteSchema stores the content of a schema reference, replacing any previous content.
+	WriteSchema(ref string, content []byte) error
+
+	// DeleteSchema deletes the content of a schema reference, returning an error wrapping
+	// fs.ErrNotExist if it is not stored.
+	DeleteSchema(ref string) error
+
+	// MoveSchema moves the content of a schema reference to another reference.
+	MoveSchema(oldRef, newRef string) error
+
+	// SchemaRefs returns the stored schema references, sorted.
+	SchemaRefs() ([]string, error)
+
+	// ReadDocument returns a state document, or an error wrapping fs.ErrNotExist if there
+	// is none.
+	ReadDocument(name string) ([]byte, error)
+
+	// WriteDocument replaces a state document atomically.
+	WriteDocument(name string, data []byte) error
+
+	// WithLock calls fn while holding the named lock. Locks exclude other holders in the
+	// process and, for stores on disk, in other gqlxp processes using the same store.
+	WithLock(name string, fn func() error) error
+
+	// IndexDir returns the directory search indexes are stored in, or an empty string to
+	// keep them in memory.
+	IndexDir() string
+}
+
+// Names of the state documents.
+const (
+	metadataDocument    = "metadata"
+	configDocument      = "config"
+	sharedStateDocument = "shared-state"
+)
+
+// HomeEnvVar is the environment variable selecting where the library is stored: a
+// directory for the file store, a .json file for the single-file store, or MemoryHome for
+// an in-memory library. It defaults to the platform config directory.
+const HomeEnvVar = "GQLXP_HOME"
+
+// MemoryHome is the HomeEnvVar value selecting an in-memory library that is discarded when
+// the process exits.
+const MemoryHome = ":memory:"
+
+// Storage backends that can be selected by the "storage" setting of config.json.
+const (
+	StorageFile       = "file"
+	StorageSingleFile = "single-file"
+	StorageMemory     = "memory"
+)
+
+// singleFileName is the file of the single-file store selected by config.json.
+const singleFileName = "library.json"
+
+// OpenStore opens the store selected by HomeEnvVar or, within the config directory, by the
+// "storage" setting of config.json.
+func OpenStore() (Store, error) {
+	home := os.Getenv(HomeEnvVar)
+	switch {
+	case home == MemoryHome:
+		return NewMemoryStore(), nil
+	case home != "":
+		if isSingleFile(home) {
+			return NewSingleFileStore(home), nil
+		}
+		return NewFileStore(home), nil
+	}
+
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	store := NewFileStore(dir)
+	config, err := readUserConfig(store)
+	if err != nil {
+		return nil, err
+	}
+	switch config.Storage {
+	case "", StorageFile:
+		return store, nil
+	case StorageSingleFile:
+		return NewSingleFileStore(filepath.Join(dir, singleFileName)), nil
+	case StorageMemory:
+		return NewMemoryStore(), nil
+	}
+	return nil, fmt.Errorf("unknown storage '%s' in config.json: must be %s, %s, or %s",
+		config.Storage, StorageFile, StorageSingleFile, StorageMemory)
+}
+
+// isSingleFile reports whether a library home is a single-file store: an existing file, or
+// a path ending in .json.
+func isSingleFile(home string) bool {
+	if info, err := os.Stat(home); err == nil {
+		return !info.IsDir()
+	}
+	return strings.EqualFold(filepath.Ext(home), ".json")
+}
+
+// errStore is a store that could not be opened; every operation fails with the reason.
+type errStore struct {
+	err error
+}
+
+func (s errStore) ReadSchema(string) ([]byte, error)   { return nil, s.err }
+func (s errStore) WriteSchema(string, []byte) error    { return s.err }
+func (s errStore) DeleteSchema(string) error           { return s.err }
+func (s errStore) MoveSchema(string, string) error     { return s.err }
+func (s errStore) SchemaRefs() ([]string, error)       { return nil, s.err }
+func (s errStore) ReadDocument(string) ([]byte, error) { return nil, s.err }
+func (s errStore) WriteDocument(string, []byte) error  { return s.err }
+func (s errStore) WithLock(string, func() error) error { return s.err }
+func (s errStore) IndexDir() string                    { return "" }
@@ -0,0 +1,105 @@
+package library_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+// testStoreLibrary exercises a library in store: adding, renaming, searching, and
+// configuring schemas.
+func testStoreLibrary(t *testing.T, store library.Store) {
+	t.Helper()
+	is := is.New(t)
+
+	lib := library.NewLibraryWithStore(store)
+	is.NoErr(lib.AddFromContent("api", "API", []byte(`type Query { invoice: Invoice } type Invoice { id: ID! }`), ""))
+	is.NoErr(lib.AddFromContent("users", "Users", []byte(`type Query { user: ID }`), ""))
+	is.NoErr(lib.SetDefaultSchema("api"))
+	is.NoErr(lib.Rename("api", "billing"))
+	is.NoErr(lib.WaitForIndexing())
+
+	schemas, err := lib.List()
+	is.NoErr(err)
+	is.Equal(len(schemas), 2)
+	is.Equal(schemas[0].ID, "billing")
+	is.Equal(schemas[0].DisplayName, "API")
+	defaultID, err := lib.GetDefaultSchema()
+	is.NoErr(err)
+	is.Equal(defaultID, "billing")
+
+	response, err := lib.Search(nil, "Invoice", 10)
+	is.NoErr(err)
+	results := response.Results
+	is.True(len(results) > 0)
+	for _, result := range results {
+		is.Equal(result.SchemaID, "billing")
+	}
+
+	is.NoErr(lib.Remove("users"))
+	_, err = lib.Get("users")
+	is.True(err != nil)
+
+	problems, err := library.Diagnose(lib)
+	is.NoErr(err)
+	is.Equal(len(problems), 0)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStoreLibrary(t, library.NewMemoryStore())
+}
+
+func TestSingleFileStore(t *testing.T) {
+	is := is.New(t)
+	path := filepath.Join(t.TempDir(), "library.json")
+	testStoreLibrary(t, library.NewSingleFileStore(path))
+
+	// The library persists in the file
+	lib := library.NewLibraryWithStore(library.NewSingleFileStore(path))
+	schema, err := lib.Get("billing")
+	is.NoErr(err)
+	is.Equal(schema.Metadata.DisplayName, "API")
+	_, err = os.Stat(path)
+	is.NoErr(err)
+}
+
+func TestFileStore(t *testing.T) {
+	testStoreLibrary(t, library.NewFileStore(t.TempDir()))
+}
+
+func TestOpenStore(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	// GQLXP_HOME selects the store
+	t.Setenv(library.HomeEnvVar, library.MemoryHome)
+	lib := library.NewLibrary()
+	is.NoErr(lib.AddFromContent("api", "API", []byte(`type Query { a: ID }`), ""))
+	_, err := library.NewLibrary().Get("api")
+	is.True(err != nil) // each in-memory library starts empty
+
+	home := filepath.Join(t.TempDir(), "gqlxp.json")
+	t.Setenv(library.HomeEnvVar, home)
+	is.NoErr(library.NewLibrary().AddFromContent("api", "API", []byte(`type Query { a: ID }`), ""))
+	_, err = library.NewLibraryWithStore(library.NewSingleFileStore(home)).Get("api")
+	is.NoErr(err)
+
+	// Without GQLXP_HOME, the storage setting of config.json selects the store
+	t.Setenv(library.HomeEnvVar, "")
+	dir, err := library.SchemasDir()
+	is.NoErr(err)
+	configFile := filepath.Join(filepath.Dir(dir), "config.json")
+	is.NoErr(os.MkdirAll(filepath.Dir(configFile), 0755))
+	is.NoErr(os.WriteFile(configFile, []byte(`{"storage": "single-file"}`), 0644))
+	is.NoErr(library.NewLibrary().AddFromContent("users", "Users", []byte(`type Query { b: ID }`), ""))
+	_, err = os.Stat(filepath.Join(filepath.Dir(dir), "library.json"))
+	is.NoErr(err)
+
+	is.NoErr(os.WriteFile(configFile, []byte(`{"storage": "cloud"}`), 0644))
+	_, err = library.OpenStore()
+	is.True(err != nil) // unknown storage
+}
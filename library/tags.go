@@ -0,0 +1,76 @@
+package library
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// ValidateTag checks that tag can be assigned to a schema.
+func ValidateTag(tag string) error {
+	if !tagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid tag '%s': must contain only lowercase letters, numbers, and hyphens", tag)
+	}
+	return nil
+}
+
+// HasTag reports whether the schema has the given tag.
+func (m SchemaMetadata) HasTag(tag string) bool {
+	return slices.Contains(m.Tags, tag)
+}
+
+// AddTags adds tags to the schema, keeping its tags sorted and unique.
+func (m *SchemaMetadata) AddTags(tags ...string) {
+	for _, tag := range tags {
+		if !m.HasTag(tag) {
+			m.Tags = append(m.Tags, tag)
+		}
+	}
+	sort.Strings(m.Tags)
+}
+
+// RemoveTags removes tags from the schema. Tags it doesn't have are ignored.
+func (m *SchemaMetadata) RemoveTags(tags ...string) {
+	m.Tags = slices.DeleteFunc(m.Tags, func(tag string) bool {
+		return slices.Contains(tags, tag)
+	})
+	if len(m.Tags) == 0 {
+		m.Tags = nil
+	}
+}
+
+// HasTag reports whether the schema has the given tag.
+func (i SchemaInfo) HasTag(tag string) bool {
+	return slices.Contains(i.Tags, tag)
+}
+
+// FilterByTag returns the schemas that have the given tag.
+func FilterByTag(schemas []SchemaInfo, tag string) []SchemaInfo {
+	var filtered []SchemaInfo
+	for _, schema := range schemas {
+		if schema.HasTag(tag) {
+			filtered = append(filtered, schema)
+		}
+	}
+	return filtered
+}
+
+// SchemaIDsWithTag returns the IDs of the library schemas that have the given tag, or an
+// error if there are none.
+func SchemaIDsWithTag(lib Library, tag string) ([]string, error) {
+	schemas, err := lib.List()
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, schema := range FilterByTag(schemas, tag) {
+		ids = append(ids, schema.ID)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no schemas tagged '%s'", tag)
+	}
+	return ids, nil
+}
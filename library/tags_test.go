@@ -0,0 +1,64 @@
+package library_test
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+func TestValidateTag(t *testing.T) {
+	is := is.New(t)
+
+	is.NoErr(library.ValidateTag("payments"))
+	is.NoErr(library.ValidateTag("team-2"))
+	is.True(library.ValidateTag("Payments") != nil)
+	is.True(library.ValidateTag("a b") != nil)
+	is.True(library.ValidateTag("") != nil)
+}
+
+func TestSchemaMetadata_AddRemoveTags(t *testing.T) {
+	is := is.New(t)
+
+	var metadata library.SchemaMetadata
+	metadata.AddTags("payments", "core")
+	metadata.AddTags("core", "billing")
+	is.Equal(metadata.Tags, []string{"billing", "core", "payments"}) // sorted and unique
+	is.True(metadata.HasTag("core"))
+
+	metadata.RemoveTags("core", "unknown")
+	is.Equal(metadata.Tags, []string{"billing", "payments"})
+
+	metadata.RemoveTags("billing", "payments")
+	is.Equal(metadata.Tags, nil)
+}
+
+func TestLibrary_TagsAndDescription(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	is.NoErr(lib.AddFromContent("billing", "Billing", []byte(`type Query { invoice: ID }`), "billing.graphqls"))
+	is.NoErr(lib.AddFromContent("users", "Users", []byte(`type Query { user: ID }`), "users.graphqls"))
+
+	schema, err := lib.Get("billing")
+	is.NoErr(err)
+	schema.Metadata.AddTags("payments")
+	schema.Metadata.Description = "Invoices and payments"
+	is.NoErr(lib.UpdateMetadata("billing", schema.Metadata))
+
+	schemas, err := lib.List()
+	is.NoErr(err)
+	tagged := library.FilterByTag(schemas, "payments")
+	is.Equal(len(tagged), 1)
+	is.Equal(tagged[0].ID, "billing")
+	is.Equal(tagged[0].Description, "Invoices and payments")
+
+	ids, err := library.SchemaIDsWithTag(lib, "payments")
+	is.NoErr(err)
+	is.Equal(ids, []string{"billing"})
+
+	_, err = library.SchemaIDsWithTag(lib, "unknown")
+	is.True(err != nil) // no schemas have the tag
+}
@@ -11,6 +11,14 @@ type SchemaMetadata struct {
 	URLPatterns map[string]string `json:"urlPatterns"`
 	CreatedAt   time.Time         `json:"createdAt"`
 	UpdatedAt   time.Time         `json:"updatedAt"`
+	// Connection holds request settings for SourceURL; nil uses the defaults.
+	Connection *ConnectionProfile `json:"connection,omitempty"`
+	// Environments are named deployments of the schema, each with its own snapshot.
+	Environments map[string]Environment `json:"environments,omitempty"`
+	// Tags group related schemas, e.g. by team or domain; kept sorted and unique.
+	Tags []string `json:"tags,omitempty"`
+	// Description is free text describing the schema.
+	Description string `json:"description,omitempty"`
 }
 
 // Schema represents a stored schema with its content and metadata.
@@ -18,6 +26,8 @@ type Schema struct {
 	ID       string
 	Content  []byte
 	Metadata SchemaMetadata
+	// Provenance tells whether the schema comes from the personal or the shared library.
+	Provenance Provenance
 }
 
 // SchemaInfo represents basic schema information for listing.
@@ -25,9 +35,22 @@ type SchemaInfo struct {
 	ID          string
 	DisplayName string
 	UpdatedAt   time.Time
+	// Environments lists the schema's environments sorted by name.
+	Environments []EnvironmentInfo
+	Tags         []string
+	Description  string
+	Provenance   Provenance
 }
 
 // UserConfig contains user preferences and settings.
 type UserConfig struct {
 	DefaultSchema string `json:"defaultSchema,omitempty"`
+	// SharedLibrary is the directory of a read-only shared library mounted alongside the
+	// personal one.
+	SharedLibrary string `json:"sharedLibrary,omitempty"`
+	// StaleAfterDays is the age after which schemas are flagged as stale; 0 disables it.
+	StaleAfterDays int `json:"staleAfterDays,omitempty"`
+	// Storage selects the storage backend of the library; read from the config directory
+	// only. See OpenStore.
+	Storage string `json:"storage,omitempty"`
 }
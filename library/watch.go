@@ -0,0 +1,151 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tonysyu/gqlxp/gql"
+)
+
+// DefaultWatchInterval is how often watched source files are checked for changes.
+const DefaultWatchInterval = 500 * time.Millisecond
+
+// SourceChange is a change to the source file of a watched schema. Content is the new
+// schema content, already saved to the library, or Err is why the change could not be
+// loaded (for example, a schema that does not parse while it is being edited).
+type SourceChange struct {
+	ID      string
+	Content []byte
+	Err     error
+}
+
+// SourceWatcher watches the source file, or the files of a source directory, of a library
+// schema and updates the library when they change.
+type SourceWatcher struct {
+	lib      Library
+	id       string
+	path     string
+	interval time.Duration
+	done     chan struct{}
+	// stamps are the modification times and sizes of the source files last seen
+	stamps map[string]fileStamp
+}
+
+// fileStamp identifies a version of a file without reading it.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewSourceWatcher creates a watcher for the source file of schema id. It returns
+// ErrNoSource if the schema was not loaded from a local file.
+func NewSourceWatcher(lib Library, id string) (*SourceWatcher, error) {
+	schema, err := lib.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Metadata.SourceFile == "" || schema.Provenance == ProvenanceShared {
+		return nil, ErrNoSource
+	}
+	w := &SourceWatcher{
+		lib:      lib,
+		id:       id,
+		path:     schema.Metadata.SourceFile,
+		interval: DefaultWatchInterval,
+		done:     make(chan struct{}),
+	}
+	w.stamps = w.stat()
+	return w, nil
+}
+
+// Path returns the watched source file or directory.
+func (w *SourceWatcher) Path() string {
+	return w.path
+}
+
+// Close stops the watcher, making pending and later calls to Next return false. It must
+// be called at most once.
+func (w *SourceWatcher) Close() {
+	close(w.done)
+}
+
+// Next blocks until the source changes and returns the change, or returns false once the
+// watcher is closed. Changed content is saved to the library and reindexed in the background;
+// changes that leave the content as stored in the library are ignored.
+func (w *SourceWatcher) Next() (SourceChange, bool) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return SourceChange{}, false
+		case <-ticker.C:
+		}
+
+		stamps := w.stat()
+		if sameStamps(stamps, w.stamps) {
+			continue
+		}
+		w.stamps = stamps
+		if change, changed := w.load(); changed {
+			return change, true
+		}
+	}
+}
+
+// load reads the source and saves it to the library if its content changed.
+func (w *SourceWatcher) load() (SourceChange, bool) {
+	change := SourceChange{ID: w.id}
+	content, err := ReadSourceFile(w.path)
+	if err != nil {
+		change.Err = err
+		return change, true
+	}
+	schema, err := w.lib.Get(w.id)
+	if err != nil {
+		change.Err = err
+		return change, true
+	}
+	if CalculateFileHash(content) == schema.Metadata.FileHash {
+		return change, false
+	}
+	if _, err := gql.ParseSchema(content); err != nil {
+		change.Err = fmt.Errorf("invalid GraphQL schema in %s: %w", w.path, err)
+		return change, true
+	}
+	if err := w.lib.UpdateContent(w.id, content); err != nil {
+		change.Err = err
+		return change, true
+	}
+	change.Content = content
+	return change, true
+}
+
+// stat returns the stamps of the source files. Files that cannot be read are left out, so
+// that they count as changed when they reappear.
+func (w *SourceWatcher) stat() map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	files, err := SourceFiles(w.path)
+	if err != nil {
+		return stamps
+	}
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			stamps[file] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps
+}
+
+func sameStamps(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for file, stamp := range a {
+		if other, ok := b[file]; !ok || !other.modTime.Equal(stamp.modTime) || other.size != stamp.size {
+			return false
+		}
+	}
+	return true
+}
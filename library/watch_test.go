@@ -0,0 +1,99 @@
+package library_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/library"
+)
+
+// nextChange waits for the next change seen by w, failing the test if none is seen in time.
+func nextChange(t *testing.T, w *library.SourceWatcher) library.SourceChange {
+	t.Helper()
+	changes := make(chan library.SourceChange, 1)
+	go func() {
+		change, _ := w.Next()
+		changes <- change
+	}()
+	select {
+	case change := <-changes:
+		return change
+	case <-time.After(5 * time.Second):
+		w.Close()
+		t.Fatal("no change seen")
+		return library.SourceChange{}
+	}
+}
+
+func TestSourceWatcher(t *testing.T) {
+	is := is.New(t)
+	tmpDir, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	sourceFile := filepath.Join(tmpDir, "api.graphqls")
+	is.NoErr(os.WriteFile(sourceFile, []byte(`type Query { a: ID }`), 0644))
+	indexer := newMockIndexer()
+	lib := library.NewLibraryWithIndexer(indexer)
+	is.NoErr(lib.Add("api", "API", sourceFile))
+	lib.WaitForIndexing()
+	indexer.indexed = make(map[string]bool)
+
+	w, err := library.NewSourceWatcher(lib, "api")
+	is.NoErr(err)
+	w.SetInterval(10 * time.Millisecond)
+
+	// Invalid SDL while editing is reported without updating the library
+	is.NoErr(os.WriteFile(sourceFile, []byte(`type Query {`), 0644))
+	change := nextChange(t, w)
+	is.True(change.Err != nil)
+
+	is.NoErr(os.WriteFile(sourceFile, []byte(`type Query { a: ID b: ID }`), 0644))
+	change = nextChange(t, w)
+	is.NoErr(change.Err)
+	is.Equal(string(change.Content), `type Query { a: ID b: ID }`)
+	lib.WaitForIndexing()
+
+	schema, err := lib.Get("api")
+	is.NoErr(err)
+	is.Equal(string(schema.Content), `type Query { a: ID b: ID }`)
+	is.Equal(indexer.indexed, map[string]bool{"api": true})
+
+	// A closed watcher stops waiting
+	w.Close()
+	_, ok := w.Next()
+	is.True(!ok)
+}
+
+func TestSourceWatcher_NoSourceFile(t *testing.T) {
+	is := is.New(t)
+	_, cleanup := setupTestLibrary(t)
+	defer cleanup()
+
+	lib := library.NewLibraryWithIndexer(newMockIndexer())
+	is.NoErr(lib.AddFromContent("api", "API", []byte(`type Query { a: ID }`), "https://example.com/graphql"))
+
+	_, err := library.NewSourceWatcher(lib, "api")
+	is.Equal(err, library.ErrNoSource)
+}
+
+func TestReadSourceFile_Directory(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	is.NoErr(os.WriteFile(filepath.Join(dir, "b.graphqls"), []byte("type User { id: ID }\n"), 0644))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "a.graphql"), []byte("type Query { user: User }\n"), 0644))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "notes.md"), []byte("# Notes"), 0644))
+
+	files, err := library.SourceFiles(dir)
+	is.NoErr(err)
+	is.Equal(files, []string{filepath.Join(dir, "a.graphql"), filepath.Join(dir, "b.graphqls")})
+
+	content, err := library.ReadSourceFile(dir)
+	is.NoErr(err)
+	is.Equal(string(content), "type Query { user: User }\n\ntype User { id: ID }\n")
+
+	_, err = library.ReadSourceFile(t.TempDir())
+	is.True(err != nil) // no schema files
+}
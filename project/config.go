@@ -0,0 +1,285 @@
+// Package project discovers project-local configuration: gqlxp's own .gqlxp.yaml and the
+// standard graphql-config formats (.graphqlrc and graphql.config.*).
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileNames lists the config files searched for in each directory, in priority order.
+// YAML is a superset of JSON, so all formats are parsed as YAML.
+var fileNames = []string{
+	".gqlxp.yaml",
+	".gqlxp.yml",
+	".graphqlrc",
+	".graphqlrc.yaml",
+	".graphqlrc.yml",
+	".graphqlrc.json",
+	"graphql.config.yaml",
+	"graphql.config.yml",
+	"graphql.config.json",
+}
+
+// unsupportedFileNames are graphql-config formats that require a JavaScript or TOML runtime.
+var unsupportedFileNames = []string{
+	".graphqlrc.js",
+	".graphqlrc.ts",
+	".graphqlrc.toml",
+	"graphql.config.js",
+	"graphql.config.cjs",
+	"graphql.config.mjs",
+	"graphql.config.ts",
+	"graphql.config.toml",
+}
+
+// defaultProjectName is the project used when a config declares several projects.
+const defaultProjectName = "default"
+
+// Config is a project configuration. Relative paths are resolved from the directory of
+// the config file.
+type Config struct {
+	// Path is the config file this configuration was loaded from.
+	Path string
+	// Schema lists schema sources: library IDs (optionally <id>@<env>), files, globs, or URLs.
+	Schema []SchemaPointer
+	// Documents are glob patterns matching the project's operation documents.
+	Documents []string
+	// Projects are named sub-projects (graphql-config multi-project format).
+	Projects map[string]*Config
+}
+
+// SchemaPointer is a schema source with optional request headers for URL sources.
+type SchemaPointer struct {
+	Pointer string
+	Headers map[string]string
+}
+
+// rawConfig mirrors the file format. Scalar mappings and lint rules, at the top level or
+// under extensions.gqlxp, are only decoded to be rejected.
+type rawConfig struct {
+	Schema     yaml.Node             `yaml:"schema"`
+	Documents  yaml.Node             `yaml:"documents"`
+	Scalars    yaml.Node             `yaml:"scalars"`
+	Lint       yaml.Node             `yaml:"lint"`
+	Extensions rawExtensions         `yaml:"extensions"`
+	Projects   map[string]*rawConfig `yaml:"projects"`
+}
+
+type rawExtensions struct {
+	Gqlxp struct {
+		Scalars yaml.Node `yaml:"scalars"`
+		Lint    yaml.Node `yaml:"lint"`
+	} `yaml:"gqlxp"`
+}
+
+// checkSupported rejects gqlxp settings that no command applies yet, so that they are not
+// silently ignored.
+func (r *rawConfig) checkSupported() error {
+	unsupported := []struct {
+		name string
+		node *yaml.Node
+	}{
+		{"scalars", &r.Scalars},
+		{"lint", &r.Lint},
+		{"extensions.gqlxp.scalars", &r.Extensions.Gqlxp.Scalars},
+		{"extensions.gqlxp.lint", &r.Extensions.Gqlxp.Lint},
+	}
+	for _, setting := range unsupported {
+		if setting.node.Kind != 0 {
+			return fmt.Errorf("'%s' is not supported by gqlxp: remove it from the config", setting.name)
+		}
+	}
+	return nil
+}
+
+// Discover searches dir and its parents for a project config file. It returns nil if none
+// is found. Config files in unsupported formats, which may belong to directories the user
+// doesn't control, are passed over and returned as skipped.
+func Discover(dir string) (cfg *Config, skipped []string, err error) {
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	for {
+		for _, name := range fileNames {
+			path := filepath.Join(dir, name)
+			if isFile(path) {
+				cfg, err := Load(path)
+				return cfg, skipped, err
+			}
+		}
+		for _, name := range unsupportedFileNames {
+			path := filepath.Join(dir, name)
+			if isFile(path) {
+				skipped = append(skipped, path)
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, skipped, nil
+		}
+		dir = parent
+	}
+}
+
+// IsConfigFile reports whether name is the file name of a supported project config.
+func IsConfigFile(name string) bool {
+	return slices.Contains(fileNames, name)
+}
+
+// Load reads a project config file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config: %w", err)
+	}
+	var raw rawConfig
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse project config '%s': %w", path, err)
+	}
+	cfg, err := raw.toConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project config '%s': %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (r *rawConfig) toConfig(path string) (*Config, error) {
+	if err := r.checkSupported(); err != nil {
+		return nil, err
+	}
+	schema, err := parseSchemaPointers(&r.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("schema: %w", err)
+	}
+	documents, err := parseStrings(&r.Documents)
+	if err != nil {
+		return nil, fmt.Errorf("documents: %w", err)
+	}
+	cfg := &Config{
+		Path:      path,
+		Schema:    schema,
+		Documents: documents,
+	}
+	if len(r.Projects) > 0 {
+		cfg.Projects = make(map[string]*Config, len(r.Projects))
+		for name, rawProject := range r.Projects {
+			project, err := rawProject.toConfig(path)
+			if err != nil {
+				return nil, fmt.Errorf("project '%s': %w", name, err)
+			}
+			cfg.Projects[name] = project
+		}
+	}
+	return cfg, nil
+}
+
+// Project returns the named project. An empty name selects the top-level configuration
+// if it declares a schema, and otherwise the project named "default".
+func (c *Config) Project(name string) (*Config, error) {
+	if name == "" {
+		if len(c.Schema) > 0 || len(c.Projects) == 0 {
+			return c, nil
+		}
+		name = defaultProjectName
+	}
+	project, ok := c.Projects[name]
+	if !ok {
+		return nil, fmt.Errorf("project '%s' not found in %s. Available: %s", name, c.Path, strings.Join(c.ProjectNames(), ", "))
+	}
+	return project, nil
+}
+
+// ProjectNames returns the names of the config's projects in sorted order.
+func (c *Config) ProjectNames() []string {
+	names := make([]string, 0, len(c.Projects))
+	for name := range c.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Dir returns the directory that relative paths are resolved from.
+func (c *Config) Dir() string {
+	return filepath.Dir(c.Path)
+}
+
+// ResolvePath returns path relative to the config directory, unless it is absolute.
+func (c *Config) ResolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.Dir(), path)
+}
+
+// parseSchemaPointers accepts the graphql-config schema forms: a string, a list of strings,
+// or pointers mapped to options such as headers (either as a map or as a list of maps).
+func parseSchemaPointers(node *yaml.Node) ([]SchemaPointer, error) {
+	switch node.Kind {
+	case 0:
+		return nil, nil
+	case yaml.ScalarNode:
+		return []SchemaPointer{{Pointer: node.Value}}, nil
+	case yaml.MappingNode:
+		return parsePointerMap(node)
+	case yaml.SequenceNode:
+		var pointers []SchemaPointer
+		for _, item := range node.Content {
+			parsed, err := parseSchemaPointers(item)
+			if err != nil {
+				return nil, err
+			}
+			pointers = append(pointers, parsed...)
+		}
+		return pointers, nil
+	default:
+		return nil, errors.New("expected a string, list, or map")
+	}
+}
+
+func parsePointerMap(node *yaml.Node) ([]SchemaPointer, error) {
+	var options map[string]struct {
+		Headers map[string]string `yaml:"headers"`
+	}
+	if err := node.Decode(&options); err != nil {
+		return nil, err
+	}
+	var pointers []SchemaPointer
+	// Mapping keys are decoded in document order so that the first source stays first
+	for i := 0; i < len(node.Content); i += 2 {
+		pointer := node.Content[i].Value
+		pointers = append(pointers, SchemaPointer{Pointer: pointer, Headers: options[pointer].Headers})
+	}
+	return pointers, nil
+}
+
+// parseStrings accepts a string or a list of strings.
+func parseStrings(node *yaml.Node) ([]string, error) {
+	switch node.Kind {
+	case 0:
+		return nil, nil
+	case yaml.ScalarNode:
+		return []string{node.Value}, nil
+	default:
+		var values []string
+		if err := node.Decode(&values); err != nil {
+			return nil, errors.New("expected a string or a list of strings")
+		}
+		return values, nil
+	}
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
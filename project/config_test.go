@@ -0,0 +1,215 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/project"
+)
+
+// writeFiles creates files (relative path -> content) under a temporary directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDiscover_WalksUpFromWorkingDirectory(t *testing.T) {
+	is := is.New(t)
+	dir := writeFiles(t, map[string]string{
+		".gqlxp.yaml":       "schema: github\n",
+		"src/app/main.go":   "",
+		".graphqlrc.yml":    "schema: ignored\n", // .gqlxp.yaml takes priority
+		"other/.graphqlrc":  "schema: other\n",
+		"other/nested/x.go": "",
+	})
+
+	cfg, _, err := project.Discover(filepath.Join(dir, "src", "app"))
+
+	is.NoErr(err)
+	is.Equal(cfg.Path, filepath.Join(dir, ".gqlxp.yaml"))
+	is.Equal(cfg.Schema, []project.SchemaPointer{{Pointer: "github"}})
+
+	cfg, _, err = project.Discover(filepath.Join(dir, "other", "nested"))
+
+	is.NoErr(err)
+	is.Equal(cfg.Schema, []project.SchemaPointer{{Pointer: "other"}}) // nearest config wins
+}
+
+func TestDiscover_NoConfig(t *testing.T) {
+	is := is.New(t)
+
+	cfg, _, err := project.Discover(t.TempDir())
+
+	is.NoErr(err)
+	is.Equal(cfg, nil)
+}
+
+func TestDiscover_UnsupportedFormat(t *testing.T) {
+	is := is.New(t)
+	dir := writeFiles(t, map[string]string{
+		".gqlxp.yaml":             "schema: github\n",
+		"app/graphql.config.ts":   "export default {}",
+		"app/src/.graphqlrc.toml": "schema = 'x'",
+		"other/graphql.config.ts": "export default {}",
+	})
+
+	cfg, skipped, err := project.Discover(filepath.Join(dir, "app", "src"))
+
+	is.NoErr(err)
+	is.Equal(cfg.Schema, []project.SchemaPointer{{Pointer: "github"}}) // search continues past unsupported configs
+	is.Equal(skipped, []string{
+		filepath.Join(dir, "app", "src", ".graphqlrc.toml"),
+		filepath.Join(dir, "app", "graphql.config.ts"),
+	})
+
+	cfg, skipped, err = project.Discover(filepath.Join(dir, "other"))
+
+	is.NoErr(err)
+	is.Equal(cfg.Schema, []project.SchemaPointer{{Pointer: "github"}})
+	is.Equal(skipped, []string{filepath.Join(dir, "other", "graphql.config.ts")}) // JavaScript configs can't be evaluated
+}
+
+func TestLoad_Formats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    project.Config
+	}{
+		{
+			name: "gqlxp yaml",
+			file: ".gqlxp.yaml",
+			content: `
+schema: api@staging
+documents: src/**/*.graphql
+`,
+			want: project.Config{
+				Schema:    []project.SchemaPointer{{Pointer: "api@staging"}},
+				Documents: []string{"src/**/*.graphql"},
+			},
+		},
+		{
+			name:    "graphqlrc json",
+			file:    ".graphqlrc.json",
+			content: `{"schema": ["schema.graphql"], "documents": ["a.graphql", "b.graphql"]}`,
+			want: project.Config{
+				Schema:    []project.SchemaPointer{{Pointer: "schema.graphql"}},
+				Documents: []string{"a.graphql", "b.graphql"},
+			},
+		},
+		{
+			name: "graphql config with headers and extensions",
+			file: "graphql.config.yaml",
+			content: `
+schema:
+  - https://example.com/graphql:
+      headers:
+        Authorization: Bearer ${env:TOKEN}
+extensions:
+  codegen:
+    generates: ./types.ts
+`,
+			want: project.Config{
+				Schema: []project.SchemaPointer{{
+					Pointer: "https://example.com/graphql",
+					Headers: map[string]string{"Authorization": "Bearer ${env:TOKEN}"},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			dir := writeFiles(t, map[string]string{tt.file: tt.content})
+
+			cfg, err := project.Load(filepath.Join(dir, tt.file))
+
+			is.NoErr(err)
+			tt.want.Path = filepath.Join(dir, tt.file)
+			is.Equal(*cfg, tt.want)
+		})
+	}
+}
+
+func TestLoad_UnsupportedSettings(t *testing.T) {
+	for _, content := range []string{
+		"schema: api\nscalars:\n  DateTime: string\n",
+		"schema: api\nlint:\n  no-deprecated: warn\n",
+		"schema: api\nextensions:\n  gqlxp:\n    scalars:\n      URI: string\n",
+		"projects:\n  default:\n    schema: api\n    lint:\n      no-deprecated: warn\n",
+	} {
+		t.Run(content, func(t *testing.T) {
+			is := is.New(t)
+			dir := writeFiles(t, map[string]string{".graphqlrc.yml": content})
+
+			_, err := project.Load(filepath.Join(dir, ".graphqlrc.yml"))
+
+			is.True(err != nil) // settings that no command applies are rejected
+			is.True(strings.Contains(err.Error(), "not supported"))
+		})
+	}
+}
+
+func TestConfig_Project(t *testing.T) {
+	is := is.New(t)
+	dir := writeFiles(t, map[string]string{"graphql.config.yml": `
+projects:
+  default:
+    schema: app.graphql
+  admin:
+    schema: admin.graphql
+`})
+	cfg, err := project.Load(filepath.Join(dir, "graphql.config.yml"))
+	is.NoErr(err)
+
+	defaultProject, err := cfg.Project("")
+	is.NoErr(err)
+	is.Equal(defaultProject.Schema[0].Pointer, "app.graphql")
+
+	admin, err := cfg.Project("admin")
+	is.NoErr(err)
+	is.Equal(admin.Schema[0].Pointer, "admin.graphql")
+	is.Equal(admin.Dir(), dir) // projects resolve paths from the config file
+
+	_, err = cfg.Project("missing")
+	is.True(err != nil)
+}
+
+func TestConfig_DocumentFiles(t *testing.T) {
+	is := is.New(t)
+	dir := writeFiles(t, map[string]string{
+		".gqlxp.yaml":                  "documents:\n  - src/**/*.{graphql,gql}\n  - extra.graphql\n",
+		"src/query.graphql":            "",
+		"src/users/list.gql":           "",
+		"src/users/deep/get.graphql":   "",
+		"src/users/readme.md":          "",
+		"extra.graphql":                "",
+		"node_modules/pkg/ignored.gql": "",
+	})
+	cfg, err := project.Load(filepath.Join(dir, ".gqlxp.yaml"))
+	is.NoErr(err)
+
+	files, err := cfg.DocumentFiles()
+
+	is.NoErr(err)
+	is.Equal(files, []string{
+		filepath.Join(dir, "extra.graphql"),
+		filepath.Join(dir, "src", "query.graphql"),
+		filepath.Join(dir, "src", "users", "deep", "get.graphql"),
+		filepath.Join(dir, "src", "users", "list.gql"),
+	})
+}
@@ -0,0 +1,142 @@
+package project
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DocumentFiles returns the files matching the Documents patterns, sorted and de-duplicated.
+func (c *Config) DocumentFiles() ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	for _, pattern := range c.Documents {
+		matches, err := c.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				files = append(files, m)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Glob returns the files matching pattern, resolved from the config directory. Besides the
+// filepath.Match syntax, patterns may use ** to match any number of directories and {a,b}
+// to match alternatives, as in "src/**/*.{graphql,gql}".
+func (c *Config) Glob(pattern string) ([]string, error) {
+	var files []string
+	for _, p := range expandBraces(filepath.ToSlash(pattern)) {
+		matches, err := globPattern(filepath.ToSlash(c.ResolvePath(p)))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// globPattern matches an absolute, slash-separated pattern against the filesystem.
+func globPattern(pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		matches, err := filepath.Glob(filepath.FromSlash(pattern))
+		if err != nil {
+			return nil, err
+		}
+		return filesOnly(matches), nil
+	}
+
+	// Walk from the longest directory prefix without wildcards
+	segments := strings.Split(pattern, "/")
+	rootEnd := 0
+	for rootEnd < len(segments) && !hasMeta(segments[rootEnd]) {
+		rootEnd++
+	}
+	root := filepath.FromSlash(strings.Join(segments[:rootEnd], "/"))
+	if root == "" {
+		root = "/"
+	}
+	rest := segments[rootEnd:]
+
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return fs.SkipAll // a missing root has no matches
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		if matchSegments(rest, strings.Split(filepath.ToSlash(rel), "/")) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// matchSegments matches path segments against pattern segments, where ** matches zero or
+// more segments.
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	ok, err := filepath.Match(pattern[0], path[0])
+	return err == nil && ok && matchSegments(pattern[1:], path[1:])
+}
+
+// expandBraces expands {a,b} alternatives into separate patterns.
+func expandBraces(pattern string) []string {
+	start := strings.Index(pattern, "{")
+	if start < 0 {
+		return []string{pattern}
+	}
+	end := strings.Index(pattern[start:], "}")
+	if end < 0 {
+		return []string{pattern}
+	}
+	end += start
+	var patterns []string
+	for _, alt := range strings.Split(pattern[start+1:end], ",") {
+		patterns = append(patterns, expandBraces(pattern[:start]+alt+pattern[end+1:])...)
+	}
+	return patterns
+}
+
+func hasMeta(segment string) bool {
+	return strings.ContainsAny(segment, "*?[")
+}
+
+func filesOnly(paths []string) []string {
+	var files []string
+	for _, p := range paths {
+		if isFile(p) {
+			files = append(files, p)
+		}
+	}
+	return files
+}
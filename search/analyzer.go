@@ -0,0 +1,73 @@
+package search
+
+import (
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/token/camelcase"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/token/ngram"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/single"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+)
+
+// Analyzers of GraphQL names.
+const (
+	// nameAnalyzer splits names into their lowercase words, so that createPullRequestReview
+	// is indexed as "create", "pull", "request", and "review".
+	nameAnalyzer = "gqlName"
+	// nameNgramAnalyzer indexes the trigrams of a lowercase name, so that "requestrev",
+	// whose trigrams are all in createPullRequestReview, matches it.
+	nameNgramAnalyzer = "gqlNameNgram"
+	// nameExactAnalyzer indexes a lowercase name as a single token, for exact and prefix
+	// matches.
+	nameExactAnalyzer = "gqlNameExact"
+)
+
+// Index fields holding the name of a document as analyzed by nameNgramAnalyzer and
+// nameExactAnalyzer. They are only used for ranking and are not included in _all.
+const (
+	nameNgramField = "nameNgram"
+	nameExactField = "nameExact"
+)
+
+// nameNgramFilter produces the fragments indexed by nameNgramAnalyzer.
+const nameNgramFilter = "gqlNameNgram"
+
+// nameNgramLength is the length of the name fragments indexed by nameNgramAnalyzer.
+// Trigrams keep indexes small while rarely matching a name that doesn't contain the query.
+const nameNgramLength = 3
+
+// addNameAnalyzers registers the analyzers of GraphQL names with an index mapping.
+func addNameAnalyzers(indexMapping *mapping.IndexMappingImpl) error {
+	err := indexMapping.AddCustomTokenFilter(nameNgramFilter, map[string]any{
+		"type": ngram.Name,
+		"min":  nameNgramLength,
+		"max":  nameNgramLength,
+	})
+	if err != nil {
+		return err
+	}
+	analyzers := map[string]map[string]any{
+		nameAnalyzer: {
+			"type":          custom.Name,
+			"tokenizer":     unicode.Name,
+			"token_filters": []string{camelcase.Name, lowercase.Name},
+		},
+		nameNgramAnalyzer: {
+			"type":          custom.Name,
+			"tokenizer":     single.Name,
+			"token_filters": []string{lowercase.Name, nameNgramFilter},
+		},
+		nameExactAnalyzer: {
+			"type":          custom.Name,
+			"tokenizer":     single.Name,
+			"token_filters": []string{lowercase.Name},
+		},
+	}
+	for name, config := range analyzers {
+		if err := indexMapping.AddCustomAnalyzer(name, config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
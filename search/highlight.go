@@ -0,0 +1,65 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/blevesearch/bleve/v2/registry"
+	"github.com/blevesearch/bleve/v2/search/highlight"
+	"github.com/blevesearch/bleve/v2/search/highlight/format/plain"
+	simplefragmenter "github.com/blevesearch/bleve/v2/search/highlight/fragmenter/simple"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/simple"
+)
+
+// Markers around the terms of a fragment that matched a query.
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// highlighterName is the bleve highlighter marking matched terms with HighlightStart and
+// HighlightEnd. Unlike bleve's html highlighter, it leaves the rest of the text unescaped.
+const highlighterName = "gqlxp"
+
+// highlightFields are the fields whose matched terms are returned in SearchResult.Fragments.
+var highlightFields = []string{"name", "description", "signature"}
+
+func init() {
+	err := registry.RegisterHighlighter(highlighterName, func(config map[string]any, cache *registry.Cache) (highlight.Highlighter, error) {
+		fragmenter, err := cache.FragmenterNamed(simplefragmenter.Name)
+		if err != nil {
+			return nil, err
+		}
+		formatter := plain.NewFragmentFormatter(HighlightStart, HighlightEnd)
+		return simple.NewHighlighter(fragmenter, formatter, simple.DefaultSeparator), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// HighlightSegment is a part of a fragment, which matched the query if Match is set.
+type HighlightSegment struct {
+	Text  string
+	Match bool
+}
+
+// SplitHighlights splits a fragment into the terms that matched the query, marked by
+// HighlightStart and HighlightEnd, and the text around them.
+func SplitHighlights(fragment string) []HighlightSegment {
+	var segments []HighlightSegment
+	for fragment != "" {
+		before, rest, found := strings.Cut(fragment, HighlightStart)
+		if before != "" {
+			segments = append(segments, HighlightSegment{Text: before})
+		}
+		if !found {
+			break
+		}
+		match, after, _ := strings.Cut(rest, HighlightEnd)
+		if match != "" {
+			segments = append(segments, HighlightSegment{Text: match, Match: true})
+		}
+		fragment = after
+	}
+	return segments
+}
@@ -1,9 +1,15 @@
 package search
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/mapping"
@@ -16,12 +22,30 @@ type document struct {
 	Name        string   `json:"name"`        // Type or field name
 	Description string   `json:"description"` // Description text
 	Path        string   `json:"path"`        // Full path (e.g., "Query.user.name")
+	Parent      string   `json:"parent"`      // Type or directive a field, argument, or enum value belongs to
 	SchemaID    string   `json:"schemaID"`    // Schema identifier
 	Signature   string   `json:"signature"`   // Field signature (e.g., "getUser(id: ID!): User")
 	Usage       []string `json:"usage"`       // ParentKinds of types/fields that reference this type
 	Implements  []string `json:"implements"`  // Interface names this type implements (Object/Interface only)
+	Directives  []string `json:"directive"`   // Names of the directives applied to this type, field, argument, or enum value
+	Returns     string   `json:"returns"`     // Return type of a Query, Mutation, ObjectField, or InterfaceField
+	Accepts     []string `json:"accepts"`     // Argument types of a field or directive, or the type of an argument
 }
 
+// MappingVersion is the version of the index mapping and documents. Increase it whenever
+// they change, so that indexes built with an earlier mapping are detected as outdated.
+const MappingVersion = 6
+
+// mappingVersionKey is the internal index key storing the mapping version of an index.
+var mappingVersionKey = []byte("mappingVersion")
+
+// documentHashesKey is the internal index key storing the content hash of each document of
+// an index, by document ID, so that updates only reindex the documents that changed.
+var documentHashesKey = []byte("documentHashes")
+
+// TempIndexPrefix starts the names of the temporary directories indexes are built in.
+const TempIndexPrefix = ".tmp-"
+
 // BleveIndexer implements Indexer using Bleve
 type BleveIndexer struct {
 	baseDir string // Base directory for storing indexes
@@ -32,37 +56,169 @@ func NewIndexer(baseDir string) *BleveIndexer {
 	return &BleveIndexer{baseDir: baseDir}
 }
 
-// Index creates or updates the index for a schema
+// Index creates or updates the index for a schema. The index is built in a temporary
+// directory and then swapped in, so that searches never see a partially written index and
+// an interrupted build leaves the previous index in place.
 func (b *BleveIndexer) Index(schemaID string, schema *gql.GraphQLSchema) error {
 	indexPath := b.getIndexPath(schemaID)
 
-	// Remove existing index if it exists
-	if err := os.RemoveAll(indexPath); err != nil {
-		return fmt.Errorf("failed to remove existing index: %w", err)
+	if err := os.MkdirAll(b.baseDir, 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+	tempDir, err := os.MkdirTemp(b.baseDir, TempIndexPrefix+schemaID+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary index directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	builtPath := filepath.Join(tempDir, "index")
+	if err := buildIndex(builtPath, schemaID, schema); err != nil {
+		return err
+	}
+	return swapIndex(builtPath, indexPath, filepath.Join(tempDir, "previous"))
+}
+
+// Update brings the index for a schema up to date by reindexing only the documents that
+// were added, changed, or removed, which is much faster than Index for large schemas. A
+// missing or unreadable index, or one built with an earlier mapping version, is rebuilt
+// with Index instead.
+func (b *BleveIndexer) Update(schemaID string, schema *gql.GraphQLSchema) error {
+	index, err := bleve.Open(b.getIndexPath(schemaID))
+	if err != nil {
+		return b.Index(schemaID, schema)
+	}
+	if outdated, err := indexOutdated(index); err != nil || outdated {
+		index.Close()
+		return b.Index(schemaID, schema)
+	}
+	if err := populateIndex(index, schemaID, schema); err != nil {
+		index.Close()
+		return err
 	}
+	if err := index.Close(); err != nil {
+		return fmt.Errorf("failed to close index: %w", err)
+	}
+	return nil
+}
 
-	// Create new index
-	index, err := bleve.New(indexPath, buildIndexMapping())
+// buildIndex creates a new index of a schema at indexPath.
+func buildIndex(indexPath, schemaID string, schema *gql.GraphQLSchema) error {
+	indexMapping, err := buildIndexMapping()
+	if err != nil {
+		return err
+	}
+	index, err := bleve.New(indexPath, indexMapping)
 	if err != nil {
 		return fmt.Errorf("failed to create index: %w", err)
 	}
-	defer index.Close()
+	if err := populateIndex(index, schemaID, schema); err != nil {
+		index.Close()
+		return err
+	}
+
+	// The index must be closed before it is moved into place
+	if err := index.Close(); err != nil {
+		return fmt.Errorf("failed to close index: %w", err)
+	}
+	return nil
+}
+
+// populateIndex makes the documents of an index, new or built with the current mapping
+// version, match a schema. Only documents whose content hash differs from the one recorded
+// in the index are indexed, and documents no longer in the schema are deleted. The content
+// hashes and mapping version are recorded in the same batch, so an interrupted update
+// leaves the index as it was.
+func populateIndex(index bleve.Index, schemaID string, schema *gql.GraphQLSchema) error {
+	previousHashes, err := documentHashes(index)
+	if err != nil {
+		return err
+	}
 
-	// Extract and index documents
 	docs := extractDocuments(schemaID, schema)
+	hashes := make(map[string]string, len(docs))
 	batch := index.NewBatch()
-
-	for i, doc := range docs {
-		id := fmt.Sprintf("%s-%d", schemaID, i)
+	for _, doc := range docs {
+		id := schemaID + ":" + doc.Path
+		hash, err := documentHash(doc)
+		if err != nil {
+			return err
+		}
+		hashes[id] = hash
+		if previousHashes[id] == hash {
+			continue
+		}
 		if err := batch.Index(id, doc); err != nil {
 			return fmt.Errorf("failed to add document to batch: %w", err)
 		}
 	}
+	for id := range previousHashes {
+		if _, ok := hashes[id]; !ok {
+			batch.Delete(id)
+		}
+	}
 
+	hashesJSON, err := json.Marshal(hashes)
+	if err != nil {
+		return fmt.Errorf("failed to encode document hashes: %w", err)
+	}
+	batch.SetInternal(documentHashesKey, hashesJSON)
+	batch.SetInternal(mappingVersionKey, []byte(strconv.Itoa(MappingVersion)))
 	if err := index.Batch(batch); err != nil {
 		return fmt.Errorf("failed to index batch: %w", err)
 	}
+	return nil
+}
+
+// documentHashes returns the content hashes recorded in an index, by document ID.
+func documentHashes(index bleve.Index) (map[string]string, error) {
+	value, err := index.GetInternal(documentHashesKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document hashes: %w", err)
+	}
+	hashes := make(map[string]string)
+	if len(value) > 0 {
+		if err := json.Unmarshal(value, &hashes); err != nil {
+			return nil, fmt.Errorf("failed to decode document hashes: %w", err)
+		}
+	}
+	return hashes, nil
+}
+
+// documentHash returns a hash of the content of a document.
+func documentHash(doc document) (string, error) {
+	content, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode document: %w", err)
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// indexOutdated reports whether an index was built with an earlier mapping version.
+func indexOutdated(index bleve.Index) (bool, error) {
+	value, err := index.GetInternal(mappingVersionKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read mapping version: %w", err)
+	}
+	version, _ := strconv.Atoi(string(value))
+	return version < MappingVersion, nil
+}
 
+// swapIndex replaces the index at indexPath with the one built at builtPath, moving any
+// existing index to previousPath. The previous index is restored if the swap fails.
+func swapIndex(builtPath, indexPath, previousPath string) error {
+	hadPrevious := true
+	if err := os.Rename(indexPath, previousPath); os.IsNotExist(err) {
+		hadPrevious = false
+	} else if err != nil {
+		return fmt.Errorf("failed to replace existing index: %w", err)
+	}
+	if err := os.Rename(builtPath, indexPath); err != nil {
+		if hadPrevious {
+			_ = os.Rename(previousPath, indexPath)
+		}
+		return fmt.Errorf("failed to replace existing index: %w", err)
+	}
 	return nil
 }
 
@@ -82,6 +238,17 @@ func (b *BleveIndexer) Exists(schemaID string) bool {
 	return err == nil
 }
 
+// Outdated reports whether the index for a schema was built with an earlier mapping
+// version. Indexes from before mapping versions were recorded are outdated.
+func (b *BleveIndexer) Outdated(schemaID string) (bool, error) {
+	index, err := bleve.Open(b.getIndexPath(schemaID))
+	if err != nil {
+		return false, fmt.Errorf("failed to open index: %w", err)
+	}
+	defer index.Close()
+	return indexOutdated(index)
+}
+
 // Close closes the indexer (no-op for BleveIndexer as we don't keep indexes open)
 func (b *BleveIndexer) Close() error {
 	return nil
@@ -93,30 +260,56 @@ func (b *BleveIndexer) getIndexPath(schemaID string) string {
 }
 
 // buildIndexMapping creates the index mapping for schema documents
-func buildIndexMapping() *mapping.IndexMappingImpl {
+func buildIndexMapping() (*mapping.IndexMappingImpl, error) {
+	indexMapping := bleve.NewIndexMapping()
+	if err := addNameAnalyzers(indexMapping); err != nil {
+		return nil, fmt.Errorf("failed to create name analyzers: %w", err)
+	}
+
 	// Create a text field mapping with standard analyzer
 	textFieldMapping := bleve.NewTextFieldMapping()
 	textFieldMapping.Analyzer = "standard"
 
+	// Names are split into words, and also indexed as fragments and as a whole for ranking
+	nameFieldMapping := bleve.NewTextFieldMapping()
+	nameFieldMapping.Analyzer = nameAnalyzer
+	nameNgramFieldMapping := bleve.NewTextFieldMapping()
+	nameNgramFieldMapping.Name = nameNgramField
+	nameNgramFieldMapping.Analyzer = nameNgramAnalyzer
+	nameNgramFieldMapping.Store = false
+	nameNgramFieldMapping.IncludeInAll = false
+	nameExactFieldMapping := bleve.NewTextFieldMapping()
+	nameExactFieldMapping.Name = nameExactField
+	nameExactFieldMapping.Analyzer = nameExactAnalyzer
+	nameExactFieldMapping.Store = false
+	nameExactFieldMapping.IncludeInAll = false
+
+	// Signatures are split into words like names, so that matched names and types can be
+	// highlighted in them. They are only searched with signature: queries.
+	signatureFieldMapping := bleve.NewTextFieldMapping()
+	signatureFieldMapping.Analyzer = nameAnalyzer
+	signatureFieldMapping.IncludeInAll = false
+
 	// Create a keyword field mapping (exact match, no analysis)
 	keywordFieldMapping := bleve.NewKeywordFieldMapping()
 
 	// Create document mapping
 	docMapping := bleve.NewDocumentMapping()
 	docMapping.AddFieldMappingsAt("kind", keywordFieldMapping)
-	docMapping.AddFieldMappingsAt("name", textFieldMapping)
+	docMapping.AddFieldMappingsAt("name", nameFieldMapping, nameNgramFieldMapping, nameExactFieldMapping)
 	docMapping.AddFieldMappingsAt("description", textFieldMapping)
 	docMapping.AddFieldMappingsAt("path", textFieldMapping)
+	docMapping.AddFieldMappingsAt("parent", keywordFieldMapping)
 	docMapping.AddFieldMappingsAt("schemaID", keywordFieldMapping)
-	docMapping.AddFieldMappingsAt("signature", keywordFieldMapping)
+	docMapping.AddFieldMappingsAt("signature", signatureFieldMapping)
 	docMapping.AddFieldMappingsAt("usage", keywordFieldMapping)
 	docMapping.AddFieldMappingsAt("implements", keywordFieldMapping)
+	docMapping.AddFieldMappingsAt("directive", keywordFieldMapping)
+	docMapping.AddFieldMappingsAt("returns", keywordFieldMapping)
+	docMapping.AddFieldMappingsAt("accepts", keywordFieldMapping)
 
-	// Create index mapping
-	indexMapping := bleve.NewIndexMapping()
 	indexMapping.DefaultMapping = docMapping
-
-	return indexMapping
+	return indexMapping, nil
 }
 
 // extractDocuments extracts searchable documents from a schema
@@ -125,15 +318,9 @@ func extractDocuments(schemaID string, schema *gql.GraphQLSchema) []document {
 
 	schema.Walk(gql.SchemaVisitor{
 		VisitField: func(ctx gql.VisitContext, name string, field *gql.Field) {
-			docs = append(docs, document{
-				Kind:        ctx.Kind,
-				Name:        name,
-				Description: field.Description(),
-				Path:        ctx.Kind + "." + name,
-				SchemaID:    schemaID,
-				Signature:   field.Signature(),
-				Usage:       fieldUsage(field),
-			})
+			path := ctx.Kind + "." + name
+			docs = append(docs, outputFieldDocument(schemaID, ctx.Kind, path, field))
+			docs = append(docs, argumentDocuments(schemaID, "Argument", path, field.Arguments())...)
 		},
 		VisitObject: func(ctx gql.VisitContext, name string, obj *gql.Object) {
 			docs = append(docs, document{
@@ -143,18 +330,13 @@ func extractDocuments(schemaID string, schema *gql.GraphQLSchema) []document {
 				Path:        name,
 				SchemaID:    schemaID,
 				Implements:  obj.Interfaces(),
+				Directives:  directiveNames(obj.Directives()),
 			})
 		},
 		VisitObjectField: func(ctx gql.VisitContext, field *gql.Field) {
-			docs = append(docs, document{
-				Kind:        "ObjectField",
-				Name:        field.Name(),
-				Description: field.Description(),
-				Path:        ctx.ParentName + "." + field.Name(),
-				SchemaID:    schemaID,
-				Signature:   field.Signature(),
-				Usage:       fieldUsage(field),
-			})
+			path := ctx.ParentName + "." + field.Name()
+			docs = append(docs, outputFieldDocument(schemaID, "ObjectField", path, field))
+			docs = append(docs, argumentDocuments(schemaID, "Argument", path, field.Arguments())...)
 		},
 		VisitInterface: func(ctx gql.VisitContext, name string, iface *gql.Interface) {
 			docs = append(docs, document{
@@ -164,18 +346,13 @@ func extractDocuments(schemaID string, schema *gql.GraphQLSchema) []document {
 				Path:        name,
 				SchemaID:    schemaID,
 				Implements:  iface.Interfaces(),
+				Directives:  directiveNames(iface.Directives()),
 			})
 		},
 		VisitInterfaceField: func(ctx gql.VisitContext, field *gql.Field) {
-			docs = append(docs, document{
-				Kind:        "InterfaceField",
-				Name:        field.Name(),
-				Description: field.Description(),
-				Path:        ctx.ParentName + "." + field.Name(),
-				SchemaID:    schemaID,
-				Signature:   field.Signature(),
-				Usage:       fieldUsage(field),
-			})
+			path := ctx.ParentName + "." + field.Name()
+			docs = append(docs, outputFieldDocument(schemaID, "InterfaceField", path, field))
+			docs = append(docs, argumentDocuments(schemaID, "Argument", path, field.Arguments())...)
 		},
 		VisitInput: func(ctx gql.VisitContext, name string, input *gql.InputObject) {
 			docs = append(docs, document{
@@ -184,6 +361,7 @@ func extractDocuments(schemaID string, schema *gql.GraphQLSchema) []document {
 				Description: input.Description(),
 				Path:        name,
 				SchemaID:    schemaID,
+				Directives:  directiveNames(input.Directives()),
 			})
 		},
 		VisitInputField: func(ctx gql.VisitContext, field *gql.Field) {
@@ -195,6 +373,7 @@ func extractDocuments(schemaID string, schema *gql.GraphQLSchema) []document {
 				SchemaID:    schemaID,
 				Signature:   field.Signature(),
 				Usage:       fieldUsage(field),
+				Directives:  directiveNames(field.Directives()),
 			})
 		},
 		VisitEnum: func(ctx gql.VisitContext, name string, enum *gql.Enum) {
@@ -204,6 +383,18 @@ func extractDocuments(schemaID string, schema *gql.GraphQLSchema) []document {
 				Description: enum.Description(),
 				Path:        name,
 				SchemaID:    schemaID,
+				Directives:  directiveNames(enum.Directives()),
+			})
+		},
+		VisitEnumValue: func(ctx gql.VisitContext, value *gql.EnumValue) {
+			docs = append(docs, document{
+				Kind:        "EnumValue",
+				Name:        value.Name(),
+				Description: value.Description(),
+				Path:        ctx.ParentName + "." + value.Name(),
+				SchemaID:    schemaID,
+				Signature:   value.Signature(),
+				Directives:  directiveNames(value.Directives()),
 			})
 		},
 		VisitScalar: func(ctx gql.VisitContext, name string, scalar *gql.Scalar) {
@@ -213,6 +404,7 @@ func extractDocuments(schemaID string, schema *gql.GraphQLSchema) []document {
 				Description: scalar.Description(),
 				Path:        name,
 				SchemaID:    schemaID,
+				Directives:  directiveNames(scalar.Directives()),
 			})
 		},
 		VisitUnion: func(ctx gql.VisitContext, name string, union *gql.Union) {
@@ -222,22 +414,95 @@ func extractDocuments(schemaID string, schema *gql.GraphQLSchema) []document {
 				Description: union.Description(),
 				Path:        name,
 				SchemaID:    schemaID,
+				Directives:  directiveNames(union.Directives()),
 			})
 		},
 		VisitDirective: func(ctx gql.VisitContext, name string, directive *gql.DirectiveDef) {
+			path := "@" + name
 			docs = append(docs, document{
 				Kind:        "Directive",
 				Name:        name,
 				Description: directive.Description(),
-				Path:        "@" + name,
+				Path:        path,
 				SchemaID:    schemaID,
+				Signature:   directive.Signature(),
+				Accepts:     argumentTypes(directive.Arguments()),
 			})
+			docs = append(docs, argumentDocuments(schemaID, "DirectiveArgument", path, directive.Arguments())...)
 		},
 	})
 
+	// Fields, arguments, and enum values belong to the type or directive starting their path
+	for i := range docs {
+		if parent, _, ok := strings.Cut(docs[i].Path, "."); ok {
+			docs[i].Parent = parent
+		}
+	}
 	return docs
 }
 
+// outputFieldDocument returns the document of a Query, Mutation, ObjectField, or
+// InterfaceField at path.
+func outputFieldDocument(schemaID, kind, path string, field *gql.Field) document {
+	return document{
+		Kind:        kind,
+		Name:        field.Name(),
+		Description: field.Description(),
+		Path:        path,
+		SchemaID:    schemaID,
+		Signature:   field.Signature(),
+		Usage:       fieldUsage(field),
+		Directives:  directiveNames(field.Directives()),
+		Returns:     field.ObjectTypeName(),
+		Accepts:     argumentTypes(field.Arguments()),
+	}
+}
+
+// argumentDocuments returns the documents of the arguments of the field or directive at
+// parentPath, with paths like "Query.user.id" or "@auth.requires".
+func argumentDocuments(schemaID, kind, parentPath string, args []*gql.Argument) []document {
+	docs := make([]document, 0, len(args))
+	for _, arg := range args {
+		var accepts []string
+		if typeName := arg.ObjectTypeName(); typeName != "" {
+			accepts = []string{typeName}
+		}
+		docs = append(docs, document{
+			Kind:        kind,
+			Name:        arg.Name(),
+			Description: arg.Description(),
+			Path:        parentPath + "." + arg.Name(),
+			SchemaID:    schemaID,
+			Signature:   arg.Signature(),
+			Directives:  directiveNames(arg.Directives()),
+			Accepts:     accepts,
+		})
+	}
+	return docs
+}
+
+// directiveNames returns the names of applied directives, without the @.
+func directiveNames(directives []*gql.AppliedDirective) []string {
+	var names []string
+	for _, directive := range directives {
+		if !slices.Contains(names, directive.Name()) {
+			names = append(names, directive.Name())
+		}
+	}
+	return names
+}
+
+// argumentTypes returns the named types of arguments, without duplicates.
+func argumentTypes(args []*gql.Argument) []string {
+	var types []string
+	for _, arg := range args {
+		if typeName := arg.ObjectTypeName(); typeName != "" && !slices.Contains(types, typeName) {
+			types = append(types, typeName)
+		}
+	}
+	return types
+}
+
 // fieldUsage returns a slice containing the field's return type name, or nil if the type is a
 // built-in scalar (no object type name).
 func fieldUsage(field *gql.Field) []string {
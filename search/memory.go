@@ -0,0 +1,148 @@
+package search
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/tonysyu/gqlxp/gql"
+)
+
+// MemoryIndex implements Indexer and Searcher with indexes kept in memory, for libraries
+// that are not stored on disk.
+type MemoryIndex struct {
+	mu      sync.RWMutex
+	indexes map[string]bleve.Index
+}
+
+// NewMemoryIndex creates an empty MemoryIndex.
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{indexes: make(map[string]bleve.Index)}
+}
+
+// Index creates or replaces the index for a schema.
+func (m *MemoryIndex) Index(schemaID string, schema *gql.GraphQLSchema) error {
+	indexMapping, err := buildIndexMapping()
+	if err != nil {
+		return err
+	}
+	index, err := bleve.NewMemOnly(indexMapping)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	if err := populateIndex(index, schemaID, schema); err != nil {
+		index.Close()
+		return err
+	}
+
+	m.mu.Lock()
+	previous := m.indexes[schemaID]
+	m.indexes[schemaID] = index
+	m.mu.Unlock()
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+// Update reindexes only the documents of a schema that were added, changed, or removed,
+// rebuilding the index if it is missing or was built with an earlier mapping version.
+func (m *MemoryIndex) Update(schemaID string, schema *gql.GraphQLSchema) error {
+	m.mu.Lock()
+	index, ok := m.indexes[schemaID]
+	if ok {
+		if outdated, err := indexOutdated(index); err == nil && !outdated {
+			defer m.mu.Unlock()
+			return populateIndex(index, schemaID, schema)
+		}
+	}
+	m.mu.Unlock()
+	return m.Index(schemaID, schema)
+}
+
+// Remove deletes the index for a schema.
+func (m *MemoryIndex) Remove(schemaID string) error {
+	m.mu.Lock()
+	index := m.indexes[schemaID]
+	delete(m.indexes, schemaID)
+	m.mu.Unlock()
+	if index != nil {
+		return index.Close()
+	}
+	return nil
+}
+
+// Exists checks if an index exists for a schema.
+func (m *MemoryIndex) Exists(schemaID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.indexes[schemaID]
+	return ok
+}
+
+// Outdated reports whether the index for a schema was built with an earlier mapping version.
+func (m *MemoryIndex) Outdated(schemaID string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	index, ok := m.indexes[schemaID]
+	if !ok {
+		return false, fmt.Errorf("no index for schema '%s'", schemaID)
+	}
+	return indexOutdated(index)
+}
+
+// Search finds matching types and fields in a schema.
+func (m *MemoryIndex) Search(schemaID string, query string, limit int) (*SearchResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	index, ok := m.indexes[schemaID]
+	if !ok {
+		return nil, fmt.Errorf("failed to open index: no index for schema '%s'", schemaID)
+	}
+
+	searchRequest := newSearchRequest(query)
+	searchRequest.Size = limit
+	searchResults, err := index.Search(searchRequest)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	return toSearchResponse(searchResults), nil
+}
+
+// SearchSchemas finds matching types and fields across several schemas at once. Schemas
+// without an index are skipped.
+func (m *MemoryIndex) SearchSchemas(schemaIDs []string, query string, limit int) (*SearchResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	alias := bleve.NewIndexAlias()
+	for _, schemaID := range schemaIDs {
+		if index, ok := m.indexes[schemaID]; ok {
+			alias.Add(index)
+		}
+	}
+
+	searchRequest := newSearchRequest(query)
+	searchRequest.Fields = append(searchRequest.Fields, "schemaID")
+	searchRequest.Size = limit
+	searchResults, err := alias.Search(searchRequest)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	return toSearchResponse(searchResults), nil
+}
+
+// Close closes all indexes.
+func (m *MemoryIndex) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for schemaID, index := range m.indexes {
+		index.Close()
+		delete(m.indexes, schemaID)
+	}
+	return nil
+}
+
+var (
+	_ Indexer  = (*MemoryIndex)(nil)
+	_ Searcher = (*MemoryIndex)(nil)
+)
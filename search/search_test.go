@@ -1,8 +1,10 @@
 package search_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/matryer/is"
@@ -61,27 +63,31 @@ func TestIndexerAndSearcher(t *testing.T) {
 	defer searcher.Close()
 
 	// Test 1: Search for "user" should find the User type and user field
-	results, err := searcher.Search(schemaID, "user", 10)
-	is.NoErr(err)                                // should search successfully
+	response, err := searcher.Search(schemaID, "user", 10)
+	is.NoErr(err) // should search successfully
+	results := response.Results
 	is.True(len(results) > 0)                    // should find results
 	is.True(containsPath(results, "User"))       // should find User type
 	is.True(containsPath(results, "Query.user")) // should find user field
 
 	// Test 2: Search for "search" should find searchUsers field
-	results, err = searcher.Search(schemaID, "search", 10)
-	is.NoErr(err)                                       // should search successfully
+	response, err = searcher.Search(schemaID, "search", 10)
+	is.NoErr(err) // should search successfully
+	results = response.Results
 	is.True(len(results) > 0)                           // should find results
 	is.True(containsPath(results, "Query.searchUsers")) // should find searchUsers field
 
-	// Test 3: Search for mid-word fragment should find searchUsers via wildcard on name
-	results, err = searcher.Search(schemaID, "Users", 10)
-	is.NoErr(err)                                       // should search successfully
+	// Test 3: Search for a word within a name should find searchUsers
+	response, err = searcher.Search(schemaID, "Users", 10)
+	is.NoErr(err) // should search successfully
+	results = response.Results
 	is.True(len(results) > 0)                           // should find results
-	is.True(containsPath(results, "Query.searchUsers")) // should find searchUsers via fragment
+	is.True(containsPath(results, "Query.searchUsers")) // should find searchUsers by word
 
 	// Test 4: Query fields should have signatures
-	results, err = searcher.Search(schemaID, "user", 10)
+	response, err = searcher.Search(schemaID, "user", 10)
 	is.NoErr(err)
+	results = response.Results
 	for _, r := range results {
 		if r.Path == "Query.user" {
 			is.Equal(r.Signature, "user(id: ID!): User") // Query field should have signature
@@ -92,8 +98,9 @@ func TestIndexerAndSearcher(t *testing.T) {
 	}
 
 	// Test 5: Search for non-existent term
-	results, err = searcher.Search(schemaID, "nonexistent", 10)
-	is.NoErr(err)             // should search successfully
+	response, err = searcher.Search(schemaID, "nonexistent", 10)
+	is.NoErr(err) // should search successfully
+	results = response.Results
 	is.Equal(len(results), 0) // should find no results
 
 	// Test 6: Remove index
@@ -139,6 +146,20 @@ func TestReindexing(t *testing.T) {
 	is.NoErr(err)
 	is.True(newInfo.ModTime().After(originalInfo.ModTime()) ||
 		newInfo.ModTime().Equal(originalInfo.ModTime())) // index should be recreated
+
+	// Indexes are built in a temporary directory that is removed once swapped in
+	entries, err := os.ReadDir(tmpDir)
+	is.NoErr(err)
+	is.Equal(len(entries), 1)
+	is.Equal(entries[0].Name(), schemaID+".bleve")
+
+	// The replaced index is searchable
+	searcher := search.NewSearcher(tmpDir)
+	defer searcher.Close()
+	response, err := searcher.Search(schemaID, "user", 10)
+	is.NoErr(err)
+	results := response.Results
+	is.True(len(results) > 0)
 }
 
 func TestSearchResultOrdering(t *testing.T) {
@@ -162,8 +183,9 @@ func TestSearchResultOrdering(t *testing.T) {
 	defer searcher.Close()
 
 	// Search for "user" - results should be ordered by relevance (score)
-	results, err := searcher.Search(schemaID, "user", 10)
+	response, err := searcher.Search(schemaID, "user", 10)
 	is.NoErr(err)
+	results := response.Results
 	is.True(len(results) > 1) // should have multiple results
 
 	// Verify scores are in descending order
@@ -225,19 +247,22 @@ func TestUsageIndex(t *testing.T) {
 	defer searcher.Close()
 
 	// Query fields that return User
-	results, err := searcher.Search(schemaID, "+usage:User +kind:Query", 10)
+	response, err := searcher.Search(schemaID, "+usage:User +kind:Query", 10)
 	is.NoErr(err)
+	results := response.Results
 	is.True(containsPath(results, "Query.user"))
 	is.True(containsPath(results, "Query.users"))
 
 	// Mutation fields that return User
-	results, err = searcher.Search(schemaID, "+usage:User +kind:Mutation", 10)
+	response, err = searcher.Search(schemaID, "+usage:User +kind:Mutation", 10)
 	is.NoErr(err)
+	results = response.Results
 	is.True(containsPath(results, "Mutation.createUser"))
 
 	// Object fields that reference UserRole
-	results, err = searcher.Search(schemaID, "+usage:UserRole", 10)
+	response, err = searcher.Search(schemaID, "+usage:UserRole", 10)
 	is.NoErr(err)
+	results = response.Results
 	is.True(containsPath(results, "User.role"))
 }
 
@@ -262,26 +287,431 @@ func TestImplementsIndex(t *testing.T) {
 	defer searcher.Close()
 
 	// User implements Node
-	results, err := searcher.Search(schemaID, "+implements:Node +kind:Object", 10)
+	response, err := searcher.Search(schemaID, "+implements:Node +kind:Object", 10)
 	is.NoErr(err)
+	results := response.Results
 	is.True(containsPath(results, "User"))
 
 	// User implements Timestamped
-	results, err = searcher.Search(schemaID, "+implements:Timestamped", 10)
+	response, err = searcher.Search(schemaID, "+implements:Timestamped", 10)
 	is.NoErr(err)
+	results = response.Results
 	is.True(containsPath(results, "User"))
 
 	// Timestamped interface extends Node
-	results, err = searcher.Search(schemaID, "+implements:Node +kind:Interface", 10)
+	response, err = searcher.Search(schemaID, "+implements:Node +kind:Interface", 10)
 	is.NoErr(err)
+	results = response.Results
 	is.True(containsPath(results, "Timestamped"))
 
 	// Searching for a non-existent interface returns no results
-	results, err = searcher.Search(schemaID, "+implements:NonExistent", 10)
+	response, err = searcher.Search(schemaID, "+implements:NonExistent", 10)
 	is.NoErr(err)
+	results = response.Results
 	is.Equal(len(results), 0)
 }
 
+func TestSearchSchemas(t *testing.T) {
+	is := is.New(t)
+
+	tmpDir := t.TempDir()
+
+	billing, err := gql.ParseSchema([]byte(`
+		type Query { invoice(id: ID!): Invoice }
+		type Invoice { id: ID! total: Int! }
+	`))
+	is.NoErr(err)
+	users, err := gql.ParseSchema([]byte(testSchema))
+	is.NoErr(err)
+
+	indexer := search.NewIndexer(tmpDir)
+	defer indexer.Close()
+	is.NoErr(indexer.Index("billing", &billing))
+	is.NoErr(indexer.Index("users", &users))
+
+	searcher := search.NewSearcher(tmpDir)
+	defer searcher.Close()
+
+	// Results are annotated with the schema they came from
+	response, err := searcher.SearchSchemas([]string{"billing", "users"}, "Invoice", 10)
+	is.NoErr(err)
+	results := response.Results
+	is.True(len(results) > 0)
+	for _, result := range results {
+		is.Equal(result.SchemaID, "billing") // only billing defines Invoice
+	}
+
+	response, err = searcher.SearchSchemas([]string{"billing", "users"}, "+kind:Query", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.True(containsSchemaPath(results, "billing", "Query.invoice"))
+	is.True(containsSchemaPath(results, "users", "Query.user"))
+
+	// Schemas without an index are skipped
+	response, err = searcher.SearchSchemas([]string{"users", "missing"}, "+kind:Query", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.True(containsSchemaPath(results, "users", "Query.user"))
+	is.True(!containsSchemaPath(results, "billing", "Query.invoice")) // billing not requested
+}
+
+func TestSearchFacets(t *testing.T) {
+	is := is.New(t)
+
+	billing, err := gql.ParseSchema([]byte(`
+		type Query { invoice(id: ID!): Invoice }
+		type Invoice { id: ID! total: Int! }
+	`))
+	is.NoErr(err)
+	users, err := gql.ParseSchema([]byte(testSchema))
+	is.NoErr(err)
+
+	index := search.NewMemoryIndex()
+	defer index.Close()
+	is.NoErr(index.Index("billing", &billing))
+	is.NoErr(index.Index("users", &users))
+
+	// Counts include the matches past the limit
+	response, err := index.Search("users", "+kind:ObjectField", 1)
+	is.NoErr(err)
+	is.Equal(len(response.Results), 1)
+	is.Equal(response.Total, uint64(3))
+	is.Equal(response.Kinds, []search.FacetCount{{Term: "ObjectField", Count: 3}})
+	is.Equal(response.Parents, []search.FacetCount{{Term: "User", Count: 3}})
+
+	// Fields, arguments, and enum values are counted by parent type, most frequent first
+	response, err = index.SearchSchemas([]string{"billing", "users"}, "+name:id", 10)
+	is.NoErr(err)
+	is.Equal(response.Total, uint64(len(response.Results)))
+	is.Equal(response.Kinds, []search.FacetCount{
+		{Term: "Argument", Count: 2},    // Query.invoice.id, Query.user.id
+		{Term: "ObjectField", Count: 2}, // Invoice.id, User.id
+	})
+	is.Equal(response.Parents, []search.FacetCount{
+		{Term: "Query", Count: 2},
+		{Term: "Invoice", Count: 1},
+		{Term: "User", Count: 1},
+	})
+}
+
+func TestSearchHighlights(t *testing.T) {
+	is := is.New(t)
+
+	schema, err := gql.ParseSchema([]byte(testSchema))
+	is.NoErr(err)
+	index := search.NewMemoryIndex()
+	defer index.Close()
+	is.NoErr(index.Index("users", &schema))
+
+	response, err := index.Search("users", "users", 10)
+	is.NoErr(err)
+	is.True(containsPath(response.Results, "Query.searchUsers"))
+	for _, result := range response.Results {
+		if result.Path == "Query.searchUsers" {
+			is.Equal(result.Fragments["name"], []string{"search<mark>Users</mark>"})
+			is.Equal(result.Fragments["description"], []string{"Search for <mark>users</mark> by name"})
+			is.Equal(len(result.Fragments["signature"]), 0) // signatures are only searched by field
+		}
+	}
+
+	response, err = index.Search("users", "signature:user", 10)
+	is.NoErr(err)
+	is.True(len(response.Results) > 0)
+	is.Equal(response.Results[0].Path, "Query.user")
+	is.Equal(response.Results[0].Fragments, map[string][]string{
+		"signature": {"<mark>user</mark>(id: ID!): <mark>User</mark>"},
+	})
+
+	// Results matching no highlighted field have no fragments
+	response, err = index.Search("users", "+kind:Object", 10)
+	is.NoErr(err)
+	is.Equal(len(response.Results), 1)
+	is.Equal(response.Results[0].Fragments, nil)
+}
+
+func TestSplitHighlights(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(search.SplitHighlights("Get a <mark>user</mark> by <mark>ID</mark>"), []search.HighlightSegment{
+		{Text: "Get a "},
+		{Text: "user", Match: true},
+		{Text: " by "},
+		{Text: "ID", Match: true},
+	})
+	is.Equal(search.SplitHighlights("<mark>User</mark>"), []search.HighlightSegment{{Text: "User", Match: true}})
+	is.Equal(search.SplitHighlights("no matches"), []search.HighlightSegment{{Text: "no matches"}})
+	is.Equal(len(search.SplitHighlights("")), 0)
+}
+
+func TestMemoryIndex(t *testing.T) {
+	is := is.New(t)
+
+	schema, err := gql.ParseSchema([]byte(testSchema))
+	is.NoErr(err)
+
+	index := search.NewMemoryIndex()
+	defer index.Close()
+	is.True(!index.Exists("users"))
+	is.NoErr(index.Index("users", &schema))
+	is.True(index.Exists("users"))
+
+	outdated, err := index.Outdated("users")
+	is.NoErr(err)
+	is.True(!outdated)
+
+	response, err := index.Search("users", "+kind:Query", 10)
+	is.NoErr(err)
+	results := response.Results
+	is.True(containsPath(results, "Query.user"))
+
+	response, err = index.SearchSchemas([]string{"users", "missing"}, "User", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.True(containsSchemaPath(results, "users", "User"))
+
+	is.NoErr(index.Remove("users"))
+	is.True(!index.Exists("users"))
+	_, err = index.Search("users", "User", 10)
+	is.True(err != nil) // removed indexes can't be searched
+}
+
+func TestIndexUpdate(t *testing.T) {
+	original, err := gql.ParseSchema([]byte(`
+		type Query { user(id: ID!): User }
+		"A user"
+		type User { id: ID! name: String! }
+		type Team { id: ID! }
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Team is removed, User.name is changed, and User.email is added
+	updated, err := gql.ParseSchema([]byte(`
+		type Query { user(id: ID!): User }
+		"A user"
+		type User { id: ID! "Full name" name: String! email: String }
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpDir := t.TempDir()
+	memoryIndex := search.NewMemoryIndex()
+	indexes := map[string]struct {
+		indexer  search.Indexer
+		searcher search.Searcher
+	}{
+		"bleve":  {search.NewIndexer(tmpDir), search.NewSearcher(tmpDir)},
+		"memory": {memoryIndex, memoryIndex},
+	}
+	for name, tt := range indexes {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			index := tt.searcher
+			// Updating a missing index builds it
+			is.NoErr(tt.indexer.Update("users", &original))
+			is.NoErr(tt.indexer.Update("users", &updated))
+
+			response, err := index.Search("users", "+kind:ObjectField", 10)
+			is.NoErr(err)
+			is.Equal(response.Total, uint64(3)) // User.id, User.name, User.email
+			is.True(containsPath(response.Results, "User.email"))
+
+			response, err = index.Search("users", "Team", 10)
+			is.NoErr(err)
+			is.Equal(response.Total, uint64(0)) // removed documents are deleted
+
+			response, err = index.Search("users", "+description:full", 10)
+			is.NoErr(err)
+			is.True(containsPath(response.Results, "User.name")) // changed documents are reindexed
+
+			// Updating with unchanged content changes nothing
+			is.NoErr(tt.indexer.Update("users", &updated))
+			response, err = index.Search("users", "+kind:ObjectField", 10)
+			is.NoErr(err)
+			is.Equal(response.Total, uint64(3))
+		})
+	}
+}
+
+const elementTestSchema = `
+	directive @auth(requires: Role = ADMIN) on FIELD_DEFINITION
+
+	enum Role {
+		ADMIN
+		"A regular user"
+		MEMBER @deprecated(reason: "Use VIEWER")
+		VIEWER
+	}
+
+	input UserInput {
+		name: String!
+	}
+
+	type User {
+		id: ID!
+		friends(first: Int): [User!]!
+	}
+
+	type Query {
+		user(id: ID!): User
+	}
+
+	type Mutation {
+		updateUser(id: ID!, input: UserInput!): User @auth(requires: ADMIN)
+	}
+`
+
+func TestElementIndex(t *testing.T) {
+	is := is.New(t)
+
+	schema, err := gql.ParseSchema([]byte(elementTestSchema))
+	is.NoErr(err)
+	index := search.NewMemoryIndex()
+	defer index.Close()
+	is.NoErr(index.Index("users", &schema))
+
+	// Field and directive arguments and enum values are indexed
+	response, err := index.Search("users", "+kind:Argument", 10)
+	is.NoErr(err)
+	results := response.Results
+	is.True(containsPath(results, "Query.user.id"))
+	is.True(containsPath(results, "Mutation.updateUser.input"))
+	is.True(containsPath(results, "User.friends.first"))
+	is.True(!containsPath(results, "@auth.requires"))
+
+	response, err = index.Search("users", "+kind:DirectiveArgument +name:requires", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.Equal(len(results), 1)
+	is.Equal(results[0].Path, "@auth.requires")
+	is.Equal(results[0].Signature, "requires: Role = ADMIN")
+
+	response, err = index.Search("users", "+kind:EnumValue", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.Equal(len(results), 3)
+	is.True(containsPath(results, "Role.MEMBER"))
+
+	// Applied directives
+	response, err = index.Search("users", "+directive:auth", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.Equal(len(results), 1)
+	is.Equal(results[0].Path, "Mutation.updateUser")
+	response, err = index.Search("users", "+directive:deprecated", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.Equal(len(results), 1)
+	is.Equal(results[0].Path, "Role.MEMBER")
+
+	// Return types
+	response, err = index.Search("users", "+returns:User", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.True(containsPath(results, "Query.user"))
+	is.True(containsPath(results, "Mutation.updateUser"))
+	is.True(containsPath(results, "User.friends"))
+	is.True(!containsPath(results, "User")) // types don't return anything
+
+	// Argument types, of fields, directives, and arguments themselves
+	response, err = index.Search("users", "+accepts:UserInput", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.True(containsPath(results, "Mutation.updateUser"))
+	is.True(containsPath(results, "Mutation.updateUser.input"))
+	is.True(!containsPath(results, "Query.user"))
+	response, err = index.Search("users", "+accepts:Role +kind:Directive", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.Equal(len(results), 1)
+	is.Equal(results[0].Path, "@auth")
+}
+
+const nameTestSchema = `
+	type Query {
+		repository(name: String!): Repository
+		pullRequestReview(id: ID!): PullRequestReview
+	}
+
+	type Mutation {
+		createPullRequestReview(body: String!): PullRequestReview
+	}
+
+	type Repository {
+		id: ID!
+		pullRequests: [PullRequest!]!
+	}
+
+	type PullRequest {
+		id: ID!
+		reviews: [PullRequestReview!]!
+	}
+
+	type PullRequestReview {
+		id: ID!
+		body: String!
+	}
+`
+
+func TestNameSearch(t *testing.T) {
+	is := is.New(t)
+
+	schema, err := gql.ParseSchema([]byte(nameTestSchema))
+	is.NoErr(err)
+	index := search.NewMemoryIndex()
+	defer index.Close()
+	is.NoErr(index.Index("github", &schema))
+
+	// Names are split into words
+	response, err := index.Search("github", "pull request review", 10)
+	is.NoErr(err)
+	results := response.Results
+	is.True(containsPath(results, "Mutation.createPullRequestReview"))
+	is.Equal(results[0].Path, "PullRequestReview") // exact matches rank first
+
+	// Names match fragments spanning words
+	response, err = index.Search("github", "questrev", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.True(containsPath(results, "Mutation.createPullRequestReview"))
+
+	// Misspelled names match
+	response, err = index.Search("github", "repostory", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.True(containsPath(results, "Repository"))
+	is.True(containsPath(results, "Query.repository"))
+
+	// Names starting with the query rank above names containing it
+	response, err = index.Search("github", "pullrequest", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.True(len(results) > 0)
+	is.Equal(results[0].Path, "PullRequest")
+	rank := make(map[string]int)
+	for i, result := range results {
+		rank[result.Path] = i
+	}
+	is.True(rank["Repository.pullRequests"] < rank["Mutation.createPullRequestReview"])
+
+	// Short words are not matched with typos
+	response, err = index.Search("github", "ix", 10)
+	is.NoErr(err)
+	results = response.Results
+	is.Equal(len(results), 0)
+}
+
+// containsSchemaPath checks if any result from schemaID has the given path
+func containsSchemaPath(results []search.SearchResult, schemaID, path string) bool {
+	for _, result := range results {
+		if result.SchemaID == schemaID && result.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
 // containsPath checks if any result has the given path
 func containsPath(results []search.SearchResult, path string) bool {
 	for _, result := range results {
@@ -291,3 +721,62 @@ func containsPath(results []search.SearchResult, path string) bool {
 	}
 	return false
 }
+
+// definitionRankingSchema has many fields and enum values sharing the names of its types,
+// as in large schemas where e.g. every mutation payload has a "user" field.
+func definitionRankingSchema() string {
+	var sdl strings.Builder
+	sdl.WriteString(`
+		type Query { viewer: User }
+		type User { login: String! }
+		type Repository { name: String! }
+		type PullRequestReview { body: String! }
+		type PullRequestReviewComment { body: String! }
+	`)
+	for i := range 20 {
+		fmt.Fprintf(&sdl, `
+			type Payload%d {
+				user: User
+				repository: Repository
+				pullRequestReview: PullRequestReview
+			}
+			enum ActorType%d { USER REPOSITORY }
+		`, i, i)
+	}
+	return sdl.String()
+}
+
+func TestNameSearch_DefinitionsRankFirst(t *testing.T) {
+	is := is.New(t)
+
+	schema, err := gql.ParseSchema([]byte(definitionRankingSchema()))
+	is.NoErr(err)
+	index := search.NewMemoryIndex()
+	defer index.Close()
+	is.NoErr(index.Index("github", &schema))
+
+	// Types rank above the fields and enum values named like them
+	for query, path := range map[string]string{
+		"user":                "User",
+		"repository":          "Repository",
+		"pull request review": "PullRequestReview",
+		"pullrequestreview":   "PullRequestReview",
+	} {
+		response, err := index.Search("github", query, 10)
+		is.NoErr(err)
+		is.True(len(response.Results) > 0)
+		is.Equal(response.Results[0].Path, path) // exact type name ranks first
+	}
+
+	// Names starting with the query are boosted too
+	response, err := index.Search("github", "pullrequestrev", 10)
+	is.NoErr(err)
+	is.True(containsPath(response.Results[:2], "PullRequestReview"))
+	is.True(containsPath(response.Results[:2], "PullRequestReviewComment"))
+
+	// A misspelled type name finds the type among the top results
+	response, err = index.Search("github", "repostory", 10)
+	is.NoErr(err)
+	is.True(len(response.Results) > 0)
+	is.Equal(response.Results[0].Path, "Repository")
+}
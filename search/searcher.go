@@ -2,10 +2,14 @@ package search
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
 )
 
 // BleveSearcher implements Searcher using Bleve
@@ -19,7 +23,7 @@ func NewSearcher(baseDir string) *BleveSearcher {
 }
 
 // Search finds matching types and fields in a schema
-func (b *BleveSearcher) Search(schemaID string, query string, limit int) ([]SearchResult, error) {
+func (b *BleveSearcher) Search(schemaID string, query string, limit int) (*SearchResponse, error) {
 	indexPath := b.getIndexPath(schemaID)
 
 	// Open the index
@@ -40,7 +44,66 @@ func (b *BleveSearcher) Search(schemaID string, query string, limit int) ([]Sear
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
-	// Convert results to SearchResult slice
+	return toSearchResponse(searchResults), nil
+}
+
+// SearchSchemas finds matching types and fields across several schemas at once, using an
+// index alias so results are ranked together. Each result's SchemaID identifies the schema it
+// came from. Schemas without an index are skipped.
+func (b *BleveSearcher) SearchSchemas(schemaIDs []string, query string, limit int) (*SearchResponse, error) {
+	alias := bleve.NewIndexAlias()
+	for _, schemaID := range schemaIDs {
+		indexPath := b.getIndexPath(schemaID)
+		if _, err := os.Stat(indexPath); os.IsNotExist(err) {
+			continue
+		}
+		index, err := bleve.Open(indexPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open index for schema '%s': %w", schemaID, err)
+		}
+		defer index.Close()
+		alias.Add(index)
+	}
+
+	searchRequest := newSearchRequest(query)
+	searchRequest.Fields = append(searchRequest.Fields, "schemaID")
+	searchRequest.Size = limit
+
+	searchResults, err := alias.Search(searchRequest)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	return toSearchResponse(searchResults), nil
+}
+
+// toSearchResponse converts a bleve search result to a SearchResponse.
+func toSearchResponse(searchResults *bleve.SearchResult) *SearchResponse {
+	return &SearchResponse{
+		Total:   searchResults.Total,
+		Kinds:   facetCounts(searchResults.Facets["kind"]),
+		Parents: facetCounts(searchResults.Facets["parent"]),
+		Results: toSearchResults(searchResults),
+	}
+}
+
+// facetCounts converts the terms of a bleve facet to FacetCounts.
+func facetCounts(facet *search.FacetResult) []FacetCount {
+	if facet == nil {
+		return nil
+	}
+	terms := facet.Terms.Terms()
+	counts := make([]FacetCount, 0, len(terms))
+	for _, term := range terms {
+		// Documents without a value, such as types for the parent facet, are indexed as ""
+		if term.Term != "" {
+			counts = append(counts, FacetCount{Term: term.Term, Count: term.Count})
+		}
+	}
+	return counts
+}
+
+// toSearchResults converts bleve hits to SearchResults.
+func toSearchResults(searchResults *bleve.SearchResult) []SearchResult {
 	results := make([]SearchResult, 0, len(searchResults.Hits))
 	for _, hit := range searchResults.Hits {
 		result := SearchResult{
@@ -63,39 +126,154 @@ func (b *BleveSearcher) Search(schemaID string, query string, limit int) ([]Sear
 		if sigVal, ok := hit.Fields["signature"].(string); ok {
 			result.Signature = sigVal
 		}
+		if schemaIDVal, ok := hit.Fields["schemaID"].(string); ok {
+			result.SchemaID = schemaIDVal
+		}
+		// Fields without a match get a fragment too, with nothing highlighted
+		for field, fragments := range hit.Fragments {
+			for _, fragment := range fragments {
+				if strings.Contains(fragment, HighlightStart) {
+					if result.Fragments == nil {
+						result.Fragments = make(map[string][]string)
+					}
+					result.Fragments[field] = append(result.Fragments[field], fragment)
+				}
+			}
+		}
 
 		results = append(results, result)
 	}
-
-	return results, nil
+	return results
 }
 
+// Boosts ranking an exact name above a name starting with the query, above names
+// containing its words, above misspelled names.
+const (
+	exactNameBoost  = 10
+	prefixNameBoost = 5
+	nameWordBoost   = 2
+	fuzzyNameBoost  = 0.5
+)
+
+// definitionBoost ranks type and directive definitions above the many fields, arguments,
+// and enum values sharing their name, e.g. the User type above every "user" field, when the
+// whole name matches the query, starts with it, or is a misspelling of it.
+const definitionBoost = 20
+
+// definitionKinds are the kinds of documents boosted by definitionBoost.
+var definitionKinds = []string{"Object", "Interface", "Union", "Enum", "Input", "Scalar", "Directive"}
+
+// Number of terms counted by the kind and parent facets. Every kind is counted, and only the
+// parent types with the most matches.
+const (
+	kindFacetSize   = 20
+	parentFacetSize = 10
+)
+
+// minFuzzyWordLength is the length of the shortest query word matched with typos, since
+// short words are within an edit or two of too many names.
+const minFuzzyWordLength = 4
+
 func newSearchRequest(query string) *bleve.SearchRequest {
 	// QueryStringQuery provides flexible, user-defined search configuration.
 	// See https://blevesearch.com/docs/Query-String-Query/
 	queryStringQuery := bleve.NewQueryStringQuery(query)
 
 	searchRequest := bleve.NewSearchRequest(queryStringQuery)
-	if isSimpleTerm(query) {
-		// For bare single-word queries, also match name as a wildcard fragment so
-		// e.g. "org" finds "OrgFooBar" (indexed as the single token "orgfoobar").
-		nameWildcard := bleve.NewWildcardQuery("*" + strings.ToLower(query) + "*")
-		nameWildcard.SetField("name")
-		searchRequest = bleve.NewSearchRequest(bleve.NewDisjunctionQuery(queryStringQuery, nameWildcard))
+	if words := simpleWords(query); len(words) > 0 {
+		// For bare words, also match names by word, fragment, and with typos, so e.g.
+		// "pull request" and "quest" find "createPullRequest", and "repostory" finds
+		// "Repository".
+		searchRequest = bleve.NewSearchRequest(bleve.NewDisjunctionQuery(queryStringQuery, nameQuery(words)))
 	}
 	searchRequest.Fields = []string{"kind", "name", "description", "path", "signature"}
+	searchRequest.Highlight = bleve.NewHighlightWithStyle(highlighterName)
+	searchRequest.Highlight.Fields = highlightFields
+	searchRequest.AddFacet("kind", bleve.NewFacetRequest("kind", kindFacetSize))
+	searchRequest.AddFacet("parent", bleve.NewFacetRequest("parent", parentFacetSize))
 	return searchRequest
 }
 
-// isSimpleTerm returns true for single bare words with no query operators or field specifiers.
-func isSimpleTerm(query string) bool {
+// nameQuery matches names against the words of a simple query. Names equal to the words
+// run together rank first, then names starting with them, then names matching every word.
+func nameQuery(words []string) query.Query {
+	joined := strings.ToLower(strings.Join(words, ""))
+	exact := bleve.NewTermQuery(joined)
+	exact.SetField(nameExactField)
+	exact.SetBoost(exactNameBoost)
+	prefix := bleve.NewPrefixQuery(joined)
+	prefix.SetField(nameExactField)
+	prefix.SetBoost(prefixNameBoost)
+
+	wordQueries := make([]query.Query, len(words))
+	for i, word := range words {
+		wordQueries[i] = nameWordQuery(word)
+	}
+	return bleve.NewDisjunctionQuery(exact, prefix, bleve.NewConjunctionQuery(wordQueries...), definitionQuery(joined))
+}
+
+// definitionQuery matches type and directive definitions whose whole name equals, starts
+// with, or, for longer names, is a misspelling of joined.
+func definitionQuery(joined string) query.Query {
+	name := bleve.NewPrefixQuery(joined)
+	name.SetField(nameExactField)
+	names := []query.Query{name}
+	if length := utf8.RuneCountInString(joined); length >= minFuzzyWordLength {
+		fuzzy := bleve.NewFuzzyQuery(joined)
+		fuzzy.SetField(nameExactField)
+		fuzzy.SetFuzziness(min(length/4, 2))
+		fuzzy.SetPrefix(1)
+		names = append(names, fuzzy)
+	}
+
+	kinds := make([]query.Query, len(definitionKinds))
+	for i, kind := range definitionKinds {
+		kindQuery := bleve.NewTermQuery(kind)
+		kindQuery.SetField("kind")
+		kinds[i] = kindQuery
+	}
+
+	definition := bleve.NewConjunctionQuery(bleve.NewDisjunctionQuery(names...), bleve.NewDisjunctionQuery(kinds...))
+	definition.SetBoost(definitionBoost)
+	return definition
+}
+
+// nameWordQuery matches a name containing word as one of its words, as a fragment, or,
+// for longer words, misspelled by up to two edits.
+func nameWordQuery(word string) query.Query {
+	match := bleve.NewMatchQuery(word)
+	match.SetField("name")
+	match.SetOperator(query.MatchQueryOperatorAnd)
+	match.SetBoost(nameWordBoost)
+
+	fragment := bleve.NewMatchQuery(word)
+	fragment.SetField(nameNgramField)
+	fragment.Analyzer = nameNgramAnalyzer // the field isn't a document path to look it up by
+	fragment.SetOperator(query.MatchQueryOperatorAnd)
+
+	queries := []query.Query{match, fragment}
+	if length := utf8.RuneCountInString(word); length >= minFuzzyWordLength {
+		fuzzy := bleve.NewMatchQuery(word)
+		fuzzy.SetField("name")
+		fuzzy.SetOperator(query.MatchQueryOperatorAnd)
+		fuzzy.SetFuzziness(min(length/4, 2))
+		fuzzy.SetPrefix(1)
+		fuzzy.SetBoost(fuzzyNameBoost)
+		queries = append(queries, fuzzy)
+	}
+	return bleve.NewDisjunctionQuery(queries...)
+}
+
+// simpleWords returns the words of a query made of bare words, with no query operators or
+// field specifiers, or nil for other queries.
+func simpleWords(query string) []string {
 	for _, ch := range query {
 		switch ch {
-		case ' ', ':', '*', '?', '"', '+', '-', '(', ')', '~', '^', '\\':
-			return false
+		case ':', '*', '?', '"', '+', '-', '(', ')', '~', '^', '\\':
+			return nil
 		}
 	}
-	return true
+	return strings.Fields(query)
 }
 
 // Close closes the searcher (no-op for BleveSearcher)
@@ -4,12 +4,40 @@ import "github.com/tonysyu/gqlxp/gql"
 
 // SearchResult represents a single search result with ranking information
 type SearchResult struct {
-	Kind        string  `json:"kind"`        // Structural kind of result (Object, Query, ObjectField, etc.)
-	Name        string  `json:"name"`        // Name of the type or field
-	Path        string  `json:"path"`        // Full path (e.g., "Query.user.name")
-	Description string  `json:"description"` // Description text
-	Score       float64 `json:"score"`       // Relevance score from Bleve
-	Signature   string  `json:"signature"`   // Field signature (e.g., "getUser(id: ID!): User")
+	Kind        string  `json:"kind"`                 // Structural kind of result (Object, Query, ObjectField, etc.)
+	Name        string  `json:"name"`                 // Name of the type or field
+	Path        string  `json:"path"`                 // Full path (e.g., "Query.user.name")
+	Description string  `json:"description"`          // Description text
+	Score       float64 `json:"score"`                // Relevance score from Bleve
+	Signature   string  `json:"signature"`            // Field signature (e.g., "getUser(id: ID!): User")
+	SchemaID    string  `json:"schemaID,omitempty"`   // Schema the result belongs to (cross-schema search only)
+	SchemaName  string  `json:"schemaName,omitempty"` // Display name of the schema, when known
+	// Fragments of the name, description, and signature, by field, in which the terms that
+	// matched the query are marked by HighlightStart and HighlightEnd
+	Fragments map[string][]string `json:"fragments,omitempty"`
+}
+
+// SearchResponse holds the results of a search, which are limited in number, along with
+// counts of every matching document, so that a query can be refined.
+type SearchResponse struct {
+	Total   uint64         `json:"total"`   // Number of matching documents, including those past the limit
+	Kinds   []FacetCount   `json:"kinds"`   // Matching documents per kind, most frequent first
+	Parents []FacetCount   `json:"parents"` // Matching fields, arguments, and enum values per parent type, most frequent first
+	Results []SearchResult `json:"results"`
+	// Skipped lists the schemas of a cross-schema search that couldn't be searched
+	Skipped []SkippedSchema `json:"skipped,omitempty"`
+}
+
+// SkippedSchema is a schema left out of a search, such as because it failed to index.
+type SkippedSchema struct {
+	SchemaID string `json:"schemaID"`
+	Error    string `json:"error"`
+}
+
+// FacetCount is the number of matching documents with a field value.
+type FacetCount struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
 }
 
 // Indexer manages schema indexing operations
@@ -17,12 +45,19 @@ type Indexer interface {
 	// Index creates or updates the index for a schema
 	Index(schemaID string, schema *gql.GraphQLSchema) error
 
+	// Update reindexes only the documents of a schema that were added, changed, or removed,
+	// rebuilding the index if it is missing or was built with an earlier mapping version
+	Update(schemaID string, schema *gql.GraphQLSchema) error
+
 	// Remove deletes the index for a schema
 	Remove(schemaID string) error
 
 	// Exists checks if an index exists for a schema
 	Exists(schemaID string) bool
 
+	// Outdated reports whether an existing index was built with an earlier mapping version
+	Outdated(schemaID string) (bool, error)
+
 	// Close closes the indexer and releases resources
 	Close() error
 }
@@ -30,7 +65,10 @@ type Indexer interface {
 // Searcher performs search operations on indexed schemas
 type Searcher interface {
 	// Search finds matching types and fields in a schema
-	Search(schemaID string, query string, limit int) ([]SearchResult, error)
+	Search(schemaID string, query string, limit int) (*SearchResponse, error)
+
+	// SearchSchemas finds matching types and fields across several schemas at once
+	SearchSchemas(schemaIDs []string, query string, limit int) (*SearchResponse, error)
 
 	// Close closes the searcher and releases resources
 	Close() error
@@ -23,6 +23,7 @@ var ALLOWED_IMPORTERS = map[string][]string{
 	"github.com/urfave/cli/v3":         {"cli"},
 	"github.com/vektah/gqlparser/v2":   {"gql"},
 	"golang.org/x/term":                {"utils/terminal"},
+	"gopkg.in/yaml.v3":                 {"project"},
 }
 
 // TestDependencyRestrictions enforces that certain external dependencies
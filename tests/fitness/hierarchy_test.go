@@ -22,6 +22,7 @@ var PACKAGE_HIERARCHY = []string{
 	"cli",
 	"tui",
 	"library",
+	"project",
 	"introspection",
 	"search",
 	"gqlfmt",
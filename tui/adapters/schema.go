@@ -119,6 +119,19 @@ func (p *SchemaView) ResolveField(kind, typeName, fieldName string) (*gql.Field,
 	}
 }
 
+// ResolveOutputField looks up a field that can take arguments by its parent type name:
+// a Query or Mutation field, or a field of an object or interface type.
+func (p *SchemaView) ResolveOutputField(typeName, fieldName string) (*gql.Field, error) {
+	switch {
+	case typeName == "Query" || typeName == "Mutation":
+		return p.ResolveField(typeName, typeName, fieldName)
+	case p.schema.Interface[typeName] != nil:
+		return p.ResolveField("InterfaceField", typeName, fieldName)
+	default:
+		return p.ResolveField("ObjectField", typeName, fieldName)
+	}
+}
+
 // findFieldByName searches for a field by name in a slice of fields.
 func findFieldByName(fields []*gql.Field, name string) (*gql.Field, error) {
 	for _, field := range fields {
@@ -20,6 +20,12 @@ type searchResultItem struct {
 	resolver     gql.TypeResolver
 	parentType   string // Parent type name (e.g., "User" for "User.email")
 	fieldName    string // Field name (e.g., "email" for "User.email")
+
+	// For arguments and enum values, the item whose panel lists them, the tab they are
+	// listed in, and their name in it
+	parentItem components.ListItem
+	parentTab  string
+	childName  string
 }
 
 func newSearchResultItem(
@@ -44,6 +50,20 @@ func newSearchResultItem(
 	}
 }
 
+// newSearchElementItem creates a search result item for an argument or enum value, which
+// opens the panel of parentItem on parentTab with childName selected.
+func newSearchElementItem(
+	result search.SearchResult,
+	wrappedItem, parentItem components.ListItem,
+	parentTab, childName string,
+) components.ListItem {
+	item := newSearchResultItem(result, wrappedItem, nil, "", "").(searchResultItem)
+	item.parentItem = parentItem
+	item.parentTab = parentTab
+	item.childName = childName
+	return item
+}
+
 func (i searchResultItem) Title() string { return i.displayTitle }
 func (i searchResultItem) FilterValue() string {
 	// Use wrapped item's filter value for better searching
@@ -53,7 +73,8 @@ func (i searchResultItem) TypeName() string { return i.result.Kind }
 func (i searchResultItem) RefName() string {
 	// For field types, use the full path (Type.field) for breadcrumbs
 	switch i.result.Kind {
-	case "Query", "Mutation", "ObjectField", "InputField", "InterfaceField":
+	case "Query", "Mutation", "ObjectField", "InputField", "InterfaceField",
+		"Argument", "DirectiveArgument", "EnumValue":
 		if i.result.Path != "" {
 			return i.result.Path
 		}
@@ -61,8 +82,16 @@ func (i searchResultItem) RefName() string {
 	// For non-field types, delegate to wrapped item
 	return i.wrappedItem.RefName()
 }
-func (i searchResultItem) Description() string { return i.wrappedItem.Description() }
-func (i searchResultItem) Details() string     { return i.wrappedItem.Details() }
+func (i searchResultItem) Description() string {
+	// Show why the result matched, when the query matched its description or signature
+	for _, field := range []string{"description", "signature"} {
+		if fragments := i.result.Fragments[field]; len(fragments) > 0 {
+			return renderHighlights(fragments[0])
+		}
+	}
+	return i.wrappedItem.Description()
+}
+func (i searchResultItem) Details() string { return i.wrappedItem.Details() }
 
 // OpenPanel opens the parent type's panel with the field highlighted for field types,
 // or delegates to the wrapped item for non-field types
@@ -82,6 +111,15 @@ func (i searchResultItem) OpenPanel() (*components.Panel, bool) {
 			}
 		}
 	}
+	// For arguments and enum values, show the panel listing them with the element selected
+	if i.parentItem != nil {
+		panel, ok := i.parentItem.OpenPanel()
+		if ok {
+			panel.SelectTab(i.parentTab)
+			panel.SelectItemByName(i.childName)
+		}
+		return panel, ok
+	}
 	// For non-field types (Object, Enum, Directive, etc.) or fallback
 	return i.wrappedItem.OpenPanel()
 }
@@ -97,6 +135,12 @@ func AdaptSearchResult(result search.SearchResult, schemaView *SchemaView) compo
 		return adaptType(result, schemaView)
 	case "Directive":
 		return adaptDirective(result, schemaView)
+	case "Argument":
+		return adaptArgument(result, schemaView)
+	case "DirectiveArgument":
+		return adaptDirectiveArgument(result, schemaView)
+	case "EnumValue":
+		return adaptEnumValue(result, schemaView)
 	default:
 		return createFallbackItem(result)
 	}
@@ -148,6 +192,105 @@ func adaptDirective(result search.SearchResult, schemaView *SchemaView) componen
 	return newSearchResultItem(result, wrappedItem, schemaView.resolver, "", "")
 }
 
+// adaptArgument handles field Argument results.
+// Path format: "ParentType.fieldName.argName" (e.g. "Query.user.id")
+func adaptArgument(result search.SearchResult, schemaView *SchemaView) components.ListItem {
+	parts := strings.SplitN(result.Path, ".", 3)
+	if len(parts) != 3 {
+		return createFallbackItem(result)
+	}
+	typeName, fieldName, argName := parts[0], parts[1], parts[2]
+
+	field, err := schemaView.ResolveOutputField(typeName, fieldName)
+	if err != nil {
+		return createFallbackItem(result)
+	}
+	arg := findArgumentByName(field.Arguments(), argName)
+	if arg == nil {
+		return createFallbackItem(result)
+	}
+
+	wrappedItem := newArgumentItem(arg, schemaView.resolver)
+	parentItem := newFieldItem(field, schemaView.resolver)
+	return newSearchElementItem(result, wrappedItem, parentItem, "Inputs", argName)
+}
+
+// adaptDirectiveArgument handles DirectiveArgument results.
+// Path format: "@directiveName.argName" (e.g. "@deprecated.reason")
+func adaptDirectiveArgument(result search.SearchResult, schemaView *SchemaView) components.ListItem {
+	parts := strings.SplitN(strings.TrimPrefix(result.Path, "@"), ".", 2)
+	if len(parts) != 2 {
+		return createFallbackItem(result)
+	}
+	directiveName, argName := parts[0], parts[1]
+
+	directive := schemaView.schema.Directive[directiveName]
+	if directive == nil {
+		return createFallbackItem(result)
+	}
+	arg := findArgumentByName(directive.Arguments(), argName)
+	if arg == nil {
+		return createFallbackItem(result)
+	}
+
+	wrappedItem := newArgumentItem(arg, schemaView.resolver)
+	parentItem := newDirectiveDefItem(directive, schemaView.resolver)
+	return newSearchElementItem(result, wrappedItem, parentItem, "Arguments", argName)
+}
+
+// adaptEnumValue handles EnumValue results.
+// Path format: "EnumName.VALUE" (e.g. "Role.ADMIN")
+func adaptEnumValue(result search.SearchResult, schemaView *SchemaView) components.ListItem {
+	parts := strings.SplitN(result.Path, ".", 2)
+	if len(parts) != 2 {
+		return createFallbackItem(result)
+	}
+	enumName, valueName := parts[0], parts[1]
+
+	enum := schemaView.schema.Enum[enumName]
+	if enum == nil {
+		return createFallbackItem(result)
+	}
+	for _, value := range enum.Values() {
+		if value.Name() == valueName {
+			wrappedItem := adaptEnumValues([]*gql.EnumValue{value})[0]
+			parentItem := newTypeDefItem(enum, schemaView.resolver)
+			return newSearchElementItem(result, wrappedItem, parentItem, "Values", valueName)
+		}
+	}
+	return createFallbackItem(result)
+}
+
+// Matched terms only toggle bold and underline, so that they keep the color the list
+// renders the rest of the description in.
+const (
+	highlightOn  = "\x1b[1;4m"
+	highlightOff = "\x1b[22;24m"
+)
+
+// renderHighlights emphasizes the terms of a search fragment that matched the query.
+func renderHighlights(fragment string) string {
+	var rendered strings.Builder
+	for _, segment := range search.SplitHighlights(fragment) {
+		if segment.Match {
+			rendered.WriteString(highlightOn + segment.Text + highlightOff)
+		} else {
+			rendered.WriteString(segment.Text)
+		}
+	}
+	return rendered.String()
+}
+
+// findArgumentByName returns the argument with the given name, or nil.
+func findArgumentByName(args []*gql.Argument, name string) *gql.Argument {
+	for _, arg := range args {
+		if arg.Name() == name {
+			return arg
+		}
+	}
+	return nil
+}
+
 // createFallbackItem creates a SimpleItem when resolution fails
 func createFallbackItem(result search.SearchResult) components.ListItem {
 	title := result.Path
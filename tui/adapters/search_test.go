@@ -0,0 +1,41 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/search"
+)
+
+func TestSearchResultItem_Description(t *testing.T) {
+	is := is.New(t)
+
+	schemaView, err := ParseSchemaString(`
+		type Query {
+			"Get a user by ID"
+			user(id: ID!): User
+		}
+		type User { id: ID! }
+	`)
+	is.NoErr(err)
+
+	result := search.SearchResult{
+		Kind:        "Query",
+		Name:        "user",
+		Path:        "Query.user",
+		Description: "Get a user by ID",
+		Signature:   "user(id: ID!): User",
+	}
+	item := AdaptSearchResult(result, &schemaView)
+	is.Equal(item.Description(), "Get a user by ID")
+
+	// Matched terms of the description are emphasized
+	result.Fragments = map[string][]string{"description": {"Get a <mark>user</mark> by ID"}}
+	item = AdaptSearchResult(result, &schemaView)
+	is.Equal(item.Description(), "Get a "+highlightOn+"user"+highlightOff+" by ID")
+
+	// Signatures are shown when only they matched
+	result.Fragments = map[string][]string{"signature": {"user(id: ID!): <mark>User</mark>"}}
+	item = AdaptSearchResult(result, &schemaView)
+	is.Equal(item.Description(), "user(id: ID!): "+highlightOn+"User"+highlightOff)
+}
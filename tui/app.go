@@ -1,6 +1,9 @@
 package tui
 
 import (
+	"io"
+	"log"
+
 	tea "charm.land/bubbletea/v2"
 	"github.com/tonysyu/gqlxp/library"
 	"github.com/tonysyu/gqlxp/tui/adapters"
@@ -10,22 +13,25 @@ import (
 // SelectionTarget is a type alias for xplr.SelectionTarget
 type SelectionTarget = xplr.SelectionTarget
 
-func Start(schema adapters.SchemaView) (tea.Model, error) {
-	m := newModelWithXplr(schema)
+func Start(lib library.Library, schema adapters.SchemaView) (tea.Model, error) {
+	m := newModelWithXplr(lib, schema)
 	p := tea.NewProgram(m)
 	return p.Run()
 }
 
-// StartWithLibraryData starts the TUI with library metadata
-func StartWithLibraryData(schema adapters.SchemaView, schemaID string, metadata library.SchemaMetadata) (tea.Model, error) {
-	m := newModelWithXplrAndLibrary(schema, schemaID, metadata)
+// StartWithLibraryData starts the TUI with library metadata.
+// headers ("Key: Value") are sent when running operations from the explorer.
+func StartWithLibraryData(lib library.Library, schema adapters.SchemaView, schemaID string, metadata library.SchemaMetadata, headers []string) (tea.Model, error) {
+	m := newModelWithXplrAndLibrary(lib, schema, schemaID, metadata)
+	m.xplr.SetRequestHeaders(headers)
 	p := tea.NewProgram(m)
 	return p.Run()
 }
 
 // StartWithSelection starts the TUI with library metadata and a pre-selected type/field
-func StartWithSelection(schema adapters.SchemaView, schemaID string, metadata library.SchemaMetadata, target SelectionTarget) (tea.Model, error) {
-	m := newModelWithXplrAndLibrary(schema, schemaID, metadata)
+func StartWithSelection(lib library.Library, schema adapters.SchemaView, schemaID string, metadata library.SchemaMetadata, target SelectionTarget, headers []string) (tea.Model, error) {
+	m := newModelWithXplrAndLibrary(lib, schema, schemaID, metadata)
+	m.xplr.SetRequestHeaders(headers)
 	// Apply selection after model is initialized but before program runs
 	m.xplr.ApplySelection(target)
 	p := tea.NewProgram(m)
@@ -33,17 +39,22 @@ func StartWithSelection(schema adapters.SchemaView, schemaID string, metadata li
 }
 
 // StartSchemaSelector starts the schema selector TUI
-func StartSchemaSelector() (tea.Model, error) {
-	m, err := newModelWithLibselect()
+func StartSchemaSelector(lib library.Library, headers []string) (tea.Model, error) {
+	m, err := newModelWithLibselect(lib)
 	if err != nil {
 		return nil, err
 	}
+	m.xplr.SetRequestHeaders(headers)
 	p := tea.NewProgram(m)
 	return p.Run()
 }
 
+// SetupLogging writes debug logs to logFile, or discards them if logFile is empty so that
+// they don't garble the TUI.
 func SetupLogging(logFile string) error {
-	if logFile != "" {
+	if logFile == "" {
+		log.SetOutput(io.Discard)
+	} else {
 		f, err := tea.LogToFile(logFile, "debug")
 		if err != nil {
 			return err
@@ -36,7 +36,10 @@ type MainKeymaps struct {
 	GlobalKeymaps
 	NextPanel, PrevPanel, NextGQLKind, PrevGQLKind, ToggleOverlay key.Binding
 	SearchFocus, SearchSubmit, SearchClear                        key.Binding
+	NextSearchFilter, PrevSearchFilter                            key.Binding
 	OpenLibSelect                                                 key.Binding
+	ToggleSelection, EditArguments, ToggleQueryBuilder            key.Binding
+	ExportOperation, CopyOperation, RunOperation                  key.Binding
 }
 
 // NewMainKeymaps creates a new MainKeymaps with default bindings
@@ -75,10 +78,42 @@ func NewMainKeymaps() MainKeymaps {
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "clear search"),
 		),
+		NextSearchFilter: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "next filter"),
+		),
+		PrevSearchFilter: key.NewBinding(
+			key.WithKeys("shift+tab"),
+			key.WithHelp("⇧+tab", "prev filter"),
+		),
 		OpenLibSelect: key.NewBinding(
 			key.WithKeys("ctrl+o"),
 			key.WithHelp("⌃+o", "open library"),
 		),
+		ToggleSelection: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "select field"),
+		),
+		EditArguments: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "edit args"),
+		),
+		ToggleQueryBuilder: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "query builder"),
+		),
+		ExportOperation: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "export operation"),
+		),
+		CopyOperation: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy operation"),
+		),
+		RunOperation: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("⌃+r", "run operation"),
+		),
 	}
 }
 
@@ -107,6 +142,11 @@ type LibSelectKeymaps struct {
 	SetDefault    key.Binding
 	UpdateSchema  key.Binding
 	ReindexSchema key.Binding
+	RenameSchema  key.Binding
+	CloneSchema   key.Binding
+	RefreshStale  key.Binding
+	SearchAll     key.Binding
+	CloseSearch   key.Binding
 }
 
 // NewLibSelectKeymaps creates a new LibSelectKeymaps with default bindings
@@ -129,6 +169,92 @@ func NewLibSelectKeymaps() LibSelectKeymaps {
 			key.WithKeys("r"),
 			key.WithHelp("r", "reindex schema"),
 		),
+		RenameSchema: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "rename schema"),
+		),
+		CloneSchema: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "clone schema"),
+		),
+		RefreshStale: key.NewBinding(
+			key.WithKeys("U"),
+			key.WithHelp("U", "refresh stale schemas"),
+		),
+		SearchAll: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "search all schemas"),
+		),
+		CloseSearch: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back to schemas"),
+		),
+	}
+}
+
+// ArgumentFormKeymaps contains keymaps for the query builder's argument form
+type ArgumentFormKeymaps struct {
+	GlobalKeymaps
+	NextInput, PrevInput, Submit, Cancel key.Binding
+}
+
+// NewArgumentFormKeymaps creates a new ArgumentFormKeymaps with default bindings
+func NewArgumentFormKeymaps() ArgumentFormKeymaps {
+	return ArgumentFormKeymaps{
+		GlobalKeymaps: newGlobalKeymaps(),
+		NextInput: key.NewBinding(
+			key.WithKeys("tab", "down"),
+			key.WithHelp("tab/↓", "next argument"),
+		),
+		PrevInput: key.NewBinding(
+			key.WithKeys("shift+tab", "up"),
+			key.WithHelp("⇧+tab/↑", "prev argument"),
+		),
+		Submit: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "save"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+	}
+}
+
+// ResponseKeymaps contains keymaps for the response tree view
+type ResponseKeymaps struct {
+	GlobalKeymaps
+	Up, Down, Expand, Collapse, JumpToType, Close key.Binding
+}
+
+// NewResponseKeymaps creates a new ResponseKeymaps with default bindings
+func NewResponseKeymaps() ResponseKeymaps {
+	return ResponseKeymaps{
+		GlobalKeymaps: newGlobalKeymaps(),
+		Up: key.NewBinding(
+			key.WithKeys("k", "up"),
+			key.WithHelp("k/↑", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("j", "down"),
+			key.WithHelp("j/↓", "down"),
+		),
+		Expand: key.NewBinding(
+			key.WithKeys("l", "right", "space"),
+			key.WithHelp("l/→", "expand"),
+		),
+		Collapse: key.NewBinding(
+			key.WithKeys("h", "left"),
+			key.WithHelp("h/←", "collapse"),
+		),
+		JumpToType: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "go to type"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys(closeOverlayKeys...),
+			key.WithHelp("q/esc", "close"),
+		),
 	}
 }
 
@@ -9,14 +9,16 @@ import (
 
 // Layout dimensions
 const (
-	VisiblePanelCount  = 2
-	HelpHeight         = 5
-	NavbarHeight       = 3
-	BreadcrumbsHeight  = 1
-	PanelTitleHPadding = 1
-	ItemLeftPadding    = 2
-	OverlayPadding     = 1
-	OverlayMargin      = 2
+	VisiblePanelCount = 2
+	HelpHeight        = 5
+	// Fraction of the window width used by the query builder side panel when visible
+	QueryBuilderWidthDivisor = 3
+	NavbarHeight             = 3
+	BreadcrumbsHeight        = 1
+	PanelTitleHPadding       = 1
+	ItemLeftPadding          = 2
+	OverlayPadding           = 1
+	OverlayMargin            = 2
 	// Content inside the overlay must be inset by padding, margin, and a 1-char border on all sides.
 	OverlayInsetMargin = 2 * (OverlayMargin + OverlayPadding + 1)
 )
@@ -44,6 +46,15 @@ type Styles struct {
 
 	// Tag style for rendering entity/node type tags
 	Tag lipgloss.Style
+
+	// Query builder side panel and its status line
+	QueryBuilder       lipgloss.Style
+	QueryBuilderStatus lipgloss.Style
+
+	// Response tree styles for schema type annotations, errors, and the cursor row
+	ResponseType     lipgloss.Style
+	ResponseError    lipgloss.Style
+	ResponseSelected lipgloss.Style
 }
 
 // DefaultStyles returns the default style configuration
@@ -101,6 +112,25 @@ func DefaultStyles() Styles {
 			Background(terminal.ColorLightGray).
 			Foreground(terminal.ColorBlack).
 			Padding(0, 1),
+
+		QueryBuilder: lipgloss.NewStyle().
+			Foreground(terminal.ColorLightGray).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(terminal.ColorDimIndigo),
+
+		QueryBuilderStatus: lipgloss.NewStyle().
+			Foreground(terminal.ColorMidGray).
+			Italic(true),
+
+		ResponseType: lipgloss.NewStyle().
+			Foreground(terminal.ColorDimIndigo),
+
+		ResponseError: lipgloss.NewStyle().
+			Foreground(terminal.ColorSoftRed),
+
+		ResponseSelected: lipgloss.NewStyle().
+			Foreground(terminal.ColorDimMagenta).
+			Bold(true),
 	}
 }
 
@@ -3,20 +3,33 @@ package libselect
 import (
 	"context"
 	"fmt"
+	"log"
 	"sort"
+	"strings"
 	"time"
 
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/list"
 	"charm.land/bubbles/v2/spinner"
+	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/tonysyu/gqlxp/gql/introspection"
 	"github.com/tonysyu/gqlxp/library"
+	"github.com/tonysyu/gqlxp/search"
 	"github.com/tonysyu/gqlxp/tui/adapters"
 	"github.com/tonysyu/gqlxp/tui/config"
 )
 
+// schemaListTitle is the list title while schemas (rather than search results) are shown
+const schemaListTitle = "Select a Schema"
+
+// searchResultLimit is the maximum number of results of a search across all schemas
+const searchResultLimit = 50
+
+// refreshTimeout is the time limit for refreshing each stale schema
+const refreshTimeout = time.Minute
+
 // Model is the TUI for selecting a schema from the library
 type Model struct {
 	list         list.Model
@@ -29,6 +42,16 @@ type Model struct {
 	spinner      spinner.Model
 	isUpdating   bool
 	isReindexing bool
+	isSearching  bool
+	searchInput  textinput.Model
+	// idInput prompts for the new ID of the schema idSource being renamed or cloned
+	idInput  textinput.Model
+	idAction idAction
+	idSource string
+	// staleAfterDays is the age after which schemas are flagged as stale; 0 disables it
+	staleAfterDays int
+	// schemaItems holds the schema list while search results are shown in its place
+	schemaItems []list.Item
 }
 
 type schemaListItem struct {
@@ -36,9 +59,27 @@ type schemaListItem struct {
 	displayName string
 	updatedAt   time.Time
 	isDefault   bool
+	// env is the environment name for items that are environment snapshots of a schema
+	env         string
+	tags        []string
+	description string
+	provenance  library.Provenance
+	// stale marks schemas last updated longer ago than the staleness threshold
+	stale bool
 }
 
 func (i schemaListItem) Title() string {
+	if i.env != "" {
+		return i.environmentTitle()
+	}
+	if i.stale {
+		return i.schemaTitle() + " [stale]"
+	}
+	return i.schemaTitle()
+}
+
+// schemaTitle returns the title of a schema item without badges.
+func (i schemaListItem) schemaTitle() string {
 	if i.displayName == "" || i.displayName == i.id {
 		if i.isDefault {
 			return fmt.Sprintf("%s (Default)", i.id)
@@ -51,20 +92,91 @@ func (i schemaListItem) Title() string {
 	return fmt.Sprintf("%s (id: %s)", i.displayName, i.id)
 }
 
+// environmentTitle returns the title of an environment item, indented below its schema.
+func (i schemaListItem) environmentTitle() string {
+	title := fmt.Sprintf("  ↳ %s (id: %s)", i.env, i.id)
+	if i.isDefault {
+		title = fmt.Sprintf("  ↳ %s (Default; id: %s)", i.env, i.id)
+	}
+	return title
+}
+
 func (i schemaListItem) Description() string {
+	if i.env != "" {
+		if i.updatedAt.IsZero() {
+			return "    not fetched: press u to fetch"
+		}
+		return "    last updated: " + i.updatedAt.Format("2006-01-02 15:04")
+	}
+
+	var parts []string
+	switch i.provenance {
+	case library.ProvenanceShared:
+		parts = append(parts, "shared")
+	case library.ProvenanceOverride:
+		parts = append(parts, "local override")
+	}
+	if i.description != "" {
+		parts = append(parts, i.description)
+	}
 	if i.updatedAt.IsZero() {
-		return "last updated: unknown"
+		parts = append(parts, "last updated: unknown")
+	} else {
+		parts = append(parts, "last updated: "+i.updatedAt.Format("2006-01-02 15:04"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (i schemaListItem) FilterValue() string {
+	return strings.Join(append([]string{i.displayName, i.id}, i.tags...), " ")
+}
+
+// untaggedGroup is the header of the group of schemas without tags
+const untaggedGroup = "untagged"
+
+// groupHeaderItem heads a group of schemas sharing a tag; it cannot be selected
+type groupHeaderItem struct {
+	tag   string
+	count int
+}
+
+func (i groupHeaderItem) Title() string { return "▸ " + i.tag }
+
+func (i groupHeaderItem) Description() string {
+	if i.count == 1 {
+		return "1 schema"
+	}
+	return fmt.Sprintf("%d schemas", i.count)
+}
+
+func (i groupHeaderItem) FilterValue() string { return "" }
+
+// searchResultItem is a result of a search across all schemas
+type searchResultItem struct {
+	result search.SearchResult
+}
+
+func (i searchResultItem) Title() string {
+	return fmt.Sprintf("%s (%s)", i.result.Path, i.result.Kind)
+}
+
+func (i searchResultItem) Description() string {
+	if i.result.SchemaName == "" || i.result.SchemaName == i.result.SchemaID {
+		return "in " + i.result.SchemaID
 	}
-	return "last updated: " + i.updatedAt.Format("2006-01-02 15:04")
+	return fmt.Sprintf("in %s (id: %s)", i.result.SchemaName, i.result.SchemaID)
 }
 
-func (i schemaListItem) FilterValue() string { return i.displayName + " " + i.id }
+func (i searchResultItem) FilterValue() string { return i.result.Path + " " + i.result.SchemaID }
 
 // SchemaSelectedMsg is sent when a schema is selected
 type SchemaSelectedMsg struct {
 	SchemaID string
 	Schema   adapters.SchemaView
 	Metadata library.SchemaMetadata
+	// TypeName and FieldName optionally identify an item to select once the schema is open
+	TypeName  string
+	FieldName string
 }
 
 // DefaultSchemaSetMsg is sent when a schema is set as the default
@@ -93,22 +205,114 @@ type schemaReindexErrMsg struct {
 	err error
 }
 
+// LibrarySearchResultsMsg is sent when a search across all schemas completes
+type LibrarySearchResultsMsg struct {
+	Query   string
+	Results []search.SearchResult
+	// Skipped are the schemas that couldn't be searched
+	Skipped []search.SkippedSchema
+}
+
+// librarySearchErrMsg carries an error from a search across all schemas
+type librarySearchErrMsg struct {
+	err error
+}
+
+// idAction is an action that gives a schema a new ID
+type idAction int
+
+const (
+	renameAction idAction = iota
+	cloneAction
+)
+
+// SchemaRenamedMsg is sent when a schema is renamed or cloned to a new ID
+type SchemaRenamedMsg struct {
+	SchemaID string
+	NewID    string
+	Cloned   bool
+}
+
+// schemaRenameErrMsg carries an error from a rename or clone attempt
+type schemaRenameErrMsg struct {
+	err error
+}
+
+// StaleSchemasRefreshedMsg is sent when stale schemas have been refreshed from their sources
+type StaleSchemasRefreshedMsg struct {
+	Results []library.RefreshResult
+}
+
 // New creates a new library selection model
 func New(lib library.Library) (Model, error) {
 	styles := config.DefaultStyles()
 
-	// Load schemas from library
+	items, staleAfterDays, err := loadItems(lib)
+	if err != nil {
+		return Model{}, err
+	}
+
+	keymap := config.NewLibSelectKeymaps()
+
+	delegate := list.NewDefaultDelegate()
+	listModel := list.New(items, delegate, 0, 0)
+	listModel.Title = schemaListTitle
+	listModel.SetShowStatusBar(false)
+	if _, isHeader := listModel.SelectedItem().(groupHeaderItem); isHeader {
+		listModel.Select(1)
+	}
+	listModel.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{keymap.Select, keymap.SetDefault, keymap.UpdateSchema, keymap.ReindexSchema, keymap.SearchAll}
+	}
+	listModel.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{keymap.Select, keymap.SetDefault, keymap.UpdateSchema, keymap.ReindexSchema, keymap.RenameSchema, keymap.CloneSchema, keymap.RefreshStale, keymap.SearchAll, keymap.CloseSearch}
+	}
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+
+	searchInput := textinput.New()
+	searchInput.Prompt = "Search all schemas: "
+	searchInput.Placeholder = "e.g. Invoice"
+	searchInput.CharLimit = 100
+
+	idInput := textinput.New()
+	idInput.CharLimit = 100
+
+	m := Model{
+		list:           listModel,
+		lib:            lib,
+		styles:         styles,
+		keymap:         keymap,
+		spinner:        s,
+		searchInput:    searchInput,
+		idInput:        idInput,
+		staleAfterDays: staleAfterDays,
+	}
+	m.list.Title = m.schemaListTitle()
+
+	return m, nil
+}
+
+// loadItems returns the list items of the schemas in the library, with their environments,
+// and the staleness threshold used to flag them.
+func loadItems(lib library.Library) ([]list.Item, int, error) {
 	schemas, err := lib.List()
 	if err != nil {
-		return Model{}, fmt.Errorf("failed to load schemas: %w", err)
+		return nil, 0, fmt.Errorf("failed to load schemas: %w", err)
 	}
 
 	defaultID, err := lib.GetDefaultSchema()
 	if err != nil {
-		return Model{}, fmt.Errorf("failed to get default schema: %w", err)
+		return nil, 0, fmt.Errorf("failed to get default schema: %w", err)
+	}
+	staleAfterDays, err := lib.GetStaleAfterDays()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get staleness threshold: %w", err)
 	}
 
 	// Convert to list items
+	now := time.Now()
 	schemaItems := make([]schemaListItem, len(schemas))
 	for i, schema := range schemas {
 		schemaItems[i] = schemaListItem{
@@ -116,6 +320,10 @@ func New(lib library.Library) (Model, error) {
 			displayName: schema.DisplayName,
 			updatedAt:   schema.UpdatedAt,
 			isDefault:   schema.ID == defaultID,
+			tags:        schema.Tags,
+			description: schema.Description,
+			provenance:  schema.Provenance,
+			stale:       library.IsStale(schema.UpdatedAt, staleAfterDays, now),
 		}
 	}
 
@@ -124,36 +332,73 @@ func New(lib library.Library) (Model, error) {
 		return schemaItems[i].isDefault && !schemaItems[j].isDefault
 	})
 
-	items := make([]list.Item, len(schemaItems))
-	for i, item := range schemaItems {
-		items[i] = item
+	// Environments are listed below their schema
+	environments := make(map[string][]library.EnvironmentInfo, len(schemas))
+	for _, schema := range schemas {
+		environments[schema.ID] = schema.Environments
 	}
-
-	keymap := config.NewLibSelectKeymaps()
-
-	delegate := list.NewDefaultDelegate()
-	listModel := list.New(items, delegate, 0, 0)
-	listModel.Title = "Select a Schema"
-	listModel.SetShowStatusBar(false)
-	listModel.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{keymap.Select, keymap.SetDefault, keymap.UpdateSchema, keymap.ReindexSchema}
-	}
-	listModel.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{keymap.Select, keymap.SetDefault, keymap.UpdateSchema, keymap.ReindexSchema}
+	items := make([]list.Item, 0, len(schemaItems))
+	for _, item := range groupByTag(schemaItems) {
+		items = append(items, item)
+		schemaItem, ok := item.(schemaListItem)
+		if !ok {
+			continue
+		}
+		for _, env := range environments[schemaItem.id] {
+			ref := library.SchemaRef(schemaItem.id, env.Name)
+			items = append(items, schemaListItem{
+				id:          ref,
+				displayName: schemaItem.displayName,
+				updatedAt:   env.UpdatedAt,
+				isDefault:   ref == defaultID,
+				env:         env.Name,
+			})
+		}
 	}
+	return items, staleAfterDays, nil
+}
 
-	s := spinner.New()
-	s.Spinner = spinner.Dot
+// groupByTag groups schemas under a header for each tag, in tag order, with untagged
+// schemas last. Schemas with several tags are listed under their first tag. Schemas are
+// not grouped if none have tags.
+func groupByTag(schemaItems []schemaListItem) []list.Item {
+	// Untagged schemas are grouped under the empty tag
+	groups := make(map[string][]schemaListItem)
+	var tags []string
+	for _, item := range schemaItems {
+		var tag string
+		if len(item.tags) > 0 {
+			tag = item.tags[0]
+		}
+		if _, ok := groups[tag]; !ok && tag != "" {
+			tags = append(tags, tag)
+		}
+		groups[tag] = append(groups[tag], item)
+	}
 
-	m := Model{
-		list:    listModel,
-		lib:     lib,
-		styles:  styles,
-		keymap:  keymap,
-		spinner: s,
+	items := make([]list.Item, 0, len(schemaItems)+len(groups))
+	if len(tags) == 0 {
+		for _, item := range schemaItems {
+			items = append(items, item)
+		}
+		return items
 	}
 
-	return m, nil
+	sort.Strings(tags)
+	if _, ok := groups[""]; ok {
+		tags = append(tags, "")
+	}
+	for _, tag := range tags {
+		header := groupHeaderItem{tag: tag, count: len(groups[tag])}
+		if tag == "" {
+			header.tag = untaggedGroup
+		}
+		items = append(items, header)
+		for _, item := range groups[tag] {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 func (m Model) Init() tea.Cmd {
@@ -163,14 +408,34 @@ func (m Model) Init() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
+		if m.searchInput.Focused() {
+			return m.handleSearchInput(msg)
+		}
+		if m.idInput.Focused() {
+			return m.handleIDInput(msg)
+		}
 		switch {
 		case key.Matches(msg, m.keymap.Quit):
 			return m, tea.Quit
 		case key.Matches(msg, m.keymap.Select):
 			// Load selected schema
 			if item, ok := m.list.SelectedItem().(schemaListItem); ok {
+				if item.env != "" && item.updatedAt.IsZero() {
+					m.errMsg = fmt.Sprintf("environment '%s' has not been fetched: press u to fetch it", item.env)
+					m.list.SetSize(m.width, m.height-3)
+					return m, nil
+				}
 				return m, m.loadSchema(item.id)
 			}
+			if item, ok := m.list.SelectedItem().(searchResultItem); ok {
+				return m, m.loadSearchResult(item.result)
+			}
+		case key.Matches(msg, m.keymap.SearchAll) && m.list.FilterState() != list.Filtering:
+			m.errMsg = ""
+			m.list.SetSize(m.width, m.height-3)
+			return m, m.searchInput.Focus()
+		case key.Matches(msg, m.keymap.CloseSearch) && m.schemaItems != nil && m.list.FilterState() == list.Unfiltered:
+			return m.closeSearchResults()
 		case key.Matches(msg, m.keymap.SetDefault):
 			if item, ok := m.list.SelectedItem().(schemaListItem); ok {
 				return m, m.setDefaultSchema(item.id)
@@ -182,6 +447,13 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 				m.list.SetSize(m.width, m.height-3)
 				return m, tea.Batch(m.updateSchema(item.id), m.spinner.Tick)
 			}
+		case key.Matches(msg, m.keymap.RefreshStale) && m.schemaItems == nil:
+			if ids := m.staleSchemaIDs(); len(ids) > 0 {
+				m.errMsg = ""
+				m.isUpdating = true
+				m.list.SetSize(m.width, m.height-3)
+				return m, tea.Batch(m.refreshSchemas(ids), m.spinner.Tick)
+			}
 		case key.Matches(msg, m.keymap.ReindexSchema):
 			if item, ok := m.list.SelectedItem().(schemaListItem); ok {
 				m.errMsg = ""
@@ -189,9 +461,17 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 				m.list.SetSize(m.width, m.height-3)
 				return m, tea.Batch(m.reindexSchema(item.id), m.spinner.Tick)
 			}
+		case key.Matches(msg, m.keymap.RenameSchema) && m.list.FilterState() != list.Filtering:
+			if item, ok := m.list.SelectedItem().(schemaListItem); ok {
+				return m.promptForID(item, renameAction)
+			}
+		case key.Matches(msg, m.keymap.CloneSchema) && m.list.FilterState() != list.Filtering:
+			if item, ok := m.list.SelectedItem().(schemaListItem); ok {
+				return m.promptForID(item, cloneAction)
+			}
 		}
 	case spinner.TickMsg:
-		if m.isUpdating || m.isReindexing {
+		if m.isUpdating || m.isReindexing || m.isSearching {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			return m, cmd
@@ -218,6 +498,35 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 		cmd := m.list.SetItems(items)
 		return m, cmd
+	case StaleSchemasRefreshedMsg:
+		m.isUpdating = false
+		m.list.SetSize(m.width, m.height-2)
+		refreshed := make(map[string]bool, len(msg.Results))
+		var failures []string
+		for _, result := range msg.Results {
+			switch result.Status {
+			case library.RefreshUpdated, library.RefreshUnchanged:
+				refreshed[result.ID] = true
+			default:
+				failures = append(failures, fmt.Sprintf("%s (%v)", result.ID, result.Err))
+			}
+		}
+		items := m.list.Items()
+		now := time.Now()
+		for i, item := range items {
+			if si, ok := item.(schemaListItem); ok && refreshed[si.id] {
+				si.updatedAt = now
+				si.stale = false
+				items[i] = si
+			}
+		}
+		cmd := m.list.SetItems(items)
+		m.list.Title = m.schemaListTitle()
+		if len(failures) > 0 {
+			m.errMsg = "Failed to refresh: " + strings.Join(failures, ", ")
+			m.list.SetSize(m.width, m.height-3)
+		}
+		return m, cmd
 	case schemaUpdateErrMsg:
 		m.isUpdating = false
 		m.errMsg = msg.err.Error()
@@ -232,11 +541,46 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.errMsg = msg.err.Error()
 		m.list.SetSize(m.width, m.height-3)
 		return m, nil
+	case LibrarySearchResultsMsg:
+		m.isSearching = false
+		m.list.SetSize(m.width, m.height-2)
+		if m.schemaItems == nil {
+			m.schemaItems = m.list.Items()
+		}
+		items := make([]list.Item, len(msg.Results))
+		for i, result := range msg.Results {
+			items[i] = searchResultItem{result: result}
+		}
+		m.list.Title = fmt.Sprintf("Results for %q in all schemas (esc: back to schemas)", msg.Query)
+		m.list.ResetFilter()
+		cmd := m.list.SetItems(items)
+		m.list.Select(0)
+		if len(msg.Skipped) > 0 {
+			ids := make([]string, len(msg.Skipped))
+			for i, skipped := range msg.Skipped {
+				log.Printf("search skipped schema '%s': %s", skipped.SchemaID, skipped.Error)
+				ids[i] = skipped.SchemaID
+			}
+			m.errMsg = "Skipped schemas that failed to index: " + strings.Join(ids, ", ")
+			m.list.SetSize(m.width, m.height-3)
+		}
+		return m, cmd
+	case SchemaRenamedMsg:
+		return m.reloadItems(msg.NewID)
+	case schemaRenameErrMsg:
+		m.errMsg = msg.err.Error()
+		m.list.SetSize(m.width, m.height-3)
+		return m, nil
+	case librarySearchErrMsg:
+		m.isSearching = false
+		m.errMsg = msg.err.Error()
+		m.list.SetSize(m.width, m.height-3)
+		return m, nil
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		listHeight := msg.Height - 2
-		if m.errMsg != "" || m.isUpdating || m.isReindexing {
+		if m.errMsg != "" || m.isUpdating || m.isReindexing || m.isSearching || m.searchInput.Focused() || m.idInput.Focused() {
 			listHeight--
 		}
 		m.list.SetSize(msg.Width, listHeight)
@@ -247,6 +591,145 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
+// handleSearchInput handles key presses while the search input is focused.
+func (m Model) handleSearchInput(msg tea.KeyPressMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keymap.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keymap.Select):
+		query := strings.TrimSpace(m.searchInput.Value())
+		if query == "" {
+			return m, nil
+		}
+		m.searchInput.Blur()
+		m.isSearching = true
+		return m, tea.Batch(m.searchLibrary(query), m.spinner.Tick)
+	case key.Matches(msg, m.keymap.CloseSearch):
+		m.searchInput.Blur()
+		m.list.SetSize(m.width, m.height-2)
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	return m, cmd
+}
+
+// promptForID focuses the input for the new ID of a schema being renamed or cloned.
+func (m Model) promptForID(item schemaListItem, action idAction) (Model, tea.Cmd) {
+	if item.env != "" {
+		id, _ := library.ParseSchemaRef(item.id)
+		m.errMsg = fmt.Sprintf("environments cannot be renamed or cloned: select schema '%s'", id)
+		m.list.SetSize(m.width, m.height-3)
+		return m, nil
+	}
+	m.errMsg = ""
+	m.idAction = action
+	m.idSource = item.id
+	if action == renameAction {
+		m.idInput.Prompt = fmt.Sprintf("Rename %s to: ", item.id)
+		m.idInput.SetValue(item.id)
+	} else {
+		m.idInput.Prompt = fmt.Sprintf("Clone %s as: ", item.id)
+		m.idInput.SetValue(item.id + "-copy")
+	}
+	m.idInput.CursorEnd()
+	m.list.SetSize(m.width, m.height-3)
+	return m, m.idInput.Focus()
+}
+
+// handleIDInput handles key presses while the new ID input is focused.
+func (m Model) handleIDInput(msg tea.KeyPressMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keymap.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keymap.Select):
+		newID := strings.TrimSpace(m.idInput.Value())
+		m.idInput.Blur()
+		m.list.SetSize(m.width, m.height-2)
+		if newID == "" || newID == m.idSource {
+			return m, nil
+		}
+		return m, m.renameSchema(m.idSource, newID, m.idAction == cloneAction)
+	case key.Matches(msg, m.keymap.CloseSearch):
+		m.idInput.Blur()
+		m.list.SetSize(m.width, m.height-2)
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.idInput, cmd = m.idInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) renameSchema(schemaID, newID string, clone bool) tea.Cmd {
+	return func() tea.Msg {
+		if clone {
+			if err := m.lib.Clone(schemaID, newID); err != nil {
+				return schemaRenameErrMsg{fmt.Errorf("failed to clone schema: %w", err)}
+			}
+		} else if err := m.lib.Rename(schemaID, newID); err != nil {
+			return schemaRenameErrMsg{fmt.Errorf("failed to rename schema: %w", err)}
+		}
+		return SchemaRenamedMsg{SchemaID: schemaID, NewID: newID, Cloned: clone}
+	}
+}
+
+// reloadItems reloads the schema list from the library and selects schema selectID.
+func (m Model) reloadItems(selectID string) (Model, tea.Cmd) {
+	items, staleAfterDays, err := loadItems(m.lib)
+	if err != nil {
+		m.errMsg = err.Error()
+		m.list.SetSize(m.width, m.height-3)
+		return m, nil
+	}
+	m.staleAfterDays = staleAfterDays
+	m.schemaItems = nil
+	m.list.ResetFilter()
+	cmd := m.list.SetItems(items)
+	for i, item := range items {
+		if si, ok := item.(schemaListItem); ok && si.id == selectID {
+			m.list.Select(i)
+			break
+		}
+	}
+	m.list.Title = m.schemaListTitle()
+	return m, cmd
+}
+
+// closeSearchResults restores the schema list in place of search results.
+func (m Model) closeSearchResults() (Model, tea.Cmd) {
+	cmd := m.list.SetItems(m.schemaItems)
+	m.schemaItems = nil
+	m.list.Title = m.schemaListTitle()
+	m.list.Select(0)
+	return m, cmd
+}
+
+func (m Model) searchLibrary(query string) tea.Cmd {
+	return func() tea.Msg {
+		response, err := m.lib.Search(nil, query, searchResultLimit)
+		if err != nil {
+			return librarySearchErrMsg{fmt.Errorf("search failed: %w", err)}
+		}
+		return LibrarySearchResultsMsg{Query: query, Results: response.Results, Skipped: response.Skipped}
+	}
+}
+
+// loadSearchResult loads the schema of a search result and selects the result in it.
+func (m Model) loadSearchResult(result search.SearchResult) tea.Cmd {
+	typeName, rest, _ := strings.Cut(result.Path, ".")
+	fieldName, _, _ := strings.Cut(rest, ".")
+	load := m.loadSchema(result.SchemaID)
+	return func() tea.Msg {
+		msg := load()
+		if selected, ok := msg.(SchemaSelectedMsg); ok {
+			selected.TypeName = typeName
+			selected.FieldName = fieldName
+			return selected
+		}
+		return msg
+	}
+}
+
 func (m Model) setDefaultSchema(schemaID string) tea.Cmd {
 	return func() tea.Msg {
 		if err := m.lib.SetDefaultSchema(schemaID); err != nil {
@@ -259,15 +742,19 @@ func (m Model) setDefaultSchema(schemaID string) tea.Cmd {
 
 func (m Model) updateSchema(schemaID string) tea.Cmd {
 	return func() tea.Msg {
-		schema, err := m.lib.Get(schemaID)
+		sourceURL, profile, err := m.updateSource(schemaID)
 		if err != nil {
-			return schemaUpdateErrMsg{fmt.Errorf("failed to get schema: %w", err)}
+			return schemaUpdateErrMsg{err}
 		}
-		if schema.Metadata.SourceURL == "" {
+		if sourceURL == "" {
 			return schemaUpdateErrMsg{fmt.Errorf("schema '%s' has no URL to update from", schemaID)}
 		}
 
-		resp, err := introspection.FetchSchema(context.Background(), schema.Metadata.SourceURL, introspection.DefaultClientOptions())
+		opts, err := profile.ClientOptions(nil)
+		if err != nil {
+			return schemaUpdateErrMsg{err}
+		}
+		resp, err := introspection.FetchSchema(context.Background(), sourceURL, opts)
 		if err != nil {
 			return schemaUpdateErrMsg{fmt.Errorf("failed to fetch schema: %w", err)}
 		}
@@ -285,6 +772,55 @@ func (m Model) updateSchema(schemaID string) tea.Cmd {
 	}
 }
 
+// updateSource returns the URL and connection profile used to update a schema or,
+// for an <id>@<env> reference, one of its environments.
+func (m Model) updateSource(schemaRef string) (string, library.ConnectionProfile, error) {
+	id, envName := library.ParseSchemaRef(schemaRef)
+	schema, err := m.lib.Get(id)
+	if err != nil {
+		return "", library.ConnectionProfile{}, fmt.Errorf("failed to get schema: %w", err)
+	}
+	if envName == "" {
+		return schema.Metadata.SourceURL, schema.Metadata.ConnectionProfile(), nil
+	}
+	env, err := schema.Metadata.Environment(envName)
+	if err != nil {
+		return "", library.ConnectionProfile{}, err
+	}
+	return env.SourceURL, env.ConnectionProfile(), nil
+}
+
+// staleSchemaIDs returns the IDs of the listed schemas that are stale.
+func (m Model) staleSchemaIDs() []string {
+	var ids []string
+	for _, item := range m.list.Items() {
+		if si, ok := item.(schemaListItem); ok && si.stale {
+			ids = append(ids, si.id)
+		}
+	}
+	return ids
+}
+
+// schemaListTitle returns the title of the schema list, offering to refresh stale schemas.
+func (m Model) schemaListTitle() string {
+	stale := len(m.staleSchemaIDs())
+	if stale == 0 {
+		return schemaListTitle
+	}
+	return fmt.Sprintf("%s (%d older than %d days: press %s to refresh)",
+		schemaListTitle, stale, m.staleAfterDays, m.keymap.RefreshStale.Help().Key)
+}
+
+// refreshSchemas refreshes schemas from their sources, several at a time.
+func (m Model) refreshSchemas(ids []string) tea.Cmd {
+	return func() tea.Msg {
+		results := library.RefreshSchemas(context.Background(), m.lib, ids, library.RefreshOptions{
+			Timeout: refreshTimeout,
+		})
+		return StaleSchemasRefreshedMsg{Results: results}
+	}
+}
+
 func (m Model) reindexSchema(schemaID string) tea.Cmd {
 	return func() tea.Msg {
 		if err := m.lib.Reindex(schemaID); err != nil {
@@ -317,7 +853,7 @@ func (m Model) loadSchema(schemaID string) tea.Cmd {
 }
 
 func (m Model) View() string {
-	if len(m.list.Items()) == 0 {
+	if len(m.list.Items()) == 0 && m.schemaItems == nil {
 		emptyMsg := lipgloss.NewStyle().
 			Width(m.width).
 			Height(m.height).
@@ -333,6 +869,16 @@ func (m Model) View() string {
 		statusLine := m.spinner.View() + " Reindexing schema..."
 		return lipgloss.JoinVertical(lipgloss.Left, m.list.View(), statusLine)
 	}
+	if m.isSearching {
+		statusLine := m.spinner.View() + " Searching all schemas..."
+		return lipgloss.JoinVertical(lipgloss.Left, m.list.View(), statusLine)
+	}
+	if m.searchInput.Focused() {
+		return lipgloss.JoinVertical(lipgloss.Left, m.list.View(), m.searchInput.View())
+	}
+	if m.idInput.Focused() {
+		return lipgloss.JoinVertical(lipgloss.Left, m.list.View(), m.idInput.View())
+	}
 	if m.errMsg != "" {
 		errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
 		return lipgloss.JoinVertical(lipgloss.Left, m.list.View(), errStyle.Render(m.errMsg))
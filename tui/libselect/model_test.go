@@ -10,6 +10,7 @@ import (
 	"github.com/matryer/is"
 	"github.com/tonysyu/gqlxp/gql"
 	"github.com/tonysyu/gqlxp/library"
+	"github.com/tonysyu/gqlxp/search"
 	"github.com/tonysyu/gqlxp/tui/libselect"
 	"github.com/tonysyu/gqlxp/utils/testx"
 	"github.com/tonysyu/gqlxp/utils/testx/assert"
@@ -27,6 +28,11 @@ type mockLibrary struct {
 	updateContentErr error
 	reindexID        string
 	reindexErr       error
+	searchResults    []search.SearchResult
+	searchErr        error
+	searchQuery      string
+	staleAfterDays   int
+	renameErr        error
 }
 
 func (m *mockLibrary) Add(id, displayName, sourcePath string) error {
@@ -62,10 +68,40 @@ func (m *mockLibrary) Remove(id string) error {
 	return nil
 }
 
+func (m *mockLibrary) Rename(oldID, newID string) error {
+	if m.renameErr != nil {
+		return m.renameErr
+	}
+	for i, schema := range m.schemas {
+		if schema.ID == oldID {
+			m.schemas[i].ID = newID
+		}
+	}
+	return nil
+}
+
+func (m *mockLibrary) Clone(id, newID string) error {
+	if m.renameErr != nil {
+		return m.renameErr
+	}
+	for _, schema := range m.schemas {
+		if schema.ID == id {
+			schema.ID = newID
+			m.schemas = append(m.schemas, schema)
+			return nil
+		}
+	}
+	return nil
+}
+
 func (m *mockLibrary) UpdateMetadata(id string, metadata library.SchemaMetadata) error {
 	return nil
 }
 
+func (m *mockLibrary) ModifyMetadata(id string, modify func(metadata *library.SchemaMetadata) error) error {
+	return nil
+}
+
 func (m *mockLibrary) SetURLPattern(id, typePattern, urlPattern string) error {
 	return nil
 }
@@ -88,6 +124,15 @@ func (m *mockLibrary) SetDefaultSchema(id string) error {
 	return m.setDefaultErr
 }
 
+func (m *mockLibrary) GetStaleAfterDays() (int, error) {
+	return m.staleAfterDays, nil
+}
+
+func (m *mockLibrary) SetStaleAfterDays(days int) error {
+	m.staleAfterDays = days
+	return nil
+}
+
 func (m *mockLibrary) EnsureIndex(_ string, _ *gql.GraphQLSchema) error { return nil }
 
 func (m *mockLibrary) Reindex(id string) error {
@@ -95,6 +140,28 @@ func (m *mockLibrary) Reindex(id string) error {
 	return m.reindexErr
 }
 
+func (m *mockLibrary) WaitForIndexing() error { return nil }
+
+func (m *mockLibrary) Search(_ []string, query string, _ int) (*search.SearchResponse, error) {
+	m.searchQuery = query
+	if m.searchErr != nil {
+		return nil, m.searchErr
+	}
+	return &search.SearchResponse{Total: uint64(len(m.searchResults)), Results: m.searchResults}, nil
+}
+
+func (m *mockLibrary) SyncShared() (library.SharedSyncResult, error) {
+	return library.SharedSyncResult{}, nil
+}
+
+func (m *mockLibrary) SharedLibraryDir() (string, error) {
+	return "", nil
+}
+
+func (m *mockLibrary) MountSharedLibrary(string) error {
+	return nil
+}
+
 func TestModel_Init(t *testing.T) {
 	is := is.New(t)
 
@@ -444,3 +511,313 @@ func TestModel_Update_ReindexKey_Error(t *testing.T) {
 	assert.StringContains(model.View(), "index write failure")
 	is.Equal(lib.reindexID, "schema1") // Library should be called with the correct ID
 }
+
+func TestModel_View_WithEnvironments(t *testing.T) {
+	is := is.New(t)
+	assert := assert.New(t)
+
+	updatedAt := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	lib := &mockLibrary{
+		schemas: []library.SchemaInfo{
+			{
+				ID:          "api",
+				DisplayName: "API",
+				UpdatedAt:   updatedAt,
+				Environments: []library.EnvironmentInfo{
+					{Name: "prod", UpdatedAt: updatedAt},
+					{Name: "staging"},
+				},
+			},
+		},
+	}
+
+	model, err := libselect.New(lib)
+	is.NoErr(err)
+
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	assert.StringContains(testx.NormalizeView(model.View()), testx.NormalizeView(`
+		│ API (id: api)
+		│ last updated: 2024-03-15 10:30
+
+		    ↳ prod (id: api@prod)
+		      last updated: 2024-03-15 10:30
+
+		    ↳ staging (id: api@staging)
+		      not fetched: press u to fetch
+	`))
+}
+
+func TestModel_Update_SelectUnfetchedEnvironment(t *testing.T) {
+	is := is.New(t)
+	assert := assert.New(t)
+
+	lib := &mockLibrary{
+		schemas: []library.SchemaInfo{
+			{ID: "api", DisplayName: "API", Environments: []library.EnvironmentInfo{{Name: "staging"}}},
+		},
+	}
+
+	model, err := libselect.New(lib)
+	is.NoErr(err)
+
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	model, _ = model.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	model, cmd := model.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+
+	is.Equal(cmd, nil) // Unfetched environments are not loaded
+	assert.StringContains(model.View(), "environment 'staging' has not been fetched")
+}
+
+func TestModel_Update_SearchAllSchemas(t *testing.T) {
+	is := is.New(t)
+	assert := assert.New(t)
+
+	lib := &mockLibrary{
+		schemas: []library.SchemaInfo{
+			{ID: "billing", DisplayName: "Billing"},
+			{ID: "users", DisplayName: "Users"},
+		},
+		searchResults: []search.SearchResult{
+			{Kind: "Query", Name: "invoice", Path: "Query.invoice", SchemaID: "billing", SchemaName: "Billing"},
+		},
+	}
+
+	model, err := libselect.New(lib)
+	is.NoErr(err)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	// Press "f" and type a query
+	model, _ = model.Update(tea.KeyPressMsg{Code: 'f', Text: "f"})
+	assert.StringContains(model.View(), "Search all schemas:")
+	for _, r := range "Invoice" {
+		model, _ = model.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+
+	// Submit and process the search
+	model, cmd := model.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	is.True(cmd != nil)
+	assert.StringContains(model.View(), "Searching all schemas...")
+	batchMsg, ok := cmd().(tea.BatchMsg)
+	is.True(ok)
+	for _, subCmd := range batchMsg {
+		model, _ = model.Update(subCmd())
+	}
+
+	is.Equal(lib.searchQuery, "Invoice")
+	view := testx.NormalizeView(model.View())
+	assert.StringContains(view, "Query.invoice (Query)")
+	assert.StringContains(view, "in Billing (id: billing)")
+
+	// Selecting a result opens its schema with the result selected
+	_, cmd = model.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	is.True(cmd != nil)
+	selected, ok := cmd().(libselect.SchemaSelectedMsg)
+	is.True(ok)
+	is.Equal(selected.SchemaID, "billing")
+	is.Equal(selected.TypeName, "Query")
+	is.Equal(selected.FieldName, "invoice")
+
+	// Escape returns to the schema list
+	model, _ = model.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	view = testx.NormalizeView(model.View())
+	assert.StringContains(view, "Users (id: users)")
+	is.True(!strings.Contains(view, "Query.invoice"))
+}
+
+func TestModel_Update_SearchAllSchemas_Error(t *testing.T) {
+	is := is.New(t)
+	assert := assert.New(t)
+
+	lib := &mockLibrary{
+		schemas:   []library.SchemaInfo{{ID: "billing", DisplayName: "Billing"}},
+		searchErr: fmt.Errorf("index unavailable"),
+	}
+
+	model, err := libselect.New(lib)
+	is.NoErr(err)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	model, _ = model.Update(tea.KeyPressMsg{Code: 'f', Text: "f"})
+	model, _ = model.Update(tea.KeyPressMsg{Code: 'x', Text: "x"})
+	model, cmd := model.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	batchMsg, ok := cmd().(tea.BatchMsg)
+	is.True(ok)
+	for _, subCmd := range batchMsg {
+		model, _ = model.Update(subCmd())
+	}
+
+	assert.StringContains(model.View(), "index unavailable")
+	assert.StringContains(testx.NormalizeView(model.View()), "Billing (id: billing)") // schema list is kept
+}
+
+func TestModel_Update_SearchAllSchemas_Skipped(t *testing.T) {
+	is := is.New(t)
+	assert := assert.New(t)
+
+	lib := &mockLibrary{schemas: []library.SchemaInfo{{ID: "billing", DisplayName: "Billing"}}}
+	model, err := libselect.New(lib)
+	is.NoErr(err)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	model, _ = model.Update(libselect.LibrarySearchResultsMsg{
+		Query:   "Invoice",
+		Results: []search.SearchResult{{Kind: "Query", Name: "invoice", Path: "Query.invoice", SchemaID: "billing"}},
+		Skipped: []search.SkippedSchema{{SchemaID: "broken", Error: "failed to parse schema"}},
+	})
+
+	view := testx.NormalizeView(model.View())
+	assert.StringContains(view, "Query.invoice (Query)") // results of the other schemas are shown
+	assert.StringContains(view, "Skipped schemas that failed to index: broken")
+}
+
+func TestModel_View_GroupsByTag(t *testing.T) {
+	is := is.New(t)
+	assert := assert.New(t)
+
+	updatedAt := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	lib := &mockLibrary{
+		schemas: []library.SchemaInfo{
+			{ID: "users", DisplayName: "Users", UpdatedAt: updatedAt},
+			{ID: "billing", DisplayName: "Billing", UpdatedAt: updatedAt, Tags: []string{"payments"}, Description: "Invoices"},
+			{ID: "ledger", DisplayName: "Ledger", UpdatedAt: updatedAt, Tags: []string{"payments"}},
+		},
+	}
+
+	model, err := libselect.New(lib)
+	is.NoErr(err)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+
+	// Tagged schemas are grouped first, and the first schema is selected
+	assert.StringContains(testx.NormalizeView(model.View()), testx.NormalizeView(`
+		  ▸ payments
+		  2 schemas
+
+		│ Billing (id: billing)
+		│ Invoices · last updated: 2024-03-15 10:30
+
+		  Ledger (id: ledger)
+		  last updated: 2024-03-15 10:30
+
+		  ▸ untagged
+		  1 schema
+
+		  Users (id: users)
+	`))
+}
+
+func TestModel_View_FlagsStaleSchemas(t *testing.T) {
+	is := is.New(t)
+	assert := assert.New(t)
+
+	lib := &mockLibrary{
+		schemas: []library.SchemaInfo{
+			{ID: "fresh", DisplayName: "Fresh", UpdatedAt: time.Now()},
+			{ID: "old", DisplayName: "Old", UpdatedAt: time.Now().AddDate(0, 0, -10)},
+		},
+		staleAfterDays: 7,
+	}
+
+	model, err := libselect.New(lib)
+	is.NoErr(err)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+
+	view := testx.NormalizeView(model.View())
+	assert.StringContains(view, "Select a Schema (1 older than 7 days: press U to refresh)")
+	assert.StringContains(view, "Old (id: old) [stale]")
+	assert.StringContains(view, "Fresh (id: fresh)")
+	is.True(!strings.Contains(view, "Fresh (id: fresh) [stale]"))
+
+	// Once refreshed, the badge and the offer to refresh are gone
+	model, _ = model.Update(libselect.StaleSchemasRefreshedMsg{
+		Results: []library.RefreshResult{{ID: "old", Status: library.RefreshUpdated}},
+	})
+	view = testx.NormalizeView(model.View())
+	is.True(!strings.Contains(view, "[stale]"))
+	is.True(!strings.Contains(view, "press U to refresh"))
+}
+
+// typeNewID replaces the text of the new ID input and submits it.
+func typeNewID(t *testing.T, model libselect.Model, newID string) (libselect.Model, tea.Cmd) {
+	t.Helper()
+	for range 20 {
+		model, _ = model.Update(tea.KeyPressMsg{Code: tea.KeyBackspace})
+	}
+	for _, r := range newID {
+		model, _ = model.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	return model.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+}
+
+func TestModel_Update_RenameSchema(t *testing.T) {
+	is := is.New(t)
+	assert := assert.New(t)
+
+	lib := &mockLibrary{
+		schemas: []library.SchemaInfo{
+			{ID: "billing", DisplayName: "Billing"},
+		},
+	}
+	model, err := libselect.New(lib)
+	is.NoErr(err)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	model, _ = model.Update(tea.KeyPressMsg{Code: 'R', Text: "R"})
+	assert.StringContains(model.View(), "Rename billing to:")
+
+	model, cmd := typeNewID(t, model, "payments")
+	is.True(cmd != nil)
+	model, _ = model.Update(cmd())
+
+	view := testx.NormalizeView(model.View())
+	assert.StringContains(view, "Billing (id: payments)")
+	is.True(!strings.Contains(view, "id: billing"))
+}
+
+func TestModel_Update_CloneSchema(t *testing.T) {
+	is := is.New(t)
+	assert := assert.New(t)
+
+	lib := &mockLibrary{
+		schemas: []library.SchemaInfo{
+			{ID: "billing", DisplayName: "Billing"},
+		},
+	}
+	model, err := libselect.New(lib)
+	is.NoErr(err)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	// The new ID is prefilled with a suggestion
+	model, _ = model.Update(tea.KeyPressMsg{Code: 'c', Text: "c"})
+	assert.StringContains(testx.NormalizeView(model.View()), "Clone billing as: billing-copy")
+
+	model, cmd := model.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	is.True(cmd != nil)
+	model, _ = model.Update(cmd())
+
+	view := testx.NormalizeView(model.View())
+	assert.StringContains(view, "Billing (id: billing)")
+	assert.StringContains(view, "Billing (id: billing-copy)")
+}
+
+func TestModel_Update_RenameSchema_Error(t *testing.T) {
+	is := is.New(t)
+	assert := assert.New(t)
+
+	lib := &mockLibrary{
+		schemas: []library.SchemaInfo{
+			{ID: "billing", DisplayName: "Billing"},
+		},
+		renameErr: fmt.Errorf("schema already exists: 'users'"),
+	}
+	model, err := libselect.New(lib)
+	is.NoErr(err)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	model, _ = model.Update(tea.KeyPressMsg{Code: 'R', Text: "R"})
+	model, cmd := typeNewID(t, model, "users")
+	is.True(cmd != nil)
+	model, _ = model.Update(cmd())
+
+	assert.StringContains(model.View(), "failed to rename schema: schema already exists: 'users'")
+}
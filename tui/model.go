@@ -1,11 +1,16 @@
 package tui
 
 import (
+	"log"
+
 	tea "charm.land/bubbletea/v2"
+	"github.com/tonysyu/gqlxp/gql"
 	"github.com/tonysyu/gqlxp/library"
+	"github.com/tonysyu/gqlxp/search"
 	"github.com/tonysyu/gqlxp/tui/adapters"
 	"github.com/tonysyu/gqlxp/tui/libselect"
 	"github.com/tonysyu/gqlxp/tui/xplr"
+	"github.com/tonysyu/gqlxp/tui/xplr/searchmodel"
 )
 
 // sessionState tracks which submodel is active
@@ -23,52 +28,66 @@ type Model struct {
 	xplr      xplr.Model
 	width     int
 	height    int
+	lib       library.Library
+	// watcher watches the source file of the displayed library schema, if it has one
+	watcher *library.SourceWatcher
+}
+
+// sourceChangedMsg is sent when the source file of the watched schema changes
+type sourceChangedMsg struct {
+	watcher *library.SourceWatcher
+	change  library.SourceChange
 }
 
 // newModelWithLibselect creates a model starting in library selection mode
-func newModelWithLibselect() (Model, error) {
-	lib := library.NewLibrary()
+func newModelWithLibselect(lib library.Library) (Model, error) {
 	libselectModel, err := libselect.New(lib)
 	if err != nil {
 		return Model{}, err
 	}
 
 	xplrModel := xplr.NewEmpty()
-	// Set search base directory for search functionality
-	if schemasDir, err := library.GetSchemasDir(); err == nil {
-		xplrModel.SetSearchBaseDir(schemasDir)
-	}
+	// Search the library for search functionality
+	xplrModel.SetSearchFunc(librarySearch(lib))
 
 	return Model{
 		state:     libselectView,
 		libselect: libselectModel,
 		xplr:      xplrModel,
+		lib:       lib,
 	}, nil
 }
 
 // newModelWithXplr creates a model starting in explorer mode
-func newModelWithXplr(schema adapters.SchemaView) Model {
+func newModelWithXplr(lib library.Library, schema adapters.SchemaView) Model {
 	xplrModel := xplr.New(schema)
-	// Set search base directory for search functionality
-	if schemasDir, err := library.GetSchemasDir(); err == nil {
-		xplrModel.SetSearchBaseDir(schemasDir)
-	}
+	// Search the library for search functionality
+	xplrModel.SetSearchFunc(librarySearch(lib))
 	return Model{
 		state: xplrView,
 		xplr:  xplrModel,
+		lib:   lib,
 	}
 }
 
 // newModelWithXplrAndLibrary creates a model starting in explorer mode with library data
-func newModelWithXplrAndLibrary(schema adapters.SchemaView, schemaID string, metadata library.SchemaMetadata) Model {
+func newModelWithXplrAndLibrary(lib library.Library, schema adapters.SchemaView, schemaID string, metadata library.SchemaMetadata) Model {
 	xplrModel := xplr.NewFromSchemaLibrary(schema, schemaID, metadata)
-	// Set search base directory for search functionality
-	if schemasDir, err := library.GetSchemasDir(); err == nil {
-		xplrModel.SetSearchBaseDir(schemasDir)
-	}
-	return Model{
+	// Search the library for search functionality
+	xplrModel.SetSearchFunc(librarySearch(lib))
+	m := Model{
 		state: xplrView,
 		xplr:  xplrModel,
+		lib:   lib,
+	}
+	m.watchSource(schemaID)
+	return m
+}
+
+// librarySearch returns a search function over the indexes of lib.
+func librarySearch(lib library.Library) searchmodel.SearchFunc {
+	return func(schemaID, query string, limit int) (*search.SearchResponse, error) {
+		return lib.Search([]string{schemaID}, query, limit)
 	}
 }
 
@@ -77,7 +96,7 @@ func (m Model) Init() tea.Cmd {
 	case libselectView:
 		return m.libselect.Init()
 	case xplrView:
-		return m.xplr.Init()
+		return tea.Batch(m.xplr.Init(), m.nextSourceChangeCmd())
 	default:
 		return nil
 	}
@@ -115,11 +134,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			Schema:         msg.Schema,
 			SchemaID:       msg.SchemaID,
 			HasLibraryData: true,
+			Metadata:       msg.Metadata,
 		}
 		m.xplr, cmd = m.xplr.Update(schemaLoadedMsg)
+		// Select the search result the schema was opened from, if any
+		m.xplr.ApplySelection(xplr.SelectionTarget{TypeName: msg.TypeName, FieldName: msg.FieldName})
 		// Ensure search index exists in the background
-		indexCmd := ensureSearchIndexCmd(msg.SchemaID, msg.Schema)
-		return m, tea.Batch(cmd, indexCmd)
+		indexCmd := ensureSearchIndexCmd(m.lib, msg.SchemaID, msg.Schema)
+		m.watchSource(msg.SchemaID)
+		return m, tea.Batch(cmd, indexCmd, m.nextSourceChangeCmd())
+	case sourceChangedMsg:
+		if msg.watcher != m.watcher {
+			// Change to a schema that is no longer displayed
+			return m, nil
+		}
+		if loaded, ok := m.sourceLoadedMsg(msg.change); ok {
+			m.xplr, cmd = m.xplr.Update(loaded)
+		}
+		return m, tea.Batch(cmd, m.nextSourceChangeCmd())
 	}
 
 	// Delegate to active submodel
@@ -135,10 +167,61 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// watchSource starts watching the source file of library schema id, stopping any watch of
+// the previously displayed schema. Schemas without a local source file are not watched.
+func (m *Model) watchSource(id string) {
+	if m.watcher != nil {
+		m.watcher.Close()
+		m.watcher = nil
+	}
+	if watcher, err := library.NewSourceWatcher(m.lib, id); err == nil {
+		m.watcher = watcher
+	}
+}
+
+// nextSourceChangeCmd returns a tea.Cmd that waits for the next change to the watched source.
+func (m Model) nextSourceChangeCmd() tea.Cmd {
+	watcher := m.watcher
+	if watcher == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		change, ok := watcher.Next()
+		if !ok {
+			return nil
+		}
+		return sourceChangedMsg{watcher: watcher, change: change}
+	}
+}
+
+// sourceLoadedMsg converts a change to the watched source into a message reloading the
+// schema. Changes that cannot be loaded are logged and the displayed schema is kept.
+func (m Model) sourceLoadedMsg(change library.SourceChange) (xplr.SchemaLoadedMsg, bool) {
+	if change.Err != nil {
+		log.Printf("failed to reload %s: %v", m.watcher.Path(), change.Err)
+		return xplr.SchemaLoadedMsg{}, false
+	}
+	schema, err := m.lib.Get(change.ID)
+	if err != nil {
+		log.Printf("failed to reload schema '%s': %v", change.ID, err)
+		return xplr.SchemaLoadedMsg{}, false
+	}
+	parsed, err := gql.ParseSchema(change.Content)
+	if err != nil {
+		log.Printf("failed to reload schema '%s': %v", change.ID, err)
+		return xplr.SchemaLoadedMsg{}, false
+	}
+	return xplr.SchemaLoadedMsg{
+		Schema:         adapters.NewSchemaView(parsed),
+		SchemaID:       change.ID,
+		HasLibraryData: true,
+		Metadata:       schema.Metadata,
+	}, true
+}
+
 // ensureSearchIndexCmd returns a tea.Cmd that creates the search index if it doesn't exist.
-func ensureSearchIndexCmd(schemaID string, schema adapters.SchemaView) tea.Cmd {
+func ensureSearchIndexCmd(lib library.Library, schemaID string, schema adapters.SchemaView) tea.Cmd {
 	return func() tea.Msg {
-		lib := library.NewLibrary()
 		_ = lib.EnsureIndex(schemaID, schema.Schema())
 		return nil
 	}
@@ -86,7 +86,7 @@ func TestModel_TransitionFromLibselectToXplr(t *testing.T) {
 	is.NoErr(err)
 
 	// Create model starting in libselect mode
-	model, err := newModelWithLibselect()
+	model, err := newModelWithLibselect(lib)
 	is.NoErr(err)
 	is.Equal(model.state, libselectView)
 
@@ -129,7 +129,7 @@ func TestModel_XplrModeHandlesMessagesCorrectly(t *testing.T) {
 	is.NoErr(err)
 
 	// Create model starting in xplr mode
-	model := newModelWithXplr(parsedSchema)
+	model := newModelWithXplr(library.NewLibrary(), parsedSchema)
 	is.Equal(model.state, xplrView)
 
 	// Send a window size message (should be handled by xplr)
@@ -153,7 +153,7 @@ func TestModel_LibselectModeHandlesMessagesCorrectly(t *testing.T) {
 	defer cleanup()
 
 	// Create model starting in libselect mode
-	model, err := newModelWithLibselect()
+	model, err := newModelWithLibselect(library.NewLibrary())
 	is.NoErr(err)
 	is.Equal(model.state, libselectView)
 
@@ -187,7 +187,7 @@ func TestModel_ForwardsWindowSizeOnTransition(t *testing.T) {
 	is.NoErr(err)
 
 	// Create model starting in libselect mode
-	model, err := newModelWithLibselect()
+	model, err := newModelWithLibselect(lib)
 	is.NoErr(err)
 
 	// Send window size message while in libselect mode
@@ -235,7 +235,7 @@ func TestModel_TransitionFromXplrToLibselect(t *testing.T) {
 	parsedSchema, err := adapters.ParseSchemaString(schemaContent)
 	is.NoErr(err)
 
-	model := newModelWithXplr(parsedSchema)
+	model := newModelWithXplr(library.NewLibrary(), parsedSchema)
 	is.Equal(model.state, xplrView)
 
 	updatedModel, _ := model.Update(xplr.OpenLibSelectMsg{})
@@ -259,7 +259,7 @@ func TestModel_OpenLibSelectKeyTransitionsToLibselect(t *testing.T) {
 	parsedSchema, err := adapters.ParseSchemaString(schemaContent)
 	is.NoErr(err)
 
-	model := newModelWithXplr(parsedSchema)
+	model := newModelWithXplr(library.NewLibrary(), parsedSchema)
 	is.Equal(model.state, xplrView)
 
 	// Press ctrl+o to open libselect; runUpdate chains the resulting cmd
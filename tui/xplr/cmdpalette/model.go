@@ -214,6 +214,38 @@ func buildCommandItems(keymaps CommandKeymaps) []list.Item {
 		keymaps.Main.SearchClear,
 	))
 
+	// Add query builder keymaps
+	items = append(items, newCommandItem(
+		"Query Builder",
+		"Add or remove focused field in operation",
+		keymaps.Main.ToggleSelection,
+	))
+	items = append(items, newCommandItem(
+		"Query Builder",
+		"Edit arguments of focused field",
+		keymaps.Main.EditArguments,
+	))
+	items = append(items, newCommandItem(
+		"Query Builder",
+		"Show or hide query builder panel",
+		keymaps.Main.ToggleQueryBuilder,
+	))
+	items = append(items, newCommandItem(
+		"Query Builder",
+		"Export operation and variables to working directory",
+		keymaps.Main.ExportOperation,
+	))
+	items = append(items, newCommandItem(
+		"Query Builder",
+		"Copy operation to clipboard",
+		keymaps.Main.CopyOperation,
+	))
+	items = append(items, newCommandItem(
+		"Query Builder",
+		"Run operation against schema endpoint",
+		keymaps.Main.RunOperation,
+	))
+
 	// Add overlay keymaps
 	items = append(items, newCommandItem(
 		"Overlay",
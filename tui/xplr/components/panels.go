@@ -250,6 +250,19 @@ func (p *Panel) SetTabs(tabs []Tab) {
 	}
 }
 
+// SelectTab switches to the tab with the given label.
+// Returns true if found and selected, false otherwise
+func (p *Panel) SelectTab(label string) bool {
+	for i, tab := range p.tabs {
+		if tab.Label == label {
+			p.activeTab = i
+			p.switchToActiveTab()
+			return true
+		}
+	}
+	return false
+}
+
 // Update items to display with focused style (opposite of SetBlurred)
 func (p *Panel) SetFocused() {
 	p.wrapperStyle = p.styles.FocusedPanel
@@ -75,6 +75,23 @@ func TestPanel_SelectItemByName(t *testing.T) {
 	is.Equal(panel.ListModel.Index(), 0) // Should remain at previous selection
 }
 
+func TestPanel_SelectTab(t *testing.T) {
+	is := is.New(t)
+
+	panel := NewPanel([]ListItem{}, "Test Panel")
+	panel.SetTabs([]Tab{
+		{Label: "Type", Content: []ListItem{NewSimpleItem("User")}},
+		{Label: "Inputs", Content: []ListItem{NewSimpleItem("id"), NewSimpleItem("name")}},
+	})
+
+	is.True(panel.SelectTab("Inputs"))
+	is.Equal(len(panel.Items()), 2)
+	is.True(panel.SelectItemByName("name"))
+
+	is.True(!panel.SelectTab("Directives")) // no such tab
+	is.Equal(len(panel.Items()), 2)
+}
+
 func TestPanelWithEmptyItems(t *testing.T) {
 	assert := assert.New(t)
 
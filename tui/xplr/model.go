@@ -1,10 +1,15 @@
 package xplr
 
 import (
+	"errors"
+	"fmt"
+	"os"
+
 	"charm.land/bubbles/v2/help"
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/tonysyu/gqlxp/gqlfmt"
 	"github.com/tonysyu/gqlxp/library"
 	"github.com/tonysyu/gqlxp/tui/adapters"
 	"github.com/tonysyu/gqlxp/tui/config"
@@ -12,6 +17,8 @@ import (
 	"github.com/tonysyu/gqlxp/tui/xplr/components"
 	"github.com/tonysyu/gqlxp/tui/xplr/navigation"
 	"github.com/tonysyu/gqlxp/tui/xplr/overlay"
+	"github.com/tonysyu/gqlxp/tui/xplr/querybuilder"
+	"github.com/tonysyu/gqlxp/tui/xplr/responseview"
 	"github.com/tonysyu/gqlxp/tui/xplr/searchmodel"
 )
 
@@ -23,6 +30,7 @@ type SchemaLoadedMsg struct {
 	Schema         adapters.SchemaView
 	SchemaID       string
 	HasLibraryData bool
+	Metadata       library.SchemaMetadata
 }
 
 // SelectionTarget specifies a type and optional field to pre-select in the TUI
@@ -37,6 +45,8 @@ const (
 	xplrNormalView     xplrState = iota
 	xplrOverlayView              // overlay is displayed
 	xplrCmdPaletteView           // command palette is displayed
+	xplrArgFormView              // query builder argument form is displayed
+	xplrResponseView             // operation response is displayed
 )
 
 // Model is the main schema explorer model
@@ -50,11 +60,20 @@ type Model struct {
 	overlay overlay.Model
 	// Command palette for discovering and executing commands
 	commandPalette cmdpalette.Model
+	// Query builder for selecting fields into an operation
+	queryBuilder querybuilder.Model
+	// Response tree for the last executed operation
+	responseView responseview.Model
 
 	// Library integration (optional)
 	SchemaID       string // Schema ID if loaded from library
 	HasLibraryData bool   // Whether this schema has library metadata
 
+	// Endpoint, saved connection profile, and extra HTTP headers used when running operations
+	endpoint       string
+	connection     library.ConnectionProfile
+	requestHeaders []string
+
 	// Search sub-model
 	search searchmodel.Model
 
@@ -76,12 +95,14 @@ func NewEmpty() Model {
 	paletteKeymap := config.NewCommandPaletteKeymaps()
 
 	m := Model{
-		help:    help.New(),
-		Styles:  styles,
-		overlay: overlay.New(styles),
-		nav:     navigation.NewNavigationManager(config.VisiblePanelCount),
-		search:  searchmodel.New(mainKeymap),
-		keymap:  mainKeymap,
+		help:         help.New(),
+		Styles:       styles,
+		overlay:      overlay.New(styles),
+		queryBuilder: querybuilder.New(styles),
+		responseView: responseview.New(styles),
+		nav:          navigation.NewNavigationManager(config.VisiblePanelCount),
+		search:       searchmodel.New(mainKeymap),
+		keymap:       mainKeymap,
 	}
 
 	// Create command palette with all keymaps
@@ -101,6 +122,12 @@ func NewEmpty() Model {
 		m.keymap.ToggleOverlay,
 		m.keymap.CommandPalette,
 		m.keymap.OpenLibSelect,
+		m.keymap.ToggleSelection,
+		m.keymap.EditArguments,
+		m.keymap.ToggleQueryBuilder,
+		m.keymap.ExportOperation,
+		m.keymap.CopyOperation,
+		m.keymap.RunOperation,
 	}
 
 	// Don't load panels until schema is provided
@@ -111,6 +138,7 @@ func NewEmpty() Model {
 func New(schema adapters.SchemaView) Model {
 	m := NewEmpty()
 	m.schema = schema
+	m.queryBuilder = m.queryBuilder.SetSchema(*m.schema.Schema())
 	m.resetAndLoadMainPanel()
 	return m
 }
@@ -121,7 +149,10 @@ func NewFromSchemaLibrary(schema adapters.SchemaView, schemaID string, metadata
 	m.schema = schema
 	m.SchemaID = schemaID
 	m.HasLibraryData = true
+	m.endpoint = metadata.SourceURL
+	m.connection = metadata.ConnectionProfile()
 	m.search = m.search.SetContext(&m.schema, schemaID)
+	m.queryBuilder = m.queryBuilder.SetSchema(*m.schema.Schema())
 	m.resetAndLoadMainPanel()
 	return m
 }
@@ -172,6 +203,11 @@ func (m *Model) SelectItemAtIndex(idx int) {
 	}
 }
 
+// IsQueryBuilderVisible returns whether the query builder side panel is displayed
+func (m Model) IsQueryBuilderVisible() bool {
+	return m.queryBuilder.IsVisible()
+}
+
 // SetOverlayStyle sets the overlay style (primarily for testing)
 func (m *Model) SetOverlayStyle(style lipgloss.Style) {
 	m.overlay.Styles.Overlay = style
@@ -183,10 +219,24 @@ func (m Model) Init() tea.Cmd {
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	// Handle close messages from sub-views before routing
-	switch msg.(type) {
-	case overlay.ClosedMsg, cmdpalette.ClosedMsg:
+	switch msg := msg.(type) {
+	case overlay.ClosedMsg, cmdpalette.ClosedMsg, querybuilder.ClosedMsg, responseview.ClosedMsg:
 		m.state = xplrNormalView
 		return m, nil
+	case querybuilder.ExportedMsg:
+		var cmd tea.Cmd
+		m.queryBuilder, cmd = m.queryBuilder.Update(msg)
+		return m, cmd
+	case responseview.ResponseMsg:
+		return m.showResponse(msg), nil
+	case responseview.JumpToTypeMsg:
+		m.state = xplrNormalView
+		m.ApplySelection(SelectionTarget{TypeName: msg.TypeName})
+		return m, nil
+	case SchemaLoadedMsg:
+		// Handled in every view, since a watched schema can change while a sub-view is open
+		m.loadSchema(msg)
+		return m, nil
 	}
 
 	// Route to the active sub-view
@@ -199,22 +249,22 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		var cmd tea.Cmd
 		m.overlay, cmd = m.overlay.Update(msg)
 		return m, cmd
+	case xplrArgFormView:
+		var cmd tea.Cmd
+		m.queryBuilder, cmd = m.queryBuilder.Update(msg)
+		return m, cmd
+	case xplrResponseView:
+		var cmd tea.Cmd
+		m.responseView, cmd = m.responseView.Update(msg)
+		return m, cmd
 	}
 
 	var cmds []tea.Cmd
 
 	// Handle global messages (only reached in xplrNormalView)
 	switch msg := msg.(type) {
-	case SchemaLoadedMsg:
-		// Update schema and related properties
-		m.schema = msg.Schema
-		m.SchemaID = msg.SchemaID
-		m.HasLibraryData = msg.HasLibraryData
-		m.search = m.search.SetContext(&m.schema, msg.SchemaID)
-		m.resetAndLoadMainPanel()
-		return m, nil
 	case searchmodel.ResultsReadyMsg:
-		m.search = m.search.StoreResults(msg.Items)
+		m.search = m.search.ReceiveResults(msg)
 		if m.nav.CurrentKind() == navigation.SearchKind {
 			m.resetAndLoadMainPanel()
 		}
@@ -352,11 +402,123 @@ func (m Model) handleNormal(msg tea.Msg, cmds []tea.Cmd) (Model, tea.Cmd) {
 		m, cmds = m.handleNextPanel(cmds)
 	case key.Matches(keyMsg, m.keymap.PrevPanel):
 		m = m.handlePrevPanel()
+	case key.Matches(keyMsg, m.keymap.ToggleSelection):
+		m = m.toggleSelectedField()
+	case key.Matches(keyMsg, m.keymap.EditArguments):
+		var cmd tea.Cmd
+		m, cmd = m.editSelectedFieldArguments()
+		cmds = append(cmds, cmd)
+	case key.Matches(keyMsg, m.keymap.ToggleQueryBuilder):
+		m.queryBuilder = m.queryBuilder.ToggleVisible()
+	case key.Matches(keyMsg, m.keymap.ExportOperation):
+		cmds = append(cmds, m.exportOperation())
+	case key.Matches(keyMsg, m.keymap.CopyOperation):
+		var cmd tea.Cmd
+		m.queryBuilder, cmd = m.queryBuilder.Copy()
+		cmds = append(cmds, cmd)
+	case key.Matches(keyMsg, m.keymap.RunOperation):
+		var cmd tea.Cmd
+		m, cmd = m.runOperation()
+		cmds = append(cmds, cmd)
 	}
 
 	return m.updateFocusedPanel(msg, cmds)
 }
 
+// selectedFieldPath resolves the path of the field selected in the current panel.
+// The breadcrumbs leading to the current panel become the parents of the field.
+func (m Model) selectedFieldPath() (gqlfmt.SelectionPath, error) {
+	panel := m.nav.CurrentPanel()
+	if panel == nil {
+		return gqlfmt.SelectionPath{}, errors.New("no field selected")
+	}
+	listItem, ok := panel.SelectedItem().(components.ListItem)
+	if !ok {
+		return gqlfmt.SelectionPath{}, errors.New("no field selected")
+	}
+	return gqlfmt.ResolveSelectionPath(
+		m.queryBuilder.Schema(),
+		string(m.nav.CurrentKind()),
+		m.nav.Breadcrumbs(),
+		listItem.RefName(),
+	)
+}
+
+// toggleSelectedField adds or removes the selected field from the query builder
+func (m Model) toggleSelectedField() Model {
+	path, err := m.selectedFieldPath()
+	if err != nil {
+		m.queryBuilder = m.queryBuilder.SetStatus(err.Error())
+		return m
+	}
+	m.queryBuilder = m.queryBuilder.Toggle(path)
+	m.sizePanels()
+	return m
+}
+
+// editSelectedFieldArguments opens the argument form for the selected field
+func (m Model) editSelectedFieldArguments() (Model, tea.Cmd) {
+	path, err := m.selectedFieldPath()
+	if err != nil {
+		m.queryBuilder = m.queryBuilder.SetStatus(err.Error())
+		return m, nil
+	}
+	var opened bool
+	var cmd tea.Cmd
+	m.queryBuilder, opened, cmd = m.queryBuilder.ShowArguments(path, m.width, m.height)
+	if opened {
+		m.state = xplrArgFormView
+	}
+	m.sizePanels()
+	return m, cmd
+}
+
+// runOperation executes the built operation against the schema's endpoint
+func (m Model) runOperation() (Model, tea.Cmd) {
+	operation, variables := m.queryBuilder.Operation()
+	if operation == "" {
+		m.queryBuilder = m.queryBuilder.SetStatus("Nothing to run: no fields selected")
+		return m, nil
+	}
+	if m.endpoint == "" {
+		m.queryBuilder = m.queryBuilder.SetStatus("Schema has no endpoint URL; add it to the library from a URL to run operations")
+		return m, nil
+	}
+	opts, err := m.connection.ClientOptions(m.requestHeaders)
+	if err != nil {
+		m.queryBuilder = m.queryBuilder.SetStatus(err.Error())
+		return m, nil
+	}
+	m.queryBuilder = m.queryBuilder.SetStatus("Running operation against " + m.endpoint)
+	return m, responseview.Execute(m.endpoint, m.queryBuilder.RootType(), operation, variables, opts)
+}
+
+// showResponse displays the result of an executed operation
+func (m Model) showResponse(msg responseview.ResponseMsg) Model {
+	if msg.Err != nil {
+		m.queryBuilder = m.queryBuilder.SetStatus(msg.Err.Error())
+		return m
+	}
+	responseView, err := m.responseView.Show(m.queryBuilder.Schema(), msg.RootType, msg.Response, m.width, m.height)
+	if err != nil {
+		m.queryBuilder = m.queryBuilder.SetStatus(err.Error())
+		return m
+	}
+	m.responseView = responseView
+	m.queryBuilder = m.queryBuilder.SetStatus(fmt.Sprintf("Received response with %d error(s)", len(msg.Response.Errors)))
+	m.state = xplrResponseView
+	return m
+}
+
+// exportOperation writes the built operation to the working directory
+func (m Model) exportOperation() tea.Cmd {
+	dir, err := os.Getwd()
+	if err != nil {
+		return func() tea.Msg { return querybuilder.ExportedMsg{Err: err} }
+	}
+	return m.queryBuilder.Export(dir)
+}
+
 // handleNextPanel moves forward in the navigation stack
 func (m Model) handleNextPanel(cmds []tea.Cmd) (Model, []tea.Cmd) {
 	var moved bool
@@ -412,13 +574,12 @@ func (m Model) shouldFocusedPanelReceiveMessage(msg tea.Msg) bool {
 }
 
 func (m *Model) sizePanels() {
-	panelWidth := m.width / config.VisiblePanelCount
+	panelWidth := (m.width - m.queryBuilderWidth()) / config.VisiblePanelCount
 	panelHeight := m.height - config.HelpHeight - config.NavbarHeight - config.BreadcrumbsHeight
 
-	// Reserve space for search input if on Search tab
-	const searchInputHeight = 3 // Height for search input field
+	// Reserve space for search input and filter chips if on Search tab
 	if m.nav.CurrentKind() == navigation.SearchKind {
-		panelHeight -= searchInputHeight
+		panelHeight -= m.search.Height()
 	}
 
 	// Size only the visible panels (config.VisiblePanelCount = 2)
@@ -437,6 +598,14 @@ func (m *Model) sizePanels() {
 	}
 }
 
+// queryBuilderWidth returns the width reserved for the query builder side panel
+func (m Model) queryBuilderWidth() int {
+	if !m.queryBuilder.IsVisible() {
+		return 0
+	}
+	return m.width / config.QueryBuilderWidthDivisor
+}
+
 // updateKeybindings enables or disables key bindings based on search focus state
 func (m *Model) updateKeybindings() {
 	if m.search.IsFocused() {
@@ -466,9 +635,88 @@ func (m *Model) handleOpenPanel(newPanel *components.Panel) {
 	m.sizePanels()
 }
 
-// SetSearchBaseDir sets the base directory for search indexes
-func (m *Model) SetSearchBaseDir(baseDir string) {
-	m.search = m.search.SetBaseDir(baseDir)
+// SetRequestHeaders sets HTTP headers ("Key: Value") sent when running operations
+func (m *Model) SetRequestHeaders(headers []string) {
+	m.requestHeaders = headers
+}
+
+// SetSearchFunc sets the function used to search the displayed schema
+func (m *Model) SetSearchFunc(search searchmodel.SearchFunc) {
+	m.search = m.search.SetSearchFunc(search)
+}
+
+// loadSchema displays a loaded schema. Reloading the displayed schema, such as after its
+// source file changed, keeps the current position and selection, and the operation being
+// built, where they still exist. The argument form stays open only if its field still takes
+// the same arguments, and the response view, which is resolved against the schema the
+// operation ran on, is closed.
+func (m *Model) loadSchema(msg SchemaLoadedMsg) {
+	var path []string
+	reloaded := m.HasLibraryData && msg.SchemaID == m.SchemaID
+	if reloaded {
+		path = m.selectionPath()
+	}
+
+	// Update schema and related properties
+	m.schema = msg.Schema
+	m.SchemaID = msg.SchemaID
+	m.HasLibraryData = msg.HasLibraryData
+	m.endpoint = msg.Metadata.SourceURL
+	m.connection = msg.Metadata.ConnectionProfile()
+	m.search = m.search.SetContext(&m.schema, msg.SchemaID)
+	if reloaded {
+		m.queryBuilder = m.queryBuilder.ReloadSchema(*m.schema.Schema())
+	} else {
+		m.queryBuilder = m.queryBuilder.SetSchema(*m.schema.Schema())
+	}
+	switch {
+	case m.state == xplrArgFormView && (!reloaded || !m.queryBuilder.FormResolves()):
+		m.state = xplrNormalView
+		m.queryBuilder = m.queryBuilder.SetStatus("Closed the argument form: its field changed in the reloaded schema")
+	case m.state == xplrResponseView:
+		m.state = xplrNormalView
+		m.queryBuilder = m.queryBuilder.SetStatus("Closed the response: the schema was reloaded")
+	}
+	m.resetAndLoadMainPanel()
+	m.restoreSelectionPath(path)
+}
+
+// selectionPath returns the names of the items selected in each panel up to the current one.
+func (m Model) selectionPath() []string {
+	stack := m.nav.Stack()
+	panels := stack.All()
+	var path []string
+	for _, panel := range panels[:min(stack.Position()+1, len(panels))] {
+		item, ok := panel.SelectedItem().(components.ListItem)
+		if !ok {
+			break
+		}
+		path = append(path, item.RefName())
+	}
+	return path
+}
+
+// restoreSelectionPath selects the items named by path in successive panels, opening each
+// selected item, and stops at the first item that no longer exists.
+func (m *Model) restoreSelectionPath(path []string) {
+	for i, name := range path {
+		panel := m.nav.CurrentPanel()
+		if panel == nil || !panel.SelectItemByName(name) {
+			return
+		}
+		if openCmd := panel.OpenSelectedItem(); openCmd != nil {
+			if msg, ok := openCmd().(components.OpenPanelMsg); ok {
+				m.handleOpenPanel(msg.Panel)
+			}
+		}
+		if i == len(path)-1 {
+			return
+		}
+		var moved bool
+		if m.nav, moved = m.nav.NavigateForward(); !moved {
+			return
+		}
+	}
 }
 
 // resetAndLoadMainPanel defines initial panels and loads currently selected GQL type.
@@ -0,0 +1,350 @@
+package querybuilder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/gqlfmt"
+	"github.com/tonysyu/gqlxp/tui/config"
+	"github.com/tonysyu/gqlxp/tui/utils"
+	"github.com/tonysyu/gqlxp/utils/text"
+)
+
+// ClosedMsg is sent when the argument form requests to be closed
+type ClosedMsg struct{}
+
+// ExportedMsg is sent when an export started by Export completes
+type ExportedMsg struct {
+	Paths []string
+	Err   error
+}
+
+// Model holds the operation being built and renders it in a side panel.
+// It also owns the argument form, which xplr.Model displays as an overlay.
+type Model struct {
+	// builder is shared by copies of the Model, so it's cloned before each change
+	builder *gqlfmt.OperationBuilder
+	visible bool
+	status  string
+
+	// Argument form state
+	formPath   gqlfmt.SelectionPath
+	formArgs   []gqlfmt.ArgumentValue
+	inputs     []textinput.Model
+	focusIndex int
+
+	Styles config.Styles
+	keymap config.ArgumentFormKeymaps
+	help   help.Model
+	width  int
+	height int
+}
+
+// New creates an empty query builder
+func New(styles config.Styles) Model {
+	return Model{
+		builder: gqlfmt.NewOperationBuilder(gql.GraphQLSchema{}),
+		Styles:  styles,
+		keymap:  config.NewArgumentFormKeymaps(),
+		help:    help.New(),
+	}
+}
+
+// SetSchema discards the current operation and starts a new one for schema
+func (m Model) SetSchema(schema gql.GraphQLSchema) Model {
+	m.builder = gqlfmt.NewOperationBuilder(schema)
+	m.status = ""
+	return m
+}
+
+// ReloadSchema keeps the current operation for a new version of its schema, dropping the
+// selections that no longer exist in it
+func (m Model) ReloadSchema(schema gql.GraphQLSchema) Model {
+	m.builder = m.builder.Rebase(schema)
+	return m
+}
+
+// FormResolves reports whether the field of the argument form still accepts the arguments
+// shown in it, which may not be the case after the schema was reloaded
+func (m Model) FormResolves() bool {
+	args, err := m.builder.Arguments(m.formPath)
+	if err != nil || len(args) != len(m.formArgs) {
+		return false
+	}
+	for i, arg := range args {
+		if arg.Name != m.formArgs[i].Name || arg.Type != m.formArgs[i].Type {
+			return false
+		}
+	}
+	return true
+}
+
+// Schema returns the schema used to resolve selections
+func (m Model) Schema() gql.GraphQLSchema {
+	return m.builder.Schema()
+}
+
+// Operation returns the operation document and its variables
+func (m Model) Operation() (string, map[string]any) {
+	return m.builder.Operation()
+}
+
+// RootType returns the root type of the operation ("Query" or "Mutation"), or "" if empty
+func (m Model) RootType() string {
+	return m.builder.Root()
+}
+
+// Status returns the message displayed below the operation
+func (m Model) Status() string {
+	return m.status
+}
+
+// IsVisible reports whether the side panel is displayed
+func (m Model) IsVisible() bool {
+	return m.visible
+}
+
+// ToggleVisible shows or hides the side panel
+func (m Model) ToggleVisible() Model {
+	m.visible = !m.visible
+	return m
+}
+
+// SetStatus sets the message displayed below the operation
+func (m Model) SetStatus(status string) Model {
+	m.status = status
+	return m
+}
+
+// Toggle adds or removes the field at path and shows the side panel
+func (m Model) Toggle(path gqlfmt.SelectionPath) Model {
+	m.visible = true
+	m.builder = m.builder.Clone()
+	selected, err := m.builder.Toggle(path)
+	switch {
+	case err != nil:
+		m.status = err.Error()
+	case selected:
+		m.status = "Added " + path.String()
+	default:
+		m.status = "Removed " + path.String()
+	}
+	return m
+}
+
+// ShowArguments opens the argument form for the field at path.
+// Returns false if the form was not opened, in which case the status explains why.
+func (m Model) ShowArguments(path gqlfmt.SelectionPath, width, height int) (Model, bool, tea.Cmd) {
+	m.visible = true
+	args, err := m.builder.Arguments(path)
+	if err != nil {
+		m.status = err.Error()
+		return m, false, nil
+	}
+	if len(args) == 0 {
+		m.status = path.String() + " has no arguments"
+		return m, false, nil
+	}
+
+	m.width = width
+	m.height = height
+	m.formPath = path
+	m.formArgs = args
+	m.inputs = make([]textinput.Model, len(args))
+	for i, arg := range args {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.Placeholder = arg.Type
+		ti.SetWidth(max(width/2, 20))
+		ti.SetValue(arg.Value)
+		m.inputs[i] = ti
+	}
+	m.focusIndex = 0
+	return m, true, m.inputs[0].Focus()
+}
+
+// Update processes messages for the argument form and export results.
+// xplr.Model is responsible for routing key messages here only when the form is active.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ExportedMsg:
+		if msg.Err != nil {
+			m.status = msg.Err.Error()
+		} else {
+			m.status = "Exported " + strings.Join(msg.Paths, ", ")
+		}
+		return m, nil
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, m.keymap.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keymap.Cancel):
+			return m, func() tea.Msg { return ClosedMsg{} }
+		case key.Matches(msg, m.keymap.Submit):
+			m = m.saveArguments()
+			return m, func() tea.Msg { return ClosedMsg{} }
+		case key.Matches(msg, m.keymap.NextInput):
+			return m.focusInput(m.focusIndex + 1)
+		case key.Matches(msg, m.keymap.PrevInput):
+			return m.focusInput(m.focusIndex - 1)
+		}
+	}
+
+	if len(m.inputs) == 0 {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+	return m, cmd
+}
+
+// Export returns a command that writes the operation to <dir>/<Name>.graphql and its
+// variables, if any, to <dir>/<Name>.variables.json. Existing files are never overwritten:
+// the export fails instead. Completion is reported via ExportedMsg.
+func (m Model) Export(dir string) tea.Cmd {
+	operation, variables := m.builder.Operation()
+	name := m.builder.OperationName()
+	return func() tea.Msg {
+		if operation == "" {
+			return ExportedMsg{Err: fmt.Errorf("nothing to export: no fields selected")}
+		}
+		var variablesData []byte
+		if variables != nil {
+			data, err := json.MarshalIndent(variables, "", "  ")
+			if err != nil {
+				return ExportedMsg{Err: fmt.Errorf("failed to encode variables: %w", err)}
+			}
+			variablesData = append(data, '\n')
+		}
+
+		operationPath := filepath.Join(dir, name+".graphql")
+		if err := writeNewFile(operationPath, []byte(operation+"\n")); err != nil {
+			return ExportedMsg{Err: fmt.Errorf("failed to write operation: %w", err)}
+		}
+		paths := []string{operationPath}
+		if variablesData != nil {
+			variablesPath := filepath.Join(dir, name+".variables.json")
+			if err := writeNewFile(variablesPath, variablesData); err != nil {
+				// Don't leave half an export behind
+				_ = os.Remove(operationPath)
+				return ExportedMsg{Err: fmt.Errorf("failed to write variables: %w", err)}
+			}
+			paths = append(paths, variablesPath)
+		}
+		return ExportedMsg{Paths: paths}
+	}
+}
+
+// writeNewFile writes data to a file at path that must not exist yet.
+func writeNewFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists", path)
+	} else if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Copy returns the model and a command that copies the operation to the clipboard.
+// Variables are appended as a comment so the copied text remains a valid document.
+func (m Model) Copy() (Model, tea.Cmd) {
+	operation, variables := m.builder.Operation()
+	if operation == "" {
+		m.status = "Nothing to copy: no fields selected"
+		return m, nil
+	}
+	if variables != nil {
+		if data, err := json.Marshal(variables); err == nil {
+			operation += "\n\n# variables: " + string(data)
+		}
+	}
+	m.status = "Copied operation to clipboard"
+	return m, tea.SetClipboard(operation)
+}
+
+// View renders the side panel with the given outer dimensions
+func (m Model) View(width, height int) string {
+	style := m.Styles.QueryBuilder
+	contentWidth := max(width-style.GetHorizontalFrameSize(), 0)
+	contentHeight := max(height-style.GetVerticalFrameSize(), 0)
+
+	title := m.Styles.PanelTitle.Render("Query Builder")
+	operation, _ := m.builder.Operation()
+	if operation == "" {
+		operation = "Press x on a Query or Mutation field to add it to the operation."
+	}
+	body := lipgloss.NewStyle().Width(contentWidth).Render(operation)
+	content := text.JoinParagraphs(title, body)
+	if m.status != "" {
+		content = text.JoinParagraphs(content, m.Styles.QueryBuilderStatus.Width(contentWidth).Render(m.status))
+	}
+
+	return style.
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		Render(lipgloss.NewStyle().MaxHeight(contentHeight).Render(content))
+}
+
+// FormView renders the argument form as a centered overlay
+func (m Model) FormView() string {
+	lines := []string{m.Styles.PanelTitle.Render("Arguments: " + m.formPath.String())}
+	for i, arg := range m.formArgs {
+		label := arg.Name + ": " + arg.Type
+		if i == m.focusIndex {
+			label = m.Styles.CurrentBreadcrumb.Render(label)
+		}
+		lines = append(lines, "", label, m.inputs[i].View())
+	}
+	helpView := m.help.ShortHelpView([]key.Binding{
+		m.keymap.NextInput,
+		m.keymap.PrevInput,
+		m.keymap.Submit,
+		m.keymap.Cancel,
+	})
+	content := text.JoinParagraphs(strings.Join(lines, "\n"), helpView)
+	return utils.CenterOverlay(m.Styles.Overlay.Render(content), m.width, m.height)
+}
+
+func (m Model) focusInput(index int) (Model, tea.Cmd) {
+	if len(m.inputs) == 0 {
+		return m, nil
+	}
+	m.inputs[m.focusIndex].Blur()
+	m.focusIndex = (index + len(m.inputs)) % len(m.inputs)
+	return m, m.inputs[m.focusIndex].Focus()
+}
+
+func (m Model) saveArguments() Model {
+	values := make(map[string]string, len(m.formArgs))
+	for i, arg := range m.formArgs {
+		values[arg.Name] = m.inputs[i].Value()
+	}
+	m.builder = m.builder.Clone()
+	if err := m.builder.SetArguments(m.formPath, values); err != nil {
+		m.status = err.Error()
+	} else {
+		m.status = "Updated arguments for " + m.formPath.String()
+	}
+	return m
+}
@@ -0,0 +1,220 @@
+package xplr
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+	"github.com/tonysyu/gqlxp/tui/adapters"
+	"github.com/tonysyu/gqlxp/tui/xplr/querybuilder"
+	"github.com/tonysyu/gqlxp/tui/xplr/responseview"
+)
+
+const queryBuilderTestSchema = `
+	type Query {
+		user(id: ID!): User
+	}
+
+	type User {
+		id: ID!
+		name: String!
+	}
+`
+
+var (
+	keyToggleSelection = tea.KeyPressMsg{Code: 'x', Text: "x"}
+	keyEditArguments   = tea.KeyPressMsg{Code: 'a', Text: "a"}
+	keySubmit          = tea.KeyPressMsg{Code: tea.KeyEnter}
+)
+
+func TestQueryBuilder_SelectFieldFromBreadcrumbs(t *testing.T) {
+	is := is.New(t)
+	model := newTestModel(queryBuilderTestSchema)
+
+	model.Update(keyNextPanel) // user
+	model.Update(keyNextPanel) // User
+	model.Update(keyNextItem)  // name
+	model.Update(keyToggleSelection)
+
+	operation, _ := model.Model.queryBuilder.Operation()
+	is.True(model.Model.IsQueryBuilderVisible()) // selecting a field shows the builder
+	is.Equal(operation, `query User {
+  user {
+    name
+  }
+}`)
+	is.True(strings.Contains(model.View(), "Query Builder"))
+}
+
+func TestQueryBuilder_SelectOutsideRootType(t *testing.T) {
+	is := is.New(t)
+	model := newTestModel(queryBuilderTestSchema)
+
+	model.Update(keyNextType) // Mutation
+	model.Update(keyNextType) // Object
+	model.Update(keyToggleSelection)
+
+	operation, _ := model.Model.queryBuilder.Operation()
+	is.Equal(operation, "")
+	is.True(model.Model.queryBuilder.Status() != "") // explains why nothing was selected
+}
+
+func TestQueryBuilder_EditArguments(t *testing.T) {
+	is := is.New(t)
+	model := newTestModel(queryBuilderTestSchema).Model
+
+	// Update directly rather than via testModel, since the focused input's cursor blink
+	// command never settles.
+	model, _ = model.Update(keyEditArguments)
+	is.Equal(model.state, xplrArgFormView)
+
+	for _, r := range "42" {
+		model, _ = model.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	model, cmd := model.Update(keySubmit)
+	model, _ = model.Update(cmd())
+
+	is.Equal(model.state, xplrNormalView)
+	operation, variables := model.queryBuilder.Operation()
+	is.Equal(operation, `query User($id: ID!) {
+  user(id: $id) {
+    __typename
+  }
+}`)
+	is.Equal(variables["id"], "42")
+}
+
+func TestQueryBuilder_ReloadKeepsOperation(t *testing.T) {
+	is := is.New(t)
+	model := newTestModel(queryBuilderTestSchema)
+	model.Model.SchemaID = "test"
+	model.Model.HasLibraryData = true
+
+	model.Update(keyNextPanel)       // user
+	model.Update(keyNextPanel)       // User
+	model.Update(keyToggleSelection) // id
+	model.Update(keyNextItem)        // name
+	model.Update(keyToggleSelection)
+	operation, _ := model.Model.queryBuilder.Operation()
+	is.Equal(operation, `query User {
+  user {
+    id
+    name
+  }
+}`)
+
+	// The source was saved with User.id removed
+	reloaded, err := adapters.ParseSchemaString(`
+		type Query {
+			user(id: ID!): User
+		}
+
+		type User {
+			name: String!
+			email: String!
+		}
+	`)
+	is.NoErr(err)
+	model.Update(SchemaLoadedMsg{Schema: reloaded, SchemaID: "test", HasLibraryData: true})
+
+	operation, _ = model.Model.queryBuilder.Operation()
+	is.Equal(operation, `query User {
+  user {
+    name
+  }
+}`)
+
+	// Loading another schema starts a new operation
+	model.Update(SchemaLoadedMsg{Schema: reloaded, SchemaID: "other", HasLibraryData: true})
+	operation, _ = model.Model.queryBuilder.Operation()
+	is.Equal(operation, "")
+}
+
+func TestQueryBuilder_ExportDoesNotOverwrite(t *testing.T) {
+	is := is.New(t)
+	model := newTestModel(queryBuilderTestSchema)
+	model.Update(keyNextPanel) // user
+	model.Update(keyNextPanel) // User
+	model.Update(keyToggleSelection)
+	dir := t.TempDir()
+	operationPath := filepath.Join(dir, "User.graphql")
+	is.NoErr(os.WriteFile(operationPath, []byte("# my query\n"), 0644))
+
+	msg := model.Model.queryBuilder.Export(dir)()
+
+	exported, ok := msg.(querybuilder.ExportedMsg)
+	is.True(ok)
+	is.True(exported.Err != nil) // existing files are not overwritten
+	content, err := os.ReadFile(operationPath)
+	is.NoErr(err)
+	is.Equal(string(content), "# my query\n")
+
+	is.NoErr(os.Remove(operationPath))
+	exported = model.Model.queryBuilder.Export(dir)().(querybuilder.ExportedMsg)
+	is.NoErr(exported.Err)
+	is.Equal(exported.Paths, []string{operationPath})
+}
+
+func TestQueryBuilder_CopiesDoNotShareOperation(t *testing.T) {
+	is := is.New(t)
+	model := newTestModel(queryBuilderTestSchema)
+	model.Update(keyNextPanel) // user
+	model.Update(keyNextPanel) // User
+	model.Update(keyToggleSelection)
+	before := model.Model
+	operation, _ := before.queryBuilder.Operation()
+
+	model.Update(keyNextItem) // name
+	model.Update(keyToggleSelection)
+
+	unchanged, _ := before.queryBuilder.Operation()
+	is.Equal(unchanged, operation) // earlier copies of the model keep their operation
+	changed, _ := model.Model.queryBuilder.Operation()
+	is.True(changed != operation)
+}
+
+func TestQueryBuilder_ReloadClosesStaleViews(t *testing.T) {
+	is := is.New(t)
+	model := newTestModel(queryBuilderTestSchema).Model
+	model.SchemaID = "test"
+	model.HasLibraryData = true
+	reload := func(sdl string) {
+		t.Helper()
+		schema, err := adapters.ParseSchemaString(sdl)
+		is.NoErr(err)
+		model, _ = model.Update(SchemaLoadedMsg{Schema: schema, SchemaID: "test", HasLibraryData: true})
+	}
+
+	// The argument form stays open while its field takes the same arguments
+	model, _ = model.Update(keyEditArguments)
+	is.Equal(model.state, xplrArgFormView)
+	reload(queryBuilderTestSchema + "\ntype Extra { id: ID! }")
+	is.Equal(model.state, xplrArgFormView)
+
+	// ...and is closed once they change
+	reload(`
+		type Query {
+			user(login: String!): User
+		}
+
+		type User {
+			id: ID!
+		}
+	`)
+	is.Equal(model.state, xplrNormalView)
+	is.True(strings.Contains(model.queryBuilder.Status(), "argument form"))
+
+	// A response is resolved against the schema the operation ran on, so it's closed
+	model, _ = model.Update(responseview.ResponseMsg{
+		RootType: "Query",
+		Response: &introspection.OperationResponse{Data: json.RawMessage(`{"user": null}`)},
+	})
+	is.Equal(model.state, xplrResponseView)
+	reload(queryBuilderTestSchema)
+	is.Equal(model.state, xplrNormalView)
+}
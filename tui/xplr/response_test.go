@@ -0,0 +1,80 @@
+package xplr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+	"github.com/tonysyu/gqlxp/library"
+	"github.com/tonysyu/gqlxp/tui/xplr/navigation"
+	"github.com/tonysyu/gqlxp/tui/xplr/responseview"
+)
+
+func TestRunOperation_RequiresEndpoint(t *testing.T) {
+	is := is.New(t)
+	model := newTestModel(queryBuilderTestSchema)
+	model.Update(keyToggleSelection)
+
+	model.Update(tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl})
+
+	is.Equal(model.Model.state, xplrNormalView)
+	is.True(model.Model.queryBuilder.Status() != "") // explains that no endpoint is known
+}
+
+func TestRunOperation_ShowsResponse(t *testing.T) {
+	is := is.New(t)
+
+	var received introspection.OperationRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		_, _ = w.Write([]byte(`{"data": {"user": {"__typename": "User"}}}`))
+	}))
+	defer server.Close()
+
+	model := newTestModel(queryBuilderTestSchema)
+	model.Model.endpoint = server.URL
+	model.Update(keyToggleSelection)
+
+	model.Update(tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl})
+
+	is.Equal(received.Query, "query User {\n  user {\n    __typename\n  }\n}")
+	is.Equal(model.Model.state, xplrResponseView)
+}
+
+func TestRunOperation_UsesConnectionProfile(t *testing.T) {
+	is := is.New(t)
+	t.Setenv("GQLXP_TEST_TOKEN", "secret")
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"data": {"user": {"__typename": "User"}}}`))
+	}))
+	defer server.Close()
+
+	model := newTestModel(queryBuilderTestSchema)
+	model.Model.endpoint = server.URL
+	model.Model.connection = library.ConnectionProfile{
+		Headers: map[string]string{"Authorization": "Bearer ${env:GQLXP_TEST_TOKEN}"},
+	}
+	model.Update(keyToggleSelection)
+
+	model.Update(tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl})
+
+	is.Equal(authorization, "Bearer secret") // secret references are resolved at request time
+}
+
+func TestResponseView_JumpToType(t *testing.T) {
+	is := is.New(t)
+	model := newTestModel(queryBuilderTestSchema)
+	model.Model.state = xplrResponseView
+
+	model.Update(responseview.JumpToTypeMsg{TypeName: "User"})
+
+	is.Equal(model.Model.state, xplrNormalView)
+	is.Equal(model.Model.nav.CurrentKind(), navigation.ObjectKind)
+}
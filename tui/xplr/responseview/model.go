@@ -0,0 +1,250 @@
+package responseview
+
+import (
+	"context"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+	"github.com/tonysyu/gqlxp/tui/config"
+	"github.com/tonysyu/gqlxp/tui/utils"
+	"github.com/tonysyu/gqlxp/utils/text"
+)
+
+// ClosedMsg is sent when the response view requests to be closed
+type ClosedMsg struct{}
+
+// JumpToTypeMsg is sent when the user asks to view the schema type of a response node
+type JumpToTypeMsg struct {
+	TypeName string
+}
+
+// ResponseMsg is sent when an operation started by Execute completes
+type ResponseMsg struct {
+	RootType string // "Query" or "Mutation"
+	Response *introspection.OperationResponse
+	Err      error
+}
+
+// Execute returns a command that sends operation to endpoint and reports the result via ResponseMsg.
+func Execute(endpoint, rootType, operation string, variables map[string]any, opts introspection.ClientOptions) tea.Cmd {
+	return func() tea.Msg {
+		req := introspection.OperationRequest{Query: operation, Variables: variables}
+		resp, err := introspection.Execute(context.Background(), endpoint, req, opts)
+		return ResponseMsg{RootType: rootType, Response: resp, Err: err}
+	}
+}
+
+// Model displays a GraphQL response as a collapsible tree
+type Model struct {
+	root   *node
+	errors []string // response errors that don't belong to a specific node
+	rows   []*node  // visible nodes in display order
+	depths []int    // depth of each visible node
+	cursor int
+	offset int
+	Styles config.Styles
+	keymap config.ResponseKeymaps
+	help   help.Model
+	width  int
+	height int
+	loaded bool
+}
+
+// New creates an empty response view
+func New(styles config.Styles) Model {
+	return Model{
+		Styles: styles,
+		keymap: config.NewResponseKeymaps(),
+		help:   help.New(),
+	}
+}
+
+// HasResponse reports whether a response has been loaded
+func (m Model) HasResponse() bool {
+	return m.loaded
+}
+
+// Show loads resp into the tree, resolving node types against schema from rootType.
+// xplr.Model is responsible for setting its state to display the response view.
+func (m Model) Show(schema gql.GraphQLSchema, rootType string, resp *introspection.OperationResponse, width, height int) (Model, error) {
+	root, unplaced, err := buildTree(schema, rootType, resp.Data, resp.Errors)
+	if err != nil {
+		return m, err
+	}
+	m.root = root
+	m.errors = unplaced
+	m.cursor = 0
+	m.offset = 0
+	m.width = width
+	m.height = height
+	m.loaded = true
+	m.refreshRows()
+	return m, nil
+}
+
+// Update processes messages and returns (model, cmd).
+// xplr.Model is responsible for routing messages here only when the response view is active.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, m.keymap.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keymap.Close):
+			return m, func() tea.Msg { return ClosedMsg{} }
+		case key.Matches(msg, m.keymap.Up):
+			m.moveCursor(-1)
+		case key.Matches(msg, m.keymap.Down):
+			m.moveCursor(1)
+		case key.Matches(msg, m.keymap.Expand):
+			m.toggleExpanded()
+		case key.Matches(msg, m.keymap.Collapse):
+			m.collapse()
+		case key.Matches(msg, m.keymap.JumpToType):
+			if n := m.selected(); n != nil && n.typeName != "" {
+				typeName := n.typeName
+				return m, func() tea.Msg { return JumpToTypeMsg{TypeName: typeName} }
+			}
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the response tree as a centered overlay
+func (m Model) View() string {
+	contentWidth := max(m.width-config.OverlayInsetMargin, 10)
+	visibleRows := m.visibleRowCount()
+
+	var lines []string
+	for _, msg := range m.errors {
+		lines = append(lines, m.Styles.ResponseError.Render("✗ "+msg))
+	}
+	end := min(m.offset+visibleRows, len(m.rows))
+	for i := m.offset; i < end; i++ {
+		lines = append(lines, text.Truncate(m.renderRow(i), contentWidth))
+	}
+
+	helpView := m.help.ShortHelpView([]key.Binding{
+		m.keymap.Up,
+		m.keymap.Down,
+		m.keymap.Expand,
+		m.keymap.Collapse,
+		m.keymap.JumpToType,
+		m.keymap.Close,
+	})
+	title := m.Styles.PanelTitle.Render("Response")
+	content := text.JoinParagraphs(title, strings.Join(lines, "\n"), helpView)
+	return utils.CenterOverlay(m.Styles.Overlay.Render(content), m.width, m.height)
+}
+
+func (m Model) renderRow(i int) string {
+	n := m.rows[i]
+	marker := "  "
+	if n.isContainer() && len(n.children) > 0 {
+		marker = "▸ "
+		if n.expanded {
+			marker = "▾ "
+		}
+	}
+
+	line := n.label
+	if !n.isContainer() {
+		line += ": " + n.value
+	} else if !n.expanded || len(n.children) == 0 {
+		line += " " + n.summary()
+	}
+	if i == m.cursor {
+		line = m.Styles.ResponseSelected.Render(line)
+	}
+	if n.typeName != "" {
+		line += " " + m.Styles.ResponseType.Render(n.typeName)
+	}
+	for _, msg := range n.errors {
+		line += " " + m.Styles.ResponseError.Render("✗ "+msg)
+	}
+	return strings.Repeat("  ", m.depths[i]) + marker + line
+}
+
+// visibleRowCount returns the number of tree rows that fit in the overlay
+func (m Model) visibleRowCount() int {
+	const titleAndHelpHeight = 4
+	return max(m.height-config.OverlayInsetMargin-titleAndHelpHeight-len(m.errors), 1)
+}
+
+func (m Model) selected() *node {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return nil
+	}
+	return m.rows[m.cursor]
+}
+
+func (m *Model) moveCursor(delta int) {
+	if len(m.rows) == 0 {
+		return
+	}
+	m.cursor = min(max(m.cursor+delta, 0), len(m.rows)-1)
+	visibleRows := m.visibleRowCount()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+visibleRows {
+		m.offset = m.cursor - visibleRows + 1
+	}
+}
+
+func (m *Model) toggleExpanded() {
+	if n := m.selected(); n != nil && n.isContainer() {
+		n.expanded = !n.expanded
+		m.refreshRows()
+	}
+}
+
+// collapse closes the selected node, or moves to its parent if already closed
+func (m *Model) collapse() {
+	n := m.selected()
+	if n == nil {
+		return
+	}
+	if n.isContainer() && n.expanded {
+		n.expanded = false
+		m.refreshRows()
+		return
+	}
+	if n.parent == nil {
+		return
+	}
+	for i, row := range m.rows {
+		if row == n.parent {
+			m.moveCursor(i - m.cursor)
+			return
+		}
+	}
+}
+
+// refreshRows recomputes the visible nodes after nodes are expanded or collapsed
+func (m *Model) refreshRows() {
+	m.rows = nil
+	m.depths = nil
+	var walk func(n *node, depth int)
+	walk = func(n *node, depth int) {
+		m.rows = append(m.rows, n)
+		m.depths = append(m.depths, depth)
+		if !n.expanded {
+			return
+		}
+		for _, c := range n.children {
+			walk(c, depth+1)
+		}
+	}
+	if m.root != nil {
+		walk(m.root, 0)
+	}
+	m.cursor = min(m.cursor, max(len(m.rows)-1, 0))
+	m.moveCursor(0)
+}
@@ -0,0 +1,51 @@
+package responseview
+
+import (
+	"encoding/json"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+	"github.com/tonysyu/gqlxp/tui/config"
+)
+
+var (
+	keyDown     = tea.KeyPressMsg{Code: 'j', Text: "j"}
+	keyCollapse = tea.KeyPressMsg{Code: 'h', Text: "h"}
+	keyEnter    = tea.KeyPressMsg{Code: tea.KeyEnter}
+)
+
+func newTestView(t *testing.T, data string) Model {
+	t.Helper()
+	resp := &introspection.OperationResponse{Data: json.RawMessage(data)}
+	m, err := New(config.DefaultStyles()).Show(mustParseSchema(t), "Query", resp, 120, 40)
+	if err != nil {
+		t.Fatalf("failed to show response: %v", err)
+	}
+	return m
+}
+
+func TestModel_CollapseAndJump(t *testing.T) {
+	is := is.New(t)
+	m := newTestView(t, `{"user": {"name": "Ada", "friends": []}}`)
+	is.Equal(len(m.rows), 4) // data, user, name, friends
+
+	m, _ = m.Update(keyDown) // user
+	m, _ = m.Update(keyCollapse)
+	is.Equal(len(m.rows), 2) // user's fields are hidden
+
+	m, cmd := m.Update(keyEnter)
+	is.Equal(cmd(), JumpToTypeMsg{TypeName: "User"})
+}
+
+func TestModel_CollapseMovesToParent(t *testing.T) {
+	is := is.New(t)
+	m := newTestView(t, `{"user": {"name": "Ada"}}`)
+
+	m, _ = m.Update(keyDown) // user
+	m, _ = m.Update(keyDown) // name
+	m, _ = m.Update(keyCollapse)
+
+	is.Equal(m.selected().label, "user")
+}
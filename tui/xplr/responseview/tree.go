@@ -0,0 +1,179 @@
+package responseview
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+	"github.com/tonysyu/gqlxp/gqlfmt"
+)
+
+// maxExpandedListLength is the longest list that is expanded when a response is loaded.
+const maxExpandedListLength = 10
+
+// node is an entry in the response tree: an object field, a list element, or a scalar.
+type node struct {
+	label    string // field name, or "[i]" for list elements
+	value    string // rendered scalar value; empty for objects and lists
+	isObject bool
+	isList   bool
+	typeName string   // schema type the value belongs to; empty if unknown
+	errors   []string // messages of response errors whose path ends at this node
+	children []*node
+	parent   *node
+	expanded bool
+}
+
+func (n *node) isContainer() bool {
+	return n.isObject || n.isList
+}
+
+// summary describes a collapsed container, e.g. "{2 fields}" or "[3 items]".
+func (n *node) summary() string {
+	switch {
+	case n.isObject:
+		return fmt.Sprintf("{%d fields}", len(n.children))
+	case n.isList:
+		return fmt.Sprintf("[%d items]", len(n.children))
+	}
+	return n.value
+}
+
+// child returns the child reached by a response path element (field name or list index).
+func (n *node) child(pathElement any) *node {
+	label := fmt.Sprint(pathElement)
+	if n.isList {
+		label = "[" + label + "]"
+	}
+	for _, c := range n.children {
+		if c.label == label {
+			return c
+		}
+	}
+	return nil
+}
+
+// buildTree decodes response data into a tree annotated with schema types, starting at
+// rootType. Errors are attached to the deepest node reached by their path; errors without a
+// path are returned separately.
+func buildTree(schema gql.GraphQLSchema, rootType string, data json.RawMessage, errs []introspection.Error) (*node, []string, error) {
+	root := &node{label: "data", value: "null"}
+	if len(bytes.TrimSpace(data)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		decoded, err := decodeNode(dec, "data")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse response data: %w", err)
+		}
+		root = decoded
+	}
+	annotate(schema, root, rootType)
+	root.expanded = true
+
+	var unplaced []string
+	for _, e := range errs {
+		if len(e.Path) == 0 {
+			unplaced = append(unplaced, e.Message)
+			continue
+		}
+		target := root
+		for _, element := range e.Path {
+			next := target.child(element)
+			if next == nil {
+				break
+			}
+			target = next
+		}
+		target.errors = append(target.errors, e.Message)
+		// Expand ancestors so errors are visible without searching for them
+		for p := target.parent; p != nil; p = p.parent {
+			p.expanded = true
+		}
+	}
+	return root, unplaced, nil
+}
+
+// decodeNode reads the next JSON value from dec, preserving object key order.
+func decodeNode(dec *json.Decoder, label string) (*node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &node{label: label}
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '{':
+			n.isObject = true
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ := keyTok.(string)
+				child, err := decodeNode(dec, key)
+				if err != nil {
+					return nil, err
+				}
+				child.parent = n
+				n.children = append(n.children, child)
+			}
+		case '[':
+			n.isList = true
+			for i := 0; dec.More(); i++ {
+				child, err := decodeNode(dec, "["+strconv.Itoa(i)+"]")
+				if err != nil {
+					return nil, err
+				}
+				child.parent = n
+				n.children = append(n.children, child)
+			}
+		}
+		// Consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		// Long lists start collapsed to keep the tree navigable
+		n.expanded = n.isObject || len(n.children) <= maxExpandedListLength
+	case string:
+		n.value = strconv.Quote(tok)
+	case json.Number:
+		n.value = tok.String()
+	case bool:
+		n.value = strconv.FormatBool(tok)
+	case nil:
+		n.value = "null"
+	}
+	return n, nil
+}
+
+// annotate records the schema type of n and its descendants. Objects that include
+// __typename are annotated with their concrete type, which resolves unions and interfaces.
+func annotate(schema gql.GraphQLSchema, n *node, typeName string) {
+	n.typeName = typeName
+	switch {
+	case n.isList:
+		for _, c := range n.children {
+			annotate(schema, c, typeName)
+		}
+	case n.isObject:
+		for _, c := range n.children {
+			if c.label == "__typename" && !c.isContainer() {
+				if concrete, err := strconv.Unquote(c.value); err == nil && concrete != "" {
+					n.typeName = concrete
+				}
+			}
+		}
+		for _, c := range n.children {
+			if strings.HasPrefix(c.label, "__") {
+				continue
+			}
+			childType, _ := gqlfmt.FieldTypeName(schema, n.typeName, c.label)
+			annotate(schema, c, childType)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package responseview
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/gql"
+	"github.com/tonysyu/gqlxp/gql/introspection"
+)
+
+const testSchema = `
+	type Query {
+		user(id: ID!): User
+		search(query: String!): [SearchResult!]!
+	}
+	type User {
+		name: String!
+		friends: [User!]!
+	}
+	type Repository {
+		stars: Int!
+	}
+	union SearchResult = User | Repository
+`
+
+func mustParseSchema(t *testing.T) gql.GraphQLSchema {
+	t.Helper()
+	schema, err := gql.ParseSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	return schema
+}
+
+func TestBuildTree_AnnotatesSchemaTypes(t *testing.T) {
+	is := is.New(t)
+	data := json.RawMessage(`{"user": {"name": "Ada", "friends": [{"name": "Bob"}]}}`)
+
+	root, unplaced, err := buildTree(mustParseSchema(t), "Query", data, nil)
+
+	is.NoErr(err)
+	is.Equal(len(unplaced), 0)
+	user := root.child("user")
+	is.Equal(root.typeName, "Query")
+	is.Equal(user.typeName, "User")
+	is.Equal(user.child("name").value, `"Ada"`)
+	is.Equal(user.child("name").typeName, "String")
+	friends := user.child("friends")
+	is.True(friends.isList)
+	is.Equal(friends.child(0).typeName, "User") // list elements share the list's type
+}
+
+func TestBuildTree_PreservesFieldOrder(t *testing.T) {
+	is := is.New(t)
+	data := json.RawMessage(`{"user": {"name": "Ada", "friends": []}}`)
+
+	root, _, err := buildTree(mustParseSchema(t), "Query", data, nil)
+
+	is.NoErr(err)
+	user := root.child("user")
+	is.Equal(user.children[0].label, "name")
+	is.Equal(user.children[1].label, "friends")
+}
+
+func TestBuildTree_TypenameResolvesUnionMembers(t *testing.T) {
+	is := is.New(t)
+	data := json.RawMessage(`{"search": [{"__typename": "Repository", "stars": 5}, {"name": "Ada"}]}`)
+
+	root, _, err := buildTree(mustParseSchema(t), "Query", data, nil)
+
+	is.NoErr(err)
+	search := root.child("search")
+	is.Equal(search.child(0).typeName, "Repository")         // concrete type from __typename
+	is.Equal(search.child(0).child("stars").typeName, "Int") // fields resolve on concrete type
+	is.Equal(search.child(1).typeName, "SearchResult")       // falls back to declared type
+	is.Equal(search.child(1).child("name").typeName, "")     // unknown without __typename
+}
+
+func TestBuildTree_AttachesErrorsToPath(t *testing.T) {
+	is := is.New(t)
+	data := json.RawMessage(`{"user": {"name": "Ada", "friends": [{"name": null}]}}`)
+	errs := []introspection.Error{
+		{Message: "Name hidden", Path: []any{"user", "friends", float64(0), "name"}},
+		{Message: "Rate limited"},
+		{Message: "Missing", Path: []any{"user", "unknown"}},
+	}
+
+	root, unplaced, err := buildTree(mustParseSchema(t), "Query", data, errs)
+
+	is.NoErr(err)
+	user := root.child("user")
+	is.Equal(user.child("friends").child(0).child("name").errors, []string{"Name hidden"})
+	is.Equal(user.errors, []string{"Missing"}) // attached to deepest existing node
+	is.Equal(unplaced, []string{"Rate limited"})
+}
+
+func TestBuildTree_NullData(t *testing.T) {
+	is := is.New(t)
+
+	root, _, err := buildTree(mustParseSchema(t), "Query", json.RawMessage(`null`), nil)
+
+	is.NoErr(err)
+	is.Equal(root.value, "null")
+	is.True(!root.isContainer())
+}
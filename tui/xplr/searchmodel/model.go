@@ -1,8 +1,11 @@
 package searchmodel
 
 import (
+	"fmt"
+
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
 	gosearch "github.com/tonysyu/gqlxp/search"
 	"github.com/tonysyu/gqlxp/tui/adapters"
 	"github.com/tonysyu/gqlxp/tui/config"
@@ -10,24 +13,45 @@ import (
 )
 
 // ResultsReadyMsg is emitted when converted search results are ready.
-// The parent stores them via StoreResults and reloads the panel.
+// The parent stores them via ReceiveResults and reloads the panel.
 type ResultsReadyMsg struct {
 	Items []components.ListItem
+	// Kind is the kind the search was filtered by, or "" for a search of all kinds.
+	Kind string
+	// Total and Kinds count every match of a search of all kinds, including those past the
+	// result limit. They are unset for a filtered search, which keeps the filter chips of
+	// the search it refines.
+	Total uint64
+	Kinds []gosearch.FacetCount
 }
 
+// SearchFunc searches the schema identified by schemaID, returning at most limit results.
+type SearchFunc func(schemaID, query string, limit int) (*gosearch.SearchResponse, error)
+
+// resultLimit is the maximum number of results shown for a search.
+const resultLimit = 50
+
 type Model struct {
 	input    components.SearchInput
 	results  []components.ListItem
 	schemaID string
-	baseDir  string
+	search   SearchFunc
 	schema   *adapters.SchemaView
 	keymap   config.MainKeymaps
+	styles   config.Styles
+
+	// The submitted query, the counts of its matches, and the kind filter chip selected
+	query string
+	total uint64
+	kinds []gosearch.FacetCount
+	kind  string
 }
 
 func New(keymap config.MainKeymaps) Model {
 	return Model{
 		input:  components.NewSearchInput(),
 		keymap: keymap,
+		styles: config.DefaultStyles(),
 	}
 }
 
@@ -37,8 +61,8 @@ func (m Model) SetContext(schema *adapters.SchemaView, schemaID string) Model {
 	return m
 }
 
-func (m Model) SetBaseDir(dir string) Model {
-	m.baseDir = dir
+func (m Model) SetSearchFunc(search SearchFunc) Model {
+	m.search = search
 	return m
 }
 
@@ -56,6 +80,22 @@ func (m Model) StoreResults(items []components.ListItem) Model {
 	return m
 }
 
+// ReceiveResults stores the results of a search, along with the match counts shown as
+// filter chips when the search was not filtered by kind.
+func (m Model) ReceiveResults(msg ResultsReadyMsg) Model {
+	m = m.StoreResults(msg.Items)
+	if msg.Kind == "" {
+		m.total = msg.Total
+		m.kinds = msg.Kinds
+	}
+	return m
+}
+
+// KindFilter returns the kind the displayed results are filtered by, or "" for all kinds.
+func (m Model) KindFilter() string {
+	return m.kind
+}
+
 func (m Model) Focus() (Model, tea.Cmd) {
 	var cmd tea.Cmd
 	m.input, cmd = m.input.Focus()
@@ -68,13 +108,46 @@ func (m Model) Blur() Model {
 }
 
 func (m Model) View() string {
+	if chips := m.chipsView(); chips != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, m.input.View(), chips)
+	}
 	return m.input.View()
 }
 
+// Height returns the number of lines reserved for the search input and filter chips.
+func (m Model) Height() int {
+	const inputHeight = 3
+	if len(m.kinds) > 0 {
+		return inputHeight + 1
+	}
+	return inputHeight
+}
+
+// chipsView renders the match count of each kind as a chip, with the selected filter
+// highlighted, or "" before any search has matches.
+func (m Model) chipsView() string {
+	if len(m.kinds) == 0 {
+		return ""
+	}
+	chip := func(kind, label string) string {
+		if kind == m.kind {
+			return m.styles.ActiveSubTab.Render(label)
+		}
+		return m.styles.InactiveTab.Render(label)
+	}
+	chips := []string{chip("", fmt.Sprintf("All %d", m.total))}
+	for _, count := range m.kinds {
+		chips = append(chips, chip(count.Term, fmt.Sprintf("%s %d", count.Term, count.Count)))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, chips...)
+}
+
 func (m Model) HelpBindings() []key.Binding {
 	return []key.Binding{
 		m.keymap.SearchSubmit,
 		m.keymap.SearchClear,
+		m.keymap.NextSearchFilter,
+		m.keymap.PrevSearchFilter,
 		m.keymap.NextGQLKind,
 		m.keymap.PrevGQLKind,
 		m.keymap.Quit,
@@ -91,9 +164,15 @@ func (m Model) HandleMsg(msg tea.Msg) (Model, tea.Cmd) {
 			query := m.input.Value()
 			if query != "" {
 				m.input = m.input.Blur()
-				return m, m.executeSearch(query)
+				m.query = query
+				m.kind = ""
+				return m, m.executeSearch(query, "")
 			}
 			return m, nil
+		case key.Matches(msg, m.keymap.NextSearchFilter):
+			return m.cycleKindFilter(1)
+		case key.Matches(msg, m.keymap.PrevSearchFilter):
+			return m.cycleKindFilter(-1)
 		case key.Matches(msg, m.keymap.SearchClear):
 			m.input = m.input.SetValue("")
 			return m, nil
@@ -106,28 +185,54 @@ func (m Model) HandleMsg(msg tea.Msg) (Model, tea.Cmd) {
 	return m, nil
 }
 
-// executeSearch returns an async cmd that runs the search and emits ResultsReadyMsg.
+// cycleKindFilter selects the next (step 1) or previous (step -1) filter chip, where the
+// first chip shows all kinds, and reruns the submitted query with the selected filter.
+func (m Model) cycleKindFilter(step int) (Model, tea.Cmd) {
+	if len(m.kinds) == 0 {
+		return m, nil
+	}
+	filters := make([]string, 0, len(m.kinds)+1)
+	filters = append(filters, "")
+	current := 0
+	for i, count := range m.kinds {
+		filters = append(filters, count.Term)
+		if count.Term == m.kind {
+			current = i + 1
+		}
+	}
+	m.kind = filters[(current+step+len(filters))%len(filters)]
+	return m, m.executeSearch(m.query, m.kind)
+}
+
+// executeSearch returns an async cmd that runs the search, filtered by kind if non-empty,
+// and emits ResultsReadyMsg.
 // Schema is captured at call time so results convert against the correct schema version.
-func (m Model) executeSearch(query string) tea.Cmd {
-	if query == "" || m.schemaID == "" || m.baseDir == "" {
+func (m Model) executeSearch(query, kind string) tea.Cmd {
+	if query == "" || m.schemaID == "" || m.search == nil {
 		return nil
 	}
-	baseDir := m.baseDir
+	search := m.search
 	schemaID := m.schemaID
 	schema := m.schema
 	return func() tea.Msg {
-		searcher := gosearch.NewSearcher(baseDir)
-		defer searcher.Close()
-
-		results, err := searcher.Search(schemaID, query, 50)
+		filteredQuery := query
+		if kind != "" {
+			filteredQuery = "+kind:" + kind + " " + query
+		}
+		response, err := search(schemaID, filteredQuery, resultLimit)
 		if err != nil {
-			return ResultsReadyMsg{Items: nil}
+			return ResultsReadyMsg{Items: nil, Kind: kind}
 		}
 
 		var items []components.ListItem
 		if schema != nil {
-			items = adapters.AdaptSearchResults(results, schema)
+			items = adapters.AdaptSearchResults(response.Results, schema)
+		}
+		msg := ResultsReadyMsg{Items: items, Kind: kind}
+		if kind == "" {
+			msg.Total = response.Total
+			msg.Kinds = response.Kinds
 		}
-		return ResultsReadyMsg{Items: items}
+		return msg
 	}
 }
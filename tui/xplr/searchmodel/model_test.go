@@ -1,10 +1,12 @@
 package searchmodel_test
 
 import (
+	"strings"
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/matryer/is"
+	"github.com/tonysyu/gqlxp/search"
 	"github.com/tonysyu/gqlxp/tui/config"
 	"github.com/tonysyu/gqlxp/tui/xplr/searchmodel"
 )
@@ -88,10 +90,51 @@ func TestSetContext(t *testing.T) {
 	is.True(!m.IsFocused())
 }
 
-func TestSetBaseDir(t *testing.T) {
+func TestSetSearchFunc(t *testing.T) {
 	is := is.New(t)
 	m := newTestModel()
-	m = m.SetBaseDir("/tmp/indexes")
+	m = m.SetSearchFunc(func(schemaID, query string, limit int) (*search.SearchResponse, error) {
+		return nil, nil
+	})
 	// Just verify it compiles and doesn't panic
 	is.True(!m.IsFocused())
 }
+
+func TestKindFilterChips(t *testing.T) {
+	is := is.New(t)
+	var queries []string
+	m, _ := newTestModel().Focus()
+	m = m.SetContext(nil, "my-schema-id")
+	m = m.SetSearchFunc(func(schemaID, query string, limit int) (*search.SearchResponse, error) {
+		queries = append(queries, query)
+		return &search.SearchResponse{
+			Total: 5,
+			Kinds: []search.FacetCount{{Term: "ObjectField", Count: 3}, {Term: "Query", Count: 2}},
+		}, nil
+	})
+
+	// Submitting a query shows its match counts as chips
+	for _, r := range "user" {
+		m, _ = m.HandleMsg(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m, cmd := m.HandleMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = m.ReceiveResults(cmd().(searchmodel.ResultsReadyMsg))
+	is.Equal(queries, []string{"user"})
+	is.True(strings.Contains(m.View(), "All 5"))
+	is.True(strings.Contains(m.View(), "ObjectField 3"))
+	is.Equal(m.KindFilter(), "")
+
+	// Cycling chips reruns the query filtered by kind, keeping the counts of all matches
+	m, _ = m.Focus()
+	m, cmd = m.HandleMsg(tea.KeyPressMsg{Code: tea.KeyTab})
+	msg := cmd().(searchmodel.ResultsReadyMsg)
+	is.Equal(msg.Kind, "ObjectField")
+	is.Equal(queries[1], "+kind:ObjectField user")
+	m = m.ReceiveResults(msg)
+	is.Equal(m.KindFilter(), "ObjectField")
+	is.True(strings.Contains(m.View(), "Query 2"))
+
+	// Cycling back past the first kind selects all kinds
+	m, _ = m.HandleMsg(tea.KeyPressMsg{Code: tea.KeyTab, Mod: tea.ModShift})
+	is.Equal(m.KindFilter(), "")
+}
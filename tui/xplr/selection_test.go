@@ -6,6 +6,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/matryer/is"
 	"github.com/tonysyu/gqlxp/tui/adapters"
+	"github.com/tonysyu/gqlxp/tui/xplr/components"
 	"github.com/tonysyu/gqlxp/tui/xplr/navigation"
 )
 
@@ -64,3 +65,45 @@ func TestApplySelection_FieldSelection(t *testing.T) {
 	t.Logf("Breadcrumbs: %v", breadcrumbs)
 	is.True(len(breadcrumbs) > 0) // breadcrumbs should be set
 }
+
+func TestSchemaLoadedMsg_ReloadKeepsSelection(t *testing.T) {
+	is := is.New(t)
+	schema, err := adapters.ParseSchemaString(testSchema)
+	is.NoErr(err)
+
+	m := New(schema)
+	m.SchemaID = "test"
+	m.HasLibraryData = true
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.ApplySelection(SelectionTarget{TypeName: "User", FieldName: "name"})
+	is.Equal(m.nav.Breadcrumbs(), []string{"User"})
+
+	// The source gained a type and a field
+	reloaded, err := adapters.ParseSchemaString(testSchema + `
+		type Team { id: ID! }
+		extend type User { team: Team }
+	`)
+	is.NoErr(err)
+	m, _ = m.Update(SchemaLoadedMsg{Schema: reloaded, SchemaID: "test", HasLibraryData: true})
+
+	is.Equal(m.CurrentKind(), string(navigation.ObjectKind))
+	is.Equal(m.nav.Breadcrumbs(), []string{"User"})
+	item, ok := m.nav.CurrentPanel().SelectedItem().(components.ListItem)
+	is.True(ok)
+	is.Equal(item.RefName(), "name")
+}
+
+func TestSchemaLoadedMsg_OtherSchemaResetsSelection(t *testing.T) {
+	is := is.New(t)
+	schema, err := adapters.ParseSchemaString(testSchema)
+	is.NoErr(err)
+
+	m := New(schema)
+	m.SchemaID = "test"
+	m.HasLibraryData = true
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.ApplySelection(SelectionTarget{TypeName: "User", FieldName: "name"})
+
+	m, _ = m.Update(SchemaLoadedMsg{Schema: schema, SchemaID: "other", HasLibraryData: true})
+	is.Equal(len(m.nav.Breadcrumbs()), 0)
+}
@@ -16,6 +16,10 @@ func (m Model) View() string {
 		return m.commandPalette.View()
 	case xplrOverlayView:
 		return m.overlay.View()
+	case xplrArgFormView:
+		return m.queryBuilder.FormView()
+	case xplrResponseView:
+		return m.responseView.View()
 	}
 
 	var views []string
@@ -25,6 +29,9 @@ func (m Model) View() string {
 	if m.nav.NextPanel() != nil {
 		views = append(views, m.nav.NextPanel().View())
 	}
+	if m.queryBuilder.IsVisible() && len(views) > 0 {
+		views = append(views, m.queryBuilder.View(m.queryBuilderWidth(), lipgloss.Height(views[0])))
+	}
 
 	navbar := m.renderGQLKindNavbar()
 	breadcrumbs := m.renderBreadcrumbs()
@@ -51,6 +58,10 @@ func (m Model) helpBindings() []key.Binding {
 		// Only display SearchFocus key when viewing SearchKind, but not in searchFocused state
 		helpBindings = append(helpBindings, m.keymap.SearchFocus)
 	}
+	if kind := m.nav.CurrentKind(); kind == navigation.QueryKind || kind == navigation.MutationKind {
+		// Query builder selections are only possible when browsing from a root type
+		helpBindings = append(helpBindings, m.keymap.ToggleSelection)
+	}
 	return append(
 		helpBindings,
 		m.keymap.NextPanel,
@@ -15,4 +15,5 @@ var (
 	ColorDimIndigo    = lipgloss.Color("62")  // indigo, slate_blue
 	ColorBrightIndigo = lipgloss.Color("57")  // electric_indigo
 	ColorDimMagenta   = lipgloss.Color("170") // orchid (pink/purple)
+	ColorSoftRed      = lipgloss.Color("167") // indian_red
 )